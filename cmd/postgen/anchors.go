@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+type anchorsCommand struct{}
+
+type anchorsPinCommand struct {
+	previewFlags
+	All    bool `long:"all" description:"pin the headings of every post and draft"`
+	DryRun bool `short:"n" long:"dry-run" description:"print the files that would change without writing them"`
+	Args   struct {
+		Post postName `positional-arg-name:"post" description:"slug or file name of the post or draft"`
+	} `positional-args:"yes"`
+}
+
+func (c *anchorsPinCommand) Execute(args []string) error {
+	if c.All == (c.Args.Post != "") {
+		return usagef("expected either a post or --all")
+	}
+	s, _, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	g := s.generator(time.UTC)
+	var files []string
+	if c.All {
+		if files, err = g.Files(); err != nil {
+			return err
+		}
+	} else {
+		p, err := g.Find(string(c.Args.Post))
+		if err != nil {
+			return err
+		}
+		files = []string{p}
+	}
+	changes := []postgen.Change{}
+	for _, p := range files {
+		ch, err := g.PlanPinAnchors(p)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(ch.OldContent, ch.NewContent) {
+			changes = append(changes, ch)
+		}
+	}
+	if c.DiffOnly {
+		return printDiffs(s, changes, false)
+	}
+	if !c.DryRun {
+		if err := c.confirmChanges(s, changes); err != nil {
+			return err
+		}
+		if err := g.WriteChanges(changes); err != nil {
+			return err
+		}
+	}
+	return printChanges(s, changes, c.DryRun)
+}
+
+type anchorsCheckCommand struct {
+	Args struct {
+		Files []string `positional-arg-name:"files" description:"files, glob patterns or slugs (defaults to every post and draft)"`
+	} `positional-args:"yes"`
+}
+
+func (c *anchorsCheckCommand) Execute(args []string) error {
+	s, _, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	g := s.generator(time.UTC)
+	files, err := s.files(g, c.Args.Files)
+	if err != nil {
+		return err
+	}
+	drifts, err := g.CheckAnchors(files)
+	if err != nil {
+		return err
+	}
+	if opts.JSON {
+		out := []map[string]interface{}{}
+		for _, d := range drifts {
+			out = append(out, map[string]interface{}{"file": rel(s.path(d.Path)), "line": d.Line, "heading": d.Text, "id": d.ID, "generated": d.Generated})
+		}
+		return printJSON(out)
+	}
+	for _, d := range drifts {
+		d.Path = rel(s.path(d.Path))
+		fmt.Println(d)
+	}
+	if len(drifts) > 0 {
+		infof("%d heading(s) no longer match their pinned id, which is fine as long as the id stays", len(drifts))
+	}
+	return nil
+}
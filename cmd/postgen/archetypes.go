@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+type archetypesCommand struct{}
+
+func (c *archetypesCommand) Execute(args []string) error {
+	s, _, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	archetypes, err := readArchetypes(s)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(archetypes))
+	for name := range archetypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if opts.JSON {
+		out := []map[string]string{}
+		for _, name := range names {
+			out = append(out, map[string]string{"name": name, "file": rel(s.path(archetypes[name]))})
+		}
+		return printJSON(out)
+	}
+	for _, name := range names {
+		fmt.Printf("%s\t%s\n", name, rel(s.path(archetypes[name])))
+	}
+	return nil
+}
+
+// readArchetypes returns the files of the site's _archetypes folder by
+// archetype name, their file name without extension, or nothing when the
+// site has no such folder.
+func readArchetypes(s site) (map[string]string, error) {
+	dir := path.Join(s.sourceDir(), archetypesDir)
+	entries, err := os.ReadDir(s.path(dir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading folder %s: %w", dir, err)
+	}
+	archetypes := make(map[string]string)
+	for _, e := range entries {
+		if !e.IsDir() && postgen.IsPostFile(e.Name()) {
+			archetypes[postgen.TrimExt(e.Name())] = path.Join(dir, e.Name())
+		}
+	}
+	return archetypes, nil
+}
+
+// loadArchetype parses the archetype name as a template, naming it after
+// its file so errors point at the right place.
+func loadArchetype(s site, name string) (*template.Template, error) {
+	archetypes, err := readArchetypes(s)
+	if err != nil {
+		return nil, err
+	}
+	file, ok := archetypes[name]
+	if !ok {
+		if len(archetypes) == 0 {
+			return nil, usagef("unknown archetype %s: %s has none", name, path.Join(s.sourceDir(), archetypesDir))
+		}
+		names := make([]string, 0, len(archetypes))
+		for n := range archetypes {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, usagef("unknown archetype %s, expected one of %s", name, strings.Join(names, ", "))
+	}
+	b, err := os.ReadFile(s.path(file))
+	if err != nil {
+		return nil, fmt.Errorf("reading archetype %s: %w", file, err)
+	}
+	return postgen.ParseTemplate(file, string(b))
+}
+
+// flagKeys maps front matter keys of new posts to the long names of the
+// top-level flags setting them.
+var flagKeys = map[string]string{
+	"title":         "title",
+	"description":   "description",
+	"canonical_url": "canonical-url",
+	"permalink":     "permalink",
+	"author":        "author",
+	"lang":          "lang",
+	"categories":    "category",
+	"tags":          "tags",
+	"series":        "series",
+	"series_part":   "series",
+	"date":          "date",
+	"published":     "draft",
+	"image":         "cover",
+}
+
+// explicitKeys returns the front matter keys of a new post that a flag or
+// environment variable set, which its archetype does not override.
+func explicitKeys() map[string]bool {
+	keys := make(map[string]bool)
+	for key, name := range flagKeys {
+		if optionSource(name) != "" {
+			keys[key] = true
+		}
+	}
+	return keys
+}
+
+// archetypeName is an argument naming an archetype, completed with those
+// of the site.
+type archetypeName string
+
+func (archetypeName) Complete(match string) []flags.Completion {
+	s, _, _, err := loadSite()
+	if err != nil {
+		return nil
+	}
+	archetypes, err := readArchetypes(s)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for name := range archetypes {
+		names = append(names, name)
+	}
+	return completions(match, names)
+}
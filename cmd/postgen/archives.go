@@ -0,0 +1,63 @@
+package main
+
+import (
+	"path"
+	"time"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+type archivesCommand struct {
+	Monthly bool `long:"monthly" description:"also write a page per month"`
+	DryRun  bool `short:"n" long:"dry-run" description:"list the pages that would be written or deleted without touching them"`
+}
+
+func (c *archivesCommand) Execute(args []string) error {
+	s, cfg, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	dir, err := s.name(s.path(cfg.ArchivesDir))
+	if cfg.ArchivesDir == "" {
+		dir, err = path.Join(s.sourceDir(), "archives"), nil
+	}
+	if err != nil {
+		return err
+	}
+	layout := cfg.ArchiveLayout
+	if layout == "" {
+		layout = "page"
+	}
+	g := s.generator(time.UTC)
+	plan, err := g.PlanArchives(postgen.ArchiveOptions{Dir: dir, Layout: layout, Monthly: c.Monthly})
+	if err != nil {
+		return err
+	}
+	if !c.DryRun {
+		if err := g.ApplyArchives(plan); err != nil {
+			return err
+		}
+	}
+	written, deleted := []string{}, []string{}
+	for _, ch := range plan.Write {
+		written = append(written, rel(s.path(ch.Path)))
+	}
+	for _, p := range plan.Delete {
+		deleted = append(deleted, rel(s.path(p)))
+	}
+	if opts.JSON {
+		return printJSON(map[string]interface{}{"written": written, "deleted": deleted, "unchanged": plan.Unchanged, "dryRun": c.DryRun})
+	}
+	writeVerb, deleteVerb := "wrote", "deleted"
+	if c.DryRun {
+		writeVerb, deleteVerb = "would write", "would delete"
+	}
+	for _, p := range written {
+		infof("%s %s", writeVerb, p)
+	}
+	for _, p := range deleted {
+		infof("%s %s", deleteVerb, p)
+	}
+	infof("%d page(s) unchanged", plan.Unchanged)
+	return nil
+}
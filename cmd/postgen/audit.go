@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+type auditCommand struct {
+	previewFlags
+	OlderThan    string    `long:"older-than" default:"2y" description:"only list the posts older than this, such as 2y, 6w or 90d"`
+	Tag          []tagName `long:"tag" description:"only list the posts with this tag or category (repeatable)"`
+	PageviewsCSV string    `long:"pageviews-csv" description:"CSV file of page views, such as an analytics export, each row a URL, path or slug and its views"`
+	MarkOutdated bool      `long:"mark-outdated" description:"set outdated: true on the posts listed"`
+	MarkReviewed bool      `long:"mark-reviewed" description:"set reviewed_at to today's date on the posts listed"`
+	DryRun       bool      `short:"n" long:"dry-run" description:"with --mark-outdated or --mark-reviewed, list the files that would change without writing them"`
+}
+
+// auditJSON is the --json form of an audited post.
+type auditJSON struct {
+	File       string `json:"file"`
+	Title      string `json:"title"`
+	Date       string `json:"date"`
+	AgeDays    int    `json:"ageDays"`
+	Pageviews  *int   `json:"pageviews,omitempty"`
+	ReviewedAt string `json:"reviewedAt,omitempty"`
+	Outdated   bool   `json:"outdated"`
+}
+
+func (c *auditCommand) Execute(args []string) error {
+	if c.MarkOutdated && c.MarkReviewed {
+		return usagef("--mark-outdated cannot be combined with --mark-reviewed")
+	}
+	age, err := parseAge(c.OlderThan)
+	if err != nil {
+		return err
+	}
+	s, cfg, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	loc, err := location(s, cfg)
+	if err != nil {
+		return err
+	}
+	g := s.generator(loc)
+	auditOpts := postgen.AuditOptions{Before: g.Now().Add(-age), Permalink: permalinkSetting(s, cfg)}
+	for _, t := range c.Tag {
+		auditOpts.Tags = append(auditOpts.Tags, string(t))
+	}
+	if c.PageviewsCSV != "" {
+		f, err := os.Open(c.PageviewsCSV)
+		if err != nil {
+			return fmt.Errorf("reading file %s: %w", c.PageviewsCSV, err)
+		}
+		auditOpts.Pageviews, err = postgen.ParsePageviews(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("reading file %s: %w", c.PageviewsCSV, err)
+		}
+	}
+	audited, bad, err := g.Audit(auditOpts)
+	if err != nil {
+		return err
+	}
+
+	if c.MarkOutdated || c.MarkReviewed {
+		files := make([]string, len(audited))
+		for i, a := range audited {
+			files[i] = a.Path
+		}
+		key, value := "outdated", "true"
+		if c.MarkReviewed {
+			key, value = "reviewed_at", g.Now().Format(postgen.FileDateLayout)
+		}
+		changes, err := g.SetKey(files, key, value, false)
+		if err != nil {
+			return err
+		}
+		if c.DiffOnly {
+			return printDiffs(s, changes, false)
+		}
+		if !c.DryRun {
+			if err := c.confirmChanges(s, changes); err != nil {
+				return err
+			}
+			if err := g.WriteChanges(changes); err != nil {
+				return err
+			}
+		}
+		if err := printChanges(s, changes, c.DryRun); err != nil {
+			return err
+		}
+		return reportBad(s, bad)
+	}
+
+	now := g.Now()
+	out := []auditJSON{}
+	for _, a := range audited {
+		j := auditJSON{
+			File:     rel(s.path(a.Path)),
+			Title:    a.Meta.Title,
+			Date:     a.Date.Format(postgen.FileDateLayout),
+			AgeDays:  int(now.Sub(a.Date).Hours() / 24),
+			Outdated: a.Meta.Outdated,
+		}
+		if a.Pageviews >= 0 {
+			j.Pageviews = &a.Pageviews
+		}
+		if !a.Meta.ReviewedAt.IsZero() {
+			j.ReviewedAt = a.Meta.ReviewedAt.Format(postgen.FileDateLayout)
+		}
+		out = append(out, j)
+	}
+	if opts.JSON {
+		if err := printJSON(out); err != nil {
+			return err
+		}
+		return reportBad(s, bad)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	header := "DATE\tAGE\tREVIEWED\tOUTDATED\tTITLE\tFILE"
+	if auditOpts.Pageviews != nil {
+		header = "DATE\tAGE\tVIEWS\tREVIEWED\tOUTDATED\tTITLE\tFILE"
+	}
+	fmt.Fprintln(w, header)
+	for _, j := range out {
+		views := ""
+		if auditOpts.Pageviews != nil {
+			views = "-\t"
+			if j.Pageviews != nil {
+				views = strconv.Itoa(*j.Pageviews) + "\t"
+			}
+		}
+		reviewed, outdated := "-", "-"
+		if j.ReviewedAt != "" {
+			reviewed = j.ReviewedAt
+		}
+		if j.Outdated {
+			outdated = "yes"
+		}
+		fmt.Fprintf(w, "%s\t%dd\t%s%s\t%s\t%s\t%s\n", j.Date, j.AgeDays, views, reviewed, outdated, j.Title, j.File)
+	}
+	w.Flush()
+	return reportBad(s, bad)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"path"
+	"time"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+type categoriesRenameCommand struct {
+	previewFlags
+	DryRun bool `short:"n" long:"dry-run" description:"list the files that would change without writing them"`
+	Args   struct {
+		From categoryName `positional-arg-name:"from" description:"category to rename"`
+		To   string       `positional-arg-name:"to" description:"new name, merged with an existing category"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+// renamedJSON is a file renamed, in --json output.
+type renamedJSON struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+func (c *categoriesRenameCommand) Execute(args []string) error {
+	s, _, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	g := s.generator(time.UTC)
+	r, err := g.PlanCategoryRename(string(c.Args.From), c.Args.To, path.Join(s.sourceDir(), "_data", "categories.yml"))
+	if err != nil {
+		return err
+	}
+	if c.DiffOnly {
+		return printDiffs(s, categoryChanges(r), false)
+	}
+	if !c.DryRun {
+		if err := c.confirmChanges(s, categoryChanges(r)); err != nil {
+			return err
+		}
+		if err := g.ApplyCategoryRename(r); err != nil {
+			return err
+		}
+	}
+	for _, w := range r.Warnings {
+		warnf("%s", w)
+	}
+
+	files, renamed := []string{}, []renamedJSON{}
+	for _, ch := range r.Posts {
+		files = append(files, rel(s.path(ch.Path)))
+	}
+	if r.Data != nil {
+		files = append(files, rel(s.path(r.Data.Path)))
+	}
+	for _, p := range r.Pages {
+		if p.OldPath == p.NewPath {
+			files = append(files, rel(s.path(p.OldPath)))
+		} else {
+			renamed = append(renamed, renamedJSON{rel(s.path(p.OldPath)), rel(s.path(p.NewPath))})
+		}
+	}
+	if opts.JSON {
+		return printJSON(map[string]interface{}{"files": files, "renamed": renamed, "dryRun": c.DryRun})
+	}
+	verb, summary := "updated", "updated"
+	rename := "renamed"
+	if c.DryRun {
+		verb, summary, rename = "would update", "would be updated", "would rename"
+	}
+	for _, f := range files {
+		infof("%s %s", verb, f)
+	}
+	for _, p := range renamed {
+		infof("%s %s -> %s", rename, p.From, p.To)
+	}
+	infof("%d file(s) %s", len(files)+len(renamed), summary)
+	return nil
+}
+
+// categoryChanges returns the files r rewrites as changes, the pages it
+// renames under their old path.
+func categoryChanges(r postgen.CategoryRename) []postgen.Change {
+	var changes []postgen.Change
+	for _, ch := range r.Posts {
+		changes = append(changes, ch.Change)
+	}
+	if r.Data != nil {
+		changes = append(changes, *r.Data)
+	}
+	for _, p := range r.Pages {
+		changes = append(changes, postgen.Change{Path: p.OldPath, OldContent: p.OldContent, NewContent: p.NewContent})
+	}
+	return changes
+}
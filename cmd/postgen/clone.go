@@ -0,0 +1,54 @@
+package main
+
+import (
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+type cloneCommand struct {
+	Title      string `short:"t" long:"title" description:"title of the new post" required:"true"`
+	Slug       string `short:"s" long:"slug" description:"slug of the new post (defaults to one generated from the title)"`
+	Draft      bool   `long:"draft" description:"create the clone as a draft in _drafts"`
+	CopyImages bool   `long:"copy-images" description:"copy the source post's images into the new images folder"`
+	DryRun     bool   `short:"n" long:"dry-run" description:"print what would be created without writing anything"`
+	Args       struct {
+		Slug postName `positional-arg-name:"existing-post" description:"slug or file name of the post or draft to clone"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+func (c *cloneCommand) Execute(args []string) error {
+	slug, err := postSlug(c.Slug, c.Title)
+	if err != nil {
+		return err
+	}
+	s, cfg, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	loc, err := location(s, cfg)
+	if err != nil {
+		return err
+	}
+	g := s.generator(loc)
+	g.Force = opts.Force
+	g.AllowDuplicateSlug = opts.AllowDuplicateSlug
+	source, err := g.Find(string(c.Args.Slug))
+	if err != nil {
+		return err
+	}
+	p := postgen.Post{Title: c.Title, Slug: slug, Draft: c.Draft}
+	if c.DryRun {
+		r, err := g.PlanClone(source, p, c.CopyImages)
+		if err != nil {
+			return err
+		}
+		if err := printPlan(s, r); err != nil {
+			return err
+		}
+		return g.Collision(r)
+	}
+	r, err := g.Clone(source, p, c.CopyImages)
+	if err != nil {
+		return err
+	}
+	return printResult(s, "created", r)
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+// The completion scripts ask postgen itself for the candidates, through
+// the GO_FLAGS_COMPLETION protocol of go-flags, so that they follow the
+// commands and flags of the binary and the posts of the current site.
+var completionScripts = map[string]string{
+	"bash": `_postgen() {
+    local IFS=$'\n'
+    COMPREPLY=($(GO_FLAGS_COMPLETION=1 "${COMP_WORDS[0]}" "${COMP_WORDS[@]:1:$COMP_CWORD}" 2>/dev/null))
+    return 0
+}
+complete -o default -F _postgen postgen
+`,
+	"zsh": `#compdef postgen
+_postgen() {
+    local -a completions
+    completions=(${(f)"$(GO_FLAGS_COMPLETION=1 ${words[1]} "${(@)words[2,CURRENT]}" 2>/dev/null)"})
+    if (( ${#completions} )); then
+        compadd -Q -a completions
+    else
+        _files
+    fi
+}
+compdef _postgen postgen
+`,
+	"fish": `function __postgen_complete
+    set -l args (commandline -opc)
+    set -e args[1]
+    env GO_FLAGS_COMPLETION=1 postgen $args (commandline -ct) 2>/dev/null
+end
+complete -c postgen -f -a '(__postgen_complete)'
+`,
+}
+
+type completionCommand struct {
+	Args struct {
+		Shell string `positional-arg-name:"shell" description:"bash, zsh or fish"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+func (c *completionCommand) Execute(args []string) error {
+	script, ok := completionScripts[c.Args.Shell]
+	if !ok {
+		return usagef("unsupported shell %s: expected bash, zsh or fish", c.Args.Shell)
+	}
+	fmt.Print(script)
+	return nil
+}
+
+// postName is an argument naming an existing post or draft, completed
+// with their slugs.
+type postName string
+
+func (postName) Complete(match string) []flags.Completion {
+	return completeFiles(match, func(*postgen.Generator, string) bool { return true })
+}
+
+// draftName is an argument naming an existing draft, completed with
+// their slugs.
+type draftName string
+
+func (draftName) Complete(match string) []flags.Completion {
+	return completeFiles(match, (*postgen.Generator).IsDraft)
+}
+
+// categoryName and tagName are completed with the categories and tags
+// used across posts.
+type (
+	categoryName string
+	tagName      string
+)
+
+func (categoryName) Complete(match string) []flags.Completion {
+	return completeTerms(match, func(m postgen.Meta) []string { return m.Categories })
+}
+
+func (tagName) Complete(match string) []flags.Completion {
+	return completeTerms(match, func(m postgen.Meta) []string { return m.Tags })
+}
+
+// completeFiles returns the slugs starting with match of the posts and
+// drafts keep accepts. Like the other completers it returns nothing,
+// rather than failing, outside a site.
+func completeFiles(match string, keep func(*postgen.Generator, string) bool) []flags.Completion {
+	s, _, _, err := loadSite()
+	if err != nil {
+		return nil
+	}
+	g := s.generator(time.UTC)
+	files, err := g.Files()
+	if err != nil {
+		return nil
+	}
+	var slugs []string
+	for _, p := range files {
+		if !keep(g, p) {
+			continue
+		}
+		slug := postgen.TrimExt(path.Base(p))
+		if _, s, ok := postgen.ParseFileName(path.Base(p)); ok && !g.IsDraft(p) {
+			slug = s
+		}
+		slugs = append(slugs, slug)
+	}
+	return completions(match, slugs)
+}
+
+func completeTerms(match string, terms func(postgen.Meta) []string) []flags.Completion {
+	s, _, _, err := loadSite()
+	if err != nil {
+		return nil
+	}
+	entries, _, err := s.generator(time.UTC).List()
+	if err != nil {
+		return nil
+	}
+	var values []string
+	for _, e := range entries {
+		values = append(values, terms(e.Meta)...)
+	}
+	return completions(match, values)
+}
+
+// completions returns the distinct values starting with match, sorted.
+func completions(match string, values []string) []flags.Completion {
+	sort.Strings(values)
+	var items []flags.Completion
+	for i, v := range values {
+		if strings.HasPrefix(v, match) && (i == 0 || v != values[i-1]) {
+			items = append(items, flags.Completion{Item: v})
+		}
+	}
+	return items
+}
+
+// stringSlice converts between slices of string types, such as the
+// completed option values and the []string the config holds.
+func stringSlice[T, U ~string](values []U) []T {
+	var out []T
+	for _, v := range values {
+		out = append(out, T(v))
+	}
+	return out
+}
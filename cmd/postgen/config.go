@@ -0,0 +1,383 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+	"gopkg.in/yaml.v3"
+)
+
+const configFileName = ".postgen.yml"
+
+// config holds the defaults read from .postgen.yml. Paths are relative
+// to the site root unless absolute.
+type config struct {
+	Layout     string   `yaml:"layout" json:"layout"`
+	Author     string   `yaml:"author" json:"author"`
+	Categories []string `yaml:"categories" json:"categories"`
+	Tags       []string `yaml:"tags" json:"tags"`
+	Timezone   string   `yaml:"timezone" json:"timezone"`
+	// Lang is the language of new posts; Languages, when set, the ones
+	// posts may be written in.
+	Lang      string   `yaml:"lang" json:"lang"`
+	Languages []string `yaml:"languages" json:"languages"`
+	Ext       string   `yaml:"ext" json:"ext"`
+	PostsDir  string   `yaml:"posts_dir" json:"posts_dir"`
+	DraftsDir string   `yaml:"drafts_dir" json:"drafts_dir"`
+	ImagesDir string   `yaml:"images_dir" json:"images_dir"`
+	// ArchivesDir and ArchiveLayout configure the pages written by
+	// archives.
+	ArchivesDir   string `yaml:"archives_dir" json:"archives_dir"`
+	ArchiveLayout string `yaml:"archive_layout" json:"archive_layout"`
+	// SeriesDir and SeriesLayout configure the pages written by series
+	// generate.
+	SeriesDir    string `yaml:"series_dir" json:"series_dir"`
+	SeriesLayout string `yaml:"series_layout" json:"series_layout"`
+	// WordsPerMinute is the reading speed used by readingtime.
+	WordsPerMinute int `yaml:"words_per_minute" json:"words_per_minute"`
+	// LinkCache, LinkCacheTTL and LinkIgnore configure links check
+	// --external: the file results are cached in, for how long, and the
+	// domains not checked.
+	LinkCache    string   `yaml:"link_cache" json:"link_cache"`
+	LinkCacheTTL string   `yaml:"link_cache_ttl" json:"link_cache_ttl"`
+	LinkIgnore   []string `yaml:"link_ignore" json:"link_ignore"`
+	// LintDisable names the lint rules not applied.
+	LintDisable []string `yaml:"lint_disable" json:"lint_disable"`
+	// PreflightSkip names the checks preflight does not run.
+	PreflightSkip []string `yaml:"preflight_skip" json:"preflight_skip"`
+	// FeaturedMax is the number of posts featured add keeps featured,
+	// DefaultFeaturedMax when zero.
+	FeaturedMax int `yaml:"featured_max" json:"featured_max"`
+	// TouchIgnore and TouchThreshold configure touch: the regular
+	// expression matching the subjects of commits not counted as updates,
+	// and how long after its date a post must be modified to count as
+	// updated.
+	TouchIgnore    string `yaml:"touch_ignore" json:"touch_ignore"`
+	TouchThreshold string `yaml:"touch_threshold" json:"touch_threshold"`
+	// Permalink is the permalink style or pattern of the posts without
+	// a permalink of their own (defaults to the one of _config.yml).
+	Permalink string `yaml:"permalink" json:"permalink"`
+	// ImageQuality and ImageMaxWidth configure --optimize: the JPEG
+	// quality photos are encoded at, and the width wider images are
+	// scaled down to.
+	ImageQuality  int `yaml:"image_quality" json:"image_quality"`
+	ImageMaxWidth int `yaml:"image_max_width" json:"image_max_width"`
+	// TitleLength and SlugLength are the lengths past which creating a
+	// post warns about its title or slug.
+	TitleLength int `yaml:"title_length" json:"title_length"`
+	SlugLength  int `yaml:"slug_length" json:"slug_length"`
+	// StopWords lists, by language such as en or pt, the words seo leaves
+	// out of its term counts on top of its own.
+	StopWords map[string][]string `yaml:"stop_words" json:"stop_words"`
+	// FrontMatter is the format the front matter of new posts is written
+	// in without --template: key order, quoting and list styles.
+	FrontMatter *postgen.HeaderFormat `yaml:"front_matter" json:"front_matter"`
+	// Schema lists the front matter keys posts may have, enforced by
+	// validate and when creating posts.
+	Schema *postgen.Schema `yaml:"schema" json:"schema"`
+}
+
+func defaultConfig() config {
+	return config{
+		Layout:    "post",
+		Ext:       "markdown",
+		PostsDir:  path.Join(docsDir, postsDir),
+		ImagesDir: path.Join(docsDir, imagesDir),
+	}
+}
+
+// loadConfig reads the config file from the site root or its docs
+// folder, whichever is found first, on top of the defaults. It returns
+// the path of the file used, or an empty string when there is none.
+func loadConfig(root string) (config, string, error) {
+	cfg := defaultConfig()
+	for _, file := range []string{
+		filepath.Join(root, configFileName),
+		filepath.Join(root, docsDir, configFileName),
+	} {
+		b, err := os.ReadFile(file)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return cfg, "", fmt.Errorf("reading config %s: %w", rel(file), err)
+		}
+		dec := yaml.NewDecoder(bytes.NewReader(b))
+		dec.KnownFields(true)
+		if err := dec.Decode(&cfg); err != nil && err != io.EOF {
+			return cfg, "", fmt.Errorf("parsing config %s: %w", rel(file), err)
+		}
+		return cfg, file, nil
+	}
+	return cfg, "", nil
+}
+
+// withFlags returns cfg with the values given on the command line taking
+// precedence over the ones from the config file.
+func (cfg config) withFlags(o options) config {
+	if o.Author != "" {
+		cfg.Author = o.Author
+	}
+	if len(o.Categories) > 0 {
+		cfg.Categories = stringSlice[string](o.Categories)
+	}
+	if len(o.Tags) > 0 {
+		cfg.Tags = stringSlice[string](o.Tags)
+	}
+	if o.Timezone != "" {
+		cfg.Timezone = o.Timezone
+	}
+	if o.Ext != "" {
+		cfg.Ext = o.Ext
+	}
+	if o.Lang != "" {
+		cfg.Lang = o.Lang
+	}
+	if o.PostsDir != "" {
+		cfg.PostsDir = o.PostsDir
+	}
+	if o.DraftsDir != "" {
+		cfg.DraftsDir = o.DraftsDir
+	}
+	if o.ImagesDir != "" {
+		cfg.ImagesDir = o.ImagesDir
+	}
+	return cfg
+}
+
+// checkLang returns an error when the config file lists the languages
+// posts may be written in and lang is not among them.
+func (cfg config) checkLang(lang string) error {
+	if cfg.Languages == nil || set(cfg.Languages)[lang] {
+		return nil
+	}
+	return fmt.Errorf("unknown language \"%s\", expected one of %s", lang, strings.Join(cfg.Languages, ", "))
+}
+
+// configFlags maps the config keys withFlags overrides to the long
+// names of their flags.
+var configFlags = map[string]string{
+	"author":     "author",
+	"categories": "category",
+	"tags":       "tags",
+	"timezone":   "timezone",
+	"ext":        "ext",
+	"lang":       "lang",
+	"posts_dir":  "posts-dir",
+	"drafts_dir": "drafts-dir",
+	"images_dir": "images-dir",
+}
+
+type configCommand struct {
+	Explain bool `long:"explain" description:"print each effective value and where it came from: a flag, a POSTGEN_* environment variable, the config file or the default, in that order of precedence, followed by the options of other commands set by their POSTGEN_<COMMAND>_<FLAG> variable"`
+}
+
+// explainedValue is an effective value and where it came from.
+type explainedValue struct {
+	Key    string      `json:"key"`
+	Value  interface{} `json:"value"`
+	Source string      `json:"source"`
+}
+
+func (c *configCommand) Execute(args []string) error {
+	_, cfg, file, err := loadSite()
+	if err != nil {
+		return err
+	}
+	if c.Explain {
+		values, err := explain(cfg, file)
+		if err != nil {
+			return err
+		}
+		if opts.JSON {
+			if file != "" {
+				file = rel(file)
+			}
+			return printJSON(struct {
+				File   string           `json:"file"`
+				Values []explainedValue `json:"values"`
+			}{file, values})
+		}
+		fmt.Println("# precedence: flag > POSTGEN_* environment variable > config file > default")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, v := range values {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", v.Key, formatValue(v.Value), v.Source)
+		}
+		return w.Flush()
+	}
+	if opts.JSON {
+		if file != "" {
+			file = rel(file)
+		}
+		cfg.Categories, cfg.Tags, cfg.LinkIgnore, cfg.LintDisable, cfg.PreflightSkip, cfg.Languages = nonNil(cfg.Categories), nonNil(cfg.Tags), nonNil(cfg.LinkIgnore), nonNil(cfg.LintDisable), nonNil(cfg.PreflightSkip), nonNil(cfg.Languages)
+		return printJSON(struct {
+			File   string `json:"file"`
+			Config config `json:"config"`
+		}{file, cfg})
+	}
+	if file == "" {
+		fmt.Println("# no config file found, showing defaults")
+	} else {
+		fmt.Printf("# %s\n", rel(file))
+	}
+	enc := yaml.NewEncoder(os.Stdout)
+	enc.SetIndent(2)
+	defer enc.Close()
+	return enc.Encode(cfg)
+}
+
+// explain returns the effective value of every config key, followed by
+// the top-level options not backing one that a flag or environment
+// variable set and the command options an environment variable set, each
+// with where it came from.
+func explain(cfg config, file string) ([]explainedValue, error) {
+	inFile := make(map[string]bool)
+	if file != "" {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("reading config %s: %w", rel(file), err)
+		}
+		var keys map[string]interface{}
+		if err := yaml.Unmarshal(b, &keys); err != nil {
+			return nil, fmt.Errorf("parsing config %s: %w", rel(file), err)
+		}
+		for k := range keys {
+			inFile[k] = true
+		}
+	}
+	var values []explainedValue
+	backed := make(map[string]bool)
+	v := reflect.ValueOf(cfg)
+	for i := 0; i < v.NumField(); i++ {
+		key := strings.Split(v.Type().Field(i).Tag.Get("yaml"), ",")[0]
+		source := "default"
+		if inFile[key] {
+			source = "config " + rel(file)
+		}
+		if name, ok := configFlags[key]; ok {
+			backed[name] = true
+			if s := optionSource(name); s != "" {
+				source = s
+			}
+		}
+		value := v.Field(i).Interface()
+		if f := v.Field(i); f.Kind() == reflect.Slice && f.IsNil() {
+			value = []string{}
+		}
+		values = append(values, explainedValue{key, value, source})
+	}
+	for _, group := range parser.Groups() {
+		for _, o := range group.Options() {
+			if backed[o.LongName] {
+				continue
+			}
+			if s := optionSource(o.LongName); s != "" {
+				values = append(values, explainedValue{"--" + o.LongName, o.Value(), s})
+			}
+		}
+	}
+	eachCommandOption(parser.Commands(), "", func(command string, o *flags.Option) {
+		if _, ok := os.LookupEnv(o.EnvDefaultKey); ok {
+			values = append(values, explainedValue{command + " --" + o.LongName, o.Value(), "env " + o.EnvDefaultKey})
+		}
+	})
+	return values, nil
+}
+
+// setCommandEnv gives the options of every command that have none a
+// POSTGEN_<COMMAND>_<FLAG> environment variable, such as
+// POSTGEN_LINKS_CHECK_TIMEOUT for links check --timeout, the values of
+// lists being separated by commas.
+func setCommandEnv(commands []*flags.Command) {
+	eachCommandOption(commands, "", func(command string, o *flags.Option) {
+		if o.EnvDefaultKey != "" {
+			return
+		}
+		o.EnvDefaultKey = envKey(command + " " + o.LongName)
+		if reflect.ValueOf(o.Value()).Kind() == reflect.Slice {
+			o.EnvDefaultDelim = ","
+		}
+	})
+}
+
+// eachCommandOption calls fn with the options having a long name of
+// commands and their subcommands, command being the space-separated
+// path of each command after prefix.
+func eachCommandOption(commands []*flags.Command, prefix string, fn func(command string, o *flags.Option)) {
+	for _, c := range commands {
+		command := strings.TrimSpace(prefix + " " + c.Name)
+		groups := []*flags.Group{c.Group}
+		for i := 0; i < len(groups); i++ {
+			for _, o := range groups[i].Options() {
+				if o.LongName != "" {
+					fn(command, o)
+				}
+			}
+			groups = append(groups, groups[i].Groups()...)
+		}
+		eachCommandOption(c.Commands(), command, fn)
+	}
+}
+
+// envKey returns the POSTGEN_* environment variable of name, its words
+// separated by spaces or dashes.
+func envKey(name string) string {
+	return "POSTGEN_" + strings.ToUpper(strings.NewReplacer(" ", "_", "-", "_").Replace(name))
+}
+
+// optionSource returns "flag --name" or "env POSTGEN_NAME" when the
+// top-level option name got a non-empty value that way, and an empty
+// string when it has its default.
+func optionSource(name string) string {
+	o := parser.FindOptionByLongName(name)
+	if o == nil {
+		return ""
+	}
+	if v := reflect.ValueOf(o.Value()); v.IsZero() || (v.Kind() == reflect.Slice && v.Len() == 0) {
+		return ""
+	}
+	if o.IsSet() && !o.IsSetDefault() {
+		return "flag --" + name
+	}
+	if key := o.EnvKeyWithNamespace(); key != "" {
+		if _, ok := os.LookupEnv(key); ok {
+			return "env " + key
+		}
+	}
+	return ""
+}
+
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		if v == "" {
+			return `""`
+		}
+		return v
+	case []string:
+		if len(v) == 0 {
+			return "[]"
+		}
+		return strings.Join(v, ",")
+	case *postgen.HeaderFormat:
+		if v == nil {
+			return "default"
+		}
+		return "custom"
+	case *postgen.Schema:
+		if v == nil {
+			return "none"
+		}
+		return fmt.Sprintf("%d key(s)", len(v.Keys))
+	}
+	return fmt.Sprint(v)
+}
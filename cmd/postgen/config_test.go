@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExplainPrecedence(t *testing.T) {
+	tests := []struct {
+		name string
+		// flag, env and config are the values given each way, if any.
+		flag, env, config string
+		wantValue         string
+		wantSource        string
+	}{
+		{name: "default", wantValue: "", wantSource: "default"},
+		{name: "config", config: "config", wantValue: "config", wantSource: "config .postgen.yml"},
+		{name: "env", env: "env", wantValue: "env", wantSource: "env POSTGEN_AUTHOR"},
+		{name: "flag", flag: "flag", wantValue: "flag", wantSource: "flag --author"},
+		{name: "env over config", env: "env", config: "config", wantValue: "env", wantSource: "env POSTGEN_AUTHOR"},
+		{name: "flag over config", flag: "flag", config: "config", wantValue: "flag", wantSource: "flag --author"},
+		{name: "flag over env", flag: "flag", env: "env", wantValue: "flag", wantSource: "flag --author"},
+		{name: "flag over env and config", flag: "flag", env: "env", config: "config", wantValue: "flag", wantSource: "flag --author"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := newTestSite(t, nil)
+			if tt.config != "" {
+				if err := os.WriteFile(filepath.Join(dir, configFileName), []byte("author: "+tt.config+"\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var env []string
+			if tt.env != "" {
+				env = append(env, "POSTGEN_AUTHOR="+tt.env)
+			}
+			args := []string{"--json"}
+			if tt.flag != "" {
+				args = append(args, "--author", tt.flag)
+			}
+			stdout, stderr, status := runPostgen(t, dir, env, append(args, "config", "--explain")...)
+			if status != 0 {
+				t.Fatalf("exit status %d: %s", status, stderr)
+			}
+			var out struct {
+				Values []explainedValue `json:"values"`
+			}
+			if err := json.Unmarshal([]byte(stdout), &out); err != nil {
+				t.Fatalf("parsing %s: %v", stdout, err)
+			}
+			for _, v := range out.Values {
+				if v.Key != "author" {
+					continue
+				}
+				if v.Value != tt.wantValue || v.Source != tt.wantSource {
+					t.Errorf("author = %v from %s, want %s from %s", v.Value, v.Source, tt.wantValue, tt.wantSource)
+				}
+				return
+			}
+			t.Errorf("author missing from:\n%s", stdout)
+		})
+	}
+}
+
+func TestOptionsReadEnv(t *testing.T) {
+	typ := reflect.TypeOf(options{})
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		long := f.Tag.Get("long")
+		if long == "" || long == "version" {
+			continue
+		}
+		if want := "POSTGEN_" + strings.ToUpper(strings.ReplaceAll(long, "-", "_")); f.Tag.Get("env") != want {
+			t.Errorf("--%s reads env %q, want %s", long, f.Tag.Get("env"), want)
+		}
+	}
+}
+
+func TestCommandOptionsReadEnv(t *testing.T) {
+	dir := newTestSite(t, nil)
+	env := []string{"POSTGEN_RELATED_TOP=7", "POSTGEN_LINT_DISABLE=nope,headings"}
+	stdout, stderr, status := runPostgen(t, dir, env, "--json", "config", "--explain")
+	if status != 0 {
+		t.Fatalf("exit status %d: %s", status, stderr)
+	}
+	var out struct {
+		Values []explainedValue `json:"values"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &out); err != nil {
+		t.Fatalf("parsing %s: %v", stdout, err)
+	}
+	want := map[string]string{
+		"related --top":  "7 from env POSTGEN_RELATED_TOP",
+		"lint --disable": "[nope headings] from env POSTGEN_LINT_DISABLE",
+	}
+	for _, v := range out.Values {
+		if w, ok := want[v.Key]; ok {
+			if got := fmt.Sprintf("%v from %s", v.Value, v.Source); got != w {
+				t.Errorf("%s = %s, want %s", v.Key, got, w)
+			}
+			delete(want, v.Key)
+		}
+	}
+	for key := range want {
+		t.Errorf("%s missing from:\n%s", key, stdout)
+	}
+
+	_, stderr, status = runPostgen(t, dir, env, "lint")
+	if status != exitUsage || !strings.Contains(stderr, `unknown lint rule "nope"`) {
+		t.Errorf("lint with POSTGEN_LINT_DISABLE: exit status %d: %s", status, stderr)
+	}
+}
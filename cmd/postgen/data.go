@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// readData returns the values the site's _data/<name>.yml defines,
+// sorted: the keys of a mapping, or the items of a list, those that are
+// mappings being named by their slug or name key. It returns nil when the
+// site has no such file, so that the checks using it are skipped.
+func readData(s site, name string) ([]string, error) {
+	file := filepath.Join(s.path(s.sourceDir()), "_data", name+".yml")
+	b, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s %s: %w", name, rel(file), err)
+	}
+	var data yaml.Node
+	if err := yaml.Unmarshal(b, &data); err != nil {
+		return nil, fmt.Errorf("parsing %s %s: %w", name, rel(file), err)
+	}
+	keys := []string{}
+	if len(data.Content) == 0 {
+		return keys, nil
+	}
+	switch root := data.Content[0]; root.Kind {
+	case yaml.MappingNode:
+		for i := 0; i < len(root.Content); i += 2 {
+			keys = append(keys, root.Content[i].Value)
+		}
+	case yaml.SequenceNode:
+		for _, item := range root.Content {
+			var named struct {
+				Slug string `yaml:"slug"`
+				Name string `yaml:"name"`
+			}
+			switch {
+			case item.Kind == yaml.ScalarNode:
+				keys = append(keys, item.Value)
+			case item.Decode(&named) == nil && named.Slug != "":
+				keys = append(keys, named.Slug)
+			case named.Name != "":
+				keys = append(keys, named.Name)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("parsing %s %s: expected a mapping or a list", name, rel(file))
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// readAuthors returns the author keys defined in the site's
+// _data/authors.yml, or nil when the site has no such file.
+func readAuthors(s site) ([]string, error) {
+	return readData(s, "authors")
+}
+
+// readCategories returns the categories defined in the site's
+// _data/categories.yml, or nil when the site has no such file.
+func readCategories(s site) ([]string, error) {
+	return readData(s, "categories")
+}
+
+// checkAuthor returns an error when the site defines its authors and
+// author is not one of them.
+func checkAuthor(s site, author string) error {
+	authors, err := readAuthors(s)
+	if err != nil || authors == nil {
+		return err
+	}
+	for _, a := range authors {
+		if a == author {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown author \"%s\", expected one of %s", author, strings.Join(authors, ", "))
+}
+
+// checkCategories returns an error when the site defines its categories
+// and one of categories is not among them.
+func checkCategories(s site, categories []string) error {
+	known, err := readCategories(s)
+	if err != nil || known == nil {
+		return err
+	}
+	for _, c := range categories {
+		if i := sort.SearchStrings(known, c); i == len(known) || known[i] != c {
+			return fmt.Errorf("unknown category \"%s\", expected one of %s", c, strings.Join(known, ", "))
+		}
+	}
+	return nil
+}
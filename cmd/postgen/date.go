@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// dateLayouts are the formats accepted by --date.
+var dateLayouts = []string{"2006-01-02 15:04", "2006-01-02"}
+
+// parseDate parses a --date value in loc.
+func parseDate(value string, loc *time.Location) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, usagef("invalid date \"%s\": expected YYYY-MM-DD or \"YYYY-MM-DD HH:MM\"", value)
+}
+
+// location returns the time zone posts are dated in: the configured one,
+// or else the one from Jekyll's _config.yml, or else UTC.
+func location(s site, cfg config) (*time.Location, error) {
+	name := cfg.Timezone
+	if name == "" {
+		name = readJekyllConfig(s).Timezone
+	}
+	if name == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, usageError{fmt.Errorf("loading timezone %s: %w", name, err)}
+	}
+	return loc, nil
+}
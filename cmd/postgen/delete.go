@@ -0,0 +1,52 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"time"
+)
+
+type deleteCommand struct {
+	Yes  bool `short:"y" long:"yes" description:"do not ask for confirmation"`
+	Args struct {
+		Slug postName `positional-arg-name:"slug" description:"slug or file name of the post or draft to delete"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+func (c *deleteCommand) Execute(args []string) error {
+	s, _, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	g := s.generator(time.UTC)
+	markdownPath, err := g.Find(string(c.Args.Slug))
+	if err != nil {
+		return err
+	}
+	if err := g.CanDelete(markdownPath); err != nil {
+		return err
+	}
+	question := fmt.Sprintf("delete %s", rel(s.path(markdownPath)))
+	if _, err := fs.Stat(g.FS, g.ImagesFolder(markdownPath)); err == nil {
+		question += fmt.Sprintf(" and %s", rel(s.path(g.ImagesFolder(markdownPath))))
+	}
+	if !c.Yes && !confirm(question+"?") {
+		return errors.New("aborted")
+	}
+	r, err := g.Delete(markdownPath)
+	if err != nil {
+		return err
+	}
+	deleted := []string{rel(s.path(r.MarkdownPath))}
+	if r.ImagesPath != "" {
+		deleted = append(deleted, rel(s.path(r.ImagesPath)))
+	}
+	if opts.JSON {
+		return printJSON(map[string][]string{"deleted": deleted})
+	}
+	for _, p := range deleted {
+		infof("deleted %s", p)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+type describeCommand struct {
+	previewFlags
+	Auto   bool `long:"auto" description:"write a description taken from the first paragraph of the body into each post lacking one (all of them with --force)"`
+	DryRun bool `short:"n" long:"dry-run" description:"print the descriptions that would be written without writing them"`
+	Args   struct {
+		Files []string `positional-arg-name:"files" description:"files, glob patterns or slugs (defaults to every post and draft)"`
+	} `positional-args:"yes"`
+}
+
+// descriptionJSON is the --json form of a postgen.DescriptionChange.
+type descriptionJSON struct {
+	File        string `json:"file"`
+	Description string `json:"description"`
+}
+
+func (c *describeCommand) Execute(args []string) error {
+	s, _, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	g := s.generator(time.UTC)
+	files, err := s.files(g, c.Args.Files)
+	if err != nil {
+		return err
+	}
+	if !c.Auto {
+		return listUndescribed(s, g, files)
+	}
+	changes, empty, err := g.Describe(files, opts.Force)
+	if err != nil {
+		return err
+	}
+	var writes []postgen.Change
+	for _, ch := range changes {
+		writes = append(writes, ch.Change)
+	}
+	if c.DiffOnly {
+		return printDiffs(s, writes, false)
+	}
+	if !c.DryRun {
+		if err := c.confirmChanges(s, writes); err != nil {
+			return err
+		}
+		if err := g.WriteChanges(writes); err != nil {
+			return err
+		}
+	}
+	for _, p := range empty {
+		warnf("%s has no paragraph to take a description from", rel(s.path(p)))
+	}
+	out := []descriptionJSON{}
+	for _, ch := range changes {
+		out = append(out, descriptionJSON{rel(s.path(ch.Path)), ch.Description})
+	}
+	if opts.JSON {
+		return printJSON(map[string]interface{}{"files": out, "dryRun": c.DryRun})
+	}
+	verb, summary := "updated", "updated"
+	if c.DryRun {
+		verb, summary = "would update", "would be updated"
+	}
+	for _, d := range out {
+		infof("%s %s\n  %s", verb, d.File, d.Description)
+	}
+	infof("%d file(s) %s", len(out), summary)
+	return nil
+}
+
+// listUndescribed prints the files without a description.
+func listUndescribed(s site, g *postgen.Generator, files []string) error {
+	undescribed, err := g.Undescribed(files)
+	if err != nil {
+		return err
+	}
+	missing := []string{}
+	for _, p := range undescribed {
+		missing = append(missing, rel(s.path(p)))
+	}
+	if opts.JSON {
+		return printJSON(map[string]interface{}{"missing": missing})
+	}
+	for _, p := range missing {
+		fmt.Println(p)
+	}
+	infof("%d file(s) without a description", len(missing))
+	return nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen/diff"
+)
+
+type doctorCommand struct {
+	Fix bool `long:"fix" description:"make each post agree with its file name, renaming images folders and rewriting dates and image references"`
+	Yes bool `short:"y" long:"yes" description:"do not ask for confirmation before applying the repairs"`
+}
+
+// inconsistencyJSON is the --json form of a postgen.Inconsistency.
+type inconsistencyJSON struct {
+	problemJSON
+	Fix     string `json:"fix,omitempty"`
+	Unfixed string `json:"unfixed,omitempty"`
+}
+
+// folderMoveJSON is the --json form of a postgen.FolderMove.
+type folderMoveJSON struct {
+	Folder    string `json:"folder"`
+	NewFolder string `json:"newFolder"`
+}
+
+// repairJSON is the --json form of a postgen.Repair.
+type repairJSON struct {
+	File    string           `json:"file"`
+	Folders []folderMoveJSON `json:"folders"`
+	Diff    string           `json:"diff"`
+}
+
+func (c *doctorCommand) Execute(args []string) error {
+	s, _, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	g := s.generator(time.UTC)
+	report, err := g.Doctor()
+	if err != nil {
+		return err
+	}
+	found, unfixed := []inconsistencyJSON{}, 0
+	for _, in := range report.Inconsistencies {
+		found = append(found, inconsistencyJSON{problemJSON{rel(s.path(in.Path)), in.Line, in.Field, in.Message}, in.Fix, in.Unfixed})
+		if in.Fix == "" {
+			unfixed++
+		}
+	}
+	repairs := []repairJSON{}
+	for _, r := range report.Repairs {
+		p := rel(s.path(r.Post.Path))
+		j := repairJSON{File: p, Folders: []folderMoveJSON{}, Diff: diff.Unified("a/"+p, "b/"+p, string(r.Post.OldContent), string(r.Post.NewContent), 3)}
+		for _, m := range r.Folders {
+			j.Folders = append(j.Folders, folderMoveJSON{rel(s.path(m.Folder)), rel(s.path(m.NewFolder))})
+		}
+		repairs = append(repairs, j)
+	}
+	if !opts.JSON {
+		for i, in := range report.Inconsistencies {
+			in.Path = found[i].File
+			fmt.Println(in)
+			if in.Fix != "" {
+				fmt.Printf("  fix: %s\n", in.Fix)
+			} else {
+				fmt.Printf("  not fixed: %s\n", in.Unfixed)
+			}
+		}
+		if c.Fix {
+			for _, r := range repairs {
+				for _, m := range r.Folders {
+					fmt.Printf("rename %s -> %s (%s)\n", m.Folder, m.NewFolder, r.File)
+				}
+				fmt.Print(r.Diff)
+			}
+		}
+	}
+	applied := c.Fix && len(report.Repairs) > 0
+	if applied {
+		if !c.Yes && !confirm(fmt.Sprintf("repair %d post(s)?", len(report.Repairs))) {
+			return errors.New("aborted")
+		}
+		for _, r := range report.Repairs {
+			if err := g.ApplyRepair(r); err != nil {
+				return err
+			}
+		}
+		infof("repaired %d post(s)", len(report.Repairs))
+	}
+	if opts.JSON {
+		if err := printJSON(map[string]interface{}{"inconsistencies": found, "repairs": repairs, "applied": applied}); err != nil {
+			return err
+		}
+	}
+	switch {
+	case c.Fix && unfixed > 0:
+		return fmt.Errorf("%d inconsistency(ies) left to fix by hand", unfixed)
+	case !c.Fix && len(found) > 0:
+		return fmt.Errorf("%d inconsistency(ies) found, %d can be fixed by %d repair(s) with --fix", len(found), len(found)-unfixed, len(report.Repairs))
+	}
+	return nil
+}
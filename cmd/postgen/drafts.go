@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+type draftsCommand struct {
+	Git     bool   `long:"git" description:"also show the date of the last commit changing each draft, and count its age from it"`
+	Stale   string `long:"stale" description:"only list the drafts older than this, such as 90d or 72h"`
+	Archive bool   `long:"archive" description:"move the drafts older than --stale into the drafts' archive folder"`
+	DryRun  bool   `short:"n" long:"dry-run" description:"with --archive, list the drafts that would be moved without moving them"`
+}
+
+// draftJSON is the --json form of a draft.
+type draftJSON struct {
+	File      string `json:"file"`
+	Title     string `json:"title"`
+	Modified  string `json:"modified"`
+	Committed string `json:"committed,omitempty"`
+	Words     int    `json:"words"`
+	AgeDays   int    `json:"ageDays"`
+}
+
+func (c *draftsCommand) Execute(args []string) error {
+	if c.Archive && c.Stale == "" {
+		return usagef("--archive needs --stale to tell which drafts to move")
+	}
+	var stale time.Duration
+	if c.Stale != "" {
+		var err error
+		if stale, err = parseAge(c.Stale); err != nil {
+			return err
+		}
+	}
+	s, _, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	g := s.generator(time.UTC)
+	drafts, bad, err := g.Drafts()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	type aged struct {
+		postgen.Draft
+		committed time.Time
+		since     time.Time
+	}
+	var listed []aged
+	for _, d := range drafts {
+		a := aged{Draft: d, since: d.Modified}
+		if c.Git {
+			if a.committed, err = gitLastModified(s.root, d.Path, nil); err != nil {
+				return err
+			}
+			if !a.committed.IsZero() {
+				a.since = a.committed
+			}
+		}
+		if stale > 0 && now.Sub(a.since) < stale {
+			continue
+		}
+		listed = append(listed, a)
+	}
+	sort.SliceStable(listed, func(i, j int) bool { return listed[i].since.Before(listed[j].since) })
+
+	archived := []string{}
+	if c.Archive {
+		for _, a := range listed {
+			p := path.Join(g.DraftsDir, postgen.DraftArchiveDir, path.Base(a.Path))
+			if !c.DryRun {
+				if p, err = g.ArchiveDraft(a.Path); err != nil {
+					return err
+				}
+			}
+			archived = append(archived, rel(s.path(p)))
+		}
+	}
+	out := []draftJSON{}
+	for _, a := range listed {
+		d := draftJSON{
+			File:     rel(s.path(a.Path)),
+			Title:    a.Meta.Title,
+			Modified: a.Modified.Format(time.RFC3339),
+			Words:    a.Words,
+			AgeDays:  int(now.Sub(a.since).Hours() / 24),
+		}
+		if !a.committed.IsZero() {
+			d.Committed = a.committed.Format(time.RFC3339)
+		}
+		out = append(out, d)
+	}
+	if opts.JSON {
+		doc := map[string]interface{}{"drafts": out}
+		if c.Archive {
+			doc["archived"], doc["dryRun"] = archived, c.DryRun
+		}
+		if err := printJSON(doc); err != nil {
+			return err
+		}
+		return reportBad(s, bad)
+	}
+	if c.Archive {
+		verb := "archived"
+		if c.DryRun {
+			verb = "would archive"
+		}
+		for i, p := range archived {
+			infof("%s %s as %s", verb, out[i].File, p)
+		}
+		return reportBad(s, bad)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	header := "MODIFIED\tAGE\tWORDS\tTITLE\tFILE"
+	if c.Git {
+		header = "MODIFIED\tCOMMITTED\tAGE\tWORDS\tTITLE\tFILE"
+	}
+	fmt.Fprintln(w, header)
+	for i, d := range out {
+		committed := ""
+		if c.Git {
+			committed = "-\t"
+			if !listed[i].committed.IsZero() {
+				committed = listed[i].committed.Format(postgen.FileDateLayout) + "\t"
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s%dd\t%d\t%s\t%s\n", listed[i].Modified.Format(postgen.FileDateLayout), committed, d.AgeDays, d.Words, d.Title, d.File)
+	}
+	w.Flush()
+	return reportBad(s, bad)
+}
+
+// ageUnits are the suffixes parseAge reads as a number of days.
+var ageUnits = map[string]int{"d": 1, "w": 7, "y": 365}
+
+// parseAge parses an age given in days, weeks or years, such as 90d, 6w
+// or 2y, or as a duration such as 72h.
+func parseAge(value string) (time.Duration, error) {
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return d, nil
+	}
+	for unit, days := range ageUnits {
+		if count, ok := strings.CutSuffix(value, unit); ok {
+			if n, err := strconv.Atoi(count); err == nil && n > 0 {
+				return time.Duration(n*days) * 24 * time.Hour, nil
+			}
+		}
+	}
+	return 0, usagef("invalid age \"%s\": expected a number of days, weeks or years such as 90d, 6w or 2y, or a duration such as 72h", value)
+}
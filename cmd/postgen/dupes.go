@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+// dupePassageWidth is the number of characters of a passage printed.
+const dupePassageWidth = 100
+
+type dupesCommand struct {
+	Threshold float64 `long:"threshold" default:"0.05" description:"similarity, from 0 to 1, a pair of posts must reach to be reported"`
+	Shingle   int     `long:"shingle" default:"8" description:"number of consecutive words compared at a time"`
+	Passages  int     `long:"passages" default:"3" description:"number of shared passages shown per pair"`
+	Code      bool    `long:"code" description:"compare code blocks too"`
+}
+
+// dupeJSON is the --json form of a pair of similar posts.
+type dupeJSON struct {
+	File       string        `json:"file"`
+	Other      string        `json:"other"`
+	Similarity float64       `json:"similarity"`
+	Passages   []passageJSON `json:"passages"`
+}
+
+type passageJSON struct {
+	Line      int    `json:"line"`
+	OtherLine int    `json:"otherLine"`
+	Words     int    `json:"words"`
+	Text      string `json:"text"`
+}
+
+func (c *dupesCommand) Execute(args []string) error {
+	if c.Threshold <= 0 || c.Threshold > 1 {
+		return usagef("invalid --threshold %g: expected a number from 0 to 1", c.Threshold)
+	}
+	if c.Shingle < 1 || c.Passages < 1 {
+		return usagef("--shingle and --passages expect a positive number")
+	}
+	s, _, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	g := s.generator(time.UTC)
+	ctx, stop := interruptContext()
+	defer stop()
+	dupes, bad, err := g.Dupes(ctx, postgen.DupeOptions{Threshold: c.Threshold, ShingleSize: c.Shingle, Passages: c.Passages, Code: c.Code})
+	if err != nil && !interrupted(err) {
+		return err
+	}
+	// An interrupted run reports the pairs among the posts read so far.
+	stopped := err
+	if opts.JSON {
+		out := []dupeJSON{}
+		for _, d := range dupes {
+			j := dupeJSON{File: rel(s.path(d.Path)), Other: rel(s.path(d.OtherPath)), Similarity: d.Similarity, Passages: []passageJSON{}}
+			for _, p := range d.Passages {
+				j.Passages = append(j.Passages, passageJSON{p.Line, p.OtherLine, p.Words, p.Text})
+			}
+			out = append(out, j)
+		}
+		if err := printJSON(out); err != nil {
+			return err
+		}
+		if stopped != nil {
+			return stopped
+		}
+		return reportBad(s, bad)
+	}
+	for _, d := range dupes {
+		a, b := rel(s.path(d.Path)), rel(s.path(d.OtherPath))
+		fmt.Printf("%s %s: %.0f%% similar\n", a, b, d.Similarity*100)
+		for _, p := range d.Passages {
+			text := []rune(p.Text)
+			if len(text) > dupePassageWidth {
+				text = append(text[:dupePassageWidth], '…')
+			}
+			fmt.Printf("  %s:%d %s:%d (%d words): %s\n", a, p.Line, b, p.OtherLine, p.Words, string(text))
+		}
+	}
+	infof("%d similar pair(s) found", len(dupes))
+	if stopped != nil {
+		return stopped
+	}
+	return reportBad(s, bad)
+}
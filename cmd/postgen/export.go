@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+type exportCommand struct{}
+
+type exportDevToCommand struct {
+	BaseURL string `long:"base-url" description:"URL the site is served at, such as https://tiagomelo.github.io (defaults to the url and baseurl of _config.yml)"`
+	Out     string `short:"o" long:"out" description:"file to write the article to (defaults to stdout)"`
+	Args    struct {
+		Post postName `positional-arg-name:"post" description:"slug or file name of the post"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+func (c *exportDevToCommand) Execute(args []string) error {
+	s, cfg, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	jekyll := readJekyllConfig(s)
+	baseURL := c.BaseURL
+	if baseURL == "" {
+		baseURL = jekyll.siteURL()
+	}
+	if baseURL == "" {
+		return usagef("the site's URL is unknown, set url in _config.yml or use --base-url")
+	}
+	g := s.generator(time.UTC)
+	p, err := g.Find(string(c.Args.Post))
+	if err != nil {
+		return err
+	}
+	export, err := g.ExportDevTo(p, postgen.DevToOptions{SiteURL: baseURL, Permalink: permalinkSetting(s, cfg)})
+	if err != nil {
+		return err
+	}
+	if c.Out != "" {
+		if err := os.WriteFile(c.Out, export.Content, 0644); err != nil {
+			return fmt.Errorf("writing file %s: %w", c.Out, err)
+		}
+	}
+	if opts.JSON {
+		out := struct {
+			File     string   `json:"file,omitempty"`
+			Content  string   `json:"content,omitempty"`
+			Warnings []string `json:"warnings"`
+		}{File: c.Out, Warnings: nonNil(export.Warnings)}
+		if c.Out == "" {
+			out.Content = string(export.Content)
+		}
+		return printJSON(out)
+	}
+	for _, w := range export.Warnings {
+		warnf("%s: %s", rel(s.path(p)), w)
+	}
+	if c.Out == "" {
+		fmt.Print(string(export.Content))
+		return nil
+	}
+	infof("wrote %s", c.Out)
+	return nil
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+type featuredCommand struct{}
+
+type featuredListCommand struct{}
+
+type featuredAddCommand struct {
+	previewFlags
+	Position int  `long:"position" description:"position of the post among the featured ones, from 1 (defaults to last)"`
+	DryRun   bool `short:"n" long:"dry-run" description:"list the files that would change without writing them"`
+	Args     struct {
+		Post postName `positional-arg-name:"post" description:"slug or file name of the post"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+type featuredRemoveCommand struct {
+	previewFlags
+	DryRun bool `short:"n" long:"dry-run" description:"list the files that would change without writing them"`
+	Args   struct {
+		Post postName `positional-arg-name:"post" description:"slug or file name of the post"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+// featuredJSON is the --json form of a featured post.
+type featuredJSON struct {
+	Position int    `json:"position"`
+	File     string `json:"file"`
+	Title    string `json:"title"`
+	Date     string `json:"date"`
+}
+
+func (c *featuredListCommand) Execute(args []string) error {
+	s, _, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	featured, bad, err := s.generator(time.UTC).Featured()
+	if err != nil {
+		return err
+	}
+	out := []featuredJSON{}
+	for i, e := range featured {
+		out = append(out, featuredJSON{i + 1, rel(s.path(e.Path)), e.Meta.Title, e.Date.Format(postgen.FileDateLayout)})
+	}
+	if opts.JSON {
+		if err := printJSON(out); err != nil {
+			return err
+		}
+		return reportBad(s, bad)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "POSITION\tDATE\tTITLE\tFILE")
+	for _, f := range out {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", f.Position, f.Date, f.Title, f.File)
+	}
+	w.Flush()
+	return reportBad(s, bad)
+}
+
+func (c *featuredAddCommand) Execute(args []string) error {
+	if c.Position < 0 {
+		return usagef("invalid --position %d: expected a positive number", c.Position)
+	}
+	s, cfg, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	g := s.generator(time.UTC)
+	p, err := g.Find(string(c.Args.Post))
+	if err != nil {
+		return err
+	}
+	plan, err := g.PlanFeature(p, c.Position, cfg.FeaturedMax)
+	if err != nil {
+		return err
+	}
+	return applyFeatured(s, g, plan, c.previewFlags, c.DryRun)
+}
+
+func (c *featuredRemoveCommand) Execute(args []string) error {
+	s, _, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	g := s.generator(time.UTC)
+	p, err := g.Find(string(c.Args.Post))
+	if err != nil {
+		return err
+	}
+	plan, err := g.PlanUnfeature(p)
+	if err != nil {
+		return err
+	}
+	return applyFeatured(s, g, plan, c.previewFlags, c.DryRun)
+}
+
+// applyFeatured previews and writes the changes of plan, printing the
+// posts it displaces.
+func applyFeatured(s site, g *postgen.Generator, plan postgen.FeaturedPlan, preview previewFlags, dryRun bool) error {
+	if preview.DiffOnly {
+		return printDiffs(s, plan.Changes, false)
+	}
+	if !dryRun {
+		if err := preview.confirmChanges(s, plan.Changes); err != nil {
+			return err
+		}
+		if err := g.WriteChanges(plan.Changes); err != nil {
+			return err
+		}
+	}
+	for _, e := range plan.Displaced {
+		verb := "no longer featured"
+		if dryRun {
+			verb = "would no longer be featured"
+		}
+		infof("%s %s", rel(s.path(e.Path)), verb)
+	}
+	return printChanges(s, plan.Changes, dryRun)
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+	"github.com/tiagomelo/tiagomelo.github.io/postgen/frontmatter"
+)
+
+type fmCommand struct{}
+
+type fmGetCommand struct {
+	Args struct {
+		Key   string   `positional-arg-name:"key" description:"front matter key to read" required:"yes"`
+		Files []string `positional-arg-name:"files" description:"files, glob patterns or slugs (defaults to every post and draft)"`
+	} `positional-args:"yes"`
+}
+
+type fmSetCommand struct {
+	previewFlags
+	MissingOnly bool `long:"missing-only" description:"only add the key to files that do not have it"`
+	Raw         bool `long:"raw" description:"write the value as YAML source instead of as a string"`
+	DryRun      bool `short:"n" long:"dry-run" description:"list the files that would change without writing them"`
+	Args        struct {
+		Key   string   `positional-arg-name:"key" description:"front matter key to set" required:"yes"`
+		Value string   `positional-arg-name:"value" description:"value to set" required:"yes"`
+		Files []string `positional-arg-name:"files" description:"files, glob patterns or slugs (defaults to every post and draft)"`
+	} `positional-args:"yes"`
+}
+
+// keyValueJSON is the --json form of a postgen.KeyValue.
+type keyValueJSON struct {
+	File  string `json:"file"`
+	Value string `json:"value"`
+	Found bool   `json:"found"`
+}
+
+func (c *fmGetCommand) Execute(args []string) error {
+	s, _, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	g := s.generator(time.UTC)
+	files, err := s.files(g, c.Args.Files)
+	if err != nil {
+		return err
+	}
+	values, err := g.GetKey(files, c.Args.Key)
+	if err != nil {
+		return err
+	}
+	if opts.JSON {
+		out := []keyValueJSON{}
+		for _, v := range values {
+			out = append(out, keyValueJSON{rel(s.path(v.Path)), v.Raw, v.Found})
+		}
+		return printJSON(out)
+	}
+	for _, v := range values {
+		if v.Found {
+			fmt.Printf("%s: %s\n", rel(s.path(v.Path)), strings.ReplaceAll(v.Raw, "\n", "\n  "))
+		}
+	}
+	return nil
+}
+
+func (c *fmSetCommand) Execute(args []string) error {
+	s, _, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	g := s.generator(time.UTC)
+	files, err := s.files(g, c.Args.Files)
+	if err != nil {
+		return err
+	}
+	value := c.Args.Value
+	if !c.Raw {
+		value = frontmatter.Scalar(value)
+	}
+	changes, err := g.SetKey(files, c.Args.Key, value, c.MissingOnly)
+	if err != nil {
+		return err
+	}
+	if c.DiffOnly {
+		return printDiffs(s, changes, false)
+	}
+	if !c.DryRun {
+		if err := c.confirmChanges(s, changes); err != nil {
+			return err
+		}
+		if err := g.WriteChanges(changes); err != nil {
+			return err
+		}
+	}
+	return printChanges(s, changes, c.DryRun)
+}
+
+// printChanges lists the files touched by a bulk edit, or that would be
+// with dryRun, followed by a count.
+func printChanges(s site, changes []postgen.Change, dryRun bool) error {
+	files := []string{}
+	for _, ch := range changes {
+		files = append(files, rel(s.path(ch.Path)))
+	}
+	if opts.JSON {
+		return printJSON(map[string]interface{}{"files": files, "dryRun": dryRun})
+	}
+	verb, summary := "updated", "updated"
+	if dryRun {
+		verb, summary = "would update", "would be updated"
+	}
+	for _, f := range files {
+		infof("%s %s", verb, f)
+	}
+	infof("%d file(s) %s", len(files), summary)
+	return nil
+}
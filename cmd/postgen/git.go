@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+// postBranch returns the branch --git creates for the post slug.
+func postBranch(slug string) string {
+	return "post/" + slug
+}
+
+// checkGit returns an error when --git cannot go through for the post
+// slug, before anything is created: the site is not in a git repository,
+// changes are already staged or, unless --git-no-branch, the post's
+// branch exists.
+func checkGit(s site, slug string) error {
+	if _, err := git(s.root, "rev-parse", "--is-inside-work-tree"); err != nil {
+		return fmt.Errorf("--git needs the site to be in a git repository, %s is not in one", s.root)
+	}
+	out, err := git(s.root, "diff", "--cached", "--name-only")
+	if err != nil {
+		return err
+	}
+	if staged := strings.Fields(string(out)); len(staged) > 0 {
+		return fmt.Errorf("refusing --git with changes already staged, commit or unstage them first: %s", strings.Join(staged, ", "))
+	}
+	if opts.GitNoBranch {
+		return nil
+	}
+	if _, err := git(s.root, "rev-parse", "--verify", "--quiet", "refs/heads/"+postBranch(slug)); err == nil {
+		return fmt.Errorf("branch %s already exists, use --git-no-branch to commit on the current branch", postBranch(slug))
+	}
+	return nil
+}
+
+// gitPost creates the branch of the post r, titled title, stages its
+// markdown file and images folder and commits them, skipping the steps
+// --git-no-branch and --git-no-commit turn off.
+func gitPost(s site, r postgen.Result, title string) error {
+	if !opts.GitNoBranch {
+		if _, err := git(s.root, "checkout", "-b", postBranch(r.Slug)); err != nil {
+			return err
+		}
+		infof("created branch %s", postBranch(r.Slug))
+	}
+	paths := []string{s.path(r.MarkdownPath)}
+	if entries, err := os.ReadDir(s.path(r.ImagesPath)); err == nil && len(entries) > 0 {
+		paths = append(paths, s.path(r.ImagesPath))
+	}
+	if _, err := git(s.root, append([]string{"add", "--"}, paths...)...); err != nil {
+		return err
+	}
+	if opts.GitNoCommit {
+		infof("staged %s", rel(s.path(r.MarkdownPath)))
+		return nil
+	}
+	if _, err := git(s.root, "commit", "--quiet", "-m", fmt.Sprintf("docs: add post \"%s\"", title)); err != nil {
+		return err
+	}
+	infof("committed %s", rel(s.path(r.MarkdownPath)))
+	return nil
+}
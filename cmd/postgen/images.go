@@ -0,0 +1,274 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen/diff"
+)
+
+type imagesCommand struct{}
+
+type imagesCheckCommand struct {
+	Prune bool `long:"prune" description:"delete the orphaned images after confirmation"`
+	Yes   bool `short:"y" long:"yes" description:"do not ask for confirmation when pruning"`
+}
+
+// imagesJSON is the --json form of an images check.
+type imagesJSON struct {
+	Missing []missingImageJSON `json:"missing"`
+	Orphans []string           `json:"orphans"`
+	Pruned  []string           `json:"pruned"`
+}
+
+type missingImageJSON struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+	URL  string `json:"url"`
+}
+
+func (c *imagesCheckCommand) Execute(args []string) error {
+	s, _, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	g := s.generator(time.UTC)
+	report, err := g.CheckImages()
+	if err != nil {
+		return err
+	}
+	out := imagesJSON{Missing: []missingImageJSON{}, Orphans: []string{}, Pruned: []string{}}
+	for _, ref := range report.Missing {
+		out.Missing = append(out.Missing, missingImageJSON{rel(s.path(ref.Post)), ref.Line, ref.URL})
+	}
+	for _, p := range report.Orphans {
+		out.Orphans = append(out.Orphans, rel(s.path(p)))
+	}
+	if !opts.JSON {
+		for _, ref := range out.Missing {
+			fmt.Printf("%s:%d: missing image %s\n", ref.File, ref.Line, ref.URL)
+		}
+		for _, p := range out.Orphans {
+			fmt.Printf("%s: not referenced by any post\n", p)
+		}
+	}
+	orphans := report.Orphans
+	if c.Prune && len(orphans) > 0 {
+		if !c.Yes && !confirm(fmt.Sprintf("delete %d orphaned image(s)?", len(orphans))) {
+			return errors.New("aborted")
+		}
+		if err := g.PruneImages(orphans); err != nil {
+			return err
+		}
+		infof("deleted %d orphaned image(s)", len(orphans))
+		out.Pruned, out.Orphans, orphans = out.Orphans, []string{}, nil
+	}
+	if opts.JSON {
+		if err := printJSON(out); err != nil {
+			return err
+		}
+	}
+	if n := len(report.Missing) + len(orphans); n > 0 {
+		return fmt.Errorf("%d problem(s) found", n)
+	}
+	return nil
+}
+
+type imagesReconcileCommand struct {
+	DryRun bool `short:"n" long:"dry-run" description:"only print the planned renames and changes"`
+	Yes    bool `short:"y" long:"yes" description:"do not ask for confirmation before applying the plan"`
+}
+
+// folderRenameJSON is the --json form of a planned folder rename.
+type folderRenameJSON struct {
+	Folder    string `json:"folder"`
+	NewFolder string `json:"newFolder"`
+	Post      string `json:"post"`
+	Diff      string `json:"diff"`
+}
+
+// skippedFolderJSON is the --json form of a folder left alone.
+type skippedFolderJSON struct {
+	Folder string   `json:"folder"`
+	Posts  []string `json:"posts"`
+	Taken  string   `json:"taken,omitempty"`
+}
+
+func (c *imagesReconcileCommand) Execute(args []string) error {
+	s, _, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	g := s.generator(time.UTC)
+	plan, err := g.PlanImageFolders()
+	if err != nil {
+		return err
+	}
+	renames := []folderRenameJSON{}
+	for _, r := range plan.Renames {
+		p := rel(s.path(r.Post.Path))
+		renames = append(renames, folderRenameJSON{rel(s.path(r.Folder)), rel(s.path(r.NewFolder)), p, diff.Unified("a/"+p, "b/"+p, string(r.Post.OldContent), string(r.Post.NewContent), 3)})
+	}
+	skipped := []skippedFolderJSON{}
+	for _, f := range plan.Skipped {
+		j := skippedFolderJSON{Folder: rel(s.path(f.Folder)), Posts: []string{}}
+		for _, p := range f.Posts {
+			j.Posts = append(j.Posts, rel(s.path(p)))
+		}
+		if f.Taken != "" {
+			j.Taken = rel(s.path(f.Taken))
+		}
+		skipped = append(skipped, j)
+	}
+	if !opts.JSON {
+		for _, r := range renames {
+			fmt.Printf("rename %s -> %s (%s)\n", r.Folder, r.NewFolder, r.Post)
+			fmt.Print(r.Diff)
+		}
+		for _, f := range skipped {
+			if f.Taken != "" {
+				fmt.Printf("%s: referenced by %s, whose folder %s already exists, left alone\n", f.Folder, f.Posts[0], f.Taken)
+			} else {
+				fmt.Printf("%s: referenced by %s, left alone\n", f.Folder, strings.Join(f.Posts, ", "))
+			}
+		}
+	}
+	applied := !c.DryRun && len(plan.Renames) > 0
+	if applied {
+		if !c.Yes && !confirm(fmt.Sprintf("rename %d images folder(s)?", len(plan.Renames))) {
+			return errors.New("aborted")
+		}
+		for _, r := range plan.Renames {
+			if err := g.ApplyFolderRename(r); err != nil {
+				return err
+			}
+		}
+		infof("renamed %d images folder(s)", len(plan.Renames))
+	}
+	if opts.JSON {
+		return printJSON(map[string]interface{}{"renamed": renames, "skipped": skipped, "applied": applied})
+	}
+	return nil
+}
+
+type imagesBudgetCommand struct {
+	MaxPerPost string `long:"max-per-post" default:"2MB" description:"size the images folder of a post may reach, such as 2MB"`
+	MaxFile    string `long:"max-file" default:"500KB" description:"size a single image may reach, such as 500KB"`
+	Top        int    `long:"top" default:"10" description:"number of heaviest files to list"`
+	Strict     bool   `long:"strict" description:"fail when any post or file is over budget"`
+}
+
+// imageSizeJSON is the --json form of a file or folder and its size.
+type imageSizeJSON struct {
+	Path string `json:"path"`
+	Post string `json:"post,omitempty"`
+	Size int64  `json:"size"`
+}
+
+func (c *imagesBudgetCommand) Execute(args []string) error {
+	maxPerPost, err := parseByteSize(c.MaxPerPost)
+	if err != nil {
+		return usagef("invalid --max-per-post \"%s\": expected a size such as 2MB", c.MaxPerPost)
+	}
+	maxFile, err := parseByteSize(c.MaxFile)
+	if err != nil {
+		return usagef("invalid --max-file \"%s\": expected a size such as 500KB", c.MaxFile)
+	}
+	s, _, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	w, err := s.generator(time.UTC).ImageWeight()
+	if err != nil {
+		return err
+	}
+	folders, files, heaviest := []imageSizeJSON{}, []imageSizeJSON{}, []imageSizeJSON{}
+	for _, f := range w.Folders {
+		if f.Size > maxPerPost {
+			j := imageSizeJSON{Path: rel(s.path(f.Path)), Size: f.Size}
+			if f.Post != "" {
+				j.Post = rel(s.path(f.Post))
+			}
+			folders = append(folders, j)
+		}
+	}
+	for i, f := range w.Files {
+		if f.Size > maxFile {
+			files = append(files, imageSizeJSON{Path: rel(s.path(f.Path)), Size: f.Size})
+		}
+		if i < c.Top {
+			heaviest = append(heaviest, imageSizeJSON{Path: rel(s.path(f.Path)), Size: f.Size})
+		}
+	}
+	if opts.JSON {
+		err = printJSON(map[string]interface{}{
+			"total":       w.Total,
+			"files":       len(w.Files),
+			"maxPerPost":  maxPerPost,
+			"maxFile":     maxFile,
+			"overFolders": folders,
+			"overFiles":   files,
+			"heaviest":    heaviest,
+		})
+		if err != nil {
+			return err
+		}
+	} else {
+		for _, f := range folders {
+			name := f.Path
+			if f.Post != "" {
+				name = f.Post + " (" + f.Path + ")"
+			}
+			fmt.Printf("%s: images weigh %s, over the %s budget\n", name, byteSize(int(f.Size)), byteSize(int(maxPerPost)))
+		}
+		for _, f := range files {
+			fmt.Printf("%s: %s, over the %s budget\n", f.Path, byteSize(int(f.Size)), byteSize(int(maxFile)))
+		}
+		fmt.Printf("total: %s in %d file(s)\n", byteSize(int(w.Total)), len(w.Files))
+		if len(heaviest) > 0 {
+			fmt.Println("heaviest files:")
+			tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+			for _, f := range heaviest {
+				fmt.Fprintf(tw, "  %s\t  %s\n", byteSize(int(f.Size)), f.Path)
+			}
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if n := len(folders) + len(files); c.Strict && n > 0 {
+		return fmt.Errorf("%d images folder(s) and %d file(s) over budget", len(folders), len(files))
+	}
+	return nil
+}
+
+// byteSizePattern matches the sizes parseByteSize accepts.
+var byteSizePattern = regexp.MustCompile(`(?i)^\s*(\d+(?:\.\d+)?)\s*([KMG]?)B?\s*$`)
+
+// parseByteSize parses a size such as 500KB or 1.5MB, in the binary
+// units byteSize prints.
+func parseByteSize(s string) (int64, error) {
+	m := byteSizePattern.FindStringSubmatch(s)
+	if m == nil {
+		return 0, fmt.Errorf("invalid size \"%s\"", s)
+	}
+	n, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("parsing size %s: %w", s, err)
+	}
+	switch strings.ToUpper(m[2]) {
+	case "K":
+		n *= 1 << 10
+	case "M":
+		n *= 1 << 20
+	case "G":
+		n *= 1 << 30
+	}
+	return int64(n), nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+	"github.com/tiagomelo/tiagomelo.github.io/postgen/frontmatter"
+)
+
+type importCommand struct {
+	Title                string `short:"t" long:"title" description:"title of the post (defaults to the file's front matter title, or else its name)"`
+	Slug                 string `short:"s" long:"slug" description:"slug of the post (defaults to one generated from the title)"`
+	Draft                bool   `long:"draft" description:"import the file as a draft in _drafts"`
+	OverwriteFrontMatter bool   `long:"overwrite-front-matter" description:"let the generated front matter replace the keys the file already has"`
+	DryRun               bool   `short:"n" long:"dry-run" description:"print what would be created without writing anything"`
+	Args                 struct {
+		File string `positional-arg-name:"file" description:"Markdown file to import"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+func (c *importCommand) Execute(args []string) error {
+	content, err := os.ReadFile(c.Args.File)
+	if err != nil {
+		return fmt.Errorf("reading file %s: %w", c.Args.File, err)
+	}
+	title := c.Title
+	if title == "" {
+		title = sourceTitle(c.Args.File, content)
+	}
+	slug, err := postSlug(c.Slug, title)
+	if err != nil {
+		return err
+	}
+	s, cfg, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	if cfg.Author != "" {
+		if err := checkAuthor(s, cfg.Author); err != nil {
+			return err
+		}
+	}
+	categories, err := postgen.ParseCategories(cfg.Categories)
+	if err != nil {
+		return usageError{err}
+	}
+	if err := checkCategories(s, categories); err != nil {
+		return err
+	}
+	loc, err := location(s, cfg)
+	if err != nil {
+		return err
+	}
+	date, err := postDate(opts.Date, loc)
+	if err != nil {
+		return err
+	}
+	images := make(map[string][]byte)
+	for _, ref := range postgen.LocalImages(content) {
+		file := ref
+		if unescaped, err := url.PathUnescape(ref); err == nil {
+			file = unescaped
+		}
+		file = filepath.Join(filepath.Dir(c.Args.File), filepath.FromSlash(file))
+		data, err := os.ReadFile(file)
+		if errors.Is(err, os.ErrNotExist) {
+			warnf("image %s not found next to %s, its reference was left as is", ref, c.Args.File)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("reading image %s: %w", file, err)
+		}
+		images[ref] = data
+	}
+	g := s.generator(loc)
+	g.Force = opts.Force
+	g.AllowDuplicateSlug = opts.AllowDuplicateSlug
+	p := postgen.Post{
+		Layout:       cfg.Layout,
+		Title:        title,
+		Description:  opts.Description,
+		CanonicalURL: opts.CanonicalURL,
+		Slug:         slug,
+		Author:       cfg.Author,
+		Categories:   categories,
+		Tags:         postgen.ParseTags(cfg.Tags),
+		Date:         date,
+		Draft:        c.Draft,
+	}
+	if c.DryRun {
+		r, err := g.PlanImport(content, images, p, c.OverwriteFrontMatter)
+		if err != nil {
+			return err
+		}
+		if err := printPlan(s, r); err != nil {
+			return err
+		}
+		return g.Collision(r)
+	}
+	r, err := g.Import(content, images, p, c.OverwriteFrontMatter)
+	if err != nil {
+		return err
+	}
+	return printResult(s, "created", r)
+}
+
+// sourceTitle returns the front matter title of a file to import, or
+// else its name, which is the note's title in editors such as Obsidian.
+func sourceTitle(file string, content []byte) string {
+	if doc, err := frontmatter.Parse(content); err == nil {
+		var meta postgen.Meta
+		if doc.Decode(&meta) == nil && meta.Title != "" {
+			return meta.Title
+		}
+	}
+	return strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"path"
+	"time"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+type indexCommand struct {
+	Out    string `short:"o" long:"out" description:"page to write (defaults to index-of-posts.md in the Jekyll source directory)"`
+	Title  string `long:"title" default:"Index of posts" description:"title of the page when it is created"`
+	DryRun bool   `short:"n" long:"dry-run" description:"report whether the page would change without writing it"`
+}
+
+func (c *indexCommand) Execute(args []string) error {
+	s, cfg, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	out := path.Join(s.sourceDir(), "index-of-posts.md")
+	if c.Out != "" {
+		if out, err = s.name(c.Out); err != nil {
+			return err
+		}
+	}
+	jekyll := readJekyllConfig(s)
+	g := s.generator(time.UTC)
+	change, err := g.PlanIndex(out, postgen.IndexOptions{
+		Layout:    "page",
+		Title:     c.Title,
+		Permalink: permalinkSetting(s, cfg),
+		BaseURL:   jekyll.BaseURL,
+	})
+	if err != nil {
+		return err
+	}
+	changed := !bytes.Equal(change.OldContent, change.NewContent)
+	if changed && !c.DryRun {
+		if err := g.WritePage(change); err != nil {
+			return err
+		}
+	}
+	file := rel(s.path(out))
+	if opts.JSON {
+		return printJSON(map[string]interface{}{"file": file, "changed": changed, "dryRun": c.DryRun})
+	}
+	switch {
+	case !changed:
+		infof("%s is up to date", file)
+	case c.DryRun:
+		infof("would write %s", file)
+	default:
+		infof("wrote %s", file)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+	"gopkg.in/yaml.v3"
+)
+
+// jekyllConfig is the part of the site's Jekyll _config.yml postgen
+// cares about.
+type jekyllConfig struct {
+	Timezone  string   `yaml:"timezone"`
+	Permalink string   `yaml:"permalink"`
+	Theme     string   `yaml:"theme"`
+	BaseURL   string   `yaml:"baseurl"`
+	URL       string   `yaml:"url"`
+	Plugins   []string `yaml:"plugins"`
+}
+
+// pluginURLs maps the plugins postgen knows about to the URLs they
+// generate.
+var pluginURLs = map[string][]string{
+	"jekyll-feed":    {"/feed.xml"},
+	"jekyll-sitemap": {"/sitemap.xml", "/robots.txt"},
+}
+
+// readJekyllConfig reads the site's _config.yml, returning the zero value
+// when it is missing or cannot be parsed.
+func readJekyllConfig(s site) jekyllConfig {
+	var jekyll jekyllConfig
+	b, err := os.ReadFile(filepath.Join(s.path(s.sourceDir()), "_config.yml"))
+	if err != nil {
+		return jekyll
+	}
+	if err := yaml.Unmarshal(b, &jekyll); err != nil {
+		return jekyllConfig{}
+	}
+	return jekyll
+}
+
+// permalinkSetting returns the permalink style or pattern of the posts
+// without a permalink of their own: the configured one, or else the one
+// of Jekyll's _config.yml, or else Jekyll's default.
+func permalinkSetting(s site, cfg config) string {
+	if cfg.Permalink != "" {
+		return cfg.Permalink
+	}
+	if p := readJekyllConfig(s).Permalink; p != "" {
+		return p
+	}
+	return postgen.DefaultPermalink
+}
+
+// siteURL returns the absolute URL the site is served at, its url and
+// baseurl joined, or "" when _config.yml has no url.
+func (j jekyllConfig) siteURL() string {
+	if j.URL == "" {
+		return ""
+	}
+	return strings.TrimSuffix(j.URL, "/") + j.BaseURL
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+const (
+	defaultLinkCache    = ".postgen-links.json"
+	defaultLinkCacheTTL = 24 * time.Hour
+)
+
+type linksCommand struct{}
+
+type linksCheckCommand struct {
+	External bool          `long:"external" description:"also request every http(s) link and report the ones not answering with a 2xx or 3xx status"`
+	Workers  int           `long:"workers" description:"number of external links checked at once (defaults to --jobs when given, or 8)"`
+	Timeout  time.Duration `long:"timeout" default:"10s" description:"timeout of each external request"`
+	Retries  int           `long:"retries" default:"2" description:"retries after a network error, a 429 or a 5xx response"`
+	Ignore   []string      `long:"ignore" value-name:"DOMAIN" description:"domain not to check, on top of the config file's link_ignore (repeatable)"`
+	NoCache  bool          `long:"no-cache" description:"check every external link again, ignoring the cache file"`
+}
+
+// brokenLinkJSON is the --json form of a broken link.
+type brokenLinkJSON struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	URL    string `json:"url"`
+	Reason string `json:"reason"`
+}
+
+// externalJSON is the --json form of the broken external links of a post.
+type externalJSON struct {
+	File  string             `json:"file"`
+	Links []externalLinkJSON `json:"links"`
+}
+
+type externalLinkJSON struct {
+	Line   int    `json:"line"`
+	URL    string `json:"url"`
+	Reason string `json:"reason"`
+}
+
+// linkOptions returns the options resolving the links of the posts of s
+// against its permalinks and the URLs its plugins generate.
+func linkOptions(s site, cfg config) postgen.LinkOptions {
+	jekyll := readJekyllConfig(s)
+	linkOpts := postgen.LinkOptions{
+		Permalink: permalinkSetting(s, cfg),
+		BaseURL:   jekyll.BaseURL,
+		SiteURL:   jekyll.URL,
+	}
+	for _, plugin := range jekyll.Plugins {
+		linkOpts.Generated = append(linkOpts.Generated, pluginURLs[plugin]...)
+	}
+	return linkOpts
+}
+
+func (c *linksCheckCommand) Execute(args []string) error {
+	s, cfg, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	linkOpts := linkOptions(s, cfg)
+	g := s.generator(time.UTC)
+	ctx, stop := interruptContext()
+	defer stop()
+	broken, bad, err := g.CheckLinks(ctx, linkOpts)
+	if err != nil && !interrupted(err) {
+		return err
+	}
+	// An interrupted run reports the links of the posts checked so far.
+	stopped := err
+	out := []brokenLinkJSON{}
+	for _, b := range broken {
+		out = append(out, brokenLinkJSON{rel(s.path(b.Post)), b.Line, b.URL, b.Reason})
+	}
+
+	var external []externalJSON
+	if c.External && stopped == nil {
+		externalBroken, err := c.checkExternal(ctx, s, cfg, g, linkOpts.SiteURL)
+		if err != nil && !interrupted(err) {
+			return err
+		}
+		stopped = err
+		external = []externalJSON{}
+		for _, b := range externalBroken {
+			file := rel(s.path(b.Post))
+			if len(external) == 0 || external[len(external)-1].File != file {
+				external = append(external, externalJSON{File: file})
+			}
+			last := &external[len(external)-1]
+			last.Links = append(last.Links, externalLinkJSON{b.Line, b.URL, b.Reason})
+		}
+		broken = append(broken, externalBroken...)
+	}
+
+	if opts.JSON {
+		doc := map[string]interface{}{"broken": out}
+		if c.External {
+			doc["external"] = external
+		}
+		if err := printJSON(doc); err != nil {
+			return err
+		}
+	} else {
+		for _, b := range out {
+			fmt.Printf("%s:%d: broken link %s: %s\n", b.File, b.Line, b.URL, b.Reason)
+		}
+		for _, post := range external {
+			fmt.Println(post.File)
+			for _, b := range post.Links {
+				fmt.Printf("  %d: %s: %s\n", b.Line, b.URL, b.Reason)
+			}
+		}
+	}
+	if stopped != nil {
+		return stopped
+	}
+	if len(broken) > 0 {
+		reportBad(s, bad)
+		return fmt.Errorf("%d broken link(s) found", len(broken))
+	}
+	return reportBad(s, bad)
+}
+
+// checkExternal checks the external links, reading and updating the
+// cache file unless --no-cache is given, even when interrupted through
+// ctx.
+func (c *linksCheckCommand) checkExternal(ctx context.Context, s site, cfg config, g *postgen.Generator, siteURL string) ([]postgen.BrokenLink, error) {
+	ttl := defaultLinkCacheTTL
+	if cfg.LinkCacheTTL != "" {
+		var err error
+		if ttl, err = time.ParseDuration(cfg.LinkCacheTTL); err != nil {
+			return nil, fmt.Errorf("invalid link_cache_ttl \"%s\"", cfg.LinkCacheTTL)
+		}
+	}
+	cacheFile := defaultLinkCache
+	if cfg.LinkCache != "" {
+		var err error
+		if cacheFile, err = s.name(s.path(cfg.LinkCache)); err != nil {
+			return nil, err
+		}
+	}
+	var cache *postgen.LinkCache
+	if !c.NoCache {
+		var err error
+		if cache, err = g.ReadLinkCache(cacheFile, ttl); err != nil {
+			return nil, err
+		}
+	}
+	workers := c.Workers
+	if workers == 0 {
+		workers = opts.Jobs
+	}
+	if workers == 0 {
+		workers = 8
+	}
+	broken, err := g.CheckExternal(ctx, postgen.ExternalOptions{
+		Workers: workers,
+		Timeout: c.Timeout,
+		Retries: c.Retries,
+		Ignore:  append(append([]string{}, cfg.LinkIgnore...), c.Ignore...),
+		SiteURL: siteURL,
+		Cache:   cache,
+	})
+	if err != nil && !interrupted(err) {
+		return nil, err
+	}
+	if cache != nil {
+		if err := g.WriteLinkCache(cacheFile, cache); err != nil {
+			return nil, err
+		}
+		verbosef("link cache %s", rel(s.path(cacheFile)))
+	}
+	return broken, err
+}
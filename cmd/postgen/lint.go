@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+type lintCommand struct {
+	previewFlags
+	Disable   []string `long:"disable" value-name:"RULE" description:"rule not to apply, on top of the config file's lint_disable (repeatable)"`
+	ListRules bool     `long:"list-rules" description:"list the rules and exit"`
+	FixAlt    bool     `long:"fix-alt-from-filename" description:"give the images without alt text their humanized file name as a placeholder alt before linting"`
+	Args      struct {
+		Files []string `positional-arg-name:"files" description:"files, glob patterns or slugs (defaults to every post and draft)"`
+	} `positional-args:"yes"`
+}
+
+// lintProblemJSON is the --json form of a postgen.LintProblem.
+type lintProblemJSON struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (c *lintCommand) Execute(args []string) error {
+	if c.ListRules {
+		return listLintRules()
+	}
+	s, cfg, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	disabled := make(map[string]bool)
+	for _, name := range append(append([]string{}, cfg.LintDisable...), c.Disable...) {
+		if !knownLintRule(name) {
+			var names []string
+			for _, r := range postgen.LintRules {
+				names = append(names, r.Name)
+			}
+			return usagef("unknown lint rule \"%s\", expected one of %s", name, strings.Join(names, ", "))
+		}
+		disabled[name] = true
+	}
+	g := s.generator(time.UTC)
+	files, err := s.files(g, c.Args.Files)
+	if err != nil {
+		return err
+	}
+	if c.FixAlt && !disabled["image-alt"] {
+		changes, fixed, err := g.PlanFixAlt(files)
+		if err != nil {
+			return err
+		}
+		if c.DiffOnly {
+			return printDiffs(s, changes, false)
+		}
+		if err := c.confirmChanges(s, changes); err != nil {
+			return err
+		}
+		if err := g.WriteChanges(changes); err != nil {
+			return err
+		}
+		infof("added placeholder alt text to %d image(s) in %d file(s)", fixed, len(changes))
+	}
+	problems, err := g.LintFiles(files, disabled)
+	if err != nil {
+		return err
+	}
+	if opts.JSON {
+		out := []lintProblemJSON{}
+		for _, p := range problems {
+			out = append(out, lintProblemJSON{rel(s.path(p.Post)), p.Line, p.Rule, p.Message})
+		}
+		if err := printJSON(map[string]interface{}{"problems": out}); err != nil {
+			return err
+		}
+	} else {
+		for _, p := range problems {
+			fmt.Printf("%s:%d: %s: %s\n", rel(s.path(p.Post)), p.Line, p.Rule, p.Message)
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%d problem(s) found", len(problems))
+	}
+	return nil
+}
+
+func knownLintRule(name string) bool {
+	for _, r := range postgen.LintRules {
+		if r.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func listLintRules() error {
+	if opts.JSON {
+		out := []map[string]string{}
+		for _, r := range postgen.LintRules {
+			out = append(out, map[string]string{"name": r.Name, "description": r.Description})
+		}
+		return printJSON(out)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, r := range postgen.LintRules {
+		fmt.Fprintf(w, "%s\t%s\n", r.Name, r.Description)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+type liquidCommand struct{}
+
+type liquidCheckCommand struct {
+	Args struct {
+		Files []string `positional-arg-name:"files" description:"files, glob patterns or slugs (defaults to every post and draft)"`
+	} `positional-args:"yes"`
+}
+
+// liquidProblemJSON is the --json form of a postgen.LiquidProblem.
+type liquidProblemJSON struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+func (c *liquidCheckCommand) Execute(args []string) error {
+	s, _, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	g := s.generator(time.UTC)
+	files, err := s.files(g, c.Args.Files)
+	if err != nil {
+		return err
+	}
+	problems, err := g.CheckLiquid(files)
+	if err != nil {
+		return err
+	}
+	if opts.JSON {
+		out := []liquidProblemJSON{}
+		for _, p := range problems {
+			out = append(out, liquidProblemJSON{rel(s.path(p.Post)), p.Line, p.Message})
+		}
+		if err := printJSON(map[string]interface{}{"problems": out}); err != nil {
+			return err
+		}
+	} else {
+		for _, p := range problems {
+			fmt.Printf("%s:%d: %s\n", rel(s.path(p.Post)), p.Line, p.Message)
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%d Liquid problem(s) found", len(problems))
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+type listCommand struct {
+	Category categoryName `long:"category" description:"only list posts in this category"`
+	Tag      tagName      `long:"tag" description:"only list posts with this tag"`
+	Since    string       `long:"since" description:"only list posts dated on or after YYYY-MM-DD"`
+}
+
+type listEntry struct {
+	Date       string   `json:"date"`
+	Title      string   `json:"title"`
+	Categories []string `json:"categories"`
+	Tags       []string `json:"tags"`
+	File       string   `json:"file"`
+}
+
+func (c *listCommand) Execute(args []string) error {
+	s, _, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	var since time.Time
+	if c.Since != "" {
+		if since, err = time.Parse(postgen.FileDateLayout, c.Since); err != nil {
+			return usagef("invalid --since date \"%s\": expected YYYY-MM-DD", c.Since)
+		}
+	}
+	entries, bad, err := s.generator(time.UTC).List()
+	if err != nil {
+		return err
+	}
+	listed := []listEntry{}
+	for _, e := range entries {
+		if c.Category != "" && !containsFold(e.Meta.Categories, string(c.Category)) {
+			continue
+		}
+		if c.Tag != "" && !containsFold(e.Meta.Tags, string(c.Tag)) {
+			continue
+		}
+		if !since.IsZero() && e.Date.Format(postgen.FileDateLayout) < since.Format(postgen.FileDateLayout) {
+			continue
+		}
+		listed = append(listed, listEntry{
+			Date:       e.Date.Format(postgen.FileDateLayout),
+			Title:      e.Meta.Title,
+			Categories: nonNil(e.Meta.Categories),
+			Tags:       nonNil(e.Meta.Tags),
+			File:       rel(s.path(e.Path)),
+		})
+	}
+	if opts.JSON {
+		if err := printJSON(listed); err != nil {
+			return err
+		}
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "DATE\tTITLE\tCATEGORIES\tFILE")
+		for _, e := range listed {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", e.Date, e.Title, strings.Join(e.Categories, " "), e.File)
+		}
+		w.Flush()
+	}
+	return reportBad(s, bad)
+}
+
+// reportBad prints the files that could not be read to stderr, as
+// warnings with --json, and turns them into a single error.
+func reportBad(s site, bad []*postgen.FileError) error {
+	if len(bad) == 0 {
+		return nil
+	}
+	if opts.JSON {
+		for _, e := range bad {
+			warnf("%s: %v", rel(s.path(e.Path)), e.Err)
+		}
+	} else {
+		fmt.Fprintln(os.Stderr)
+		for _, e := range bad {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", rel(s.path(e.Path)), e.Err)
+		}
+	}
+	return fmt.Errorf("%d file(s) could not be read", len(bad))
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,539 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+type options struct {
+	Root               string         `long:"root" env:"POSTGEN_ROOT" description:"site repository root (defaults to the closest parent directory containing docs/_posts or .git)"`
+	PostsDir           string         `long:"posts-dir" env:"POSTGEN_POSTS_DIR" description:"folder holding the posts, relative to the site root (defaults to the config file's posts_dir, or docs/_posts)"`
+	DraftsDir          string         `long:"drafts-dir" env:"POSTGEN_DRAFTS_DIR" description:"folder holding the drafts, relative to the site root (defaults to the config file's drafts_dir, or _drafts next to the posts folder)"`
+	ImagesDir          string         `long:"images-dir" env:"POSTGEN_IMAGES_DIR" description:"folder holding the posts' images folders, relative to the site root (defaults to the config file's images_dir, or docs/assets/images)"`
+	Title              string         `short:"t" long:"title" env:"POSTGEN_TITLE" description:"article's title"`
+	Slug               string         `short:"s" long:"slug" env:"POSTGEN_SLUG" description:"slug used for the file and images folder names (defaults to one generated from the title)"`
+	Description        string         `long:"description" env:"POSTGEN_DESCRIPTION" description:"post description, the summary search engines and feeds show"`
+	CanonicalURL       string         `long:"canonical-url" env:"POSTGEN_CANONICAL_URL" description:"absolute http(s) URL the post first appeared at, written to its canonical_url"`
+	Permalink          string         `long:"permalink" env:"POSTGEN_PERMALINK" description:"URL path of the post, such as /go-tls/, replacing the site's permalink pattern"`
+	Author             string         `short:"a" long:"author" env:"POSTGEN_AUTHOR" description:"post author, a key of _data/authors.yml when the site has one (defaults to the config file's author)"`
+	Lang               string         `long:"lang" env:"POSTGEN_LANG" description:"language of the post, such as en or pt, written to its lang front matter (defaults to the config file's lang)"`
+	Categories         []categoryName `short:"c" long:"category" env:"POSTGEN_CATEGORY" description:"post category; may be repeated or given as a comma-separated list"`
+	Tags               []tagName      `long:"tags" env:"POSTGEN_TAGS" description:"comma-separated post tags; may be repeated"`
+	Images             []string       `long:"image" env:"POSTGEN_IMAGE" description:"image file to copy into the post's images folder and reference from its body; may be repeated"`
+	Optimize           bool           `long:"optimize" env:"POSTGEN_OPTIMIZE" description:"shrink the --image files copied into the post: scale them down to image_max_width, encode photos as JPEG at image_quality and recompress the other PNGs"`
+	Cover              string         `long:"cover" env:"POSTGEN_COVER" description:"image file to copy into the post's images folder as cover.<ext> and set as its image front matter"`
+	Outline            string         `long:"outline" env:"POSTGEN_OUTLINE" description:"comma-separated section titles written to the body as H2 headings, each followed by a TODO comment"`
+	OutlineFile        string         `long:"outline-file" env:"POSTGEN_OUTLINE_FILE" description:"file of section titles, one per line, indented ones written as H3 subsections of the section above them"`
+	Vars               []string       `long:"var" value-name:"KEY=VALUE" env:"POSTGEN_VAR" env-delim:"," description:"one-off front matter key, also passed to templates as .Vars.KEY; true, false and integers are written as such, other values as strings (repeatable)"`
+	Series             string         `long:"series" env:"POSTGEN_SERIES" description:"series the post belongs to; its part number follows the last existing part"`
+	Ext                string         `long:"ext" env:"POSTGEN_EXT" description:"extension of the created post, md or markdown (defaults to the config file's ext, or markdown)"`
+	Draft              bool           `long:"draft" env:"POSTGEN_DRAFT" description:"create an undated draft in _drafts instead of a post"`
+	Date               string         `long:"date" env:"POSTGEN_DATE" description:"publication date as YYYY-MM-DD or \"YYYY-MM-DD HH:MM\" (defaults to now)"`
+	Timezone           string         `long:"timezone" env:"POSTGEN_TIMEZONE" description:"IANA time zone posts are dated in (defaults to the Jekyll site's timezone, or UTC)"`
+	AllowFuture        bool           `long:"allow-future" env:"POSTGEN_ALLOW_FUTURE" description:"allow a --date in the future, which Jekyll does not render by default"`
+	Strict             bool           `long:"strict" env:"POSTGEN_STRICT" description:"refuse to create the post when its title or slug is too long or its title is already taken, instead of warning"`
+	Force              bool           `short:"f" long:"force" env:"POSTGEN_FORCE" description:"overwrite the markdown file if it already exists"`
+	AllowDuplicateSlug bool           `long:"allow-duplicate-slug" env:"POSTGEN_ALLOW_DUPLICATE_SLUG" description:"create the post even when a post of another day has the same slug"`
+	Archetype          archetypeName  `long:"archetype" env:"POSTGEN_ARCHETYPE" description:"name of a skeleton in _archetypes whose front matter and body are merged into the post, its keys replacing the defaults but not the values given with flags"`
+	Template           string         `long:"template" env:"POSTGEN_TEMPLATE" description:"front matter template file (defaults to rendering the config file's front_matter format)"`
+	PrintTemplate      bool           `long:"print-template" env:"POSTGEN_PRINT_TEMPLATE" description:"print the --template file, or else a template equivalent to the default front matter format, and exit"`
+	DryRun             bool           `short:"n" long:"dry-run" env:"POSTGEN_DRY_RUN" description:"print what would be created without writing anything"`
+	Git                bool           `long:"git" env:"POSTGEN_GIT" description:"create a post/<slug> branch off the current one, stage the new post and its images folder and commit them; refused when changes are already staged"`
+	GitNoBranch        bool           `long:"git-no-branch" env:"POSTGEN_GIT_NO_BRANCH" description:"with --git, stay on the current branch"`
+	GitNoCommit        bool           `long:"git-no-commit" env:"POSTGEN_GIT_NO_COMMIT" description:"with --git, stage the new files without committing them"`
+	Edit               bool           `short:"e" long:"edit" env:"POSTGEN_EDIT" description:"open the post in $VISUAL or $EDITOR once created; with --slug and no --title, open an existing post"`
+	KeepOnError        bool           `long:"keep-on-error" env:"POSTGEN_KEEP_ON_ERROR" description:"keep partially created files when generation fails"`
+	Quiet              bool           `short:"q" long:"quiet" env:"POSTGEN_QUIET" description:"print only the path of the created post"`
+	Verbose            bool           `short:"v" long:"verbose" env:"POSTGEN_VERBOSE" description:"also print the site root, template and images folder"`
+	JSON               bool           `long:"json" env:"POSTGEN_JSON" description:"print a single JSON document on stdout, and errors as JSON on stderr"`
+	Jobs               int            `long:"jobs" env:"POSTGEN_JOBS" description:"number of files commands going through every post, such as validate, links check, related and dupes, work on at once (defaults to GOMAXPROCS)"`
+	Version            bool           `long:"version" description:"print the version, VCS revision and build date of postgen and exit"`
+}
+
+const (
+	docsDir   = "docs"
+	postsDir  = "_posts"
+	draftsDir = "_drafts"
+	// archetypesDir, inside the Jekyll source directory, holds the
+	// skeletons --archetype names.
+	archetypesDir = "_archetypes"
+	imagesDir     = "assets/images"
+)
+
+func run(g *postgen.Generator, s site, p postgen.Post, dryRun, openEditor bool) error {
+	if (opts.GitNoBranch || opts.GitNoCommit) && !opts.Git {
+		return usagef("--git-no-branch and --git-no-commit need --git")
+	}
+	if opts.Git {
+		if err := checkGit(s, p.Slug); err != nil {
+			return err
+		}
+	}
+	if dryRun {
+		r, err := g.Plan(p)
+		if err != nil {
+			return err
+		}
+		if err := printPlan(s, r); err != nil {
+			return err
+		}
+		return g.Collision(r)
+	}
+	r, err := g.Generate(p)
+	if err != nil {
+		return err
+	}
+	if err := printResult(s, "created", r); err != nil {
+		return err
+	}
+	if opts.Git {
+		if err := gitPost(s, r, p.Title); err != nil {
+			return fmt.Errorf("post %s was created but git failed: %w", r.MarkdownPath, err)
+		}
+	}
+	if openEditor {
+		if err := edit(s.path(r.MarkdownPath)); err != nil {
+			return fmt.Errorf("post %s was created but the editor failed: %w", r.MarkdownPath, err)
+		}
+	}
+	return nil
+}
+
+// outlineBody returns the body of the --outline or --outline-file
+// sections, or nil without either.
+func outlineBody(outline, file string) ([]byte, error) {
+	var items []postgen.OutlineItem
+	switch {
+	case file != "":
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("reading outline %s: %w", file, err)
+		}
+		if items, err = postgen.ParseOutline(string(b)); err != nil {
+			return nil, fmt.Errorf("parsing outline %s: %w", file, err)
+		}
+	case outline != "":
+		for _, title := range strings.Split(outline, ",") {
+			if title = strings.TrimSpace(title); title != "" {
+				items = append(items, postgen.OutlineItem{Title: title})
+			}
+		}
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
+	return postgen.OutlineBody(items), nil
+}
+
+// postSlug returns slug, or one generated from title when it is empty,
+// in the form used for file names.
+func postSlug(slug, title string) (string, error) {
+	if slug == "" {
+		slug = title
+	}
+	slug = postgen.Slugify(slug)
+	if slug == "" {
+		return "", postgen.SlugError(title)
+	}
+	return slug, nil
+}
+
+// postDate parses the date of a new post, defaulting to now, and refuses
+// one in the future unless --allow-future is set.
+func postDate(value string, loc *time.Location) (time.Time, error) {
+	now := time.Now().In(loc)
+	if value == "" {
+		return now, nil
+	}
+	date, err := parseDate(value, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if date.After(now) {
+		if !opts.AllowFuture {
+			return time.Time{}, fmt.Errorf("date %s is in the future and Jekyll will not render the post until then, use --allow-future to create it anyway", value)
+		}
+		warnf("date %s is in the future, Jekyll will not render the post until then", value)
+	}
+	return date, nil
+}
+
+// readImages loads the files given with --image, failing before anything
+// is created when one cannot be read.
+func readImages(paths []string) ([]postgen.Image, error) {
+	var images []postgen.Image
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("reading image %s: %w", p, err)
+		}
+		images = append(images, postgen.Image{Name: filepath.Base(p), Data: data})
+	}
+	return images, nil
+}
+
+// readCover loads the --cover file, which must be an image whatever its
+// extension, naming it cover.<ext> after its format.
+func readCover(p string) (*postgen.Image, error) {
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return nil, fmt.Errorf("reading image %s: %w", p, err)
+	}
+	format, err := postgen.ImageFormat(data)
+	if err != nil {
+		return nil, usagef("invalid cover %s: %v", p, err)
+	}
+	ext := format
+	if ext == "jpeg" {
+		ext = "jpg"
+	}
+	return &postgen.Image{Name: "cover." + ext, Data: data}, nil
+}
+
+// optimizeImages shrinks images with postgen.OptimizeImage, reporting the
+// size of each before and after. The files they were read from are left
+// alone.
+func optimizeImages(images []postgen.Image, cfg config) ([]postgen.Image, error) {
+	o := postgen.OptimizeOptions{Quality: postgen.DefaultImageQuality, MaxWidth: postgen.DefaultImageMaxWidth}
+	if cfg.ImageQuality != 0 {
+		o.Quality = cfg.ImageQuality
+	}
+	if cfg.ImageMaxWidth != 0 {
+		o.MaxWidth = cfg.ImageMaxWidth
+	}
+	if o.Quality < 1 || o.Quality > 100 {
+		return nil, fmt.Errorf("invalid image_quality %d: expected 1 to 100", o.Quality)
+	}
+	var optimized []postgen.Image
+	for _, img := range images {
+		small, err := postgen.OptimizeImage(img, o)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Equal(small.Data, img.Data) {
+			infof("kept %s as is: %s", img.Name, byteSize(len(img.Data)))
+		} else {
+			infof("optimized %s: %s -> %s", img.Name, byteSize(len(img.Data)), byteSize(len(small.Data)))
+		}
+		optimized = append(optimized, small)
+	}
+	return optimized, nil
+}
+
+// byteSize formats n bytes in the largest unit keeping it at least 1.
+func byteSize(n int) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+func editExisting(slug string) error {
+	s, _, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	path, err := s.generator(time.UTC).Find(slug)
+	if err != nil {
+		return err
+	}
+	return edit(s.path(path))
+}
+
+var (
+	opts options
+	// parser is kept for config --explain to tell where each value came
+	// from.
+	parser *flags.Parser
+)
+
+func main() {
+	parser = flags.NewParser(&opts, flags.HelpFlag|flags.PassDoubleDash)
+	parser.SubcommandsOptional = true
+	parser.LongDescription = "Creates Jekyll posts and maintains the existing ones. " + exitStatuses
+	anchors, _ := parser.AddCommand("anchors", "pin heading ids", "Keeps the ids of headings, which links to them use, stable when their text changes.", &anchorsCommand{})
+	anchors.AddCommand("check", "find drifted heading ids", "Reports, as a heads-up, the headings whose pinned {#id} no longer matches the id their text would generate; the command does not fail on them.", &anchorsCheckCommand{})
+	anchors.AddCommand("pin", "pin heading ids", "Appends a {#id} attribute with the id kramdown currently generates to every heading without one, so existing links keep working when it is reworded; pinned ids are never changed.", &anchorsPinCommand{})
+	parser.AddCommand("archetypes", "list post archetypes", "Lists the skeletons of _archetypes that --archetype can name.", &archetypesCommand{})
+	parser.AddCommand("archives", "write archive pages", "Writes a page per year, and optionally per month, linking to the posts of that period.", &archivesCommand{})
+	parser.AddCommand("audit", "find old posts to review", "Lists the published posts older than --older-than, oldest first, with their age, their page views from --pageviews-csv, and their reviewed_at and outdated front matter; --mark-outdated sets outdated: true on them, for layouts to show a warning, and --mark-reviewed sets reviewed_at to today.", &auditCommand{})
+	categories, _ := parser.AddCommand("categories", "manage categories", "Works with the categories used across posts.", &categoriesCommand{})
+	categories.AddCommand("list", "list categories", "Lists every category with the number of posts using it, most used first.", &termsListCommand{key: "categories"})
+	categories.AddCommand("rename", "rename a category", "Renames a category in every post, merging it into the new name where both are present, in the _data/categories.yml entry and in the category pages declaring it, renaming those named after it.", &categoriesRenameCommand{})
+	parser.AddCommand("clone", "start a post from an existing one", "Creates a new post with the body and front matter of an existing one, a fresh date and the given title.", &cloneCommand{})
+	parser.AddCommand("completion", "print a shell completion script", "Prints the completion script for bash, zsh or fish, completing commands, flags, post slugs, categories and tags; load it with source <(postgen completion bash).", &completionCommand{})
+	parser.AddCommand("config", "show the effective configuration", "Prints the configuration resulting from .postgen.yml, the POSTGEN_* environment variables and the given flags; with --explain, where each value came from.", &configCommand{})
+	parser.AddCommand("delete", "delete a post and its images", "Removes a post or draft together with its images folder.", &deleteCommand{})
+	parser.AddCommand("describe", "write post descriptions", "Lists the posts without a description; with --auto, writes one taken from the first paragraph of each, never replacing an existing one unless --force.", &describeCommand{})
+	parser.AddCommand("doctor", "cross-check post dates and images folders", "Reports the posts whose front matter date, images folder or image references disagree with the date of their file name, the usual cause of broken images; with --fix, makes the file name the source of truth, renaming folders and rewriting dates and references where that is unambiguous.", &doctorCommand{})
+	parser.AddCommand("dupes", "find duplicated content", "Reports the pairs of posts whose bodies share at least --threshold of their runs of --shingle consecutive words, code blocks left out unless --code, most similar first, with the longest passages they share.", &dupesCommand{})
+	parser.AddCommand("drafts", "list drafts by age", "Lists the drafts, least recently modified first, with their age and word count; with --archive, moves the stale ones into _drafts/archive.", &draftsCommand{})
+	export, _ := parser.AddCommand("export", "convert posts for other platforms", "Converts posts for cross-posting.", &exportCommand{})
+	export.AddCommand("devto", "convert a post for dev.to", "Prints a post as a dev.to article, with dev.to's front matter, a canonical_url pointing at the post, absolute links and images, and the Jekyll Liquid tags translated or removed.", &exportDevToCommand{})
+	featured, _ := parser.AddCommand("featured", "manage featured posts", "Maintains the featured and featured_order front matter of the posts the homepage features, numbering them from 1 without gaps.", &featuredCommand{})
+	featured.AddCommand("add", "feature a post", "Features a post at --position, last by default, or moves it there when featured already; past featured_max posts, 5 by default, the last ones are no longer featured.", &featuredAddCommand{})
+	featured.AddCommand("list", "list featured posts", "Lists the featured posts in order.", &featuredListCommand{})
+	featured.AddCommand("remove", "stop featuring a post", "Removes the featured keys of a post, moving the ones after it up.", &featuredRemoveCommand{})
+	fm, _ := parser.AddCommand("fm", "read and edit front matter", "Reads or sets a front matter key across many posts, leaving everything else untouched.", &fmCommand{})
+	fm.AddCommand("get", "read a key", "Prints the value of a front matter key in each file that has it.", &fmGetCommand{})
+	fm.AddCommand("set", "set a key", "Sets a front matter key in each file, preserving the other keys, comments and the body byte-for-byte.", &fmSetCommand{})
+	images, _ := parser.AddCommand("images", "manage post images", "Checks the images referenced by posts against the images folder and renames images folders after their posts.", &imagesCommand{})
+	images.AddCommand("budget", "check the weight of post images", "Sums the size of each images folder, reporting the posts and files over budget, the total weight of the images and the heaviest files; with --strict, fails when anything is over budget.", &imagesBudgetCommand{})
+	images.AddCommand("check", "find missing and orphaned images", "Reports image references pointing at nonexistent files and image files no post references.", &imagesCheckCommand{})
+	images.AddCommand("reconcile", "rename images folders after their posts", "Renames each dated images folder named after no post to the folder of the single post referencing it, pointing that post's references at the new name; folders referenced by several posts are reported and left alone. The plan is printed first and applied after confirmation.", &imagesReconcileCommand{})
+	parser.AddCommand("import", "adopt a Markdown file as a post", "Creates a post from a Markdown file written elsewhere, merging the generated front matter into the file's own and copying the images it references from next to it into the post's images folder.", &importCommand{})
+	parser.AddCommand("index", "write the index of posts", "Writes a page listing every post by category, newest first, keeping the text above the "+postgen.IndexMarker+" marker.", &indexCommand{})
+	links, _ := parser.AddCommand("links", "check links between posts", "Checks the links in post bodies against the site's posts, pages and files.", &linksCommand{})
+	links.AddCommand("check", "find broken links", "Reports links to posts, pages or files that do not exist, and anchors matching no heading of their target; with --external, also requests every http(s) link.", &linksCheckCommand{})
+	liquid, _ := parser.AddCommand("liquid", "check Liquid tags", "Checks the Liquid tags and outputs of post bodies without evaluating them.", &liquidCommand{})
+	liquid.AddCommand("check", "find broken Liquid", "Reports unclosed tags and outputs, unbalanced or misnested block tags, and post_url and link tags pointing at nothing.", &liquidCheckCommand{})
+	parser.AddCommand("lint", "check post bodies", "Checks the Markdown of post bodies for H1s, skipped heading levels, code fences without a language, bare URLs, trailing whitespace, undefined, unused or repeated reference links and footnotes, images without alt text and links reading \"here\" or a bare URL; a <!-- postgen:lint-disable rule... --> comment disables rules for its post.", &lintCommand{})
+	parser.AddCommand("list", "list existing posts", "Lists the posts in _posts with their date, title and categories, newest first.", &listCommand{})
+	manifest, _ := parser.AddCommand("manifest", "detect unintended edits", "Records checksums of the bodies of published posts, front matter excluded, to catch accidental edits of old posts.", &manifestCommand{})
+	manifest.AddCommand("check", "find edited posts", "Reports the posts whose body changed, and those added or removed, since the manifest was written.", &manifestCheckCommand{})
+	manifest.AddCommand("write", "record post checksums", "Writes the SHA-256 of the body of every published post to "+postgen.ManifestFile+" in the source folder, sorted so it diffs cleanly; with --update, only refreshes the given posts.", &manifestWriteCommand{})
+	parser.AddCommand("new", "create a post", "Creates a post like postgen does without a command; with -i, prompts for the fields not given as flags.", &newCommand{})
+	parser.AddCommand("normalize", "fix line endings and whitespace", "Reports the posts and drafts with a UTF-8 byte order mark, CRLF line endings, trailing whitespace outside code blocks and hard line breaks, or no final newline; --fix fixes them.", &normalizeCommand{})
+	plan, _ := parser.AddCommand("plan", "scaffold planned posts", "Works with content plans, YAML lists of posts to write.", &planCommand{})
+	plan.AddCommand("apply", "create the posts of a plan", "Creates every post listed in a plan file that does not exist yet, reporting the status of each entry; a failing entry does not stop the others.", &planApplyCommand{})
+	parser.AddCommand("preflight", "check a post before publishing", "Runs every check against one post or draft: front matter, Liquid, internal links and images as errors, lint rules, such as missing alt text, a missing description, a stale reading_time and a future date as warnings, and prints a summary; it fails only on errors. preflight_skip in the config file names the checks not run.", &preflightCommand{})
+	parser.AddCommand("preview", "render a post to a standalone HTML page", "Renders a post or draft as a single HTML file to share for review, with inline CSS, its local images embedded as data URIs and the Liquid it cannot evaluate shown as placeholders; with --serve, serves it on localhost, reloading it whenever the post or its images change.", &previewCommand{})
+	parser.AddCommand("publish", "publish a draft", "Moves a draft from _drafts into _posts, dating it with the current time and renaming its images folder.", &publishCommand{})
+	parser.AddCommand("readingtime", "update reading times", "Counts the words of every post and writes the minutes needed to read it to its reading_time front matter key.", &readingTimeCommand{})
+	parser.AddCommand("reconcile", "match file names and titles", "Reports the posts and drafts whose file name slug is not the one their title generates; --fix-filenames renames their files and images folders, redirecting the old permalinks, --fix-titles retitles them after their file names and -i asks which for each post.", &reconcileCommand{})
+	redirects, _ := parser.AddCommand("redirects", "manage redirect_from lists", "Maintains the redirect_from front matter read by the jekyll-redirect-from plugin.", &redirectsCommand{})
+	redirects.AddCommand("add", "redirect an old URL to a post", "Adds a URL to a post's redirect_from list, keeping it sorted, unless it is the permalink of a post or already redirects elsewhere.", &redirectsAddCommand{})
+	redirects.AddCommand("check", "find broken redirects", "Reports redirects shadowing a live permalink or another redirect, redirect chains and loops.", &redirectsCheckCommand{})
+	parser.AddCommand("related", "relate posts to each other", "Writes into the related front matter list of each published post the slugs of the posts most similar to it, by shared categories and tags and by TF-IDF over titles and descriptions; posts with no match over --min-score get no related key.", &relatedCommand{})
+	parser.AddCommand("rename", "retitle a post", "Changes a post's title, renaming its file and images folder and fixing the image paths in its body.", &renameCommand{})
+	parser.AddCommand("roundup", "write a monthly roundup", "Creates a post listing every post published in a month, the previous one by default, with a post_url link and its description, in plain Markdown to annotate before publishing; the roundup archetype is used when the site has one.", &roundupCommand{})
+	parser.AddCommand("scheduled", "list posts not rendered yet", "Lists the posts dated in the future, in the site's timezone, which Jekyll does not render until then, and the posts whose file name and front matter dates are more than a day apart.", &scheduledCommand{})
+	parser.AddCommand("search", "search post bodies", "Prints the lines of post bodies, front matter excluded, matching a text or regular expression, ignoring case unless --case-sensitive, in the posts the filters select.", &searchCommand{})
+	parser.AddCommand("seo", "report the keywords of a post", "Reports the most frequent terms of a post body, stop words excluded, whether its primary keyword appears in the title, description, first paragraph and headings, and a missing or overlong description.", &seoCommand{})
+	series, _ := parser.AddCommand("series", "manage post series", "Works with posts grouped by their series front matter.", &seriesCommand{})
+	series.AddCommand("list", "list series and their parts", "Lists each series with its parts in order, reporting gaps in their numbering.", &seriesListCommand{})
+	series.AddCommand("generate", "write series index pages", "Writes a page per series listing its parts, and the series_prev and series_next front matter of each part.", &seriesGenerateCommand{})
+	sitemap, _ := parser.AddCommand("sitemap", "check the sitemap", "Works with the sitemap of the built site.", &sitemapCommand{})
+	sitemap.AddCommand("check", "cross-check the sitemap with the posts", "Reports the published posts missing from the sitemap, at the permalink their front matter and the permalink setting give them, and the sitemap entries matching no post, page or file.", &sitemapCheckCommand{})
+	snippets, _ := parser.AddCommand("snippets", "manage embedded code", "Keeps the code blocks following <!-- snippet: file#L10-L42 --> directives in sync with their source files.", &snippetsCommand{})
+	snippets.AddCommand("sync", "update embedded code", "Replaces the code block after each snippet directive with the current content of its file, line range or snippet:start/snippet:end region.", &snippetsSyncCommand{})
+	parser.AddCommand("stats", "show posting statistics", "Reports the number of posts per year and month, word counts, the longest gap between posts and the current monthly streak.", &statsCommand{})
+	tags, _ := parser.AddCommand("tags", "manage tags", "Works with the tags, and categories, used across posts.", &tagsCommand{})
+	tags.AddCommand("list", "list tags", "Lists every tag with the number of posts using it, most used first.", &termsListCommand{key: "tags"})
+	tags.AddCommand("rename", "rename a tag", "Renames a tag, and a category of the same name, in every post, merging it into the new name where both are present.", &tagsRenameCommand{})
+	templates, _ := parser.AddCommand("templates", "work with front matter templates", "Works with the templates --template and --archetype read.", &templatesCommand{})
+	templates.AddCommand("funcs", "list template functions", "Lists the functions templates can call, with an example of each and its output.", &templatesFuncsCommand{})
+	parser.AddCommand("toc", "write tables of contents", "Refreshes the list of links to the headings of a post between its <!-- toc --> and <!-- /toc --> markers.", &tocCommand{})
+	parser.AddCommand("touch", "update last_modified_at", "Sets the last_modified_at front matter of each post to the date of the last commit changing it, when that is past a threshold after its date; posts with uncommitted changes are skipped.", &touchCommand{})
+	parser.AddCommand("translate", "translate a post", "Creates a sibling of a post in another language, with its front matter and body to translate, its images left in the original's folder and translation_of naming the original, which gets the reciprocal key.", &translateCommand{})
+	parser.AddCommand("unpublish", "move a post back to drafts", "Moves a post back into the drafts folder under its undated slug with published: false, renaming its images folder; the inverse of publish.", &unpublishCommand{})
+	parser.AddCommand("update-note", "add an entry to a post's Updates section", "Adds a dated entry to the Updates section at the end of a post, newest first, creating the section when missing, and sets its last_modified_at to now.", &updateNoteCommand{})
+	parser.AddCommand("validate", "validate front matter", "Checks the front matter of every post and draft and reports each problem found.", &validateCommand{})
+	parser.AddCommand("wc", "count words", "Counts the words and characters of post bodies, code, HTML and Liquid left out, with their reading time and, with --target, the progress towards a word count.", &wcCommand{})
+	setCommandEnv(parser.Commands())
+	args, err := parser.Parse()
+	if err != nil {
+		if flagsErr, ok := err.(*flags.Error); ok && flagsErr.Type == flags.ErrHelp {
+			fmt.Println(err)
+			os.Exit(0)
+		}
+		fail(err)
+	}
+	if parser.Active != nil {
+		return
+	}
+	if len(args) > 0 {
+		fail(usagef("unknown command %q", args[0]))
+	}
+	if opts.Version {
+		if err := printVersion(); err != nil {
+			fail(err)
+		}
+		return
+	}
+	if err := create(); err != nil {
+		fail(err)
+	}
+}
+
+// create scaffolds the post described by the top-level flags.
+func create() error {
+	if opts.PrintTemplate {
+		text, err := readTemplate(opts.Template)
+		if err != nil {
+			return err
+		}
+		if opts.JSON {
+			return printJSON(map[string]string{"template": text})
+		}
+		fmt.Print(text)
+		return nil
+	}
+	if opts.Edit && opts.DryRun {
+		return usagef("--edit cannot be combined with --dry-run")
+	}
+	if opts.Edit && opts.JSON {
+		return usagef("--edit cannot be combined with --json")
+	}
+	if opts.Quiet && opts.Verbose {
+		return usagef("--quiet cannot be combined with --verbose")
+	}
+	if opts.Outline != "" && opts.OutlineFile != "" {
+		return usagef("--outline cannot be combined with --outline-file")
+	}
+	if opts.Edit && opts.Title == "" && opts.Slug != "" {
+		return editExisting(opts.Slug)
+	}
+	if opts.Title == "" {
+		return usagef("the required flag `-t, --title' was not specified")
+	}
+	slug, err := postSlug(opts.Slug, opts.Title)
+	if err != nil {
+		return err
+	}
+	if opts.CanonicalURL != "" {
+		if _, err := postgen.ParseCanonicalURL(opts.CanonicalURL); err != nil {
+			return usageError{err}
+		}
+	}
+	permalink := ""
+	if opts.Permalink != "" {
+		if permalink, err = postgen.ParsePermalink(opts.Permalink); err != nil {
+			return usageError{err}
+		}
+	}
+	s, cfg, cfgFile, err := loadSite()
+	if err != nil {
+		return err
+	}
+	verbosef("site root: %s", s.root)
+	if cfgFile != "" {
+		verbosef("config file: %s", rel(cfgFile))
+	}
+	if cfg.Author != "" {
+		if err := checkAuthor(s, cfg.Author); err != nil {
+			return err
+		}
+	}
+	if cfg.Lang != "" {
+		if err := cfg.checkLang(cfg.Lang); err != nil {
+			return err
+		}
+	}
+	categories, err := postgen.ParseCategories(cfg.Categories)
+	if err != nil {
+		return usageError{err}
+	}
+	if err := checkCategories(s, categories); err != nil {
+		return err
+	}
+	loc, err := location(s, cfg)
+	if err != nil {
+		return err
+	}
+	date, err := postDate(opts.Date, loc)
+	if err != nil {
+		return err
+	}
+	postImages, err := readImages(opts.Images)
+	if err != nil {
+		return err
+	}
+	var cover *postgen.Image
+	if opts.Cover != "" {
+		if cover, err = readCover(opts.Cover); err != nil {
+			return err
+		}
+	}
+	if opts.Optimize {
+		if postImages, err = optimizeImages(postImages, cfg); err != nil {
+			return err
+		}
+		if cover != nil {
+			optimized, err := optimizeImages([]postgen.Image{*cover}, cfg)
+			if err != nil {
+				return err
+			}
+			cover = &optimized[0]
+		}
+	}
+	body, err := outlineBody(opts.Outline, opts.OutlineFile)
+	if err != nil {
+		return err
+	}
+	vars, err := postgen.ParseVars(opts.Vars)
+	if err != nil {
+		return usageError{err}
+	}
+	tmpl, err := loadTemplate(opts.Template)
+	if err != nil {
+		return err
+	}
+	if opts.Template != "" {
+		verbosef("template: %s", opts.Template)
+	} else {
+		verbosef("template: none, rendering the front_matter format")
+	}
+	var archetype *template.Template
+	if opts.Archetype != "" {
+		if archetype, err = loadArchetype(s, string(opts.Archetype)); err != nil {
+			return err
+		}
+		verbosef("archetype: %s", opts.Archetype)
+	}
+	g := s.generator(loc)
+	g.Template = tmpl
+	g.Force = opts.Force
+	g.AllowDuplicateSlug = opts.AllowDuplicateSlug
+	g.KeepOnError = opts.KeepOnError
+	hints, err := g.TitleHints(opts.Title, slug, postgen.HintOptions{TitleLength: cfg.TitleLength, SlugLength: cfg.SlugLength})
+	if err != nil {
+		return err
+	}
+	for _, h := range hints {
+		warnf("%s", h)
+	}
+	if opts.Strict && len(hints) > 0 {
+		return fmt.Errorf("%d title or slug problem(s) found with --strict, not creating the post", len(hints))
+	}
+	seriesPart := 0
+	if opts.Series != "" {
+		if seriesPart, err = g.NextSeriesPart(opts.Series); err != nil {
+			return err
+		}
+	}
+	p := postgen.Post{
+		Layout:       cfg.Layout,
+		Title:        opts.Title,
+		Description:  opts.Description,
+		CanonicalURL: opts.CanonicalURL,
+		Permalink:    permalink,
+		Slug:         slug,
+		Author:       cfg.Author,
+		Lang:         cfg.Lang,
+		Categories:   categories,
+		Tags:         postgen.ParseTags(cfg.Tags),
+		Series:       opts.Series,
+		SeriesPart:   seriesPart,
+		Date:         date,
+		Draft:        opts.Draft,
+		Images:       postImages,
+		Cover:        cover,
+		Archetype:    archetype,
+		Explicit:     explicitKeys(),
+		Body:         body,
+		Vars:         vars,
+	}
+	return run(g, s, p, opts.DryRun, opts.Edit)
+}
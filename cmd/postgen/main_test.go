@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+// TestMain runs postgen instead of the tests when the test binary is
+// started by runPostgen.
+func TestMain(m *testing.M) {
+	if os.Getenv("RUN_POSTGEN_MAIN") == "1" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// siteFiles are the files of the site the golden tests run postgen in.
+var siteFiles = map[string]string{
+	"docs/_posts/2024-01-15-first-post.markdown": `---
+layout: post
+title:  "First post"
+date:   2024-01-15 09:00:00 +0000
+categories: go
+tags: [go, testing]
+---
+Hello.
+`,
+	"docs/_posts/2024-02-02-untitled.markdown": `---
+layout: post
+date:   2024-02-02 09:00:00 +0000
+---
+Body.
+`,
+	"docs/assets/images/.keep": "",
+}
+
+// brokenPost is added to siteFiles by the tests of unreadable front
+// matter.
+var brokenPost = map[string]string{
+	"docs/_posts/2024-02-01-broken.markdown": `---
+layout: post
+date:   2024-02-01 09:00:00 +0000
+categories: [go
+---
+Body.
+`,
+}
+
+// newTestSite writes siteFiles and extra into a temporary folder and
+// returns it.
+func newTestSite(t *testing.T, extra map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, files := range []map[string]string{siteFiles, extra} {
+		for name, content := range files {
+			p := filepath.Join(dir, filepath.FromSlash(name))
+			if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return dir
+}
+
+// runPostgen runs postgen with args in dir, with the variables of env
+// in place of the POSTGEN_* ones of the environment, returning its
+// stdout, its stderr and its exit status.
+func runPostgen(t *testing.T, dir string, env []string, args ...string) (string, string, int) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Dir = dir
+	cmd.Env = append([]string{"RUN_POSTGEN_MAIN=1"}, env...)
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, "POSTGEN_") {
+			cmd.Env = append(cmd.Env, kv)
+		}
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		t.Fatalf("running postgen: %v", err)
+	}
+	return stdout.String(), stderr.String(), cmd.ProcessState.ExitCode()
+}
+
+// checkGolden compares got with testdata/name.golden, rewriting it with
+// -update.
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	p := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.WriteFile(p, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("%s differs from %s:\n%s", name, p, got)
+	}
+}
+
+func TestJSONGolden(t *testing.T) {
+	create := []string{"--json", "-t", "Second post", "-c", "go", "--tags", "go,json", "--date", "2024-03-01", "--timezone", "UTC"}
+	tests := []struct {
+		name  string
+		files map[string]string
+		// before runs ahead of args in the same site.
+		before []string
+		args   []string
+		status int
+	}{
+		{name: "create", args: create},
+		{name: "create_dry_run", args: append([]string{"--dry-run"}, create...)},
+		{name: "list", args: []string{"--json", "list"}},
+		{name: "list_unreadable", files: brokenPost, args: []string{"--json", "list"}, status: exitFailure},
+		{name: "validate", files: brokenPost, args: []string{"--json", "validate"}, status: exitFailure},
+		{name: "error_post_exists", before: create, args: create, status: exitConflict},
+		{name: "error_usage", args: []string{"--json", "-t", "x", "--date", "yesterday"}, status: exitUsage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := newTestSite(t, tt.files)
+			if tt.before != nil {
+				if _, stderr, status := runPostgen(t, dir, nil, tt.before...); status != 0 {
+					t.Fatalf("postgen %s: exit status %d: %s", strings.Join(tt.before, " "), status, stderr)
+				}
+			}
+			stdout, stderr, status := runPostgen(t, dir, nil, tt.args...)
+			if status != tt.status {
+				t.Errorf("exit status = %d, want %d", status, tt.status)
+			}
+			checkGolden(t, tt.name, stdout+stderr)
+		})
+	}
+}
+
+func TestInvalidFlagValuesAreUsageErrors(t *testing.T) {
+	tests := [][]string{
+		{"-t", "x", "-c", "web dev"},
+		{"-t", "x", "--timezone", "Foo/Bar"},
+		{"-t", "x", "--ext", "txt"},
+		{"-t", "x", "--date", "yesterday"},
+		{"-t", "x", "--jobs", "-1"},
+	}
+	for _, args := range tests {
+		t.Run(strings.Join(args, " "), func(t *testing.T) {
+			_, stderr, status := runPostgen(t, newTestSite(t, nil), nil, args...)
+			if status != exitUsage {
+				t.Errorf("exit status = %d, want %d: %s", status, exitUsage, stderr)
+			}
+		})
+	}
+}
+
+func TestUnknownCommand(t *testing.T) {
+	dir := newTestSite(t, nil)
+	_, stderr, status := runPostgen(t, dir, nil, "-t", "x", "lsit")
+	if status != exitUsage || !strings.Contains(stderr, `unknown command "lsit"`) {
+		t.Errorf("exit status = %d, want %d: %s", status, exitUsage, stderr)
+	}
+	posts, err := filepath.Glob(filepath.Join(dir, "docs", "_posts", "*-x.markdown"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(posts) > 0 {
+		t.Errorf("post created: %s", posts)
+	}
+}
+
+func TestArchetypeKeepsFlags(t *testing.T) {
+	dir := newTestSite(t, map[string]string{
+		"docs/_archetypes/tutorial.md": "---\nlayout: tutorial\ncategories: tutorials\n---\n## Steps\n",
+	})
+	stdout, stderr, status := runPostgen(t, dir, nil, "--dry-run", "-t", "Hello", "--archetype", "tutorial", "-c", "go")
+	if status != 0 {
+		t.Fatalf("exit status %d: %s", status, stderr)
+	}
+	for _, want := range []string{"\nlayout: tutorial\n", "\ncategories: go\n"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("no %q in:\n%s", strings.TrimSpace(want), stdout)
+		}
+	}
+}
+
+func TestVarsFromEnv(t *testing.T) {
+	stdout, stderr, status := runPostgen(t, newTestSite(t, nil), []string{"POSTGEN_VAR=difficulty=easy,level=2"}, "--dry-run", "-t", "Vars")
+	if status != 0 {
+		t.Fatalf("exit status %d: %s", status, stderr)
+	}
+	for _, want := range []string{"\ndifficulty: easy\n", "\nlevel: 2\n"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("no %q in:\n%s", strings.TrimSpace(want), stdout)
+		}
+	}
+}
+
+func TestDoctorWithoutFix(t *testing.T) {
+	dir := newTestSite(t, map[string]string{
+		"docs/_posts/2024-03-01-shifted.markdown": "---\nlayout: post\ntitle: Shifted\ndate: 2024-03-02 09:00:00 +0000\n---\nBody.\n",
+	})
+	stdout, stderr, status := runPostgen(t, dir, nil, "doctor")
+	want := "1 inconsistency(ies) found, 1 can be fixed by 1 repair(s) with --fix"
+	if status != exitFailure || !strings.Contains(stderr, want) {
+		t.Errorf("exit status %d, want %d with %q:\n%s%s", status, exitFailure, want, stdout, stderr)
+	}
+	if strings.Count(stdout+stderr, "inconsistency(ies)") != 1 {
+		t.Errorf("summary printed more than once:\n%s%s", stdout, stderr)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"time"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+type manifestCommand struct{}
+
+type manifestWriteCommand struct {
+	Update []postName `long:"update" value-name:"POST" description:"only refresh the entry of this post after an intentional edit (repeatable)"`
+}
+
+type manifestCheckCommand struct{}
+
+// manifestDiffJSON is the --json form of a postgen.ManifestDiff.
+type manifestDiffJSON struct {
+	File   string `json:"file"`
+	Status string `json:"status"`
+}
+
+func (c *manifestWriteCommand) Execute(args []string) error {
+	s, _, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	g := s.generator(time.UTC)
+	var m postgen.Manifest
+	var bad []*postgen.FileError
+	if len(c.Update) == 0 {
+		if m, bad, err = g.BuildManifest(); err != nil {
+			return err
+		}
+	} else {
+		if m, err = g.ReadManifest(); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return fmt.Errorf("%s does not exist yet, run postgen manifest write", rel(s.path(g.ManifestPath())))
+			}
+			return err
+		}
+		for _, post := range c.Update {
+			p, err := g.Find(string(post))
+			if err != nil {
+				return err
+			}
+			if err := g.UpdateManifest(m, p); err != nil {
+				return err
+			}
+		}
+	}
+	if err := g.WriteManifest(m); err != nil {
+		return err
+	}
+	if opts.JSON {
+		if err := printJSON(map[string]interface{}{"file": rel(s.path(g.ManifestPath())), "posts": len(m)}); err != nil {
+			return err
+		}
+	} else {
+		infof("wrote %s, %d post(s)", rel(s.path(g.ManifestPath())), len(m))
+	}
+	return reportBad(s, bad)
+}
+
+func (c *manifestCheckCommand) Execute(args []string) error {
+	s, _, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	g := s.generator(time.UTC)
+	old, err := g.ReadManifest()
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("%s does not exist yet, run postgen manifest write", rel(s.path(g.ManifestPath())))
+		}
+		return err
+	}
+	diffs, bad, err := g.CheckManifest(old)
+	if err != nil {
+		return err
+	}
+	out := []manifestDiffJSON{}
+	for _, d := range diffs {
+		out = append(out, manifestDiffJSON{rel(s.path(g.PostsDir + "/" + d.Post)), d.Status})
+	}
+	if opts.JSON {
+		if err := printJSON(map[string]interface{}{"posts": out}); err != nil {
+			return err
+		}
+	} else {
+		for _, d := range out {
+			fmt.Printf("%s: %s\n", d.File, d.Status)
+		}
+	}
+	if len(diffs) > 0 {
+		reportBad(s, bad)
+		return fmt.Errorf("%d post(s) differ from the manifest, run postgen manifest write --update <post> after intentional edits", len(diffs))
+	}
+	return reportBad(s, bad)
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+type newCommand struct {
+	Interactive bool `short:"i" long:"interactive" description:"prompt for the title, slug, categories, tags and draft status (only when stdin is a terminal)"`
+}
+
+func (c *newCommand) Execute(args []string) error {
+	if c.Interactive && isTerminal(os.Stdin) {
+		if err := promptPost(); err != nil {
+			return err
+		}
+	}
+	return create()
+}
+
+// promptPost asks for the post fields, offering the flags and config
+// values as defaults, and stores the answers in opts.
+func promptPost() error {
+	s, cfg, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	known, err := usedCategories(s)
+	if err != nil {
+		return err
+	}
+	for opts.Title == "" {
+		if opts.Title, err = ask("Title", ""); err != nil {
+			return err
+		}
+	}
+	slug := opts.Slug
+	if slug == "" {
+		slug = postgen.Slugify(opts.Title)
+	}
+	if opts.Slug, err = ask("Slug", slug); err != nil {
+		return err
+	}
+	if opts.Description, err = ask("Description (optional)", opts.Description); err != nil {
+		return err
+	}
+	if len(known) > 0 {
+		fmt.Fprintf(os.Stderr, "Known categories: %s\n", strings.Join(known, ", "))
+	}
+	categories, err := ask("Categories (comma-separated, unique prefixes are completed)", strings.Join(cfg.Categories, ","))
+	if err != nil {
+		return err
+	}
+	opts.Categories = stringSlice[categoryName](completeCategories(strings.Split(categories, ","), known))
+	tags, err := ask("Tags (comma-separated)", strings.Join(cfg.Tags, ","))
+	if err != nil {
+		return err
+	}
+	opts.Tags = []tagName{tagName(tags)}
+	draft := "n"
+	if opts.Draft {
+		draft = "y"
+	}
+	if draft, err = ask("Draft (y/n)", draft); err != nil {
+		return err
+	}
+	opts.Draft = strings.HasPrefix(strings.ToLower(draft), "y")
+	if !confirm(fmt.Sprintf("create \"%s\" as %s?", opts.Title, opts.Slug)) {
+		return errors.New("aborted")
+	}
+	return nil
+}
+
+// usedCategories returns the categories of the existing posts, sorted.
+func usedCategories(s site) ([]string, error) {
+	entries, _, err := s.generator(time.UTC).List()
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var categories []string
+	for _, e := range entries {
+		for _, c := range e.Meta.Categories {
+			if !seen[c] {
+				seen[c] = true
+				categories = append(categories, c)
+			}
+		}
+	}
+	sort.Strings(categories)
+	return categories, nil
+}
+
+// completeCategories replaces each value that is the prefix of exactly
+// one known category with that category.
+func completeCategories(values, known []string) []string {
+	var completed []string
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v == "" {
+			continue
+		}
+		var matches []string
+		for _, k := range known {
+			if k == v {
+				matches = []string{k}
+				break
+			}
+			if strings.HasPrefix(k, v) {
+				matches = append(matches, k)
+			}
+		}
+		if len(matches) == 1 {
+			v = matches[0]
+		}
+		completed = append(completed, v)
+	}
+	return completed
+}
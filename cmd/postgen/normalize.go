@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+type normalizeCommand struct {
+	previewFlags
+	Fix  bool `long:"fix" description:"fix the issues found instead of reporting them"`
+	Args struct {
+		Files []string `positional-arg-name:"files" description:"files, glob patterns or slugs (defaults to every post and draft)"`
+	} `positional-args:"yes"`
+}
+
+// whitespaceIssueJSON is the --json form of a postgen.WhitespaceIssue.
+type whitespaceIssueJSON struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+func (c *normalizeCommand) Execute(args []string) error {
+	s, _, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	g := s.generator(time.UTC)
+	files, err := s.files(g, c.Args.Files)
+	if err != nil {
+		return err
+	}
+	changes, issues, err := g.PlanNormalize(files)
+	if err != nil {
+		return err
+	}
+	if c.DiffOnly {
+		return printDiffs(s, changes, false)
+	}
+	if c.Fix {
+		if err := c.confirmChanges(s, changes); err != nil {
+			return err
+		}
+		if err := g.WriteChanges(changes); err != nil {
+			return err
+		}
+		return printChanges(s, changes, false)
+	}
+	if opts.JSON {
+		out := []whitespaceIssueJSON{}
+		for _, i := range issues {
+			out = append(out, whitespaceIssueJSON{rel(s.path(i.Path)), i.Line, i.Message})
+		}
+		if err := printJSON(out); err != nil {
+			return err
+		}
+	} else {
+		for _, i := range issues {
+			fmt.Printf("%s:%d: %s\n", rel(s.path(i.Path)), i.Line, i.Message)
+		}
+	}
+	if len(issues) > 0 {
+		return fmt.Errorf("%d whitespace issue(s) found in %d file(s), fix them with --fix", len(issues), len(changes))
+	}
+	return nil
+}
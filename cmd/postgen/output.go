@@ -0,0 +1,194 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/jessevdk/go-flags"
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+// The exit statuses of a failed run.
+const (
+	exitFailure  = 1
+	exitUsage    = 2
+	exitConflict = 3
+	exitIO       = 4
+	exitTemplate = 5
+	exitNoRoot   = 6
+	// exitInterrupted, that of a shell for a command killed by SIGINT,
+	// is returned by the runs interrupted after printing what they found.
+	exitInterrupted = 130
+)
+
+// exitStatuses documents the exit statuses in the help text.
+const exitStatuses = "Exit status: 0 on success, 2 for usage errors and titles no slug can be generated from, 3 when an existing post, file, folder or redirect is in the way, 4 for I/O failures, 5 when a template or archetype fails to parse or execute, 6 when the site root or posts folder cannot be found, 130 when interrupted with Ctrl-C after printing the results so far, and 1 for anything else."
+
+// errorKinds names the sentinel errors in the kind of --json errors, the
+// most specific first.
+var errorKinds = []struct {
+	err  error
+	name string
+}{
+	{postgen.ErrPostExists, "post_exists"},
+	{postgen.ErrConflict, "conflict"},
+	{postgen.ErrInvalidTitle, "invalid_title"},
+	{postgen.ErrTemplate, "template"},
+	{postgen.ErrRootNotFound, "root_not_found"},
+	{postgen.ErrIO, "io"},
+	{postgen.ErrInterrupted, "interrupted"},
+}
+
+// usageError is an error in how postgen was invoked.
+type usageError struct {
+	error
+}
+
+func usagef(format string, args ...interface{}) error {
+	return usageError{fmt.Errorf(format, args...)}
+}
+
+// exitStatus maps err to the exit status of the run it ended.
+func exitStatus(err error) int {
+	var (
+		flagsErr *flags.Error
+		usage    usageError
+		pathErr  *fs.PathError
+	)
+	switch {
+	case errors.As(err, &flagsErr), errors.As(err, &usage), errors.Is(err, postgen.ErrInvalidTitle):
+		return exitUsage
+	case errors.Is(err, postgen.ErrConflict):
+		return exitConflict
+	case errors.Is(err, postgen.ErrTemplate):
+		return exitTemplate
+	case errors.Is(err, postgen.ErrRootNotFound):
+		return exitNoRoot
+	case errors.Is(err, postgen.ErrIO), errors.As(err, &pathErr):
+		return exitIO
+	case errors.Is(err, postgen.ErrInterrupted):
+		return exitInterrupted
+	}
+	return exitFailure
+}
+
+// fail prints err to stderr, as {"error": "...", "kind": "..."} with
+// --json, kind naming the sentinel error it matches if any, and exits with
+// the status exitStatus maps it to.
+func fail(err error) {
+	if opts.JSON {
+		out := map[string]string{"error": err.Error()}
+		for _, k := range errorKinds {
+			if errors.Is(err, k.err) {
+				out["kind"] = k.name
+				break
+			}
+		}
+		writeJSON(os.Stderr, out)
+	} else {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	os.Exit(exitStatus(err))
+}
+
+// warnf prints a warning to stderr, keeping stdout clean for scripts.
+func warnf(format string, args ...interface{}) {
+	if opts.JSON {
+		writeJSON(os.Stderr, map[string]string{"warning": fmt.Sprintf(format, args...)})
+		return
+	}
+	fmt.Fprintf(os.Stderr, "warning: "+format+"\n", args...)
+}
+
+// infof prints a progress message unless --quiet or --json is set.
+func infof(format string, args ...interface{}) {
+	if !opts.Quiet && !opts.JSON {
+		fmt.Printf(format+"\n", args...)
+	}
+}
+
+// verbosef prints a detail only shown with --verbose to stderr, keeping
+// stdout for results.
+func verbosef(format string, args ...interface{}) {
+	if opts.Verbose && !opts.JSON {
+		fmt.Fprintf(os.Stderr, format+"\n", args...)
+	}
+}
+
+// printJSON prints v to stdout as the single JSON document of a --json
+// run.
+func printJSON(v interface{}) error {
+	return writeJSON(os.Stdout, v)
+}
+
+func writeJSON(f *os.File, v interface{}) error {
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// resultJSON is the --json form of a created or published post.
+type resultJSON struct {
+	MarkdownPath string   `json:"markdownPath"`
+	ImagesPath   string   `json:"imagesPath"`
+	Slug         string   `json:"slug"`
+	Date         string   `json:"date"`
+	Images       []string `json:"images"`
+	// Content is only set for dry runs.
+	Content string `json:"content,omitempty"`
+}
+
+func newResultJSON(s site, r postgen.Result) resultJSON {
+	out := resultJSON{
+		MarkdownPath: rel(s.path(r.MarkdownPath)),
+		Slug:         r.Slug,
+		Date:         r.Date.Format(time.RFC3339),
+		Images:       []string{},
+	}
+	if r.ImagesPath != "" {
+		out.ImagesPath = rel(s.path(r.ImagesPath))
+	}
+	for _, img := range r.Images {
+		out.Images = append(out.Images, rel(s.path(img)))
+	}
+	return out
+}
+
+// printResult reports the post described by r. With --quiet only its path
+// is printed, so that it can be captured by scripts.
+func printResult(s site, action string, r postgen.Result) error {
+	if opts.JSON {
+		return printJSON(newResultJSON(s, r))
+	}
+	if opts.Quiet {
+		fmt.Println(rel(s.path(r.MarkdownPath)))
+		return nil
+	}
+	fmt.Printf("%s %s\n", action, rel(s.path(r.MarkdownPath)))
+	if r.ImagesPath != "" {
+		verbosef("images folder: %s", rel(s.path(r.ImagesPath)))
+	}
+	for _, img := range r.Images {
+		verbosef("image: %s", rel(s.path(img)))
+	}
+	return nil
+}
+
+// printPlan reports the post a dry run would create, followed by its
+// content.
+func printPlan(s site, r postgen.Result) error {
+	if opts.JSON {
+		out := newResultJSON(s, r)
+		out.Content = string(r.Content)
+		return printJSON(out)
+	}
+	printResult(s, "would create", r)
+	if !opts.Quiet {
+		fmt.Printf("%s", r.Content)
+	}
+	return nil
+}
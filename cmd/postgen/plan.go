@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+	"gopkg.in/yaml.v3"
+)
+
+type planCommand struct{}
+
+// planEntry is a post listed in a plan file. Categories and tags default
+// to the configured ones.
+type planEntry struct {
+	Title      string   `yaml:"title"`
+	Slug       string   `yaml:"slug"`
+	Date       string   `yaml:"date"`
+	Categories []string `yaml:"categories"`
+	Tags       []string `yaml:"tags"`
+	Draft      bool     `yaml:"draft"`
+}
+
+type planApplyCommand struct {
+	DryRun bool `short:"n" long:"dry-run" description:"print the post each entry resolves to without writing anything"`
+	Args   struct {
+		File string `positional-arg-name:"plan" description:"YAML file listing the posts, each with a title and optionally a slug, date, categories, tags and draft flag"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+// planStatusJSON is the --json form of the outcome of a plan entry.
+type planStatusJSON struct {
+	// Entry is the position of the entry in the plan, from 1.
+	Entry        int    `json:"entry"`
+	Title        string `json:"title"`
+	Status       string `json:"status"`
+	MarkdownPath string `json:"markdownPath,omitempty"`
+	Error        string `json:"error,omitempty"`
+	// Content is only set for dry runs.
+	Content string `json:"content,omitempty"`
+}
+
+func (c *planApplyCommand) Execute(args []string) error {
+	entries, err := readPlan(c.Args.File)
+	if err != nil {
+		return err
+	}
+	s, cfg, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	if cfg.Author != "" {
+		if err := checkAuthor(s, cfg.Author); err != nil {
+			return err
+		}
+	}
+	if cfg.Lang != "" {
+		if err := cfg.checkLang(cfg.Lang); err != nil {
+			return err
+		}
+	}
+	loc, err := location(s, cfg)
+	if err != nil {
+		return err
+	}
+	g := s.generator(loc)
+	statuses := []planStatusJSON{}
+	planned := make(map[string]int)
+	failed := 0
+	for i, e := range entries {
+		status := planStatusJSON{Entry: i + 1, Title: e.Title}
+		p, r, err := planPost(g, loc, cfg, e)
+		if err == nil {
+			err = checkCategories(s, p.Categories)
+		}
+		switch {
+		case err != nil:
+			status.Status, status.Error = "failed", err.Error()
+		case planned[r.MarkdownPath] > 0:
+			status.Status, status.MarkdownPath = "failed", rel(s.path(r.MarkdownPath))
+			status.Error = fmt.Sprintf("same file as entry %d", planned[r.MarkdownPath])
+		case g.Collision(r) != nil:
+			status.Status, status.MarkdownPath, status.Error = "skipped", rel(s.path(r.MarkdownPath)), "already exists"
+		case c.DryRun:
+			status.Status, status.MarkdownPath, status.Content = "would create", rel(s.path(r.MarkdownPath)), string(r.Content)
+		default:
+			status.Status, status.MarkdownPath = "created", rel(s.path(r.MarkdownPath))
+			if _, err := g.Generate(p); err != nil {
+				status.Status, status.Error = "failed", err.Error()
+			}
+		}
+		if err == nil {
+			planned[r.MarkdownPath] = i + 1
+		}
+		if status.Status == "failed" {
+			failed++
+		}
+		statuses = append(statuses, status)
+	}
+	if opts.JSON {
+		if err := printJSON(struct {
+			Entries []planStatusJSON `json:"entries"`
+			DryRun  bool             `json:"dryRun"`
+		}{statuses, c.DryRun}); err != nil {
+			return err
+		}
+	} else {
+		printPlanStatuses(statuses)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d entries failed", failed, len(entries))
+	}
+	return nil
+}
+
+// readPlan reads the entries of the plan file p, failing on unknown keys
+// so that typos do not silently drop a field.
+func readPlan(p string) ([]planEntry, error) {
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return nil, fmt.Errorf("reading plan %s: %w", p, err)
+	}
+	var entries []planEntry
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(&entries); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("parsing plan %s: %w", p, err)
+	}
+	return entries, nil
+}
+
+// planPost resolves e the way create resolves the top-level flags, and
+// renders the post it stands for.
+func planPost(g *postgen.Generator, loc *time.Location, cfg config, e planEntry) (postgen.Post, postgen.Result, error) {
+	if e.Title == "" {
+		return postgen.Post{}, postgen.Result{}, errors.New("missing title")
+	}
+	slug, err := postSlug(e.Slug, e.Title)
+	if err != nil {
+		return postgen.Post{}, postgen.Result{}, err
+	}
+	date, err := postDate(e.Date, loc)
+	if err != nil {
+		return postgen.Post{}, postgen.Result{}, err
+	}
+	if len(e.Categories) == 0 {
+		e.Categories = cfg.Categories
+	}
+	if len(e.Tags) == 0 {
+		e.Tags = cfg.Tags
+	}
+	categories, err := postgen.ParseCategories(e.Categories)
+	if err != nil {
+		return postgen.Post{}, postgen.Result{}, err
+	}
+	p := postgen.Post{
+		Layout:     cfg.Layout,
+		Title:      e.Title,
+		Slug:       slug,
+		Author:     cfg.Author,
+		Lang:       cfg.Lang,
+		Categories: categories,
+		Tags:       postgen.ParseTags(e.Tags),
+		Date:       date,
+		Draft:      e.Draft,
+	}
+	r, err := g.Plan(p)
+	if err != nil {
+		return postgen.Post{}, postgen.Result{}, err
+	}
+	return p, r, nil
+}
+
+// printPlanStatuses prints the outcome of each entry, followed by the
+// content of the posts a dry run would create.
+func printPlanStatuses(statuses []planStatusJSON) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, st := range statuses {
+		target := st.MarkdownPath
+		switch {
+		case target != "":
+		case st.Title != "":
+			target = fmt.Sprintf("entry %d \"%s\"", st.Entry, st.Title)
+		default:
+			target = fmt.Sprintf("entry %d", st.Entry)
+		}
+		if st.Error != "" {
+			target += ": " + st.Error
+		}
+		fmt.Fprintf(w, "%s\t%s\n", st.Status, target)
+	}
+	w.Flush()
+	if opts.Quiet {
+		return
+	}
+	for _, st := range statuses {
+		if st.Content != "" {
+			fmt.Printf("\n# %s\n%s", st.MarkdownPath, st.Content)
+		}
+	}
+}
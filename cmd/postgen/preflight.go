@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+// The severities of preflight checks: errors fail the run, warnings are
+// only reported.
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+)
+
+// preflightChecks names the checks preflight runs, in order, which
+// preflight_skip can name.
+var preflightChecks = []string{"validate", "liquid", "links", "images", "lint", "description", "readingtime", "scheduled"}
+
+type preflightCommand struct {
+	Args struct {
+		Post postName `positional-arg-name:"post" description:"slug or file name of the post or draft"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+// preflightFinding is a problem a check found.
+type preflightFinding struct {
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+// preflightResult is the outcome of a check, and its --json form.
+type preflightResult struct {
+	Name     string             `json:"name"`
+	Severity string             `json:"severity"`
+	Skipped  bool               `json:"skipped,omitempty"`
+	Findings []preflightFinding `json:"findings"`
+}
+
+func (c *preflightCommand) Execute(args []string) error {
+	s, cfg, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	skip := make(map[string]bool)
+	for _, name := range cfg.PreflightSkip {
+		skip[name] = true
+	}
+	for _, name := range preflightChecks {
+		delete(skip, name)
+	}
+	for name := range skip {
+		return usagef("unknown preflight check \"%s\" in preflight_skip, expected one of %s", name, strings.Join(preflightChecks, ", "))
+	}
+	for _, name := range cfg.PreflightSkip {
+		skip[name] = true
+	}
+	loc, err := location(s, cfg)
+	if err != nil {
+		return err
+	}
+	g := s.generator(loc)
+	p, err := g.Find(string(c.Args.Post))
+	if err != nil {
+		return err
+	}
+	e, err := g.Read(p)
+	if err != nil {
+		return fmt.Errorf("parsing %s: %w", p, err)
+	}
+
+	checks := map[string]struct {
+		severity string
+		run      func() ([]preflightFinding, error)
+	}{
+		"validate": {severityError, func() ([]preflightFinding, error) {
+			validateOpts, err := validateOptions(s, cfg)
+			if err != nil {
+				return nil, err
+			}
+			site, err := g.ValidateSite(validateOpts)
+			if err != nil {
+				return nil, err
+			}
+			var findings []preflightFinding
+			for _, pr := range append(g.ValidateFile(p, validateOpts), site...) {
+				if pr.Path == p {
+					findings = append(findings, preflightFinding{pr.Line, pr.Field + ": " + pr.Message})
+				}
+			}
+			return findings, nil
+		}},
+		"liquid": {severityError, func() ([]preflightFinding, error) {
+			problems, err := g.CheckLiquid([]string{p})
+			var findings []preflightFinding
+			for _, pr := range problems {
+				findings = append(findings, preflightFinding{pr.Line, pr.Message})
+			}
+			return findings, err
+		}},
+		"links": {severityError, func() ([]preflightFinding, error) {
+			broken, _, err := g.CheckLinks(context.Background(), linkOptions(s, cfg))
+			var findings []preflightFinding
+			for _, b := range broken {
+				if b.Post == p {
+					findings = append(findings, preflightFinding{b.Line, b.URL + ": " + b.Reason})
+				}
+			}
+			return findings, err
+		}},
+		"images": {severityError, func() ([]preflightFinding, error) {
+			content, err := g.FS.ReadFile(p)
+			if err != nil {
+				return nil, fmt.Errorf("reading file %s: %w", p, err)
+			}
+			var findings []preflightFinding
+			for _, ref := range g.ImageRefs(p, content) {
+				if _, err := g.FS.Stat(ref.Path); err != nil {
+					findings = append(findings, preflightFinding{ref.Line, fmt.Sprintf("image %s does not exist", ref.URL)})
+				}
+			}
+			return findings, nil
+		}},
+		"lint": {severityWarning, func() ([]preflightFinding, error) {
+			disabled := make(map[string]bool)
+			for _, name := range cfg.LintDisable {
+				disabled[name] = true
+			}
+			problems, err := g.LintFiles([]string{p}, disabled)
+			var findings []preflightFinding
+			for _, pr := range problems {
+				findings = append(findings, preflightFinding{pr.Line, pr.Rule + ": " + pr.Message})
+			}
+			return findings, err
+		}},
+		"description": {severityWarning, func() ([]preflightFinding, error) {
+			if strings.TrimSpace(e.Meta.Description) == "" {
+				return []preflightFinding{{Message: "no description, search engines and feeds will show an excerpt instead"}}, nil
+			}
+			return nil, nil
+		}},
+		"readingtime": {severityWarning, func() ([]preflightFinding, error) {
+			wpm := cfg.WordsPerMinute
+			if wpm <= 0 {
+				wpm = postgen.DefaultWordsPerMinute
+			}
+			ch, stale, err := g.PlanReadingTime(p, wpm)
+			if err != nil || !stale || ch.Stored == "" {
+				return nil, err
+			}
+			return []preflightFinding{{Message: fmt.Sprintf("reading_time is %s, the body now reads in %d minute(s), run postgen readingtime", strings.TrimSpace(ch.Stored), ch.Minutes)}}, nil
+		}},
+		"scheduled": {severityWarning, func() ([]preflightFinding, error) {
+			if !g.IsDraft(p) && e.Date.After(g.Now()) {
+				return []preflightFinding{{Message: fmt.Sprintf("dated %s, in the future: Jekyll does not render it until then", e.Date.Format(postgen.DateLayout))}}, nil
+			}
+			return nil, nil
+		}},
+	}
+
+	var results []preflightResult
+	failed, warned := 0, 0
+	for _, name := range preflightChecks {
+		check := checks[name]
+		r := preflightResult{Name: name, Severity: check.severity, Skipped: skip[name], Findings: []preflightFinding{}}
+		if !r.Skipped {
+			findings, err := check.run()
+			if err != nil {
+				return err
+			}
+			r.Findings = append(r.Findings, findings...)
+		}
+		if r.Severity == severityError {
+			failed += len(r.Findings)
+		} else {
+			warned += len(r.Findings)
+		}
+		results = append(results, r)
+	}
+
+	if opts.JSON {
+		err = printJSON(map[string]interface{}{"file": rel(s.path(p)), "passed": failed == 0, "checks": results})
+	} else {
+		err = printPreflight(rel(s.path(p)), results)
+	}
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("preflight failed: %d error(s), %d warning(s)", failed, warned)
+	}
+	infof("preflight passed with %d warning(s)", warned)
+	return nil
+}
+
+// printPreflight prints the status of each check, then their findings
+// grouped by severity.
+func printPreflight(file string, results []preflightResult) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, r := range results {
+		status := "ok"
+		switch {
+		case r.Skipped:
+			status = "skip"
+		case len(r.Findings) > 0 && r.Severity == severityError:
+			status = fmt.Sprintf("FAIL\t%d error(s)", len(r.Findings))
+		case len(r.Findings) > 0:
+			status = fmt.Sprintf("warn\t%d warning(s)", len(r.Findings))
+		}
+		fmt.Fprintf(w, "%s\t%s\n", r.Name, status)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	for _, severity := range []string{severityError, severityWarning} {
+		printed := false
+		for _, r := range results {
+			if r.Severity != severity {
+				continue
+			}
+			for _, f := range r.Findings {
+				if !printed {
+					fmt.Printf("\n%ss:\n", severity)
+					printed = true
+				}
+				if f.Line > 0 {
+					fmt.Printf("  %s:%d: %s: %s\n", file, f.Line, r.Name, f.Message)
+				} else {
+					fmt.Printf("  %s: %s: %s\n", file, r.Name, f.Message)
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+	"github.com/tiagomelo/tiagomelo.github.io/postgen/diff"
+)
+
+// previewLimit is how many files a preview shows on a terminal before
+// summing up the rest; --diff-only always shows them all.
+const previewLimit = 20
+
+// previewFlags are the flags of the bulk edits that show their changes
+// as a diff and ask before writing them.
+type previewFlags struct {
+	Yes      bool `short:"y" long:"yes" description:"write the changes without showing them and asking for confirmation"`
+	DiffOnly bool `long:"diff-only" description:"print the changes as a unified diff and exit without writing them"`
+}
+
+// confirmChanges shows changes as a unified diff and asks whether to
+// write them, failing when the answer is no. Nothing is asked with
+// --yes, with --json or when there is nothing to change.
+func (f previewFlags) confirmChanges(s site, changes []postgen.Change) error {
+	if f.Yes || opts.JSON || len(changes) == 0 {
+		return nil
+	}
+	if err := printDiffs(s, changes, isTerminal(os.Stdout)); err != nil {
+		return err
+	}
+	if !confirm(fmt.Sprintf("write %d file(s)?", len(changes))) {
+		return errors.New("aborted")
+	}
+	return nil
+}
+
+// printDiffs prints changes as unified diffs, colored when stdout is a
+// terminal, stopping after previewLimit files when truncate is set.
+func printDiffs(s site, changes []postgen.Change, truncate bool) error {
+	if opts.JSON {
+		out := []map[string]string{}
+		for _, ch := range changes {
+			out = append(out, map[string]string{"file": rel(s.path(ch.Path)), "diff": unifiedChange(ch)})
+		}
+		return printJSON(out)
+	}
+	color := isTerminal(os.Stdout) && os.Getenv("NO_COLOR") == ""
+	for i, ch := range changes {
+		if truncate && i == previewLimit {
+			fmt.Printf("... and %d more file(s), print them all with --diff-only\n", len(changes)-i)
+			break
+		}
+		d := unifiedChange(ch)
+		if color {
+			d = colorDiff(d)
+		}
+		fmt.Print(d)
+	}
+	return nil
+}
+
+// unifiedChange returns ch as a unified diff with git's a/ and b/
+// prefixes, so that it applies from the site root.
+func unifiedChange(ch postgen.Change) string {
+	return diff.Unified("a/"+ch.Path, "b/"+ch.Path, string(ch.OldContent), string(ch.NewContent), 3)
+}
+
+// colorDiff colors the file headers, hunk headers and the removed and
+// added lines of the unified diff d.
+func colorDiff(d string) string {
+	lines := strings.SplitAfter(d, "\n")
+	for i, line := range lines {
+		code := ""
+		switch {
+		case strings.HasPrefix(line, "--- "), strings.HasPrefix(line, "+++ "):
+			code = "1"
+		case strings.HasPrefix(line, "@@"):
+			code = "36"
+		case strings.HasPrefix(line, "-"):
+			code = "31"
+		case strings.HasPrefix(line, "+"):
+			code = "32"
+		}
+		if code != "" {
+			text := strings.TrimSuffix(line, "\n")
+			lines[i] = "\x1b[" + code + "m" + text + "\x1b[0m" + line[len(text):]
+		}
+	}
+	return strings.Join(lines, "")
+}
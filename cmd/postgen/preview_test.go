@@ -0,0 +1,73 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// previewPost is the post the bulk edits of TestBulkEditsConfirm change.
+const previewPost = "docs/_posts/2024-03-01-preview.markdown"
+
+func TestBulkEditsConfirm(t *testing.T) {
+	extra := map[string]string{
+		previewPost: `---
+layout: post
+title:  "Preview"
+date:   2024-03-01 09:00:00 +0000
+categories: go
+---
+<!-- toc -->
+<!-- /toc -->
+
+## Setup
+
+![](/assets/images/2024-03-01-preview/go-logo.png)
+
+<!-- snippet: main.go -->
+`,
+		"main.go": "package main\n",
+	}
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"toc", []string{"toc", "--all"}},
+		{"categories rename", []string{"categories", "rename", "go", "golang"}},
+		{"snippets sync", []string{"snippets", "sync", previewPost}},
+		{"lint", []string{"lint", "--fix-alt-from-filename", previewPost}},
+		{"redirects add", []string{"redirects", "add", "preview", "/old-preview/"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := newTestSite(t, extra)
+			post := filepath.Join(dir, filepath.FromSlash(previewPost))
+			unchanged := func() bool {
+				b, err := os.ReadFile(post)
+				return err == nil && string(b) == extra[previewPost]
+			}
+
+			stdout, stderr, status := runPostgen(t, dir, nil, append(tt.args, "--diff-only")...)
+			if status != 0 || !strings.Contains(stdout, "+++ b/"+previewPost) {
+				t.Errorf("--diff-only: exit status %d, no diff of %s:\n%s%s", status, previewPost, stdout, stderr)
+			}
+			if !unchanged() {
+				t.Fatalf("--diff-only wrote %s", previewPost)
+			}
+
+			_, stderr, status = runPostgen(t, dir, nil, tt.args...)
+			if status == 0 || !strings.Contains(stderr, "aborted") {
+				t.Errorf("declined: exit status %d: %s", status, stderr)
+			}
+			if !unchanged() {
+				t.Fatalf("declined changes written to %s", previewPost)
+			}
+
+			runPostgen(t, dir, nil, append(tt.args, "--yes")...)
+			if unchanged() {
+				t.Errorf("--yes did not write %s", previewPost)
+			}
+		})
+	}
+}
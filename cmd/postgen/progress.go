@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+	"golang.org/x/term"
+)
+
+// progressLine shows on stderr how far a site-wide operation got: the
+// files done out of the total and the last one done.
+type progressLine struct {
+	mu      sync.Mutex
+	shown   bool
+	stopped bool
+}
+
+// progress is the line the generators of s.generator report to when
+// stderr is a terminal.
+var progress progressLine
+
+// report is a postgen.Generator Progress, clearing the line once the
+// last file is done.
+func (p *progressLine) report(done, total int, name string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stopped {
+		return
+	}
+	if done == total {
+		p.clear()
+		return
+	}
+	line := []rune(fmt.Sprintf("[%d/%d] %s", done, total, name))
+	if width, _, err := term.GetSize(int(os.Stderr.Fd())); err == nil && width > 1 && len(line) >= width {
+		line = line[:width-1]
+	}
+	fmt.Fprintf(os.Stderr, "\r\033[K%s", string(line))
+	p.shown = true
+}
+
+// stop clears the line for good, so that partial results print cleanly
+// while the files in flight are finished.
+func (p *progressLine) stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clear()
+	p.stopped = true
+}
+
+func (p *progressLine) clear() {
+	if p.shown {
+		fmt.Fprint(os.Stderr, "\r\033[K")
+		p.shown = false
+	}
+}
+
+// progressName is how a file, or URL, reported to Progress is shown.
+func progressName(s site, name string) string {
+	if strings.Contains(name, "://") {
+		return name
+	}
+	return rel(s.path(name))
+}
+
+// interruptContext returns a context canceled by the first SIGINT, upon
+// which site-wide operations finish the files in flight and return what
+// they found so far; a second SIGINT kills postgen as usual. The
+// returned function releases the signal.
+func interruptContext() (context.Context, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	go func() {
+		select {
+		case <-signals:
+			signal.Stop(signals)
+			progress.stop()
+			warnf("interrupted, finishing the files in flight; interrupt again to quit at once")
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, func() {
+		signal.Stop(signals)
+		cancel()
+	}
+}
+
+// interrupted reports whether err is that of an operation stopped by
+// interruptContext, whose partial results are still to be printed.
+func interrupted(err error) bool {
+	return errors.Is(err, postgen.ErrInterrupted)
+}
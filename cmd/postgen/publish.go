@@ -0,0 +1,29 @@
+package main
+
+import (
+	"path/filepath"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+type publishCommand struct {
+	Args struct {
+		Slug draftName `positional-arg-name:"slug" description:"slug or file name of the draft to publish"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+func (c *publishCommand) Execute(args []string) error {
+	s, cfg, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	loc, err := location(s, cfg)
+	if err != nil {
+		return err
+	}
+	r, err := s.generator(loc).Publish(postgen.TrimExt(filepath.Base(string(c.Args.Slug))))
+	if err != nil {
+		return err
+	}
+	return printResult(s, "published", r)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+type readingTimeCommand struct {
+	previewFlags
+	WPM   int  `long:"wpm" description:"reading speed in words per minute (defaults to the config file's words_per_minute, or 200)"`
+	Check bool `long:"check" description:"only report posts whose reading_time is missing or stale, failing if there are any"`
+}
+
+// readingTimeJSON is the --json form of a reading time update.
+type readingTimeJSON struct {
+	File    string `json:"file"`
+	Stored  string `json:"stored"`
+	Minutes int    `json:"minutes"`
+}
+
+func (c *readingTimeCommand) Execute(args []string) error {
+	s, cfg, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	wpm := cfg.WordsPerMinute
+	if c.WPM != 0 {
+		wpm = c.WPM
+	}
+	if wpm == 0 {
+		wpm = postgen.DefaultWordsPerMinute
+	}
+	g := s.generator(time.UTC)
+	changes, err := g.ReadingTimes(wpm)
+	if err != nil {
+		return err
+	}
+	if !c.Check {
+		plain := make([]postgen.Change, len(changes))
+		for i, ch := range changes {
+			plain[i] = ch.Change
+		}
+		if c.DiffOnly {
+			return printDiffs(s, plain, false)
+		}
+		if err := c.confirmChanges(s, plain); err != nil {
+			return err
+		}
+		if err := g.WriteChanges(plain); err != nil {
+			return err
+		}
+	}
+	out := []readingTimeJSON{}
+	for _, ch := range changes {
+		out = append(out, readingTimeJSON{rel(s.path(ch.Path)), ch.Stored, ch.Minutes})
+	}
+	if opts.JSON {
+		if err := printJSON(out); err != nil {
+			return err
+		}
+	}
+	for _, ch := range out {
+		switch {
+		case opts.JSON:
+		case c.Check && ch.Stored == "":
+			fmt.Printf("%s: reading_time is missing, expected %d\n", ch.File, ch.Minutes)
+		case c.Check:
+			fmt.Printf("%s: reading_time is %s, expected %d\n", ch.File, ch.Stored, ch.Minutes)
+		default:
+			infof("%s: reading_time set to %d", ch.File, ch.Minutes)
+		}
+	}
+	if c.Check && len(changes) > 0 {
+		return fmt.Errorf("%d post(s) have a missing or stale reading_time", len(changes))
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+type reconcileCommand struct {
+	FixFilenames bool `long:"fix-filenames" description:"rename the files and images folders of the mismatched posts after their titles, redirecting their old permalinks"`
+	FixTitles    bool `long:"fix-titles" description:"retitle the mismatched posts after their file names, the opposite direction"`
+	Interactive  bool `short:"i" long:"interactive" description:"ask for each mismatched post which side to fix (only when stdin is a terminal)"`
+	DryRun       bool `short:"n" long:"dry-run" description:"show the planned renames and changes as a diff without applying them"`
+	Args         struct {
+		Files []string `positional-arg-name:"files" description:"files, glob patterns or slugs (defaults to every post and draft)"`
+	} `positional-args:"yes"`
+}
+
+// reconcileJSON is the --json form of reconcile's fixes.
+type reconcileJSON struct {
+	Renamed  []renameJSON `json:"renamed"`
+	Retitled []string     `json:"retitled"`
+	Skipped  []string     `json:"skipped"`
+	DryRun   bool         `json:"dryRun"`
+}
+
+func (c *reconcileCommand) Execute(args []string) error {
+	modes := 0
+	for _, set := range []bool{c.FixFilenames, c.FixTitles, c.Interactive} {
+		if set {
+			modes++
+		}
+	}
+	switch {
+	case modes > 1:
+		return usagef("--fix-filenames, --fix-titles and --interactive are mutually exclusive")
+	case c.Interactive && !isTerminal(os.Stdin):
+		return usagef("--interactive needs stdin to be a terminal")
+	case c.DryRun && modes == 0:
+		return usagef("--dry-run needs --fix-filenames, --fix-titles or --interactive")
+	}
+	s, cfg, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	g := s.generator(time.UTC)
+	files, err := s.files(g, c.Args.Files)
+	if err != nil {
+		return err
+	}
+	mismatches, err := g.SlugMismatches(files)
+	if err != nil {
+		return err
+	}
+	if modes == 0 {
+		if opts.JSON {
+			out := []map[string]string{}
+			for _, m := range mismatches {
+				out = append(out, map[string]string{"file": rel(s.path(m.Entry.Path)), "title": m.Entry.Meta.Title, "titleSlug": m.TitleSlug, "fileSlug": m.Entry.Slug})
+			}
+			if err := printJSON(out); err != nil {
+				return err
+			}
+		} else {
+			for _, m := range mismatches {
+				fmt.Printf("%s: title slug %s differs from file slug %s\n", rel(s.path(m.Entry.Path)), m.TitleSlug, m.Entry.Slug)
+			}
+		}
+		if len(mismatches) > 0 {
+			return fmt.Errorf("%d mismatched slug(s) found, fix them with --fix-filenames, --fix-titles or -i", len(mismatches))
+		}
+		return nil
+	}
+
+	permalink := permalinkSetting(s, cfg)
+	out := reconcileJSON{Renamed: []renameJSON{}, Retitled: []string{}, Skipped: []string{}, DryRun: c.DryRun}
+	for _, m := range mismatches {
+		fix := "filename"
+		if c.FixTitles {
+			fix = "title"
+		}
+		if c.Interactive {
+			if fix, err = askFix(s, m); err != nil {
+				return err
+			}
+		}
+		switch fix {
+		case "filename":
+			r, err := g.PlanRename(m.Entry.Path, m.Entry.Meta.Title, m.TitleSlug, reconcileRedirect(g, m, permalink))
+			if err != nil {
+				return err
+			}
+			if !c.DryRun {
+				if err := g.ApplyRename(r); err != nil {
+					return err
+				}
+			}
+			if opts.JSON {
+				j := renameJSON{OldPath: rel(s.path(r.OldPath)), NewPath: rel(s.path(r.NewPath))}
+				if r.OldImages != "" {
+					j.OldImages, j.NewImages = rel(s.path(r.OldImages)), rel(s.path(r.NewImages))
+				}
+				out.Renamed = append(out.Renamed, j)
+			} else if c.DryRun {
+				if err := printRename(s, r); err != nil {
+					return err
+				}
+			} else if err := printRename(s, postgen.Rename{OldPath: r.OldPath, NewPath: r.NewPath, OldImages: r.OldImages, NewImages: r.NewImages}); err != nil {
+				return err
+			}
+		case "title":
+			if postgen.Slugify(m.Entry.Slug) != m.Entry.Slug {
+				warnf("skipping %s, no title would generate its file slug %s", rel(s.path(m.Entry.Path)), m.Entry.Slug)
+				out.Skipped = append(out.Skipped, rel(s.path(m.Entry.Path)))
+				continue
+			}
+			title := postgen.TitleFromSlug(m.Entry.Meta.Title, m.Entry.Slug)
+			ch, err := g.PlanRetitle(m.Entry.Path, title)
+			if err != nil {
+				return err
+			}
+			if !c.DryRun {
+				if err := g.WriteChanges([]postgen.Change{ch}); err != nil {
+					return err
+				}
+			}
+			out.Retitled = append(out.Retitled, rel(s.path(ch.Path)))
+			if !opts.JSON {
+				verb := "retitled"
+				if c.DryRun {
+					verb = "would retitle"
+				}
+				fmt.Printf("%s %s: %s -> %s\n", verb, rel(s.path(ch.Path)), m.Entry.Meta.Title, title)
+			}
+		default:
+			out.Skipped = append(out.Skipped, rel(s.path(m.Entry.Path)))
+		}
+	}
+	if opts.JSON {
+		return printJSON(out)
+	}
+	return nil
+}
+
+// reconcileRedirect returns the permalink of the post m to redirect from
+// once its file is renamed, or an empty string when it has none, keeps it
+// or the redirect would collide, which is warned about.
+func reconcileRedirect(g *postgen.Generator, m postgen.SlugMismatch, permalink string) string {
+	if g.IsDraft(m.Entry.Path) || m.Entry.Meta.Permalink != "" {
+		return ""
+	}
+	renamed := m.Entry
+	renamed.Slug = m.TitleSlug
+	old := postgen.Permalink(permalink, m.Entry)
+	if old == postgen.Permalink(permalink, renamed) {
+		return ""
+	}
+	if err := g.RedirectCollision(old, permalink, m.Entry.Path); err != nil {
+		warnf("not redirecting %s: %v", old, err)
+		return ""
+	}
+	return old
+}
+
+// askFix asks which side of the mismatch m to fix: "filename", "title"
+// or, for anything else, an empty string to skip it.
+func askFix(s site, m postgen.SlugMismatch) (string, error) {
+	fmt.Fprintf(os.Stderr, "%s\n  title: %s\n  title slug: %s\n  file slug:  %s\n", rel(s.path(m.Entry.Path)), m.Entry.Meta.Title, m.TitleSlug, m.Entry.Slug)
+	for {
+		answer, err := ask("fix the [f]ilename, the [t]itle or [s]kip", "s")
+		if err != nil {
+			return "", err
+		}
+		switch answer {
+		case "f", "filename":
+			return "filename", nil
+		case "t", "title":
+			return "title", nil
+		case "s", "skip":
+			return "", nil
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+type redirectsCommand struct{}
+
+type redirectsAddCommand struct {
+	previewFlags
+	DryRun bool `short:"n" long:"dry-run" description:"report the file that would change without writing it"`
+	Args   struct {
+		Post postName `positional-arg-name:"post" description:"slug or file name of the post"`
+		URL  string   `positional-arg-name:"old-url" description:"URL path to redirect to the post, such as /2019/02/01/old-slug.html"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+type redirectsCheckCommand struct{}
+
+// redirectProblemJSON is the --json form of a postgen.RedirectProblem.
+type redirectProblemJSON struct {
+	File    string `json:"file"`
+	URL     string `json:"url"`
+	Message string `json:"message"`
+}
+
+func (c *redirectsAddCommand) Execute(args []string) error {
+	s, cfg, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	g := s.generator(time.UTC)
+	markdownPath, err := g.Find(string(c.Args.Post))
+	if err != nil {
+		return err
+	}
+	changes, err := g.PlanRedirect(markdownPath, c.Args.URL, permalinkSetting(s, cfg))
+	if err != nil {
+		return err
+	}
+	if c.DiffOnly {
+		return printDiffs(s, changes, false)
+	}
+	if !c.DryRun {
+		if err := c.confirmChanges(s, changes); err != nil {
+			return err
+		}
+		if err := g.WriteChanges(changes); err != nil {
+			return err
+		}
+	}
+	return printChanges(s, changes, c.DryRun)
+}
+
+func (c *redirectsCheckCommand) Execute(args []string) error {
+	s, cfg, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	problems, bad, err := s.generator(time.UTC).CheckRedirects(permalinkSetting(s, cfg))
+	if err != nil {
+		return err
+	}
+	out := []redirectProblemJSON{}
+	for _, p := range problems {
+		out = append(out, redirectProblemJSON{rel(s.path(p.Post)), p.URL, p.Message})
+	}
+	if opts.JSON {
+		if err := printJSON(map[string]interface{}{"problems": out}); err != nil {
+			return err
+		}
+	} else {
+		for _, p := range out {
+			fmt.Printf("%s: %s: %s\n", p.File, p.URL, p.Message)
+		}
+	}
+	if len(problems) > 0 {
+		reportBad(s, bad)
+		return fmt.Errorf("%d problem(s) found", len(problems))
+	}
+	return reportBad(s, bad)
+}
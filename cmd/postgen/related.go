@@ -0,0 +1,68 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+type relatedCommand struct {
+	previewFlags
+	Top      int      `long:"top" default:"3" description:"number of related posts written per post"`
+	MinScore float64  `long:"min-score" default:"0.1" description:"score, from 0 to 1, a post must exceed to be related; posts without any such match get no related key"`
+	Lang     []string `long:"lang" value-name:"LANG" description:"language whose stop words are left out of the titles and descriptions compared (repeatable, defaults to all of them)"`
+	DryRun   bool     `short:"n" long:"dry-run" description:"list the files that would change without writing them"`
+}
+
+func (c *relatedCommand) Execute(args []string) error {
+	if c.Top < 1 {
+		return usagef("invalid --top %d: expected a positive number", c.Top)
+	}
+	if c.MinScore < 0 || c.MinScore >= 1 {
+		return usagef("invalid --min-score %g: expected a number from 0 to 1", c.MinScore)
+	}
+	s, cfg, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	stop, err := stopWords(cfg, c.Lang)
+	if err != nil {
+		return err
+	}
+	g := s.generator(time.UTC)
+	ctx, release := interruptContext()
+	defer release()
+	planned, bad, err := g.Related(ctx, postgen.RelatedOptions{Count: c.Top, MinScore: c.MinScore, StopWords: stop})
+	if err != nil && !interrupted(err) {
+		return err
+	}
+	changes := make([]postgen.Change, len(planned))
+	for i, ch := range planned {
+		changes[i] = ch.Change
+		verbosef("%s: %s", rel(s.path(ch.Path)), strings.Join(ch.Related, ", "))
+	}
+	if err != nil {
+		// An interrupted run lists the changes planned so far, writing
+		// none of them.
+		if err := printChanges(s, changes, true); err != nil {
+			return err
+		}
+		return err
+	}
+	if c.DiffOnly {
+		return printDiffs(s, changes, false)
+	}
+	if !c.DryRun {
+		if err := c.confirmChanges(s, changes); err != nil {
+			return err
+		}
+		if err := g.WriteChanges(changes); err != nil {
+			return err
+		}
+	}
+	if err := printChanges(s, changes, c.DryRun); err != nil {
+		return err
+	}
+	return reportBad(s, bad)
+}
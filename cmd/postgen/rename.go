@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+	"github.com/tiagomelo/tiagomelo.github.io/postgen/diff"
+)
+
+type renameCommand struct {
+	Title    string `short:"t" long:"title" description:"new title" required:"true"`
+	Slug     string `short:"s" long:"slug" description:"new slug (defaults to one generated from the new title)"`
+	Redirect bool   `long:"redirect" description:"add the old permalink to redirect_from so existing links keep working"`
+	DryRun   bool   `short:"n" long:"dry-run" description:"show the planned renames and changes as a diff without applying them"`
+	Args     struct {
+		Slug postName `positional-arg-name:"old-slug" description:"slug or file name of the post or draft to rename"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+func (c *renameCommand) Execute(args []string) error {
+	s, cfg, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	g := s.generator(time.UTC)
+	markdownPath, err := g.Find(string(c.Args.Slug))
+	if err != nil {
+		return err
+	}
+	redirect := ""
+	if c.Redirect {
+		if g.IsDraft(markdownPath) {
+			return errors.New("drafts are not published, so there is no permalink to redirect from")
+		}
+		e, err := g.Read(markdownPath)
+		if err != nil {
+			return err
+		}
+		if e.Meta.Permalink == "" {
+			permalink := permalinkSetting(s, cfg)
+			redirect = postgen.Permalink(permalink, e)
+			if err := g.RedirectCollision(redirect, permalink, markdownPath); err != nil {
+				return err
+			}
+		}
+	}
+	r, err := g.PlanRename(markdownPath, c.Title, c.Slug, redirect)
+	if err != nil {
+		return err
+	}
+	if c.DryRun {
+		return printRename(s, r)
+	}
+	if err := g.ApplyRename(r); err != nil {
+		return err
+	}
+	return printRename(s, postgen.Rename{OldPath: r.OldPath, NewPath: r.NewPath, OldImages: r.OldImages, NewImages: r.NewImages})
+}
+
+// renameJSON is the --json form of a rename; Diff is only set for dry
+// runs.
+type renameJSON struct {
+	OldPath   string `json:"oldPath"`
+	NewPath   string `json:"newPath"`
+	OldImages string `json:"oldImagesPath"`
+	NewImages string `json:"newImagesPath"`
+	Diff      string `json:"diff,omitempty"`
+}
+
+// printRename prints the renames in r followed by the diff of its
+// content, if any.
+func printRename(s site, r postgen.Rename) error {
+	if opts.JSON {
+		out := renameJSON{
+			OldPath: rel(s.path(r.OldPath)),
+			NewPath: rel(s.path(r.NewPath)),
+			Diff:    diff.Unified("a/"+r.OldPath, "b/"+r.NewPath, string(r.OldContent), string(r.NewContent), 3),
+		}
+		if r.OldImages != "" {
+			out.OldImages, out.NewImages = rel(s.path(r.OldImages)), rel(s.path(r.NewImages))
+		}
+		return printJSON(out)
+	}
+	if r.OldPath != r.NewPath {
+		fmt.Printf("rename %s -> %s\n", rel(s.path(r.OldPath)), rel(s.path(r.NewPath)))
+	}
+	if r.OldImages != "" {
+		fmt.Printf("rename %s -> %s\n", rel(s.path(r.OldImages)), rel(s.path(r.NewImages)))
+	}
+	fmt.Print(diff.Unified("a/"+r.OldPath, "b/"+r.NewPath, string(r.OldContent), string(r.NewContent), 3))
+	return nil
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+// reloadScript makes a page served by preview --serve reload when the
+// state served at /version changes.
+const reloadScript = `<script>
+let version;
+setInterval(async () => {
+  const v = await fetch("/version").then(r => r.text()).catch(() => version);
+  if (version && v !== version) location.reload();
+  version = v;
+}, 1000);
+</script>
+`
+
+type previewCommand struct {
+	BaseURL string `long:"base-url" description:"URL the site is served at, which post_url links point at (defaults to the url and baseurl of _config.yml)"`
+	Out     string `short:"o" long:"out" description:"file to write the page to (defaults to stdout)"`
+	Serve   bool   `long:"serve" description:"serve the page instead, reloading it whenever the post or its images change, until interrupted"`
+	Addr    string `long:"addr" default:"localhost:4001" description:"address --serve listens on"`
+	Args    struct {
+		Post postName `positional-arg-name:"post" description:"slug or file name of the post or draft"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+func (c *previewCommand) Execute(args []string) error {
+	if c.Serve && (c.Out != "" || opts.JSON) {
+		return usagef("--serve cannot be combined with --out or --json")
+	}
+	s, cfg, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	jekyll := readJekyllConfig(s)
+	htmlOpts := postgen.HTMLOptions{SiteURL: jekyll.siteURL(), BaseURL: jekyll.BaseURL, Permalink: permalinkSetting(s, cfg)}
+	if c.BaseURL != "" {
+		htmlOpts.SiteURL = c.BaseURL
+	}
+	p, err := s.generator(time.UTC).Find(string(c.Args.Post))
+	if err != nil {
+		return err
+	}
+	if c.Serve {
+		return c.serve(s, p, htmlOpts)
+	}
+	page, err := s.generator(time.UTC).RenderHTML(p, htmlOpts)
+	if err != nil {
+		return err
+	}
+	if c.Out != "" {
+		if err := os.WriteFile(c.Out, page.Content, 0644); err != nil {
+			return fmt.Errorf("writing file %s: %w", c.Out, err)
+		}
+	}
+	if opts.JSON {
+		out := struct {
+			File     string   `json:"file,omitempty"`
+			Content  string   `json:"content,omitempty"`
+			Warnings []string `json:"warnings"`
+		}{File: c.Out, Warnings: nonNil(page.Warnings)}
+		if c.Out == "" {
+			out.Content = string(page.Content)
+		}
+		return printJSON(out)
+	}
+	for _, w := range page.Warnings {
+		warnf("%s: %s", rel(s.path(p)), w)
+	}
+	if c.Out == "" {
+		fmt.Print(string(page.Content))
+		return nil
+	}
+	infof("wrote %s", c.Out)
+	return nil
+}
+
+// serve serves the page of the post at p on c.Addr, rendering it again on
+// every request, until interrupted.
+func (c *previewCommand) serve(s site, p string, htmlOpts postgen.HTMLOptions) error {
+	g := s.generator(time.UTC)
+	watched := func() []string {
+		files := []string{s.path(p)}
+		folder := s.path(g.ImagesFolder(p))
+		entries, _ := os.ReadDir(folder)
+		for _, e := range entries {
+			files = append(files, filepath.Join(folder, e.Name()))
+		}
+		return files
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		page, err := s.generator(time.UTC).RenderHTML(p, htmlOpts)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for _, warning := range page.Warnings {
+			verbosef("%s: %s", rel(s.path(p)), warning)
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(bytes.Replace(page.Content, []byte("</body>"), []byte(reloadScript+"</body>"), 1))
+	})
+	mux.HandleFunc("/version", func(w http.ResponseWriter, r *http.Request) {
+		states := statFiles(watched())
+		files := make([]string, 0, len(states))
+		for f := range states {
+			files = append(files, f)
+		}
+		sort.Strings(files)
+		for _, f := range files {
+			fmt.Fprintf(w, "%s %d %d\n", f, states[f].size, states[f].modTime.UnixNano())
+		}
+	})
+	server := &http.Server{Addr: c.Addr, Handler: mux}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		server.Shutdown(context.Background())
+	}()
+	infof("serving %s at http://%s, reloading on changes", rel(s.path(p)), c.Addr)
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("serving %s: %w", c.Addr, err)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"text/template"
+	"time"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+// roundupArchetype is the archetype roundups use when the site has one.
+const roundupArchetype = "roundup"
+
+type roundupCommand struct {
+	Month  string `long:"month" description:"month to round up, as YYYY-MM (defaults to the previous calendar month)"`
+	Title  string `short:"t" long:"title" description:"title of the roundup (defaults to \"What I wrote in\" and the month)"`
+	Slug   string `short:"s" long:"slug" description:"slug of the roundup (defaults to one generated from the title)"`
+	Draft  bool   `long:"draft" description:"create the roundup as a draft in _drafts"`
+	DryRun bool   `short:"n" long:"dry-run" description:"print what would be created without writing anything"`
+}
+
+func (c *roundupCommand) Execute(args []string) error {
+	s, cfg, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	loc, err := location(s, cfg)
+	if err != nil {
+		return err
+	}
+	now := time.Now().In(loc)
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc).AddDate(0, -1, 0)
+	if c.Month != "" {
+		if month, err = time.ParseInLocation(postgen.RoundupMonthLayout, c.Month, loc); err != nil {
+			return usagef("invalid month \"%s\": expected YYYY-MM", c.Month)
+		}
+	}
+	title := c.Title
+	if title == "" {
+		title = "What I wrote in " + month.Format("January 2006")
+	}
+	slug, err := postSlug(c.Slug, title)
+	if err != nil {
+		return err
+	}
+	categories, err := postgen.ParseCategories(cfg.Categories)
+	if err != nil {
+		return usageError{err}
+	}
+	var archetype *template.Template
+	archetypes, err := readArchetypes(s)
+	if err != nil {
+		return err
+	}
+	if _, ok := archetypes[roundupArchetype]; ok {
+		if archetype, err = loadArchetype(s, roundupArchetype); err != nil {
+			return err
+		}
+		verbosef("archetype: %s", roundupArchetype)
+	}
+	tmpl, err := loadTemplate(opts.Template)
+	if err != nil {
+		return err
+	}
+	g := s.generator(loc)
+	g.Template = tmpl
+	g.Force = opts.Force
+	body, err := g.Roundup(month)
+	if err != nil {
+		return err
+	}
+	explicit := explicitKeys()
+	if c.Title != "" {
+		explicit["title"] = true
+	}
+	p := postgen.Post{
+		Layout:     cfg.Layout,
+		Title:      title,
+		Slug:       slug,
+		Author:     cfg.Author,
+		Lang:       cfg.Lang,
+		Categories: categories,
+		Tags:       postgen.ParseTags(cfg.Tags),
+		Date:       now,
+		Draft:      c.Draft,
+		Archetype:  archetype,
+		Explicit:   explicit,
+		Body:       body,
+	}
+	return run(g, s, p, c.DryRun, false)
+}
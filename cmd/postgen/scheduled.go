@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+type scheduledCommand struct {
+	Strict bool `long:"strict" description:"fail when any post is scheduled or has mismatched dates, to catch them before a deploy"`
+}
+
+// scheduledJSON is the --json form of a postgen.Scheduled.
+type scheduledJSON struct {
+	File            string `json:"file"`
+	Date            string `json:"date"`
+	FileDate        string `json:"fileDate,omitempty"`
+	FrontMatterDate string `json:"frontMatterDate,omitempty"`
+	Future          bool   `json:"future"`
+	// In is how long until the post renders, in seconds.
+	In       int64 `json:"in,omitempty"`
+	Mismatch bool  `json:"mismatch"`
+}
+
+func (c *scheduledCommand) Execute(args []string) error {
+	s, cfg, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	loc, err := location(s, cfg)
+	if err != nil {
+		return err
+	}
+	now := time.Now().In(loc)
+	scheduled, bad, err := s.generator(loc).ScheduledPosts(now)
+	if err != nil {
+		return err
+	}
+	if opts.JSON {
+		out := []scheduledJSON{}
+		for _, p := range scheduled {
+			j := scheduledJSON{
+				File:     rel(s.path(p.Path)),
+				Date:     p.Date.Format(postgen.DateLayout),
+				Future:   p.Future,
+				Mismatch: p.Mismatch,
+			}
+			if !p.FileDate.IsZero() {
+				j.FileDate = p.FileDate.Format(postgen.FileDateLayout)
+			}
+			if !p.FrontMatterDate.IsZero() {
+				j.FrontMatterDate = p.FrontMatterDate.Format(postgen.DateLayout)
+			}
+			if p.Future {
+				j.In = int64(renderedAt(p).Sub(now) / time.Second)
+			}
+			out = append(out, j)
+		}
+		if err := printJSON(out); err != nil {
+			return err
+		}
+	} else if len(scheduled) > 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "DATE\tIN\tFILE\tPROBLEM")
+		for _, p := range scheduled {
+			in, problem := "", ""
+			if p.Future {
+				in = untilText(renderedAt(p).Sub(now))
+			}
+			if p.Mismatch {
+				problem = fmt.Sprintf("front matter date %s, file name date %s", p.FrontMatterDate.Format(postgen.FileDateLayout), p.FileDate.Format(postgen.FileDateLayout))
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", p.Date.Format("2006-01-02 15:04"), in, rel(s.path(p.Path)), problem)
+		}
+		w.Flush()
+	}
+	if err := reportBad(s, bad); err != nil {
+		return err
+	}
+	if c.Strict && len(scheduled) > 0 {
+		return fmt.Errorf("%d post(s) scheduled or with mismatched dates found", len(scheduled))
+	}
+	return nil
+}
+
+// renderedAt returns when Jekyll starts rendering p: the later of its
+// date and the start of its file name date.
+func renderedAt(p postgen.Scheduled) time.Time {
+	if p.FileDate.After(p.Date) {
+		return p.FileDate
+	}
+	return p.Date
+}
+
+// untilText formats a duration in its two largest units, such as 3d 4h.
+func untilText(d time.Duration) string {
+	days, hours, minutes := int(d/(24*time.Hour)), int(d%(24*time.Hour)/time.Hour), int(d%time.Hour/time.Minute)
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	}
+	return fmt.Sprintf("%dm", minutes)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+// searchContext is how many characters of a line are shown around a
+// match.
+const searchContext = 60
+
+type searchCommand struct {
+	Regex         bool         `long:"regex" description:"take the query as a regular expression"`
+	CaseSensitive bool         `long:"case-sensitive" description:"match case, which is ignored by default"`
+	Category      categoryName `long:"category" description:"only search posts in this category"`
+	Tag           tagName      `long:"tag" description:"only search posts with this tag"`
+	After         string       `long:"after" description:"only search posts dated after YYYY-MM-DD"`
+	Before        string       `long:"before" description:"only search posts dated before YYYY-MM-DD"`
+	Args          struct {
+		Query string `positional-arg-name:"query" description:"text, or with --regex regular expression, to search for"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+// matchJSON is the --json form of a postgen.Match.
+type matchJSON struct {
+	File  string `json:"file"`
+	Line  int    `json:"line"`
+	Text  string `json:"text"`
+	Match string `json:"match"`
+}
+
+func (c *searchCommand) Execute(args []string) error {
+	expr := c.Args.Query
+	if !c.Regex {
+		expr = regexp.QuoteMeta(expr)
+	}
+	if !c.CaseSensitive {
+		expr = "(?i)" + expr
+	}
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return usagef("invalid regular expression %s: %v", c.Args.Query, err)
+	}
+	var after, before string
+	for _, d := range []struct {
+		flag  string
+		value string
+		to    *string
+	}{{"--after", c.After, &after}, {"--before", c.Before, &before}} {
+		if d.value == "" {
+			continue
+		}
+		if _, err := time.Parse(postgen.FileDateLayout, d.value); err != nil {
+			return usagef("invalid %s date \"%s\": expected YYYY-MM-DD", d.flag, d.value)
+		}
+		*d.to = d.value
+	}
+	s, _, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	matches, bad, err := s.generator(time.UTC).Search(pattern, func(e postgen.Entry) bool {
+		date := e.Date.Format(postgen.FileDateLayout)
+		return (c.Category == "" || containsFold(e.Meta.Categories, string(c.Category))) &&
+			(c.Tag == "" || containsFold(e.Meta.Tags, string(c.Tag))) &&
+			(after == "" || date > after) && (before == "" || date < before)
+	})
+	if err != nil {
+		return err
+	}
+	if opts.JSON {
+		out := []matchJSON{}
+		for _, m := range matches {
+			out = append(out, matchJSON{rel(s.path(m.Path)), m.Line, m.Text, m.Text[m.Start:m.End]})
+		}
+		if err := printJSON(out); err != nil {
+			return err
+		}
+	} else {
+		highlight := isTerminal(os.Stdout) && os.Getenv("NO_COLOR") == ""
+		for _, m := range matches {
+			fmt.Printf("%s:%d: %s\n", rel(s.path(m.Path)), m.Line, snippet(m, highlight))
+		}
+	}
+	return reportBad(s, bad)
+}
+
+// snippet returns the text around the match of m, trimmed to
+// searchContext characters on each side, with the match in bold red when
+// highlight is set.
+func snippet(m postgen.Match, highlight bool) string {
+	before, match, after := []rune(m.Text[:m.Start]), m.Text[m.Start:m.End], []rune(m.Text[m.End:])
+	prefix, suffix := "", ""
+	if len(before) > searchContext {
+		before, prefix = before[len(before)-searchContext:], "…"
+	}
+	if len(after) > searchContext {
+		after, suffix = after[:searchContext], "…"
+	}
+	if highlight {
+		match = "\x1b[1;31m" + match + "\x1b[0m"
+	}
+	return prefix + strings.TrimLeft(string(before), " \t") + match + string(after) + suffix
+}
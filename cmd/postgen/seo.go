@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+type seoCommand struct {
+	Keyword string   `short:"k" long:"keyword" description:"primary keyword of the post (defaults to its first tag)"`
+	Top     int      `long:"top" default:"10" description:"number of most frequent terms to report"`
+	Lang    []string `long:"lang" value-name:"LANG" description:"language whose stop words are left out of the terms, such as en or pt (repeatable, defaults to all of them)"`
+	Args    struct {
+		Post postName `positional-arg-name:"post" required:"yes" description:"slug or file name of the post or draft"`
+	} `positional-args:"yes"`
+}
+
+// frequencyJSON is the --json form of a postgen.Frequency.
+type frequencyJSON struct {
+	Term    string  `json:"term"`
+	Count   int     `json:"count"`
+	Density float64 `json:"density"`
+}
+
+func (c *seoCommand) Execute(args []string) error {
+	if c.Top < 1 {
+		return usagef("invalid --top %d: expected a positive number", c.Top)
+	}
+	s, cfg, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	stop, err := stopWords(cfg, c.Lang)
+	if err != nil {
+		return err
+	}
+	g := s.generator(time.UTC)
+	p, err := g.Find(string(c.Args.Post))
+	if err != nil {
+		return err
+	}
+	r, err := g.SEO(p, postgen.SEOOptions{Keyword: c.Keyword, Terms: c.Top, StopWords: stop})
+	if err != nil {
+		return err
+	}
+	if opts.JSON {
+		terms := []frequencyJSON{}
+		for _, t := range r.Terms {
+			terms = append(terms, frequencyJSON{t.Term, t.Count, t.Density})
+		}
+		return printJSON(map[string]interface{}{
+			"file":        rel(s.path(p)),
+			"words":       r.Words,
+			"terms":       terms,
+			"description": r.Description,
+			"keyword": map[string]interface{}{
+				"keyword":        r.Keyword,
+				"count":          r.KeywordCount,
+				"title":          r.InTitle,
+				"description":    r.InDescription,
+				"firstParagraph": r.InFirstParagraph,
+				"heading":        r.InHeading,
+			},
+			"warnings": nonNil(r.Warnings),
+		})
+	}
+
+	yesNo := func(b bool) string {
+		if b {
+			return "yes"
+		}
+		return "no"
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "file:\t%s\n", rel(s.path(p)))
+	fmt.Fprintf(w, "words:\t%d\n", r.Words)
+	if r.Description == "" {
+		fmt.Fprintf(w, "description:\tnone\n")
+	} else {
+		fmt.Fprintf(w, "description:\t%d characters\n", len([]rune(r.Description)))
+	}
+	if r.Keyword != "" {
+		fmt.Fprintf(w, "keyword:\t%s, %d time(s)\n", r.Keyword, r.KeywordCount)
+		fmt.Fprintf(w, "  in title:\t%s\n", yesNo(r.InTitle))
+		fmt.Fprintf(w, "  in description:\t%s\n", yesNo(r.InDescription))
+		fmt.Fprintf(w, "  in first paragraph:\t%s\n", yesNo(r.InFirstParagraph))
+		fmt.Fprintf(w, "  in a heading:\t%s\n", yesNo(r.InHeading))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if len(r.Terms) > 0 {
+		fmt.Println("\ntop terms:")
+		w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, t := range r.Terms {
+			fmt.Fprintf(w, "  %s\t%d\t%.1f%%\n", t.Term, t.Count, t.Density)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	for _, warning := range r.Warnings {
+		warnf("%s", warning)
+	}
+	return nil
+}
+
+// stopWords returns the stop words of langs, or of every language when
+// langs is empty, the config file's own included, lowercase.
+func stopWords(cfg config, langs []string) (map[string]bool, error) {
+	lists := make(map[string][]string)
+	for lang, words := range postgen.StopWords {
+		lists[lang] = words
+	}
+	for lang, words := range cfg.StopWords {
+		lists[lang] = append(append([]string{}, lists[lang]...), words...)
+	}
+	if len(langs) == 0 {
+		for lang := range lists {
+			langs = append(langs, lang)
+		}
+	}
+	stop := make(map[string]bool)
+	for _, lang := range langs {
+		words, ok := lists[lang]
+		if !ok {
+			known := make([]string, 0, len(lists))
+			for lang := range lists {
+				known = append(known, lang)
+			}
+			sort.Strings(known)
+			return nil, usagef("unknown language \"%s\", expected one of %s", lang, strings.Join(known, ", "))
+		}
+		for _, w := range words {
+			stop[strings.ToLower(w)] = true
+		}
+	}
+	return stop, nil
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+type seriesCommand struct{}
+
+type seriesListCommand struct{}
+
+type seriesGenerateCommand struct {
+	DryRun bool `short:"n" long:"dry-run" description:"list the pages and posts that would be written or deleted without touching them"`
+}
+
+// seriesJSON is the --json form of a series.
+type seriesJSON struct {
+	Name  string           `json:"name"`
+	Parts []seriesPartJSON `json:"parts"`
+	Gaps  []int            `json:"gaps"`
+}
+
+type seriesPartJSON struct {
+	Part  int    `json:"part"`
+	Date  string `json:"date"`
+	Title string `json:"title"`
+	File  string `json:"file"`
+}
+
+func (c *seriesListCommand) Execute(args []string) error {
+	s, _, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	series, bad, err := s.generator(time.UTC).Series()
+	if err != nil {
+		return err
+	}
+	out := []seriesJSON{}
+	for _, sr := range series {
+		js := seriesJSON{Name: sr.Name, Gaps: sr.Gaps()}
+		if js.Gaps == nil {
+			js.Gaps = []int{}
+		}
+		for _, e := range sr.Parts {
+			js.Parts = append(js.Parts, seriesPartJSON{
+				Part:  e.Meta.SeriesPart,
+				Date:  e.Date.Format(postgen.FileDateLayout),
+				Title: e.Meta.Title,
+				File:  rel(s.path(e.Path)),
+			})
+		}
+		out = append(out, js)
+	}
+	if opts.JSON {
+		if err := printJSON(out); err != nil {
+			return err
+		}
+		return reportBad(s, bad)
+	}
+	for i, sr := range out {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Println(sr.Name)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, p := range sr.Parts {
+			fmt.Fprintf(w, "  %d\t%s\t%s\t%s\n", p.Part, p.Date, p.Title, p.File)
+		}
+		w.Flush()
+		if len(sr.Gaps) > 0 {
+			gaps := make([]string, len(sr.Gaps))
+			for i, g := range sr.Gaps {
+				gaps[i] = fmt.Sprint(g)
+			}
+			fmt.Printf("  missing part(s): %s\n", strings.Join(gaps, ", "))
+		}
+	}
+	return reportBad(s, bad)
+}
+
+func (c *seriesGenerateCommand) Execute(args []string) error {
+	s, cfg, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	dir, err := s.name(s.path(cfg.SeriesDir))
+	if cfg.SeriesDir == "" {
+		dir, err = path.Join(s.sourceDir(), "series"), nil
+	}
+	if err != nil {
+		return err
+	}
+	layout := cfg.SeriesLayout
+	if layout == "" {
+		layout = "page"
+	}
+	g := s.generator(time.UTC)
+	plan, err := g.PlanSeriesPages(postgen.SeriesOptions{Dir: dir, Layout: layout, Permalink: permalinkSetting(s, cfg)})
+	if err != nil {
+		return err
+	}
+	if !c.DryRun {
+		if err := g.ApplySeriesPages(plan); err != nil {
+			return err
+		}
+	}
+	written, deleted, posts := []string{}, []string{}, []string{}
+	for _, ch := range plan.Write {
+		written = append(written, rel(s.path(ch.Path)))
+	}
+	for _, p := range plan.Delete {
+		deleted = append(deleted, rel(s.path(p)))
+	}
+	for _, ch := range plan.Posts {
+		posts = append(posts, rel(s.path(ch.Path)))
+	}
+	if opts.JSON {
+		return printJSON(map[string]interface{}{"written": written, "deleted": deleted, "posts": posts, "unchanged": plan.Unchanged, "dryRun": c.DryRun})
+	}
+	writeVerb, deleteVerb, postVerb := "wrote", "deleted", "updated"
+	if c.DryRun {
+		writeVerb, deleteVerb, postVerb = "would write", "would delete", "would update"
+	}
+	for _, p := range written {
+		infof("%s %s", writeVerb, p)
+	}
+	for _, p := range deleted {
+		infof("%s %s", deleteVerb, p)
+	}
+	for _, p := range posts {
+		infof("%s %s", postVerb, p)
+	}
+	infof("%d page(s) unchanged", plan.Unchanged)
+	return nil
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+// site holds the resolved locations of the directories postgen works in.
+// Directories are slash-separated and relative to root, the way
+// postgen.FS expects them.
+type site struct {
+	root      string
+	postsDir  string
+	draftsDir string
+	imagesDir string
+	ext       string
+	schema    *postgen.Schema
+	header    postgen.HeaderFormat
+}
+
+func newSite(root string, cfg config) (site, error) {
+	inRoot := func(key, dir string) (string, error) {
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(root, dir)
+		}
+		r, err := filepath.Rel(root, dir)
+		if err != nil || r == ".." || strings.HasPrefix(r, ".."+string(filepath.Separator)) {
+			return "", fmt.Errorf("%s %s is outside the site root %s", key, dir, root)
+		}
+		return filepath.ToSlash(r), nil
+	}
+	postsDir, err := inRoot("posts_dir", cfg.PostsDir)
+	if err != nil {
+		return site{}, err
+	}
+	// The drafts folder defaults to the one next to the posts folder.
+	drafts := path.Join(path.Dir(postsDir), draftsDir)
+	if cfg.DraftsDir != "" {
+		if drafts, err = inRoot("drafts_dir", cfg.DraftsDir); err != nil {
+			return site{}, err
+		}
+	}
+	imagesDir, err := inRoot("images_dir", cfg.ImagesDir)
+	if err != nil {
+		return site{}, err
+	}
+	ext := strings.TrimPrefix(cfg.Ext, ".")
+	if !postgen.IsPostFile("post." + ext) {
+		return site{}, usagef("invalid ext \"%s\": expected one of %s", cfg.Ext, strings.Join(postgen.Exts, ", "))
+	}
+	s := site{
+		root:      root,
+		postsDir:  postsDir,
+		draftsDir: drafts,
+		imagesDir: imagesDir,
+		ext:       ext,
+		schema:    cfg.Schema,
+	}
+	if cfg.FrontMatter != nil {
+		s.header = *cfg.FrontMatter
+	}
+	return s, nil
+}
+
+// sourceDir returns the Jekyll source directory, the one holding _posts.
+func (s site) sourceDir() string {
+	return path.Dir(s.postsDir)
+}
+
+// path returns the operating system path of name, a slash-separated path
+// relative to the site root.
+func (s site) path(name string) string {
+	return filepath.Join(s.root, filepath.FromSlash(name))
+}
+
+// name returns the slash-separated path, relative to the site root, of
+// the operating system path p, failing when p is outside the root.
+func (s site) name(p string) (string, error) {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return "", fmt.Errorf("resolving %s: %w", p, err)
+	}
+	r, err := filepath.Rel(s.root, abs)
+	if err != nil || r == ".." || strings.HasPrefix(r, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the site root %s", p, s.root)
+	}
+	return filepath.ToSlash(r), nil
+}
+
+// files resolves the file arguments of commands working on many posts:
+// glob patterns, or slugs as accepted by Find. Without arguments every
+// post and draft is returned.
+func (s site) files(g *postgen.Generator, args []string) ([]string, error) {
+	if len(args) == 0 {
+		return g.Files()
+	}
+	var files []string
+	seen := make(map[string]bool)
+	for _, arg := range args {
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			return nil, fmt.Errorf("matching %s: %w", arg, err)
+		}
+		var names []string
+		for _, m := range matches {
+			if info, err := os.Stat(m); err == nil && info.IsDir() {
+				continue
+			}
+			name, err := s.name(m)
+			if err != nil {
+				return nil, err
+			}
+			names = append(names, name)
+		}
+		if len(names) == 0 {
+			name, err := g.Find(arg)
+			if err != nil {
+				return nil, fmt.Errorf("no file matches %s", arg)
+			}
+			names = append(names, name)
+		}
+		for _, name := range names {
+			if !seen[name] {
+				seen[name] = true
+				files = append(files, name)
+			}
+		}
+	}
+	return files, nil
+}
+
+// generator returns a postgen.Generator working on the site with its
+// clock set to loc.
+func (s site) generator(loc *time.Location) *postgen.Generator {
+	g := postgen.NewGenerator(postgen.DirFS(s.root))
+	g.PostsDir = s.postsDir
+	g.DraftsDir = s.draftsDir
+	g.ImagesDir = s.imagesDir
+	g.Ext = s.ext
+	g.Schema = s.schema
+	g.Header = s.header
+	g.Now = func() time.Time { return time.Now().In(loc) }
+	g.Jobs = opts.Jobs
+	if !opts.Quiet && !opts.JSON && isTerminal(os.Stderr) {
+		g.Progress = func(done, total int, name string) {
+			progress.report(done, total, progressName(s, name))
+		}
+	}
+	return g
+}
+
+// loadSite resolves the site root, reads its config file and applies the
+// command line flags on top of it.
+func loadSite() (site, config, string, error) {
+	if opts.Jobs < 0 {
+		return site{}, config{}, "", usagef("invalid --jobs %d: expected a positive number", opts.Jobs)
+	}
+	root, err := resolveRoot(opts.Root)
+	if err != nil {
+		return site{}, config{}, "", err
+	}
+	cfg, path, err := loadConfig(root)
+	if err != nil {
+		return site{}, config{}, "", err
+	}
+	cfg = cfg.withFlags(opts)
+	s, err := newSite(root, cfg)
+	if err != nil {
+		return site{}, config{}, "", err
+	}
+	// The drafts and images folders are created when first needed, but
+	// a missing posts folder means the site is not where it is expected.
+	if info, err := os.Stat(s.path(s.postsDir)); err != nil || !info.IsDir() {
+		return site{}, config{}, "", postgen.Mark(postgen.ErrRootNotFound, fmt.Errorf("posts folder %s does not exist, set it with --posts-dir or posts_dir", s.path(s.postsDir)))
+	}
+	return s, cfg, path, nil
+}
+
+// resolveRoot returns root made absolute, or the closest ancestor of the
+// working directory that looks like the repository root when root is
+// empty.
+func resolveRoot(root string) (string, error) {
+	if root != "" {
+		abs, err := filepath.Abs(root)
+		if err != nil {
+			return "", fmt.Errorf("resolving root %s: %w", root, err)
+		}
+		return abs, nil
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("getting working directory: %w", err)
+	}
+	return findRoot(wd)
+}
+
+// findRoot walks up from dir looking for a docs/_posts directory or a
+// .git entry, whichever comes first.
+func findRoot(dir string) (string, error) {
+	for current := dir; ; {
+		if info, err := os.Stat(filepath.Join(current, docsDir, postsDir)); err == nil && info.IsDir() {
+			return current, nil
+		}
+		if _, err := os.Stat(filepath.Join(current, ".git")); err == nil {
+			return current, nil
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return "", postgen.Mark(postgen.ErrRootNotFound, fmt.Errorf("could not find the site root (a directory containing %s or .git) above %s, use --root to set it", filepath.Join(docsDir, postsDir), dir))
+		}
+		current = parent
+	}
+}
+
+// rel returns path relative to the working directory when it lives
+// below it, which keeps the printed paths short, and path otherwise.
+func rel(path string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	r, err := filepath.Rel(wd, path)
+	if err != nil || r == ".." || strings.HasPrefix(r, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return r
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSitePath(t *testing.T) {
+	root := t.TempDir()
+	s := site{root: root}
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"docs/_posts/2024-05-01-hello.markdown", filepath.Join(root, "docs", "_posts", "2024-05-01-hello.markdown")},
+		{"docs", filepath.Join(root, "docs")},
+		{".", root},
+	}
+	for _, tt := range tests {
+		if got := s.path(tt.name); got != tt.want {
+			t.Errorf("path(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSiteName(t *testing.T) {
+	root := t.TempDir()
+	s := site{root: root}
+	tests := []struct {
+		p    string
+		want string
+	}{
+		{filepath.Join(root, "docs", "_posts", "2024-05-01-hello.markdown"), "docs/_posts/2024-05-01-hello.markdown"},
+		{filepath.Join(root, "docs", "..", "docs", "_drafts", "draft.md"), "docs/_drafts/draft.md"},
+		{root, "."},
+	}
+	for _, tt := range tests {
+		got, err := s.name(tt.p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("name(%q) = %q, want %q", tt.p, got, tt.want)
+		}
+	}
+	for _, p := range []string{filepath.Dir(root), filepath.Join(root, "..", "other", "post.md")} {
+		if got, err := s.name(p); err == nil {
+			t.Errorf("name(%q) = %q, want an error for a path outside the root", p, got)
+		}
+	}
+}
+
+func TestSiteNameRoundTrips(t *testing.T) {
+	s := site{root: t.TempDir()}
+	for _, name := range []string{"docs/_posts/2024-05-01-hello.markdown", "docs/assets/images/2024-05-01-hello/a.png"} {
+		got, err := s.name(s.path(name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != name {
+			t.Errorf("name(path(%q)) = %q", name, got)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"path"
+	"time"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+type sitemapCommand struct{}
+
+type sitemapCheckCommand struct {
+	Sitemap string        `long:"sitemap" value-name:"FILE" description:"sitemap file to check (defaults to the built site's _site/sitemap.xml)"`
+	URL     string        `long:"url" description:"URL to fetch the sitemap from instead of reading a file"`
+	Timeout time.Duration `long:"timeout" default:"10s" description:"timeout of the request with --url"`
+}
+
+// sitemapProblemJSON is the --json form of a postgen.SitemapProblem.
+type sitemapProblemJSON struct {
+	File    string `json:"file,omitempty"`
+	URL     string `json:"url"`
+	Message string `json:"message"`
+}
+
+func (c *sitemapCheckCommand) Execute(args []string) error {
+	if c.Sitemap != "" && c.URL != "" {
+		return usagef("--sitemap cannot be combined with --url")
+	}
+	s, cfg, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	var content []byte
+	if c.URL != "" {
+		if content, err = fetchSitemap(c.URL, c.Timeout); err != nil {
+			return err
+		}
+	} else {
+		file := c.Sitemap
+		if file == "" {
+			file = s.path(path.Join(s.sourceDir(), "_site", "sitemap.xml"))
+		}
+		if content, err = os.ReadFile(file); err != nil {
+			return fmt.Errorf("reading sitemap %s: %w", rel(file), err)
+		}
+	}
+	urls, err := postgen.ParseSitemap(content)
+	if err != nil {
+		return err
+	}
+	loc, err := location(s, cfg)
+	if err != nil {
+		return err
+	}
+	jekyll := readJekyllConfig(s)
+	problems, bad, err := s.generator(loc).CheckSitemap(urls, postgen.SitemapOptions{
+		Permalink: permalinkSetting(s, cfg),
+		SiteURL:   jekyll.URL,
+		BaseURL:   jekyll.BaseURL,
+		Now:       time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	out := []sitemapProblemJSON{}
+	for _, p := range problems {
+		file := ""
+		if p.Post != "" {
+			file = rel(s.path(p.Post))
+		}
+		out = append(out, sitemapProblemJSON{file, p.URL, p.Message})
+	}
+	if opts.JSON {
+		if err := printJSON(map[string]interface{}{"urls": len(urls), "problems": out}); err != nil {
+			return err
+		}
+	} else {
+		for _, p := range out {
+			if p.File == "" {
+				fmt.Printf("%s: %s\n", p.URL, p.Message)
+			} else {
+				fmt.Printf("%s: %s: %s\n", p.File, p.URL, p.Message)
+			}
+		}
+		infof("%d URL(s) in the sitemap", len(urls))
+	}
+	if len(problems) > 0 {
+		reportBad(s, bad)
+		return fmt.Errorf("%d problem(s) found", len(problems))
+	}
+	return reportBad(s, bad)
+}
+
+// fetchSitemap downloads the sitemap at u.
+func fetchSitemap(u string, timeout time.Duration) ([]byte, error) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, usagef("invalid sitemap URL \"%s\"", u)
+	}
+	resp, err := (&http.Client{Timeout: timeout}).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching sitemap %s: %w", u, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching sitemap %s: %s", u, resp.Status)
+	}
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("fetching sitemap %s: %w", u, err)
+	}
+	return content, nil
+}
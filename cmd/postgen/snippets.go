@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+type snippetsCommand struct{}
+
+type snippetsSyncCommand struct {
+	previewFlags
+	Check bool `long:"check" description:"only report the stale snippets, failing if there are any, without writing anything"`
+	Args  struct {
+		Files []string `positional-arg-name:"files" description:"files, glob patterns or slugs (defaults to every post and draft)"`
+	} `positional-args:"yes"`
+}
+
+// staleSnippetJSON is the --json form of a stale postgen.Snippet.
+type staleSnippetJSON struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Source string `json:"source"`
+}
+
+func (c *snippetsSyncCommand) Execute(args []string) error {
+	s, _, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	g := s.generator(time.UTC)
+	files, err := s.files(g, c.Args.Files)
+	if err != nil {
+		return err
+	}
+	syncs, err := g.SyncSnippets(files)
+	if err != nil {
+		return err
+	}
+	if !c.Check {
+		var changes []postgen.Change
+		for _, sync := range syncs {
+			changes = append(changes, sync.Change)
+		}
+		if c.DiffOnly {
+			return printDiffs(s, changes, false)
+		}
+		if err := c.confirmChanges(s, changes); err != nil {
+			return err
+		}
+		if err := g.WriteChanges(changes); err != nil {
+			return err
+		}
+		return printChanges(s, changes, false)
+	}
+	stale := []staleSnippetJSON{}
+	for _, sync := range syncs {
+		for _, sn := range sync.Stale {
+			stale = append(stale, staleSnippetJSON{rel(s.path(sync.Path)), sn.Line, sn.Source})
+		}
+	}
+	if opts.JSON {
+		if err := printJSON(map[string]interface{}{"stale": stale}); err != nil {
+			return err
+		}
+	} else {
+		for _, sn := range stale {
+			fmt.Printf("%s:%d: snippet %s is stale\n", sn.File, sn.Line, sn.Source)
+		}
+	}
+	if len(stale) > 0 {
+		return fmt.Errorf("%d stale snippet(s) found, run postgen snippets sync", len(stale))
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+// chartWidth is the width of the longest bar of the stats chart.
+const chartWidth = 40
+
+type statsCommand struct {
+	Format        string `long:"format" choice:"text" choice:"json" default:"text" description:"output format; json is the same as --json"`
+	Chart         bool   `long:"chart" description:"draw a bar chart of the posts per month"`
+	IncludeDrafts bool   `long:"include-drafts" description:"count drafts and unpublished posts too"`
+}
+
+// statsJSON is the --json form of postgen.Stats.
+type statsJSON struct {
+	Posts        int               `json:"posts"`
+	PerYear      []periodCountJSON `json:"perYear"`
+	PerMonth     []periodCountJSON `json:"perMonth"`
+	AverageWords float64           `json:"averageWords"`
+	MedianWords  float64           `json:"medianWords"`
+	LongestGap   *gapJSON          `json:"longestGap"`
+	Streak       int               `json:"streak"`
+}
+
+type periodCountJSON struct {
+	Period string `json:"period"`
+	Count  int    `json:"count"`
+}
+
+type gapJSON struct {
+	Days int    `json:"days"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+func (c *statsCommand) Execute(args []string) error {
+	s, _, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	stats, bad, err := s.generator(time.UTC).Stats(c.IncludeDrafts, time.Now())
+	if err != nil {
+		return err
+	}
+	if opts.JSON || c.Format == "json" {
+		out := statsJSON{
+			Posts:        stats.Posts,
+			PerYear:      []periodCountJSON{},
+			PerMonth:     []periodCountJSON{},
+			AverageWords: stats.AverageWords,
+			MedianWords:  stats.MedianWords,
+			Streak:       stats.Streak,
+		}
+		for _, p := range stats.PerYear {
+			out.PerYear = append(out.PerYear, periodCountJSON{p.Period, p.Count})
+		}
+		for _, p := range stats.PerMonth {
+			out.PerMonth = append(out.PerMonth, periodCountJSON{p.Period, p.Count})
+		}
+		if gap := stats.LongestGap; gap != nil {
+			out.LongestGap = &gapJSON{gap.Days(), rel(s.path(gap.From.Path)), rel(s.path(gap.To.Path))}
+		}
+		if err := printJSON(out); err != nil {
+			return err
+		}
+		return reportBad(s, bad)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "posts:\t%d\n", stats.Posts)
+	fmt.Fprintf(w, "words:\taverage %.0f, median %.0f\n", stats.AverageWords, stats.MedianWords)
+	if gap := stats.LongestGap; gap != nil {
+		fmt.Fprintf(w, "longest gap:\t%d days, %s to %s\n", gap.Days(), gap.From.Date.Format(postgen.FileDateLayout), gap.To.Date.Format(postgen.FileDateLayout))
+	}
+	fmt.Fprintf(w, "current streak:\t%d month(s)\n", stats.Streak)
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if len(stats.PerYear) > 0 {
+		fmt.Println("\nper year:")
+		for _, p := range stats.PerYear {
+			fmt.Printf("  %s  %d\n", p.Period, p.Count)
+		}
+		fmt.Println("\nper month:")
+		max := 0
+		for _, p := range stats.PerMonth {
+			if p.Count > max {
+				max = p.Count
+			}
+		}
+		for _, p := range stats.PerMonth {
+			if !c.Chart {
+				if p.Count > 0 {
+					fmt.Printf("  %s  %d\n", p.Period, p.Count)
+				}
+				continue
+			}
+			bar := strings.Repeat("#", (p.Count*chartWidth+max-1)/max)
+			fmt.Printf("  %s  %-*s %d\n", p.Period, chartWidth, bar, p.Count)
+		}
+	}
+	return reportBad(s, bad)
+}
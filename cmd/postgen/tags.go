@@ -0,0 +1,48 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+type tagsCommand struct{}
+
+type tagsRenameCommand struct {
+	previewFlags
+	DryRun bool `short:"n" long:"dry-run" description:"list the affected files without writing them"`
+	Args   struct {
+		From tagName `positional-arg-name:"from" description:"tag or category to rename"`
+		To   string  `positional-arg-name:"to" description:"new name, merged with an existing one"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+func (c *tagsRenameCommand) Execute(args []string) error {
+	s, _, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	g := s.generator(time.UTC)
+	changes, err := g.RenameTerm(string(c.Args.From), c.Args.To, postgen.TaxonomyKeys)
+	if err != nil {
+		return err
+	}
+	plain := make([]postgen.Change, len(changes))
+	for i, ch := range changes {
+		plain[i] = ch.Change
+		verbosef("%s: %s", rel(s.path(ch.Path)), strings.Join(ch.Keys, ", "))
+	}
+	if c.DiffOnly {
+		return printDiffs(s, plain, false)
+	}
+	if !c.DryRun {
+		if err := c.confirmChanges(s, plain); err != nil {
+			return err
+		}
+		if err := g.WriteChanges(plain); err != nil {
+			return err
+		}
+	}
+	return printChanges(s, plain, c.DryRun)
+}
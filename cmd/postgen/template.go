@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/template"
+	"time"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+type templatesCommand struct{}
+
+type templatesFuncsCommand struct{}
+
+func (c *templatesFuncsCommand) Execute(args []string) error {
+	type funcJSON struct {
+		Name        string `json:"name"`
+		Usage       string `json:"usage"`
+		Description string `json:"description"`
+		Example     string `json:"example"`
+		Output      string `json:"output"`
+	}
+	out := []funcJSON{}
+	for _, f := range postgen.TemplateFuncs {
+		output, err := f.Run(time.Now)
+		if err != nil {
+			return err
+		}
+		out = append(out, funcJSON{f.Name, f.Usage, f.Description, f.Example, output})
+	}
+	if opts.JSON {
+		return printJSON(out)
+	}
+	for i, f := range out {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s\n    %s\n    %s\n    gives %s\n", f.Usage, f.Description, f.Example, f.Output)
+	}
+	return nil
+}
+
+// readTemplate returns the text of the template at path, or when path is
+// empty the template equivalent to the default front matter format.
+func readTemplate(path string) (string, error) {
+	if path == "" {
+		return postgen.DefaultTemplate, nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("reading template %s: %w", path, err)
+	}
+	return string(b), nil
+}
+
+// loadTemplate parses the template at path, naming it after its file so
+// errors point at the right place. It returns nil when path is empty, the
+// front matter being rendered in the configured format then.
+func loadTemplate(path string) (*template.Template, error) {
+	if path == "" {
+		return nil, nil
+	}
+	text, err := readTemplate(path)
+	if err != nil {
+		return nil, err
+	}
+	return postgen.ParseTemplate(path, text)
+}
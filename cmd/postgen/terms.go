@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+type categoriesCommand struct{}
+
+// termsListCommand lists the values of a taxonomy key, categories or
+// tags, across posts.
+type termsListCommand struct {
+	key     string
+	Orphans bool `long:"orphans" description:"only list values used by exactly one post, candidates for cleanup"`
+}
+
+// termJSON is the --json form of a postgen.Term.
+type termJSON struct {
+	Name  string   `json:"name"`
+	Count int      `json:"count"`
+	Posts []string `json:"posts"`
+}
+
+func (c *termsListCommand) Execute(args []string) error {
+	s, _, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	terms, bad, err := s.generator(time.UTC).Terms(c.key)
+	if err != nil {
+		return err
+	}
+	out := []termJSON{}
+	for _, t := range terms {
+		if c.Orphans && len(t.Posts) != 1 {
+			continue
+		}
+		js := termJSON{Name: t.Name, Count: len(t.Posts), Posts: []string{}}
+		for _, p := range t.Posts {
+			js.Posts = append(js.Posts, rel(s.path(p)))
+		}
+		out = append(out, js)
+	}
+	if opts.JSON {
+		if err := printJSON(out); err != nil {
+			return err
+		}
+		return reportBad(s, bad)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if c.Orphans {
+		fmt.Fprintln(w, "NAME\tPOST")
+		for _, t := range out {
+			fmt.Fprintf(w, "%s\t%s\n", t.Name, t.Posts[0])
+		}
+	} else {
+		fmt.Fprintln(w, "COUNT\tNAME")
+		for _, t := range out {
+			fmt.Fprintf(w, "%d\t%s\n", t.Count, t.Name)
+		}
+	}
+	w.Flush()
+	return reportBad(s, bad)
+}
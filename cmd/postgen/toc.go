@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+type tocCommand struct {
+	previewFlags
+	All              bool `long:"all" description:"refresh the table of contents of every post and draft having one"`
+	InsertAfterIntro bool `long:"insert-after-intro" description:"add the <!-- toc --> and <!-- /toc --> markers after the first paragraph of the posts without them"`
+	Check            bool `long:"check" description:"only report stale tables of contents, failing if there are any"`
+	Args             struct {
+		Post postName `positional-arg-name:"post" description:"slug or file name of the post or draft"`
+	} `positional-args:"yes"`
+}
+
+func (c *tocCommand) Execute(args []string) error {
+	if c.All == (c.Args.Post != "") {
+		return usagef("expected either a post or --all")
+	}
+	s, _, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	g := s.generator(time.UTC)
+	var files []string
+	if c.All {
+		if files, err = g.Files(); err != nil {
+			return err
+		}
+	} else {
+		p, err := g.Find(string(c.Args.Post))
+		if err != nil {
+			return err
+		}
+		files = []string{p}
+	}
+	changes := []postgen.Change{}
+	for _, p := range files {
+		ch, ok, err := g.PlanTOC(p, c.InsertAfterIntro && !c.Check)
+		if err != nil {
+			return err
+		}
+		if !ok && !c.All {
+			return fmt.Errorf("%s has no %s marker, use --insert-after-intro to add one", p, postgen.TOCStart)
+		}
+		if ok && !bytes.Equal(ch.OldContent, ch.NewContent) {
+			changes = append(changes, ch)
+		}
+	}
+	if c.Check {
+		stale := []string{}
+		for _, ch := range changes {
+			stale = append(stale, rel(s.path(ch.Path)))
+		}
+		if opts.JSON {
+			if err := printJSON(map[string][]string{"stale": stale}); err != nil {
+				return err
+			}
+		} else {
+			for _, f := range stale {
+				fmt.Printf("%s: table of contents is stale\n", f)
+			}
+		}
+		if len(stale) > 0 {
+			return fmt.Errorf("%d stale table(s) of contents found, run postgen toc", len(stale))
+		}
+		return nil
+	}
+	if c.DiffOnly {
+		return printDiffs(s, changes, false)
+	}
+	if err := c.confirmChanges(s, changes); err != nil {
+		return err
+	}
+	if err := g.WriteChanges(changes); err != nil {
+		return err
+	}
+	return printChanges(s, changes, false)
+}
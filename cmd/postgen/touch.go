@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+// defaultTouchThreshold is how long after its date a post must be
+// modified to count as updated, unless touch_threshold says otherwise.
+const defaultTouchThreshold = 24 * time.Hour
+
+type touchCommand struct {
+	previewFlags
+	FromGit   bool   `long:"from-git" description:"take the modification dates from the git history, the only source supported"`
+	Ignore    string `long:"ignore" description:"regular expression matching the subjects of commits not counted as updates, such as typo (defaults to the config file's touch_ignore)"`
+	Threshold string `long:"threshold" description:"how long after its date a post must be modified to count as updated, such as 72h (defaults to the config file's touch_threshold, or 24h)"`
+	DryRun    bool   `short:"n" long:"dry-run" description:"print the files that would change without writing them"`
+	Args      struct {
+		Files []string `positional-arg-name:"files" description:"files, glob patterns or slugs (defaults to every post)"`
+	} `positional-args:"yes"`
+}
+
+func (c *touchCommand) Execute(args []string) error {
+	if !c.FromGit {
+		return usagef("touch needs --from-git, the only source of modification dates")
+	}
+	s, cfg, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	pattern := cfg.TouchIgnore
+	if c.Ignore != "" {
+		pattern = c.Ignore
+	}
+	var ignore *regexp.Regexp
+	if pattern != "" {
+		if ignore, err = regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("parsing ignore pattern %s: %w", pattern, err)
+		}
+	}
+	threshold := defaultTouchThreshold
+	value := cfg.TouchThreshold
+	if c.Threshold != "" {
+		value = c.Threshold
+	}
+	if value != "" {
+		if threshold, err = time.ParseDuration(value); err != nil {
+			return usagef("invalid threshold \"%s\": expected a duration such as 72h", value)
+		}
+	}
+	loc, err := location(s, cfg)
+	if err != nil {
+		return err
+	}
+	g := s.generator(loc)
+	files, err := s.files(g, c.Args.Files)
+	if err != nil {
+		return err
+	}
+	var changes []postgen.Change
+	for _, p := range files {
+		if g.IsDraft(p) {
+			continue
+		}
+		dirty, err := gitDirty(s.root, p)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			warnf("skipping %s, which has uncommitted changes", rel(s.path(p)))
+			continue
+		}
+		modified, err := gitLastModified(s.root, p, ignore)
+		if err != nil {
+			return err
+		}
+		if modified.IsZero() {
+			continue
+		}
+		planned, err := g.PlanTouch(p, modified, threshold)
+		if err != nil {
+			return err
+		}
+		changes = append(changes, planned...)
+	}
+	if c.DiffOnly {
+		return printDiffs(s, changes, false)
+	}
+	if !c.DryRun {
+		if err := c.confirmChanges(s, changes); err != nil {
+			return err
+		}
+		if err := g.WriteChanges(changes); err != nil {
+			return err
+		}
+	}
+	return printChanges(s, changes, c.DryRun)
+}
+
+// gitDirty reports whether the file p, relative to root, has changes not
+// committed yet, or is not tracked at all.
+func gitDirty(root, p string) (bool, error) {
+	out, err := git(root, "status", "--porcelain", "--", p)
+	if err != nil {
+		return false, err
+	}
+	return len(bytes.TrimSpace(out)) > 0, nil
+}
+
+// gitLastModified returns the date of the last commit changing the
+// content of p, relative to root, following renames. Commits whose
+// subject matches ignore, and those only changing its last_modified_at
+// line, such as the ones recording what touch wrote, are not counted.
+// The zero time is returned when no commit counts.
+func gitLastModified(root, p string, ignore *regexp.Regexp) (time.Time, error) {
+	out, err := git(root, "log", "--follow", "--no-color", "--unified=0", "--format=%x1e%cI%x1f%s", "-p", "--", p)
+	if err != nil {
+		return time.Time{}, err
+	}
+	for _, commit := range strings.Split(string(out), "\x1e")[1:] {
+		header, patch, _ := strings.Cut(commit, "\n")
+		date, subject, _ := strings.Cut(header, "\x1f")
+		if ignore != nil && ignore.MatchString(subject) || !changesContent(patch) {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, date)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("parsing the date of a commit of %s: %w", p, err)
+		}
+		return t, nil
+	}
+	return time.Time{}, nil
+}
+
+// changesContent reports whether patch changes a line other than the
+// last_modified_at key.
+func changesContent(patch string) bool {
+	for _, line := range strings.Split(patch, "\n") {
+		if strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---") {
+			continue
+		}
+		if (strings.HasPrefix(line, "+") || strings.HasPrefix(line, "-")) && !strings.HasPrefix(line[1:], "last_modified_at:") {
+			return true
+		}
+	}
+	return false
+}
+
+// git runs git in root, returning its output or, when it fails, an error
+// with what it printed to stderr.
+func git(root string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-C", root}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("running git %s: %s: %w", args[0], strings.TrimSpace(stderr.String()), err)
+	}
+	return out, nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+	"github.com/tiagomelo/tiagomelo.github.io/postgen/diff"
+)
+
+type translateCommand struct {
+	Lang   string `long:"lang" description:"language of the translation, such as pt" required:"true"`
+	Title  string `short:"t" long:"title" description:"title of the translation" required:"true"`
+	Slug   string `short:"s" long:"slug" description:"slug of the translation (defaults to one generated from the title)"`
+	DryRun bool   `short:"n" long:"dry-run" description:"print the translation and the change to the original without writing anything"`
+	Args   struct {
+		Post postName `positional-arg-name:"existing-post" description:"slug or file name of the post or draft to translate"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+// translationJSON is the --json form of a translation; Diff, the change
+// to the original, is only set for dry runs.
+type translationJSON struct {
+	resultJSON
+	Original string `json:"original"`
+	Diff     string `json:"diff,omitempty"`
+}
+
+func (c *translateCommand) Execute(args []string) error {
+	s, cfg, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	if err := cfg.checkLang(c.Lang); err != nil {
+		return err
+	}
+	loc, err := location(s, cfg)
+	if err != nil {
+		return err
+	}
+	g := s.generator(loc)
+	g.AllowDuplicateSlug = opts.AllowDuplicateSlug
+	p, err := g.Find(string(c.Args.Post))
+	if err != nil {
+		return err
+	}
+	translateOpts := postgen.TranslationOptions{Lang: c.Lang, Title: c.Title, Slug: c.Slug, OriginalLang: cfg.Lang}
+	var t postgen.Translation
+	if c.DryRun {
+		if t, err = g.PlanTranslation(p, translateOpts); err != nil {
+			return err
+		}
+		if err := g.Collision(t.Post); err != nil {
+			return err
+		}
+	} else if t, err = g.Translate(p, translateOpts); err != nil {
+		return err
+	}
+	changes := diff.Unified("a/"+t.Original.Path, "b/"+t.Original.Path, string(t.Original.OldContent), string(t.Original.NewContent), 3)
+	if opts.JSON {
+		out := translationJSON{resultJSON: newResultJSON(s, t.Post), Original: rel(s.path(p))}
+		if c.DryRun {
+			out.Content, out.Diff = string(t.Post.Content), changes
+		}
+		return printJSON(out)
+	}
+	if c.DryRun {
+		if err := printPlan(s, t.Post); err != nil {
+			return err
+		}
+		fmt.Print(changes)
+		return nil
+	}
+	if err := printResult(s, "created", t.Post); err != nil {
+		return err
+	}
+	if !opts.Quiet {
+		fmt.Printf("updated %s\n", rel(s.path(p)))
+	}
+	return nil
+}
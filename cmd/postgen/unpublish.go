@@ -0,0 +1,29 @@
+package main
+
+type unpublishCommand struct {
+	KeepDate bool `long:"keep-date" description:"record the post's date as original_date, which publish restores"`
+	Args     struct {
+		Post postName `positional-arg-name:"post" description:"slug or file name of the post to unpublish"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+func (c *unpublishCommand) Execute(args []string) error {
+	s, cfg, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	loc, err := location(s, cfg)
+	if err != nil {
+		return err
+	}
+	g := s.generator(loc)
+	p, err := g.Find(string(c.Args.Post))
+	if err != nil {
+		return err
+	}
+	r, err := g.Unpublish(p, c.KeepDate)
+	if err != nil {
+		return err
+	}
+	return printResult(s, "unpublished", r)
+}
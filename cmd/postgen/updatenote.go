@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+type updateNoteCommand struct {
+	Message string `short:"m" long:"message" description:"what changed, written as the entry of the Updates section" required:"true"`
+	DryRun  bool   `short:"n" long:"dry-run" description:"print the change as a diff without writing it"`
+	Args    struct {
+		Post postName `positional-arg-name:"post" description:"slug or file name of the post or draft"`
+	} `positional-args:"yes" required:"yes"`
+}
+
+func (c *updateNoteCommand) Execute(args []string) error {
+	s, cfg, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	loc, err := location(s, cfg)
+	if err != nil {
+		return err
+	}
+	g := s.generator(loc)
+	p, err := g.Find(string(c.Args.Post))
+	if err != nil {
+		return err
+	}
+	ch, err := g.PlanUpdateNote(p, c.Message)
+	if err != nil {
+		return err
+	}
+	if c.DryRun && !opts.JSON {
+		fmt.Print(unifiedChange(ch))
+		return nil
+	}
+	if !c.DryRun {
+		if err := g.WriteChanges([]postgen.Change{ch}); err != nil {
+			return err
+		}
+	}
+	return printChanges(s, []postgen.Change{ch}, c.DryRun)
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+type validateCommand struct {
+	Watch bool `long:"watch" description:"keep running, validating the posts and drafts again as they change and printing the problems found and resolved"`
+}
+
+// problemJSON is the --json form of a postgen.Problem.
+type problemJSON struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// themeLayouts lists the layouts shipped by themes the site may use
+// without having a _layouts folder of its own.
+var themeLayouts = map[string][]string{
+	"minima": {"default", "home", "page", "post"},
+}
+
+func (c *validateCommand) Execute(args []string) error {
+	s, cfg, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	validateOpts, err := validateOptions(s, cfg)
+	if err != nil {
+		return err
+	}
+	if c.Watch && opts.JSON {
+		return usagef("--watch cannot be combined with --json")
+	}
+	g := s.generator(time.UTC)
+	if c.Watch {
+		return watchValidate(s, g, validateOpts)
+	}
+	ctx, stop := interruptContext()
+	defer stop()
+	problems, err := g.Validate(ctx, validateOpts)
+	if err != nil && !interrupted(err) {
+		return err
+	}
+	if opts.JSON {
+		out := []problemJSON{}
+		for _, p := range problems {
+			out = append(out, problemJSON{rel(s.path(p.Path)), p.Line, p.Field, p.Message})
+		}
+		if err := printJSON(out); err != nil {
+			return err
+		}
+	} else {
+		for _, p := range problems {
+			p.Path = rel(s.path(p.Path))
+			fmt.Println(p)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%d problem(s) found", len(problems))
+	}
+	return nil
+}
+
+// validateOptions returns the options validating the posts of s against
+// its layouts, authors, categories and languages.
+func validateOptions(s site, cfg config) (postgen.ValidateOptions, error) {
+	authors, err := readAuthors(s)
+	if err != nil {
+		return postgen.ValidateOptions{}, err
+	}
+	categories, err := readCategories(s)
+	if err != nil {
+		return postgen.ValidateOptions{}, err
+	}
+	return postgen.ValidateOptions{
+		Layouts:    layouts(s),
+		Authors:    set(authors),
+		Categories: set(categories),
+		Languages:  set(cfg.Languages),
+		SiteURL:    readJekyllConfig(s).siteURL(),
+		Permalink:  permalinkSetting(s, cfg),
+	}, nil
+}
+
+// watchValidate prints the problems of the site, then validates each
+// post or draft again when it changes, printing the problems found (+)
+// and resolved (-) with the time, until interrupted. The checks spanning
+// several posts are run again on every change.
+func watchValidate(s site, g *postgen.Generator, validateOpts postgen.ValidateOptions) error {
+	files, err := g.Files()
+	if err != nil {
+		return err
+	}
+	byFile := make(map[string][]postgen.Problem)
+	for _, p := range files {
+		byFile[p] = g.ValidateFile(p, validateOpts)
+	}
+	all := func() ([]postgen.Problem, error) {
+		problems, err := g.ValidateSite(validateOpts)
+		if err != nil {
+			return nil, err
+		}
+		for _, found := range byFile {
+			problems = append(problems, found...)
+		}
+		postgen.SortProblems(problems)
+		return problems, nil
+	}
+	previous, err := all()
+	if err != nil {
+		return err
+	}
+	for _, p := range previous {
+		p.Path = rel(s.path(p.Path))
+		fmt.Println(p)
+	}
+	fmt.Printf("%s %d problem(s) found, watching for changes\n", time.Now().Format("15:04:05"), len(previous))
+
+	return watch(func() (map[string]fileState, error) {
+		files, err := g.Files()
+		if err != nil {
+			return nil, err
+		}
+		paths := make([]string, len(files))
+		for i, p := range files {
+			paths[i] = s.path(p)
+		}
+		return statFiles(paths), nil
+	}, func(changed, removed []string) error {
+		var names []string
+		for _, p := range removed {
+			name, err := s.name(p)
+			if err != nil {
+				return err
+			}
+			delete(byFile, name)
+			names = append(names, "removed "+rel(p))
+		}
+		for _, p := range changed {
+			name, err := s.name(p)
+			if err != nil {
+				return err
+			}
+			byFile[name] = g.ValidateFile(name, validateOpts)
+			names = append(names, "changed "+rel(p))
+		}
+		current, err := all()
+		if err != nil {
+			return err
+		}
+		now := time.Now().Format("15:04:05")
+		fmt.Printf("%s %s\n", now, strings.Join(names, ", "))
+		for _, p := range diffProblems(current, previous) {
+			p.Path = rel(s.path(p.Path))
+			fmt.Printf("+ %s\n", p)
+		}
+		for _, p := range diffProblems(previous, current) {
+			p.Path = rel(s.path(p.Path))
+			fmt.Printf("- %s\n", p)
+		}
+		fmt.Printf("%s %d problem(s) found\n", now, len(current))
+		previous = current
+		return nil
+	})
+}
+
+// diffProblems returns the problems of a that b lacks.
+func diffProblems(a, b []postgen.Problem) []postgen.Problem {
+	in := make(map[postgen.Problem]bool)
+	for _, p := range b {
+		in[p] = true
+	}
+	var diff []postgen.Problem
+	for _, p := range a {
+		if !in[p] {
+			diff = append(diff, p)
+		}
+	}
+	return diff
+}
+
+// layouts returns the layouts available to posts: the ones in the site's
+// _layouts folder plus the ones provided by its theme. It returns nil
+// when neither is known, so that the check is skipped.
+func layouts(s site) map[string]bool {
+	var known map[string]bool
+	add := func(name string) {
+		if known == nil {
+			known = make(map[string]bool)
+		}
+		known[name] = true
+	}
+	entries, err := os.ReadDir(filepath.Join(s.path(s.sourceDir()), "_layouts"))
+	if err == nil {
+		for _, e := range entries {
+			if !e.IsDir() {
+				add(strings.TrimSuffix(e.Name(), filepath.Ext(e.Name())))
+			}
+		}
+	}
+	for _, name := range themeLayouts[readJekyllConfig(s).Theme] {
+		add(name)
+	}
+	return known
+}
+
+// set returns the values as a set, or nil when values is.
+func set(values []string) map[string]bool {
+	if values == nil {
+		return nil
+	}
+	m := make(map[string]bool)
+	for _, v := range values {
+		m[v] = true
+	}
+	return m
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// buildDate may be set with -ldflags "-X main.buildDate=...". Without it
+// the date of the VCS revision is reported instead.
+var buildDate string
+
+// versionInfo describes the running binary.
+type versionInfo struct {
+	Version  string `json:"version"`
+	Revision string `json:"revision"`
+	Modified bool   `json:"modified"`
+	Date     string `json:"date"`
+	Go       string `json:"go"`
+}
+
+func readVersion() versionInfo {
+	v := versionInfo{Version: "(devel)", Date: buildDate, Go: runtime.Version()}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return v
+	}
+	if info.Main.Version != "" {
+		v.Version = info.Main.Version
+	}
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			v.Revision = setting.Value
+		case "vcs.modified":
+			v.Modified = setting.Value == "true"
+		case "vcs.time":
+			if v.Date == "" {
+				v.Date = setting.Value
+			}
+		}
+	}
+	return v
+}
+
+// printVersion prints the module version, VCS revision, build date and Go
+// version of the binary, "unknown" standing for what it was built
+// without.
+func printVersion() error {
+	v := readVersion()
+	if opts.JSON {
+		return printJSON(v)
+	}
+	unknown := func(s string) string {
+		if s == "" {
+			return "unknown"
+		}
+		return s
+	}
+	revision := unknown(v.Revision)
+	if v.Modified {
+		revision += " (modified)"
+	}
+	fmt.Printf("postgen %s\nrevision: %s\ndate:     %s\ngo:       %s\n", v.Version, revision, unknown(v.Date), v.Go)
+	return nil
+}
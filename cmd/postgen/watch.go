@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sort"
+	"time"
+)
+
+// watchInterval is how often the --watch modes look for changes, and
+// watchDebounce how long files must then stay unchanged before they are
+// handled, as editors often write a file several times in a row.
+const (
+	watchInterval = 250 * time.Millisecond
+	watchDebounce = 500 * time.Millisecond
+)
+
+// fileState is what tells a file changed.
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// statFiles returns the state of the existing files among paths, operating
+// system paths, by path.
+func statFiles(paths []string) map[string]fileState {
+	states := make(map[string]fileState)
+	for _, p := range paths {
+		if info, err := os.Stat(p); err == nil {
+			states[p] = fileState{info.Size(), info.ModTime()}
+		}
+	}
+	return states
+}
+
+// watch polls snapshot, which returns the state of the watched files by
+// path, and calls handle with the paths changed or created and the ones
+// removed since the previous call once they have settled, until
+// interrupted. Renaming a file shows as removing it and creating another.
+func watch(snapshot func() (map[string]fileState, error), handle func(changed, removed []string) error) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	handled, err := snapshot()
+	if err != nil {
+		return err
+	}
+	last, settled := handled, time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(watchInterval):
+		}
+		current, err := snapshot()
+		if err != nil {
+			return err
+		}
+		if !sameStates(current, last) {
+			last, settled = current, time.Now()
+			continue
+		}
+		if sameStates(current, handled) || time.Since(settled) < watchDebounce {
+			continue
+		}
+		var changed, removed []string
+		for p, state := range current {
+			if old, ok := handled[p]; !ok || old != state {
+				changed = append(changed, p)
+			}
+		}
+		for p := range handled {
+			if _, ok := current[p]; !ok {
+				removed = append(removed, p)
+			}
+		}
+		sort.Strings(changed)
+		sort.Strings(removed)
+		handled = current
+		if err := handle(changed, removed); err != nil {
+			return err
+		}
+	}
+}
+
+func sameStates(a, b map[string]fileState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for p, state := range a {
+		if other, ok := b[p]; !ok || other != state {
+			return false
+		}
+	}
+	return true
+}
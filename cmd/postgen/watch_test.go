@@ -0,0 +1,56 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+func TestDiffProblems(t *testing.T) {
+	title := postgen.Problem{Path: "a.md", Field: "title", Message: "missing required key"}
+	date := postgen.Problem{Path: "a.md", Line: 3, Field: "date", Message: "bad date"}
+	moved := date
+	moved.Line = 4
+	tests := []struct {
+		name string
+		a, b []postgen.Problem
+		want []postgen.Problem
+	}{
+		{"found", []postgen.Problem{title, date}, []postgen.Problem{title}, []postgen.Problem{date}},
+		{"resolved", []postgen.Problem{title}, []postgen.Problem{title, date}, nil},
+		{"moved line", []postgen.Problem{moved}, []postgen.Problem{date}, []postgen.Problem{moved}},
+		{"none before", []postgen.Problem{title}, nil, []postgen.Problem{title}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diffProblems(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffProblems = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSameStates(t *testing.T) {
+	now := time.Now()
+	a := map[string]fileState{"a.md": {10, now}, "b.md": {20, now}}
+	tests := []struct {
+		name string
+		b    map[string]fileState
+		want bool
+	}{
+		{"same", map[string]fileState{"a.md": {10, now}, "b.md": {20, now}}, true},
+		{"written", map[string]fileState{"a.md": {10, now.Add(time.Second)}, "b.md": {20, now}}, false},
+		{"resized", map[string]fileState{"a.md": {11, now}, "b.md": {20, now}}, false},
+		{"removed", map[string]fileState{"a.md": {10, now}}, false},
+		{"renamed", map[string]fileState{"a.md": {10, now}, "c.md": {20, now}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sameStates(a, tt.b); got != tt.want {
+				t.Errorf("sameStates = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
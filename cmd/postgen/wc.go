@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen"
+)
+
+type wcCommand struct {
+	Drafts bool `long:"drafts" description:"count every draft"`
+	All    bool `long:"all" description:"count every post and draft"`
+	Target int  `long:"target" description:"word count aimed at, shown as a percentage of progress"`
+	Watch  bool `long:"watch" description:"print the counts again whenever a file changes, until interrupted"`
+	WPM    int  `long:"wpm" description:"reading speed in words per minute (defaults to the config file's words_per_minute, or 200)"`
+	Args   struct {
+		Files []string `positional-arg-name:"files" description:"files, glob patterns or slugs"`
+	} `positional-args:"yes"`
+}
+
+// wcJSON is the --json form of the counts of a file.
+type wcJSON struct {
+	File     string `json:"file"`
+	Words    int    `json:"words"`
+	Chars    int    `json:"chars"`
+	Minutes  int    `json:"minutes"`
+	Progress *int   `json:"progress,omitempty"`
+}
+
+func (c *wcCommand) Execute(args []string) error {
+	if (len(c.Args.Files) > 0) == (c.Drafts || c.All) || c.Drafts && c.All {
+		return usagef("expected either files, --drafts or --all")
+	}
+	if c.Watch && opts.JSON {
+		return usagef("--watch cannot be combined with --json")
+	}
+	if c.Target < 0 {
+		return usagef("invalid target %d: expected a positive word count", c.Target)
+	}
+	s, cfg, _, err := loadSite()
+	if err != nil {
+		return err
+	}
+	wpm := cfg.WordsPerMinute
+	if c.WPM != 0 {
+		wpm = c.WPM
+	}
+	if wpm <= 0 {
+		wpm = postgen.DefaultWordsPerMinute
+	}
+	g := s.generator(time.UTC)
+	files, err := s.files(g, c.Args.Files)
+	if err != nil {
+		return err
+	}
+	if c.Drafts {
+		var drafts []string
+		for _, p := range files {
+			if g.IsDraft(p) {
+				drafts = append(drafts, p)
+			}
+		}
+		files = drafts
+	}
+	if err := c.print(s, g, files, wpm); err != nil || !c.Watch {
+		return err
+	}
+	paths := make([]string, len(files))
+	for i, p := range files {
+		paths[i] = s.path(p)
+	}
+	return watch(func() (map[string]fileState, error) {
+		return statFiles(paths), nil
+	}, func(changed, removed []string) error {
+		var existing []string
+		for _, p := range files {
+			if _, err := os.Stat(s.path(p)); err == nil {
+				existing = append(existing, p)
+			}
+		}
+		fmt.Printf("\n%s\n", time.Now().Format("15:04:05"))
+		return c.print(s, g, existing, wpm)
+	})
+}
+
+// print prints the counts of files, with a total when there are several.
+func (c *wcCommand) print(s site, g *postgen.Generator, files []string, wpm int) error {
+	out := []wcJSON{}
+	var total postgen.TextCount
+	for _, p := range files {
+		count, err := g.CountFile(p)
+		if err != nil {
+			return err
+		}
+		total.Words += count.Words
+		total.Chars += count.Chars
+		out = append(out, c.counts(rel(s.path(p)), count, wpm))
+	}
+	if opts.JSON {
+		return printJSON(out)
+	}
+	if len(out) > 1 {
+		sum := c.counts("total", total, wpm)
+		sum.Progress = nil
+		out = append(out, sum)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	header := "WORDS\tCHARS\tREADING\t"
+	if c.Target > 0 {
+		header += "PROGRESS\t"
+	}
+	fmt.Fprintln(w, header+"FILE")
+	for _, f := range out {
+		progress := ""
+		switch {
+		case f.Progress != nil:
+			progress = fmt.Sprintf("%d%%\t", *f.Progress)
+		case c.Target > 0:
+			progress = "-\t"
+		}
+		fmt.Fprintf(w, "%d\t%d\t%d min\t%s%s\n", f.Words, f.Chars, f.Minutes, progress, f.File)
+	}
+	return w.Flush()
+}
+
+func (c *wcCommand) counts(file string, count postgen.TextCount, wpm int) wcJSON {
+	f := wcJSON{File: file, Words: count.Words, Chars: count.Chars, Minutes: postgen.ReadingMinutes(count.Words, wpm)}
+	if c.Target > 0 {
+		progress := count.Words * 100 / c.Target
+		f.Progress = &progress
+	}
+	return f
+}
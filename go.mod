@@ -4,7 +4,9 @@ go 1.22.2
 
 require (
 	github.com/jessevdk/go-flags v1.5.0
-	github.com/pkg/errors v0.9.1
+	github.com/yuin/goldmark v1.8.6
+	golang.org/x/term v0.20.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
-require golang.org/x/sys v0.0.0-20210320140829-1e4c9ba3b0c4 // indirect
+require golang.org/x/sys v0.20.0 // indirect
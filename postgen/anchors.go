@@ -0,0 +1,96 @@
+package postgen
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// AnchorDrift is a heading whose pinned id no longer matches the one its
+// text would generate, which is fine as long as links use the pinned one.
+type AnchorDrift struct {
+	Path      string
+	Line      int
+	Text      string
+	ID        string
+	Generated string
+}
+
+func (d AnchorDrift) String() string {
+	return fmt.Sprintf("%s:%d: heading \"%s\" is pinned to #%s, its text would generate #%s", d.Path, d.Line, d.Text, d.ID, d.Generated)
+}
+
+// numberedIDPattern matches the suffix kramdown adds to repeated ids.
+var numberedIDPattern = regexp.MustCompile(`-\d+$`)
+
+// PinAnchors returns body with a {#id} attribute appended to each heading
+// without one, id being the one kramdown generates for it, so that links
+// to it keep working when its text changes. The int is the number of
+// headings pinned; ids already pinned are never changed.
+func PinAnchors(body []byte) ([]byte, int) {
+	headings := Headings(body)
+	var b bytes.Buffer
+	last, pinned := 0, 0
+	for i, m := range headingPattern.FindAllSubmatchIndex(blank(body, codeBlockPattern), -1) {
+		if headingIDPattern.Match(body[m[4]:m[5]]) || headings[i].ID == "" {
+			continue
+		}
+		b.Write(body[last:m[5]])
+		fmt.Fprintf(&b, " {#%s}", headings[i].ID)
+		last = m[5]
+		pinned++
+	}
+	b.Write(body[last:])
+	return b.Bytes(), pinned
+}
+
+// PlanPinAnchors computes pinning the heading ids of the post at p with
+// PinAnchors; NewContent equals OldContent when there is nothing to pin.
+func (g *Generator) PlanPinAnchors(p string) (Change, error) {
+	doc, content, err := g.readDocument(p)
+	if err != nil {
+		return Change{}, err
+	}
+	body, pinned := PinAnchors(doc.Body)
+	if pinned == 0 {
+		return Change{Path: p, OldContent: content, NewContent: content}, nil
+	}
+	doc.Body = body
+	return Change{Path: p, OldContent: content, NewContent: doc.Bytes()}, nil
+}
+
+// CheckAnchors returns the headings of the posts and drafts at paths
+// whose pinned id is neither the one their text generates nor a numbered
+// variant of it.
+func (g *Generator) CheckAnchors(paths []string) ([]AnchorDrift, error) {
+	var drifts []AnchorDrift
+	for _, p := range paths {
+		doc, content, err := g.readDocument(p)
+		if err != nil {
+			return nil, err
+		}
+		first := 1 + bytes.Count(content[:len(content)-len(doc.Body)], []byte("\n"))
+		masked := blank(doc.Body, codeBlockPattern)
+		for _, m := range headingPattern.FindAllSubmatchIndex(masked, -1) {
+			text := string(doc.Body[m[4]:m[5]])
+			id := headingIDPattern.FindStringSubmatchIndex(text)
+			if id == nil {
+				continue
+			}
+			pinned, text := text[id[2]:id[3]], strings.TrimSpace(text[:id[0]])
+			generated := HeadingID(text)
+			if pinned == generated || numberedIDPattern.ReplaceAllString(pinned, "") == generated {
+				continue
+			}
+			drifts = append(drifts, AnchorDrift{
+				Path:      p,
+				Line:      first + bytes.Count(doc.Body[:m[0]], []byte("\n")),
+				Text:      text,
+				ID:        pinned,
+				Generated: generated,
+			})
+		}
+	}
+	return drifts, nil
+}
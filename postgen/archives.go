@@ -0,0 +1,157 @@
+package postgen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen/frontmatter"
+)
+
+// ArchiveOptions tunes PlanArchives.
+type ArchiveOptions struct {
+	// Dir is the slash-separated folder, relative to FS, holding the
+	// archive pages.
+	Dir    string
+	Layout string
+	// Monthly adds a page per month next to the yearly ones.
+	Monthly bool
+}
+
+// ArchivePlan lists the archive pages to write and to delete.
+type ArchivePlan struct {
+	// Write holds the new or changed pages; OldContent is nil for new
+	// ones.
+	Write  []Change
+	Delete []string
+	// Unchanged counts the pages already up to date.
+	Unchanged int
+}
+
+// archiveNamePattern matches the file names of yearly and monthly
+// archive pages.
+var archiveNamePattern = regexp.MustCompile(`^(\d{4})(-\d{2})?\.(markdown|md)$`)
+
+// archiveKey marks the pages written by PlanArchives, so that only those
+// are ever deleted.
+const archiveKey = "archive"
+
+// PlanArchives computes one page per year with posts, and per month with
+// Monthly, linking to the posts of that period newest first. Pages
+// whose content would not change are left alone; generated pages for
+// periods without posts are deleted.
+func (g *Generator) PlanArchives(opts ArchiveOptions) (ArchivePlan, error) {
+	entries, bad, err := g.List()
+	if err != nil {
+		return ArchivePlan{}, err
+	}
+	if len(bad) > 0 {
+		return ArchivePlan{}, bad[0]
+	}
+	periods := make(map[string][]Entry)
+	for _, e := range entries {
+		if e.Meta.Published != nil && !*e.Meta.Published {
+			continue
+		}
+		periods[e.Date.Format("2006")] = append(periods[e.Date.Format("2006")], e)
+		if opts.Monthly {
+			periods[e.Date.Format("2006-01")] = append(periods[e.Date.Format("2006-01")], e)
+		}
+	}
+	names := make([]string, 0, len(periods))
+	for name := range periods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var plan ArchivePlan
+	wanted := make(map[string]bool)
+	for _, name := range names {
+		// An existing page keeps its extension.
+		p := path.Join(opts.Dir, name+"."+g.Ext)
+		for _, ext := range Exts {
+			if existing := path.Join(opts.Dir, name+"."+ext); g.exists(existing) {
+				p = existing
+				break
+			}
+		}
+		wanted[p] = true
+		content := archivePage(name, opts.Layout, periods[name])
+		old, err := g.FS.ReadFile(p)
+		switch {
+		case err == nil && bytes.Equal(old, content):
+			plan.Unchanged++
+		case err == nil:
+			plan.Write = append(plan.Write, Change{Path: p, OldContent: old, NewContent: content})
+		case errors.Is(err, fs.ErrNotExist):
+			plan.Write = append(plan.Write, Change{Path: p, NewContent: content})
+		default:
+			return ArchivePlan{}, fmt.Errorf("reading file %s: %w", p, err)
+		}
+	}
+
+	dirEntries, err := fs.ReadDir(g.FS, opts.Dir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return ArchivePlan{}, fmt.Errorf("reading folder %s: %w", opts.Dir, err)
+	}
+	for _, de := range dirEntries {
+		m := archiveNamePattern.FindStringSubmatch(de.Name())
+		p := path.Join(opts.Dir, de.Name())
+		if de.IsDir() || m == nil || wanted[p] || m[2] != "" && !opts.Monthly {
+			continue
+		}
+		if doc, _, err := g.readDocument(p); err == nil && doc.Has(archiveKey) {
+			plan.Delete = append(plan.Delete, p)
+		}
+	}
+	return plan, nil
+}
+
+// ApplyArchives writes and deletes the pages in plan.
+func (g *Generator) ApplyArchives(plan ArchivePlan) error {
+	for _, c := range plan.Write {
+		if err := g.WritePage(c); err != nil {
+			return err
+		}
+	}
+	for _, p := range plan.Delete {
+		if err := g.FS.Remove(p); err != nil {
+			return fmt.Errorf("removing file %s: %w", p, err)
+		}
+	}
+	return nil
+}
+
+func (g *Generator) exists(p string) bool {
+	_, err := g.FS.Stat(p)
+	return err == nil
+}
+
+// archivePage renders the archive page of period, a year or a
+// YYYY-MM month, listing posts.
+func archivePage(period, layout string, posts []Entry) []byte {
+	title := period
+	permalink := "/" + period + "/"
+	if len(period) > 4 {
+		title = posts[0].Date.Format("January 2006")
+		permalink = "/" + strings.Replace(period, "-", "/", 1) + "/"
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "---\nlayout: %s\ntitle:  %s\npermalink: %s\n%s: %s\n---\n\n", layout, frontmatter.String(title), permalink, archiveKey, frontmatter.String(period))
+	for _, e := range posts {
+		name := strings.TrimSuffix(path.Base(e.Path), path.Ext(e.Path))
+		fmt.Fprintf(&b, "- %s [%s]({%% post_url %s %%})\n", e.Date.Format(FileDateLayout), escapeLinkText(e.Meta.Title), name)
+	}
+	return b.Bytes()
+}
+
+// escapeLinkText escapes the characters that would end Markdown link
+// text early.
+func escapeLinkText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `[`, `\[`, `]`, `\]`).Replace(s)
+}
@@ -0,0 +1,113 @@
+package postgen
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AuditOptions configures Audit.
+type AuditOptions struct {
+	// Before is the date posts must be older than to be listed.
+	Before time.Time
+	// Tags, when set, restricts the posts listed to the ones with one of
+	// these tags or categories, compared ignoring case.
+	Tags []string
+	// Permalink is the site's permalink setting, as passed to Permalink,
+	// and Pageviews the views of the site's pages, as ParsePageviews
+	// returns them.
+	Permalink string
+	Pageviews map[string]int
+}
+
+// AuditEntry is a post Audit lists, with its page views, -1 when
+// Pageviews has none for it.
+type AuditEntry struct {
+	Entry
+	Pageviews int
+}
+
+// Audit returns the published posts, drafts left out, dated before
+// opts.Before and matching opts.Tags, oldest first. Unreadable posts are
+// reported as in List.
+func (g *Generator) Audit(opts AuditOptions) ([]AuditEntry, []*FileError, error) {
+	entries, bad, err := g.List()
+	if err != nil {
+		return nil, nil, err
+	}
+	var audited []AuditEntry
+	for _, e := range published(entries) {
+		if !e.Date.Before(opts.Before) || len(opts.Tags) > 0 && !hasTag(e, opts.Tags) {
+			continue
+		}
+		views, ok := opts.Pageviews[pageKey(Permalink(opts.Permalink, e))]
+		if !ok {
+			if views, ok = opts.Pageviews[e.Slug]; !ok {
+				views = -1
+			}
+		}
+		audited = append(audited, AuditEntry{Entry: e, Pageviews: views})
+	}
+	sort.SliceStable(audited, func(i, j int) bool { return audited[i].Date.Before(audited[j].Date) })
+	return audited, bad, nil
+}
+
+// hasTag reports whether e has one of tags among its tags and
+// categories, ignoring case.
+func hasTag(e Entry, tags []string) bool {
+	for _, t := range append(append([]string{}, e.Meta.Tags...), e.Meta.Categories...) {
+		for _, tag := range tags {
+			if strings.EqualFold(t, tag) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ParsePageviews reads a CSV of page views, such as an analytics export:
+// each row a page, given by URL, path or post slug, and its number of
+// views, in the first two columns. Rows whose second column is not a
+// number, such as a header, are skipped; the views of the rows of a page
+// are summed.
+func ParsePageviews(r io.Reader) (map[string]int, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	views := make(map[string]int)
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return views, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parsing page views: %w", err)
+		}
+		if len(record) < 2 {
+			continue
+		}
+		n, err := strconv.Atoi(strings.ReplaceAll(strings.TrimSpace(record[1]), ",", ""))
+		if err != nil {
+			continue
+		}
+		views[pageKey(strings.TrimSpace(record[0]))] += n
+	}
+}
+
+// pageKey returns the path of the page URL u without its trailing slash,
+// or u itself when it is a slug, so that the forms analytics tools write
+// pages in compare equal.
+func pageKey(u string) string {
+	if parsed, err := url.Parse(u); err == nil && (parsed.Host != "" || strings.HasPrefix(u, "/")) {
+		u = parsed.Path
+	}
+	if u != "/" {
+		u = strings.TrimSuffix(u, "/")
+	}
+	return u
+}
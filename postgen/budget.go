@@ -0,0 +1,90 @@
+package postgen
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+)
+
+// ImageFile is a file of the images folder and its size in bytes.
+type ImageFile struct {
+	Path string
+	Size int64
+}
+
+// ImageFolder is a folder of the images folder with the total size of
+// the files below it. Post is the post or draft it is named after or,
+// failing that, the only one referencing it; it is empty otherwise.
+type ImageFolder struct {
+	Path string
+	Post string
+	Size int64
+}
+
+// ImageWeight is how much the images folder weighs: each of its folders
+// and every file in it, both largest first, and their total.
+type ImageWeight struct {
+	Folders []ImageFolder
+	Files   []ImageFile
+	Total   int64
+}
+
+// ImageWeight measures the images folder. Files directly in it count in
+// Files and Total but belong to no folder.
+func (g *Generator) ImageWeight() (ImageWeight, error) {
+	files, err := g.Files()
+	if err != nil {
+		return ImageWeight{}, err
+	}
+	owners := make(map[string]string, len(files))
+	for _, p := range files {
+		owners[g.ImagesFolder(p)] = p
+	}
+	var w ImageWeight
+	folders := make(map[string]int)
+	err = fs.WalkDir(g.FS, g.ImagesDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path.Dir(p) == g.ImagesDir {
+				folders[p] = len(w.Folders)
+				w.Folders = append(w.Folders, ImageFolder{Path: p, Post: owners[p]})
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		w.Files = append(w.Files, ImageFile{Path: p, Size: info.Size()})
+		w.Total += info.Size()
+		for dir := path.Dir(p); dir != g.ImagesDir && dir != "."; dir = path.Dir(dir) {
+			if i, ok := folders[dir]; ok {
+				w.Folders[i].Size += info.Size()
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return ImageWeight{}, fmt.Errorf("reading folder %s: %w", g.ImagesDir, err)
+	}
+	for i, f := range w.Folders {
+		if f.Post != "" {
+			continue
+		}
+		refs, err := g.References(f.Path, "")
+		if err != nil {
+			return ImageWeight{}, err
+		}
+		if len(refs) == 1 {
+			w.Folders[i].Post = refs[0]
+		}
+	}
+	sort.SliceStable(w.Folders, func(i, j int) bool { return w.Folders[i].Size > w.Folders[j].Size })
+	sort.SliceStable(w.Files, func(i, j int) bool { return w.Files[i].Size > w.Files[j].Size })
+	return w, nil
+}
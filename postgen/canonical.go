@@ -0,0 +1,23 @@
+package postgen
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ParseCanonicalURL parses the canonical_url of a post that first
+// appeared elsewhere, which must be an absolute http(s) URL.
+func ParseCanonicalURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("invalid canonical URL %s: expected an absolute http(s) URL", raw)
+	}
+	return u, nil
+}
+
+// samePage reports whether a and b are the same page, ignoring the
+// scheme, the case of the host, a trailing slash and a query or fragment.
+func samePage(a, b *url.URL) bool {
+	return strings.EqualFold(a.Host, b.Host) && strings.TrimSuffix(a.Path, "/") == strings.TrimSuffix(b.Path, "/")
+}
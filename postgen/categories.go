@@ -0,0 +1,231 @@
+package postgen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen/frontmatter"
+	"gopkg.in/yaml.v3"
+)
+
+// CategoryRename is renaming a category across the site.
+type CategoryRename struct {
+	// Posts are the posts and drafts whose categories change.
+	Posts []TermChange
+	// Data is the change to the categories data file, nil when it has no
+	// entry for the category.
+	Data *Change
+	// Pages are the category pages rewritten, and renamed when named
+	// after the category.
+	Pages []Rename
+	// Warnings describes what was left for a human to decide.
+	Warnings []string
+}
+
+// PlanCategoryRename computes renaming the category from to to: in the
+// categories of every post and draft, merging it into to where a post
+// has both; in the data file at dataFile, when the site has one, whose
+// entry for from is renamed, or removed when to has one already; and in
+// the pages whose category front matter key, or categories list,
+// declares from. Such a page named after from, or whose permalink has a
+// from segment, follows the new name, unless a page already declares to,
+// in which case it is left alone with a warning.
+func (g *Generator) PlanCategoryRename(from, to, dataFile string) (CategoryRename, error) {
+	if _, err := ParseCategories([]string{to}); err != nil || strings.Contains(to, ",") {
+		return CategoryRename{}, fmt.Errorf("invalid category \"%s\": only letters, digits, '-', '_' and '.' are allowed", to)
+	}
+	if from == to {
+		return CategoryRename{}, fmt.Errorf("the category is already named %s", to)
+	}
+	var r CategoryRename
+	var err error
+	if r.Posts, err = g.RenameTerm(from, to, []string{"categories"}); err != nil {
+		return CategoryRename{}, err
+	}
+	if dataFile != "" && g.exists(dataFile) {
+		content, err := g.FS.ReadFile(dataFile)
+		if err != nil {
+			return CategoryRename{}, fmt.Errorf("reading file %s: %w", dataFile, err)
+		}
+		renamed, err := renameDataEntry(content, from, to)
+		if err != nil {
+			return CategoryRename{}, fmt.Errorf("parsing %s: %w", dataFile, err)
+		}
+		if !bytes.Equal(renamed, content) {
+			r.Data = &Change{Path: dataFile, OldContent: content, NewContent: renamed}
+		}
+	}
+
+	type page struct {
+		content    []byte
+		categories []string
+	}
+	declaring := make(map[string][]string)
+	read := make(map[string]page)
+	err = g.walkPages(func(p string, doc *frontmatter.Document) error {
+		var meta struct {
+			Category   string           `yaml:"category"`
+			Categories frontmatter.List `yaml:"categories"`
+		}
+		if doc.Decode(&meta) != nil {
+			return nil
+		}
+		for _, c := range append([]string{meta.Category}, meta.Categories...) {
+			if c == from || c == to {
+				declaring[c] = append(declaring[c], p)
+				read[p] = page{doc.Bytes(), meta.Categories}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return CategoryRename{}, err
+	}
+	for _, p := range declaring[from] {
+		if len(declaring[to]) > 0 {
+			r.Warnings = append(r.Warnings, fmt.Sprintf("%s declares %s, which %s already has a page for: %s; delete it if it is redundant", p, from, to, strings.Join(declaring[to], ", ")))
+			continue
+		}
+		// The document walkPages read is shared with the corpus, so the
+		// renaming happens on a copy.
+		doc, err := frontmatter.Parse(read[p].content)
+		if err != nil {
+			return CategoryRename{}, fmt.Errorf("parsing %s: %w", p, err)
+		}
+		if raw, ok := doc.Raw("category"); ok && strings.Trim(raw, `"'`) == from {
+			doc.SetRaw("category", frontmatter.Scalar(to))
+		}
+		if raw, ok := doc.Raw("categories"); ok && contains(read[p].categories, from) {
+			var renamed []string
+			for _, v := range read[p].categories {
+				if v == from {
+					v = to
+				}
+				if !contains(renamed, v) {
+					renamed = append(renamed, v)
+				}
+			}
+			doc.SetRaw("categories", renderList(raw, renamed))
+		}
+		if raw, ok := doc.Raw("permalink"); ok {
+			// The names are plain segments, which keep the value's quoting
+			// valid.
+			segments := strings.Split(raw, "/")
+			for i, s := range segments {
+				if strings.Trim(s, `"'`) == from {
+					segments[i] = strings.Replace(s, from, to, 1)
+				}
+			}
+			doc.SetRaw("permalink", strings.Join(segments, "/"))
+		}
+		newPath := p
+		if TrimExt(path.Base(p)) == from {
+			newPath = path.Join(path.Dir(p), to+path.Ext(p))
+			if g.exists(newPath) {
+				return CategoryRename{}, conflictf("file %s already exists", newPath)
+			}
+		}
+		r.Pages = append(r.Pages, Rename{OldPath: p, NewPath: newPath, OldContent: read[p].content, NewContent: doc.Bytes()})
+	}
+	return r, nil
+}
+
+// ApplyCategoryRename writes the changes r plans.
+func (g *Generator) ApplyCategoryRename(r CategoryRename) error {
+	for _, ch := range r.Posts {
+		if err := g.WriteChanges([]Change{ch.Change}); err != nil {
+			return err
+		}
+	}
+	if r.Data != nil {
+		if err := g.WriteChanges([]Change{*r.Data}); err != nil {
+			return err
+		}
+	}
+	for _, page := range r.Pages {
+		if err := g.ApplyRename(page); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renameDataEntry renames the entry from of a data file, a mapping keyed
+// by name or a list of names or of mappings with a slug or else a name
+// key, to to. When to has an entry already, the one of from is removed
+// instead. Only the lines of that entry change.
+func renameDataEntry(content []byte, from, to string) ([]byte, error) {
+	var data yaml.Node
+	if err := yaml.Unmarshal(content, &data); err != nil {
+		return nil, err
+	}
+	if len(data.Content) == 0 {
+		return content, nil
+	}
+	// Each entry is the node holding its name and the node starting it.
+	type entry struct{ name, start *yaml.Node }
+	var entries []entry
+	switch root := data.Content[0]; root.Kind {
+	case yaml.MappingNode:
+		for i := 0; i < len(root.Content); i += 2 {
+			entries = append(entries, entry{root.Content[i], root.Content[i]})
+		}
+	case yaml.SequenceNode:
+		for _, item := range root.Content {
+			switch item.Kind {
+			case yaml.ScalarNode:
+				entries = append(entries, entry{item, item})
+			case yaml.MappingNode:
+				var name *yaml.Node
+				for i := 0; i+1 < len(item.Content); i += 2 {
+					if key := item.Content[i].Value; key == "slug" || key == "name" && name == nil {
+						name = item.Content[i+1]
+					}
+				}
+				if name != nil {
+					entries = append(entries, entry{name, item})
+				}
+			}
+		}
+	default:
+		return nil, errors.New("expected a mapping or a list")
+	}
+	found, exists := -1, false
+	for i, e := range entries {
+		switch e.name.Value {
+		case from:
+			found = i
+		case to:
+			exists = true
+		}
+	}
+	if found < 0 {
+		return content, nil
+	}
+	lines := strings.SplitAfter(string(content), "\n")
+	if exists {
+		// Remove the lines of the entry, up to the next one.
+		start, end := entries[found].start.Line-1, len(lines)
+		if found+1 < len(entries) {
+			end = entries[found+1].start.Line - 1
+		}
+		return []byte(strings.Join(append(lines[:start:start], lines[end:]...), "")), nil
+	}
+	name := entries[found].name
+	line := lines[name.Line-1]
+	col := name.Column - 1
+	rest := line[col:]
+	switch {
+	case strings.HasPrefix(rest, from):
+		rest = frontmatter.Scalar(to) + rest[len(from):]
+	case strings.HasPrefix(rest, `"`+from+`"`), strings.HasPrefix(rest, `'`+from+`'`):
+		rest = rest[:1] + to + rest[len(from)+1:]
+	default:
+		return nil, fmt.Errorf("could not find %s on line %d", from, name.Line)
+	}
+	lines[name.Line-1] = line[:col] + rest
+	return []byte(strings.Join(lines, "")), nil
+}
@@ -0,0 +1,34 @@
+package postgen
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+)
+
+// Change is a rewrite of an existing post or draft.
+type Change struct {
+	Path       string
+	OldContent []byte
+	NewContent []byte
+}
+
+// WriteChanges writes the new content of each change.
+func (g *Generator) WriteChanges(changes []Change) error {
+	for _, c := range changes {
+		if err := g.writeFile(c.Path, c.NewContent, os.O_WRONLY|os.O_TRUNC); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WritePage writes the new content of a generated page, creating it and
+// its folder when needed.
+func (g *Generator) WritePage(c Change) error {
+	if err := g.FS.MkdirAll(path.Dir(c.Path), fs.ModePerm); err != nil {
+		return fmt.Errorf("creating folder %s: %w", path.Dir(c.Path), err)
+	}
+	return g.writeFile(c.Path, c.NewContent, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+}
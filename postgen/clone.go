@@ -0,0 +1,89 @@
+package postgen
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen/frontmatter"
+)
+
+// cloneDroppedKeys identify the source post and are not carried over to
+// its clones.
+var cloneDroppedKeys = []string{"permalink", "redirect_from"}
+
+// PlanClone computes a new post titled p.Title from the post or draft at
+// source: its front matter is kept with a fresh title and date, and
+// image paths in its body are pointed at the new post's images folder.
+// The source's images are copied along when copyImages is set.
+func (g *Generator) PlanClone(source string, p Post, copyImages bool) (Result, error) {
+	if p.Date.IsZero() {
+		p.Date = g.Now()
+	}
+	name := p.Date.Format(FileDateLayout) + "-" + p.Slug
+	dir := g.PostsDir
+	if p.Draft {
+		name = p.Slug
+		dir = g.DraftsDir
+	}
+	content, err := g.FS.ReadFile(source)
+	if err != nil {
+		return Result{}, fmt.Errorf("reading file %s: %w", source, err)
+	}
+	doc, err := frontmatter.Parse(content)
+	if err != nil {
+		return Result{}, fmt.Errorf("parsing %s: %w", source, err)
+	}
+	doc.SetRaw("title", frontmatter.String(p.Title))
+	doc.SetRaw("date", p.Date.Format(DateLayout))
+	for _, key := range cloneDroppedKeys {
+		doc.Delete(key)
+	}
+	if p.Draft {
+		doc.SetRaw("published", "false")
+	} else {
+		doc.Delete("published")
+	}
+	sourceImages := g.ImagesFolder(source)
+	g.rewriteImagesFolder(doc, path.Base(sourceImages), name)
+	r := Result{
+		MarkdownPath: path.Join(dir, name+"."+g.Ext),
+		ImagesPath:   path.Join(g.ImagesDir, name),
+		Slug:         p.Slug,
+		Date:         p.Date,
+		Content:      lf(doc.Bytes()),
+	}
+	if !copyImages {
+		return r, nil
+	}
+	entries, err := fs.ReadDir(g.FS, sourceImages)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return Result{}, fmt.Errorf("reading folder %s: %w", sourceImages, err)
+	}
+	for _, e := range entries {
+		if e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		data, err := g.FS.ReadFile(path.Join(sourceImages, e.Name()))
+		if err != nil {
+			return Result{}, fmt.Errorf("reading file %s: %w", path.Join(sourceImages, e.Name()), err)
+		}
+		r.Images = append(r.Images, path.Join(r.ImagesPath, e.Name()))
+		r.images = append(r.images, data)
+	}
+	return r, nil
+}
+
+// Clone creates the post planned by PlanClone.
+func (g *Generator) Clone(source string, p Post, copyImages bool) (Result, error) {
+	r, err := g.PlanClone(source, p, copyImages)
+	if err != nil {
+		return Result{}, err
+	}
+	if err := g.apply(r); err != nil {
+		return Result{}, err
+	}
+	return r, nil
+}
@@ -0,0 +1,72 @@
+package postgen
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ImagesFolder returns the images folder belonging to the post or draft
+// at markdownPath.
+func (g *Generator) ImagesFolder(markdownPath string) string {
+	name := path.Base(markdownPath)
+	return path.Join(g.ImagesDir, strings.TrimSuffix(name, path.Ext(name)))
+}
+
+// References returns the posts and drafts, other than exclude, whose body
+// references the images folder folder.
+func (g *Generator) References(folder, exclude string) ([]string, error) {
+	re := imagesFolderPattern(g.ImagesURL(), path.Base(folder))
+	files, err := g.Files()
+	if err != nil {
+		return nil, err
+	}
+	var refs []string
+	for _, p := range files {
+		if p == exclude {
+			continue
+		}
+		content, err := g.FS.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("reading file %s: %w", p, err)
+		}
+		if re.Match(content) {
+			refs = append(refs, p)
+		}
+	}
+	return refs, nil
+}
+
+// CanDelete returns an error when deleting the post or draft at
+// markdownPath would break another post referencing its images folder.
+func (g *Generator) CanDelete(markdownPath string) error {
+	folder := g.ImagesFolder(markdownPath)
+	refs, err := g.References(folder, markdownPath)
+	if err != nil {
+		return err
+	}
+	if len(refs) > 0 {
+		return conflictf("images folder %s is also used by %s", folder, strings.Join(refs, ", "))
+	}
+	return nil
+}
+
+// Delete removes the post or draft at markdownPath together with its
+// images folder. It refuses when CanDelete does.
+func (g *Generator) Delete(markdownPath string) (Result, error) {
+	if err := g.CanDelete(markdownPath); err != nil {
+		return Result{}, err
+	}
+	folder := g.ImagesFolder(markdownPath)
+	r := Result{MarkdownPath: markdownPath}
+	if _, err := g.FS.Stat(folder); err == nil {
+		if err := g.FS.RemoveAll(folder); err != nil {
+			return Result{}, fmt.Errorf("removing folder %s: %w", folder, err)
+		}
+		r.ImagesPath = folder
+	}
+	if err := g.FS.Remove(markdownPath); err != nil {
+		return Result{}, fmt.Errorf("removing file %s: %w", markdownPath, err)
+	}
+	return r, nil
+}
@@ -0,0 +1,125 @@
+package postgen
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen/frontmatter"
+)
+
+// DescriptionLength is the length descriptions are cut at, about what
+// search engines show of a snippet.
+const DescriptionLength = 160
+
+var (
+	paragraphBreakPattern = regexp.MustCompile(`\n[ \t]*\n`)
+	// nonProsePattern matches the blocks that are not a paragraph of text:
+	// headings, rules, lists, tables, HTML, Liquid tags and lone images.
+	nonProsePattern        = regexp.MustCompile(`^(?:#|[-*_]{3,}\s*$|[-*+] |\d+\. |\||<|\{%|!\[[^\]]*\]\([^)]*\)\s*$)`)
+	excerptImagePattern    = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+	excerptLinkPattern     = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)|\[([^\]]*)\]\[[^\]]*\]`)
+	excerptEmphasisPattern = regexp.MustCompile("\\*\\*|__|[*`~]|(?:^|\\s)_|_(?:\\s|$)")
+	quotePattern           = regexp.MustCompile(`(?m)^[ \t]*>[ \t]?`)
+)
+
+// Excerpt returns the first paragraph of body as plain text, with its
+// Markdown formatting stripped and cut at a word boundary to at most max
+// characters, an ellipsis marking the cut. It is empty when body has no
+// paragraph of text.
+func Excerpt(body []byte, max int) string {
+	body = codeBlockPattern.ReplaceAll(body, nil)
+	for _, block := range paragraphBreakPattern.Split(string(body), -1) {
+		block = strings.TrimSpace(quotePattern.ReplaceAllString(block, ""))
+		if block == "" || nonProsePattern.MatchString(block) {
+			continue
+		}
+		block = excerptImagePattern.ReplaceAllString(block, " ")
+		block = markupPattern.ReplaceAllString(block, " ")
+		block = excerptLinkPattern.ReplaceAllString(block, "$1$2")
+		block = excerptEmphasisPattern.ReplaceAllStringFunc(block, func(m string) string {
+			return strings.Trim(m, "*_`~")
+		})
+		if text := strings.Join(strings.Fields(block), " "); text != "" {
+			return truncate(text, max)
+		}
+	}
+	return ""
+}
+
+// truncate cuts text at the last word boundary leaving room for an
+// ellipsis within max characters.
+func truncate(text string, max int) string {
+	if utf8.RuneCountInString(text) <= max {
+		return text
+	}
+	runes := []rune(text)
+	cut := string(runes[:max-1])
+	if i := strings.LastIndexAny(cut, " \t"); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,;:.-") + "…"
+}
+
+// DescriptionChange is a post given a description.
+type DescriptionChange struct {
+	Change
+	Description string
+}
+
+// Describe computes writing a description extracted with Excerpt into the
+// front matter of each file in paths lacking one; with force, existing
+// descriptions are replaced too. Files with no paragraph to extract one
+// from are returned apart.
+func (g *Generator) Describe(paths []string, force bool) ([]DescriptionChange, []string, error) {
+	var (
+		changes []DescriptionChange
+		empty   []string
+	)
+	for _, p := range paths {
+		doc, content, err := g.readDocument(p)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !force && hasDescription(doc) {
+			continue
+		}
+		description := Excerpt(doc.Body, DescriptionLength)
+		if description == "" {
+			empty = append(empty, p)
+			continue
+		}
+		raw := frontmatter.String(description)
+		if old, ok := doc.Raw("description"); ok && old == raw {
+			continue
+		}
+		doc.SetRaw("description", raw)
+		changes = append(changes, DescriptionChange{
+			Change:      Change{Path: p, OldContent: content, NewContent: doc.Bytes()},
+			Description: description,
+		})
+	}
+	return changes, empty, nil
+}
+
+// Undescribed returns the files in paths lacking a description.
+func (g *Generator) Undescribed(paths []string) ([]string, error) {
+	var missing []string
+	for _, p := range paths {
+		doc, _, err := g.readDocument(p)
+		if err != nil {
+			return nil, err
+		}
+		if !hasDescription(doc) {
+			missing = append(missing, p)
+		}
+	}
+	return missing, nil
+}
+
+// hasDescription reports whether doc has a non-empty description.
+func hasDescription(doc *frontmatter.Document) bool {
+	raw, _ := doc.Raw("description")
+	raw = strings.TrimSpace(raw)
+	return raw != "" && raw != `""` && raw != "''" && raw != "~" && raw != "null"
+}
@@ -0,0 +1,207 @@
+// Package diff renders line-based unified diffs.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Op is the kind of an edit.
+type Op int
+
+const (
+	Equal Op = iota
+	Delete
+	Insert
+)
+
+// Edit is a single line of an edit script.
+type Edit struct {
+	Op   Op
+	Line string
+}
+
+// Lines splits text into lines, keeping a missing final newline visible
+// as a line of its own so it shows up in diffs.
+func Lines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\n")
+	}
+	if !strings.HasSuffix(text, "\n") {
+		lines[len(lines)-1] += "\n\\ No newline at end of file"
+	}
+	return lines
+}
+
+// Compute returns the shortest edit script turning a into b, using the
+// longest common subsequence of their lines. Common prefix and suffix
+// are trimmed first so that the quadratic part only covers the region
+// that actually changed.
+func Compute(a, b []string) []Edit {
+	var prefix, suffix []Edit
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		prefix = append(prefix, Edit{Equal, a[0]})
+		a, b = a[1:], b[1:]
+	}
+	for len(a) > 0 && len(b) > 0 && a[len(a)-1] == b[len(b)-1] {
+		suffix = append([]Edit{{Equal, a[len(a)-1]}}, suffix...)
+		a, b = a[:len(a)-1], b[:len(b)-1]
+	}
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	edits := prefix
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			edits = append(edits, Edit{Equal, a[i]})
+			i++
+			j++
+		case j == len(b) || i < len(a) && lcs[i+1][j] >= lcs[i][j+1]:
+			edits = append(edits, Edit{Delete, a[i]})
+			i++
+		default:
+			edits = append(edits, Edit{Insert, b[j]})
+			j++
+		}
+	}
+	return append(edits, suffix...)
+}
+
+// Unified returns the unified diff between a and b with context lines
+// around each change, or an empty string when they are equal.
+func Unified(aName, bName, a, b string, context int) string {
+	edits := Compute(Lines(a), Lines(b))
+	var out strings.Builder
+	for _, h := range hunks(edits, context) {
+		if out.Len() == 0 {
+			fmt.Fprintf(&out, "--- %s\n+++ %s\n", aName, bName)
+		}
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", span(h.aStart, h.aLen), span(h.bStart, h.bLen))
+		for _, e := range h.edits {
+			switch e.Op {
+			case Equal:
+				out.WriteString(" ")
+			case Delete:
+				out.WriteString("-")
+			case Insert:
+				out.WriteString("+")
+			}
+			out.WriteString(e.Line)
+			out.WriteString("\n")
+		}
+	}
+	return out.String()
+}
+
+type hunk struct {
+	aStart, aLen, bStart, bLen int
+	edits                      []Edit
+}
+
+// hunks groups edits into hunks, merging changes whose context overlaps.
+func hunks(edits []Edit, context int) []hunk {
+	var (
+		result []hunk
+		cur    *hunk
+		aLine  = 1
+		bLine  = 1
+		// trailing counts the equal lines seen since the last change
+		// in the current hunk.
+		trailing int
+	)
+	for i, e := range edits {
+		if e.Op != Equal {
+			if cur == nil {
+				start := i - context
+				if start < 0 {
+					start = 0
+				}
+				lead := i - start
+				cur = &hunk{aStart: aLine - lead, bStart: bLine - lead}
+				for _, c := range edits[start:i] {
+					cur.edits = append(cur.edits, c)
+					cur.aLen++
+					cur.bLen++
+				}
+			}
+			trailing = 0
+			cur.edits = append(cur.edits, e)
+			if e.Op == Delete {
+				cur.aLen++
+				aLine++
+			} else {
+				cur.bLen++
+				bLine++
+			}
+			continue
+		}
+		if cur != nil {
+			if trailing < context || nextChangeWithin(edits[i:], context-trailing+context) {
+				cur.edits = append(cur.edits, e)
+				cur.aLen++
+				cur.bLen++
+				trailing++
+			} else {
+				result = append(result, trimTrailing(*cur, trailing, context))
+				cur = nil
+			}
+		}
+		aLine++
+		bLine++
+	}
+	if cur != nil {
+		result = append(result, trimTrailing(*cur, trailing, context))
+	}
+	return result
+}
+
+// nextChangeWithin reports whether a change occurs within the first n
+// edits.
+func nextChangeWithin(edits []Edit, n int) bool {
+	for i := 0; i < n && i < len(edits); i++ {
+		if edits[i].Op != Equal {
+			return true
+		}
+	}
+	return false
+}
+
+func trimTrailing(h hunk, trailing, context int) hunk {
+	for ; trailing > context; trailing-- {
+		h.edits = h.edits[:len(h.edits)-1]
+		h.aLen--
+		h.bLen--
+	}
+	return h
+}
+
+func span(start, n int) string {
+	if n == 0 {
+		start--
+	}
+	if n == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d,%d", start, n)
+}
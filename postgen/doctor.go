@@ -0,0 +1,249 @@
+package postgen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen/frontmatter"
+)
+
+// Inconsistency is a disagreement Doctor found between the file name of
+// a post and its front matter date, images folder or image references.
+// Fix tells how the post's Repair resolves it; when it is empty, Unfixed
+// tells why the repair leaves it alone.
+type Inconsistency struct {
+	Problem
+	Fix     string
+	Unfixed string
+}
+
+// FolderMove is the renaming of an images folder.
+type FolderMove struct {
+	Folder    string
+	NewFolder string
+}
+
+// Repair makes a post agree with its file name: the images folders to
+// rename and the change to its date and image references.
+type Repair struct {
+	Folders []FolderMove
+	Post    Change
+}
+
+// DoctorReport is the outcome of Doctor.
+type DoctorReport struct {
+	Inconsistencies []Inconsistency
+	Repairs         []Repair
+}
+
+var fileDatePattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}`)
+
+// Doctor cross-checks the date in the file name of every post with its
+// front matter date, the date of its images folder and the dated folders
+// its images point into, planning the repairs that make the file name
+// the source of truth. A wrongly dated folder is renamed when it is the
+// only candidate, no post references it and the correctly dated one does
+// not exist; references are moved to the correctly dated folder when
+// every image they point at is found there. Folders named after another
+// post are that post's, and drafts, whose file names have no date, are
+// skipped.
+func (g *Generator) Doctor() (DoctorReport, error) {
+	files, err := g.Files()
+	if err != nil {
+		return DoctorReport{}, err
+	}
+	contents := make(map[string][]byte, len(files))
+	owned := make(map[string]bool, len(files))
+	for _, p := range files {
+		if contents[p], err = g.FS.ReadFile(p); err != nil {
+			return DoctorReport{}, fmt.Errorf("reading file %s: %w", p, err)
+		}
+		owned[path.Base(g.ImagesFolder(p))] = true
+	}
+	entries, err := fs.ReadDir(g.FS, g.ImagesDir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return DoctorReport{}, fmt.Errorf("reading folder %s: %w", g.ImagesDir, err)
+	}
+	// folders maps the name of each dated folder after its date to the
+	// folders of that name.
+	folders := make(map[string][]string)
+	onDisk := make(map[string]bool)
+	for _, e := range entries {
+		if _, name, ok := ParseFileName(e.Name()); ok && e.IsDir() {
+			folders[name] = append(folders[name], e.Name())
+			onDisk[e.Name()] = true
+		}
+	}
+	var report DoctorReport
+	claimed := make(map[string]bool)
+	for _, p := range files {
+		fileDate, slug, ok := ParseFileName(path.Base(p))
+		if !ok || g.IsDraft(p) {
+			continue
+		}
+		day := fileDate.Format(FileDateLayout)
+		var found []Inconsistency
+		doc, err := frontmatter.Parse(contents[p])
+		if err != nil {
+			report.Inconsistencies = append(report.Inconsistencies, Inconsistency{
+				Problem: Problem{Path: p, Line: 1, Field: "front matter", Message: err.Error()},
+				Unfixed: "the front matter needs fixing first",
+			})
+			continue
+		}
+		var meta struct {
+			Date frontmatter.Time `yaml:"date"`
+		}
+		if doc.Decode(&meta) == nil && !meta.Date.IsZero() && meta.Date.Format(FileDateLayout) != day {
+			raw, _ := doc.Raw("date")
+			if m := fileDatePattern.FindStringIndex(raw); m != nil {
+				raw = raw[:m[0]] + day + raw[m[1]:]
+				doc.SetRaw("date", raw)
+			}
+			found = append(found, Inconsistency{
+				Problem: Problem{Path: p, Line: g.keyLine(p, "date"), Field: "date", Message: fmt.Sprintf("date %s does not match the file name date %s", meta.Date.Format(FileDateLayout), day)},
+				Fix:     "set the date to " + raw,
+			})
+		}
+		content := doc.Bytes()
+		// refs groups the references into wrongly dated folders by the
+		// name of the folder after its date.
+		refs := make(map[string][]ImageRef)
+		for _, ref := range g.ImageRefs(p, contents[p]) {
+			folder, _, ok := strings.Cut(strings.TrimPrefix(ref.Path, g.ImagesDir+"/"), "/")
+			date, name, dated := ParseFileName(folder)
+			if !ok || !dated || date.Equal(fileDate) || owned[folder] {
+				continue
+			}
+			refs[name] = append(refs[name], ref)
+		}
+		if _, ok := refs[slug]; !ok {
+			for _, folder := range folders[slug] {
+				if !strings.HasPrefix(folder, day) && !owned[folder] {
+					refs[slug] = nil
+				}
+			}
+		}
+		var repair Repair
+		for _, name := range sortedKeys(refs) {
+			target := day + "-" + name
+			var sources []string
+			for _, folder := range folders[name] {
+				if folder != target && !owned[folder] {
+					sources = append(sources, folder)
+				}
+			}
+			// dir is where the images of the correctly dated folder are
+			// found before the repair.
+			dir, unfixed := "", ""
+			switch {
+			case onDisk[target] || claimed[target]:
+				dir = target
+			case len(sources) == 0:
+				unfixed = fmt.Sprintf("no images folder %s exists, nor one of another date", target)
+			case len(sources) > 1:
+				unfixed = fmt.Sprintf("several folders could be %s: %s", target, strings.Join(sources, ", "))
+			case claimed[sources[0]]:
+				unfixed = fmt.Sprintf("%s is renamed for another post", sources[0])
+			default:
+				if others := g.referencing(contents, sources[0], p); len(others) > 0 {
+					unfixed = fmt.Sprintf("%s is also referenced by %s", sources[0], strings.Join(others, ", "))
+				} else {
+					dir = sources[0]
+				}
+			}
+			for _, folder := range sources {
+				in := Inconsistency{Problem: Problem{Path: p, Field: "folder", Message: fmt.Sprintf("images folder %s does not match the file name date %s", path.Join(g.ImagesDir, folder), day)}}
+				switch {
+				case folder == dir:
+					in.Fix = "rename it to " + path.Join(g.ImagesDir, target)
+					repair.Folders = append(repair.Folders, FolderMove{path.Join(g.ImagesDir, folder), path.Join(g.ImagesDir, target)})
+					claimed[folder], claimed[target] = true, true
+				case dir == target:
+					in.Unfixed = path.Join(g.ImagesDir, target) + " already exists"
+				default:
+					in.Unfixed = unfixed
+				}
+				found = append(found, in)
+			}
+			byFolder := make(map[string][]ImageRef)
+			for _, ref := range refs[name] {
+				folder, _, _ := strings.Cut(strings.TrimPrefix(ref.Path, g.ImagesDir+"/"), "/")
+				byFolder[folder] = append(byFolder[folder], ref)
+			}
+			for _, folder := range sortedKeys(byFolder) {
+				missing := unfixed
+				for _, ref := range byFolder[folder] {
+					rest := strings.TrimPrefix(ref.Path, path.Join(g.ImagesDir, folder))
+					if _, err := g.FS.Stat(path.Join(g.ImagesDir, dir) + rest); missing == "" && err != nil {
+						missing = path.Join(g.ImagesDir, target) + rest + " would not exist"
+					}
+				}
+				if missing == "" {
+					content = RewriteImagesFolder(content, g.ImagesURL(), folder, target)
+				}
+				for _, ref := range byFolder[folder] {
+					in := Inconsistency{Problem: Problem{Path: p, Line: ref.Line, Field: "image", Message: fmt.Sprintf("%s points into %s, not dated %s", ref.URL, folder, day)}}
+					if missing == "" {
+						in.Fix = "point it into " + target
+					} else {
+						in.Unfixed = missing
+					}
+					found = append(found, in)
+				}
+			}
+		}
+		report.Inconsistencies = append(report.Inconsistencies, found...)
+		if len(repair.Folders) > 0 || !bytes.Equal(content, contents[p]) {
+			repair.Post = Change{Path: p, OldContent: contents[p], NewContent: content}
+			report.Repairs = append(report.Repairs, repair)
+		}
+	}
+	return report, nil
+}
+
+// referencing returns the files of contents, other than exclude, whose
+// content references the images folder folder.
+func (g *Generator) referencing(contents map[string][]byte, folder, exclude string) []string {
+	re := imagesFolderPattern(g.ImagesURL(), folder)
+	var refs []string
+	for _, p := range sortedKeys(contents) {
+		if p != exclude && re.Match(contents[p]) {
+			refs = append(refs, p)
+		}
+	}
+	return refs
+}
+
+// ApplyRepair carries out r, renaming its folders and then writing the
+// post, moving the folders back when a later step fails.
+func (g *Generator) ApplyRepair(r Repair) error {
+	for _, m := range r.Folders {
+		if _, err := g.FS.Stat(m.NewFolder); err == nil {
+			return conflictf("folder %s already exists", m.NewFolder)
+		}
+	}
+	undo := func(moved []FolderMove) {
+		for i := len(moved) - 1; i >= 0; i-- {
+			g.FS.Rename(moved[i].NewFolder, moved[i].Folder)
+		}
+	}
+	for i, m := range r.Folders {
+		if err := g.FS.Rename(m.Folder, m.NewFolder); err != nil {
+			undo(r.Folders[:i])
+			return fmt.Errorf("renaming folder %s: %w", m.Folder, err)
+		}
+	}
+	if !bytes.Equal(r.Post.OldContent, r.Post.NewContent) {
+		if err := g.WriteChanges([]Change{r.Post}); err != nil {
+			undo(r.Folders)
+			return err
+		}
+	}
+	return nil
+}
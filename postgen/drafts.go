@@ -0,0 +1,78 @@
+package postgen
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"time"
+)
+
+// DraftArchiveDir is the folder, inside DraftsDir, ArchiveDraft moves
+// drafts into. Files lists the drafts of DraftsDir only, so archived
+// ones are left out.
+const DraftArchiveDir = "archive"
+
+// Draft is a draft with what tells how far along and how old it is.
+type Draft struct {
+	Entry
+	Modified time.Time
+	Words    int
+}
+
+// Drafts reads every draft in DraftsDir, least recently modified first.
+// Files whose front matter cannot be parsed are skipped and reported in
+// the returned FileErrors.
+func (g *Generator) Drafts() ([]Draft, []*FileError, error) {
+	files, err := g.Files()
+	if err != nil {
+		return nil, nil, err
+	}
+	var (
+		drafts []Draft
+		bad    []*FileError
+	)
+	for _, p := range files {
+		if !g.IsDraft(p) {
+			continue
+		}
+		doc, _, err := g.readDocument(p)
+		if err != nil {
+			bad = append(bad, &FileError{Path: p, Err: err})
+			continue
+		}
+		var meta Meta
+		if err := doc.Decode(&meta); err != nil {
+			bad = append(bad, &FileError{Path: p, Err: err})
+			continue
+		}
+		info, err := g.FS.Stat(p)
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading file %s: %w", p, err)
+		}
+		drafts = append(drafts, Draft{Entry: newEntry(p, meta), Modified: info.ModTime(), Words: CountWords(doc.Body)})
+	}
+	sort.SliceStable(drafts, func(i, j int) bool { return drafts[i].Modified.Before(drafts[j].Modified) })
+	return drafts, bad, nil
+}
+
+// ArchiveDraft moves the draft at p into DraftArchiveDir, returning its
+// new path. Its images folder, named after its slug, stays where it is.
+func (g *Generator) ArchiveDraft(p string) (string, error) {
+	if !g.IsDraft(p) {
+		return "", fmt.Errorf("%s is not a draft", p)
+	}
+	dir := path.Join(g.DraftsDir, DraftArchiveDir)
+	if err := g.FS.MkdirAll(dir, fs.ModePerm); err != nil {
+		return "", fmt.Errorf("creating folder %s: %w", dir, err)
+	}
+	content, err := g.FS.ReadFile(p)
+	if err != nil {
+		return "", fmt.Errorf("reading file %s: %w", p, err)
+	}
+	archived := path.Join(dir, path.Base(p))
+	if err := g.move(p, archived, content, "", ""); err != nil {
+		return "", err
+	}
+	return archived, nil
+}
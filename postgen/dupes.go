@@ -0,0 +1,206 @@
+package postgen
+
+import (
+	"bytes"
+	"context"
+	"hash/fnv"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// DefaultShingleSize is the number of words of the shingles Dupes
+	// compares unless told otherwise.
+	DefaultShingleSize = 8
+	// DefaultDupePassages is the number of passages Dupes keeps per pair
+	// unless told otherwise.
+	DefaultDupePassages = 3
+)
+
+// dupeWordPattern matches the words Dupes shingles.
+var dupeWordPattern = regexp.MustCompile(`[\p{L}\p{N}\p{M}']+`)
+
+// DupeOptions configures Dupes.
+type DupeOptions struct {
+	// Threshold, from 0 to 1, is the similarity a pair must reach to be
+	// reported.
+	Threshold float64
+	// ShingleSize is the number of words per shingle, DefaultShingleSize
+	// when zero, and Passages the number of passages kept per pair,
+	// DefaultDupePassages when zero.
+	ShingleSize int
+	Passages    int
+	// Code includes code blocks in the text compared.
+	Code bool
+}
+
+// Dupe is a pair of posts whose bodies share a Similarity share of their
+// shingles, with their longest shared passages.
+type Dupe struct {
+	Path, OtherPath string
+	Similarity      float64
+	Passages        []Passage
+}
+
+// Passage is a run of words two posts share, starting at Line of the
+// first one and OtherLine of the second.
+type Passage struct {
+	Line, OtherLine int
+	Words           int
+	Text            string
+}
+
+// shingled is the body of a post cut into shingles.
+type shingled struct {
+	path   string
+	body   []byte
+	first  int
+	words  [][]int
+	hashes []uint64
+	// at is the position of the first shingle of each hash.
+	at map[uint64]int
+}
+
+// Dupes compares the bodies of every post, front matter and, unless
+// Code, code blocks left out, by the Jaccard similarity of their sets of
+// shingles, runs of ShingleSize lowercased words, returning the pairs
+// reaching Threshold, most similar first. The shingles are indexed once,
+// so only the pairs sharing one are compared. Unreadable posts are
+// reported as in List. Interrupted through ctx, it compares the posts
+// read so far.
+func (g *Generator) Dupes(ctx context.Context, opts DupeOptions) ([]Dupe, []*FileError, error) {
+	entries, bad, err := g.List()
+	if err != nil {
+		return nil, nil, err
+	}
+	size := opts.ShingleSize
+	if size == 0 {
+		size = DefaultShingleSize
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = e.Path
+	}
+	docs := make([]shingled, len(entries))
+	errs := make([]error, len(entries))
+	n, interrupted := g.run(ctx, names, func(i int) {
+		doc, content, err := g.readDocument(entries[i].Path)
+		if err != nil {
+			errs[i] = err
+			return
+		}
+		masked := doc.Body
+		if !opts.Code {
+			masked = blank(doc.Body, codeBlockPattern)
+		}
+		d := shingled{
+			path:  entries[i].Path,
+			body:  doc.Body,
+			first: 1 + bytes.Count(content[:len(content)-len(doc.Body)], []byte("\n")),
+			words: dupeWordPattern.FindAllIndex(masked, -1),
+			at:    make(map[uint64]int),
+		}
+		for j := 0; j+size <= len(d.words); j++ {
+			h := fnv.New64a()
+			for _, w := range d.words[j : j+size] {
+				h.Write(bytes.ToLower(d.body[w[0]:w[1]]))
+				h.Write([]byte{' '})
+			}
+			sum := h.Sum64()
+			d.hashes = append(d.hashes, sum)
+			if _, ok := d.at[sum]; !ok {
+				d.at[sum] = j
+			}
+		}
+		docs[i] = d
+	})
+	docs = docs[:n]
+	index := make(map[uint64][]int)
+	for i, d := range docs {
+		if errs[i] != nil {
+			return nil, nil, errs[i]
+		}
+		for j, sum := range d.hashes {
+			if d.at[sum] == j {
+				index[sum] = append(index[sum], i)
+			}
+		}
+	}
+	type pair struct{ a, b int }
+	shared := make(map[pair]int)
+	for _, posting := range index {
+		for x := range posting {
+			for _, b := range posting[x+1:] {
+				shared[pair{posting[x], b}]++
+			}
+		}
+	}
+	var dupes []Dupe
+	for p, n := range shared {
+		a, b := docs[p.a], docs[p.b]
+		similarity := float64(n) / float64(len(a.at)+len(b.at)-n)
+		if similarity < opts.Threshold {
+			continue
+		}
+		dupes = append(dupes, Dupe{
+			Path:       a.path,
+			OtherPath:  b.path,
+			Similarity: similarity,
+			Passages:   passages(a, b, size, opts.Passages),
+		})
+	}
+	sort.Slice(dupes, func(i, j int) bool {
+		x, y := dupes[i], dupes[j]
+		if x.Similarity != y.Similarity {
+			return x.Similarity > y.Similarity
+		}
+		if x.Path != y.Path {
+			return x.Path < y.Path
+		}
+		return x.OtherPath < y.OtherPath
+	})
+	return dupes, bad, interrupted
+}
+
+// passages returns the longest runs of consecutive shingles of a found in
+// b, at most count of them, in the order they appear in a.
+func passages(a, b shingled, size, count int) []Passage {
+	if count == 0 {
+		count = DefaultDupePassages
+	}
+	type run struct{ start, end int }
+	var runs []run
+	for i := 0; i < len(a.hashes); i++ {
+		if _, ok := b.at[a.hashes[i]]; !ok {
+			continue
+		}
+		r := run{i, i}
+		for r.end+1 < len(a.hashes) {
+			if _, ok := b.at[a.hashes[r.end+1]]; !ok {
+				break
+			}
+			r.end++
+		}
+		runs = append(runs, r)
+		i = r.end
+	}
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].end-runs[i].start > runs[j].end-runs[j].start })
+	if len(runs) > count {
+		runs = runs[:count]
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].start < runs[j].start })
+	out := make([]Passage, len(runs))
+	for i, r := range runs {
+		start, end := a.words[r.start][0], a.words[r.end+size-1][1]
+		other := b.words[b.at[a.hashes[r.start]]][0]
+		out[i] = Passage{
+			Line:      a.first + bytes.Count(a.body[:start], []byte("\n")),
+			OtherLine: b.first + bytes.Count(b.body[:other], []byte("\n")),
+			Words:     r.end - r.start + size,
+			Text:      strings.Join(strings.Fields(string(a.body[start:end])), " "),
+		}
+	}
+	return out
+}
@@ -0,0 +1,80 @@
+package postgen
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrConflict is matched, with errors.Is, by the errors of operations
+	// refused because they would overwrite or collide with existing files,
+	// folders or redirects.
+	ErrConflict = errors.New("conflict")
+	// ErrPostExists is matched by the conflicts with an existing post
+	// file, which match ErrConflict too.
+	ErrPostExists = &kindError{ErrConflict, errors.New("post already exists")}
+	// ErrInvalidTitle is matched by the errors of titles no slug can be
+	// generated from.
+	ErrInvalidTitle = errors.New("invalid title")
+	// ErrTemplate is matched by the errors of front matter templates and
+	// archetypes failing to parse or execute.
+	ErrTemplate = errors.New("template failure")
+	// ErrRootNotFound is matched by the errors of a site root or posts
+	// folder that cannot be found.
+	ErrRootNotFound = errors.New("site root not found")
+	// ErrIO is matched by the errors the FS returned.
+	ErrIO = errors.New("i/o failure")
+	// ErrInterrupted is matched by the errors of site-wide operations
+	// canceled before working through every file, which then return
+	// their results for the files done.
+	ErrInterrupted = errors.New("interrupted")
+)
+
+// kindError marks err as being of kind, one of the sentinel errors,
+// without changing its message.
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() error {
+	return e.err
+}
+
+func (e *kindError) Is(target error) bool {
+	return e.kind == target || errors.Is(e.kind, target)
+}
+
+// Mark returns err marked as matching kind, one of the sentinel errors,
+// with errors.Is, its message unchanged, or nil.
+func Mark(kind, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &kindError{kind, err}
+}
+
+// conflictf returns an error matching ErrConflict.
+func conflictf(format string, args ...interface{}) error {
+	return &kindError{ErrConflict, fmt.Errorf(format, args...)}
+}
+
+// existsf returns an error matching ErrPostExists and ErrConflict.
+func existsf(format string, args ...interface{}) error {
+	return &kindError{ErrPostExists, fmt.Errorf(format, args...)}
+}
+
+// SlugError returns the error, matching ErrInvalidTitle, of a title no
+// slug can be generated from.
+func SlugError(title string) error {
+	return &kindError{ErrInvalidTitle, fmt.Errorf("could not generate a slug from \"%s\"", title)}
+}
+
+// ioError returns err marked as matching ErrIO, or nil.
+func ioError(err error) error {
+	return Mark(ErrIO, err)
+}
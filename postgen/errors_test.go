@@ -0,0 +1,61 @@
+package postgen
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"testing"
+)
+
+func TestErrorKinds(t *testing.T) {
+	exists := newMemFS(map[string]string{"docs/_posts/2024-05-01-hello.markdown": "existing"})
+	_, existsErr := testGenerator(exists).Generate(helloPost())
+	_, titleErr := testGenerator(newMemFS(nil)).PlanRename("docs/_posts/2024-05-01-hello.markdown", "🚀", "", "")
+	_, templateErr := ParseTemplate("broken", "{{ .Title ")
+	tests := []struct {
+		name string
+		err  error
+		is   []error
+		not  []error
+	}{
+		{"existing post", existsErr, []error{ErrPostExists, ErrConflict}, []error{ErrIO, ErrInvalidTitle}},
+		{"existsf", existsf("file %s already exists", "x"), []error{ErrPostExists, ErrConflict}, []error{ErrTemplate}},
+		{"conflictf", conflictf("folder %s already exists", "x"), []error{ErrConflict}, []error{ErrPostExists}},
+		{"slug", titleErr, []error{ErrInvalidTitle}, []error{ErrConflict}},
+		{"SlugError", SlugError("🚀"), []error{ErrInvalidTitle}, []error{ErrConflict}},
+		{"template", templateErr, []error{ErrTemplate}, []error{ErrIO}},
+		{"root", Mark(ErrRootNotFound, errors.New("no root")), []error{ErrRootNotFound}, []error{ErrIO}},
+		{"wrapped", fmt.Errorf("creating post: %w", existsf("x")), []error{ErrPostExists, ErrConflict}, nil},
+		{"io", ioError(&fs.PathError{Op: "open", Path: "x", Err: fs.ErrPermission}), []error{ErrIO, fs.ErrPermission}, []error{ErrConflict}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.err == nil {
+				t.Fatal("no error")
+			}
+			for _, target := range tt.is {
+				if !errors.Is(tt.err, target) {
+					t.Errorf("%v does not match %v", tt.err, target)
+				}
+			}
+			for _, target := range tt.not {
+				if errors.Is(tt.err, target) {
+					t.Errorf("%v matches %v", tt.err, target)
+				}
+			}
+		})
+	}
+}
+
+func TestMarkKeepsMessage(t *testing.T) {
+	err := errors.New("posts folder docs/_posts does not exist")
+	if got := Mark(ErrRootNotFound, err).Error(); got != err.Error() {
+		t.Errorf("message = %q, want %q", got, err.Error())
+	}
+	if got := SlugError("🚀").Error(); got != `could not generate a slug from "🚀"` {
+		t.Errorf("message = %q", got)
+	}
+	if Mark(ErrIO, nil) != nil {
+		t.Error("Mark(ErrIO, nil) is not nil")
+	}
+}
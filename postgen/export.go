@@ -0,0 +1,225 @@
+package postgen
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen/frontmatter"
+)
+
+// DevToTags is the number of tags dev.to accepts on an article.
+const DevToTags = 4
+
+// DevToOptions configures ExportDevTo.
+type DevToOptions struct {
+	// SiteURL is the absolute URL the site is served at, its baseurl
+	// included, such as https://tiagomelo.github.io.
+	SiteURL string
+	// Permalink is the site's permalink setting, as passed to Permalink.
+	Permalink string
+}
+
+// Export is a post converted for another platform.
+type Export struct {
+	Content []byte
+	// Warnings describes what did not translate.
+	Warnings []string
+}
+
+var (
+	highlightBlockPattern = regexp.MustCompile(`(?s)\{%-?\s*highlight\s+([^\s%]+)[^%]*?-?%\}\n?(.*?)\n?\{%-?\s*endhighlight\s*-?%\}`)
+	// verbatimPattern matches what Liquid and the export leave alone:
+	// fenced code blocks and raw blocks, which dev.to supports too.
+	verbatimPattern = regexp.MustCompile("(?ms)^[ \t]*(?:```.*?^[ \t]*```|~~~.*?^[ \t]*~~~)[ \t]*$" +
+		`|\{%-?\s*raw\s*-?%\}.*?\{%-?\s*endraw\s*-?%\}`)
+	liquidTagPattern    = regexp.MustCompile(`\{%-?\s*(\w+)(.*?)-?%\}`)
+	liquidOutputPattern = regexp.MustCompile(`\{\{-?\s*(.*?)\s*-?\}\}`)
+	footnotePattern     = regexp.MustCompile(`\[\^[^\]\s]+\]`)
+	footnoteDefPattern  = regexp.MustCompile(`(?m)^[ \t]*\[\^[^\]\s]+\]:.*$`)
+	imageURLPattern     = regexp.MustCompile(`!?\[[^\]]*\]\(\s*<?([^)\s>]+)`)
+	urlAttrPattern      = regexp.MustCompile(`(?i)\b(?:src|href)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+)
+
+// ExportDevTo converts the post at p to a dev.to article: its front
+// matter is replaced by dev.to's, with the post's own canonical_url, or
+// else its permalink, as the canonical_url and at most DevToTags tags, relative links and images
+// are made absolute, highlight blocks become fenced code blocks and
+// post_url tags links to the posts. The other Liquid tags and outputs,
+// except raw blocks, are removed with a warning; footnotes, which dev.to
+// does not render, are kept with one.
+func (g *Generator) ExportDevTo(p string, opts DevToOptions) (Export, error) {
+	site, err := url.Parse(strings.TrimSuffix(opts.SiteURL, "/"))
+	if err != nil || !site.IsAbs() {
+		return Export{}, fmt.Errorf("invalid site URL %s: expected an absolute URL", opts.SiteURL)
+	}
+	e, err := g.Read(p)
+	if err != nil {
+		return Export{}, fmt.Errorf("parsing %s: %w", p, err)
+	}
+	doc, _, err := g.readDocument(p)
+	if err != nil {
+		return Export{}, err
+	}
+	var extra struct {
+		Description string      `yaml:"description"`
+		Image       interface{} `yaml:"image"`
+	}
+	if err := doc.Decode(&extra); err != nil {
+		return Export{}, fmt.Errorf("parsing %s: %w", p, err)
+	}
+	entries, _, err := g.List()
+	if err != nil {
+		return Export{}, err
+	}
+	posts := make(map[string]string)
+	for _, other := range entries {
+		posts[TrimExt(strings.TrimPrefix(other.Path, g.PostsDir+"/"))] = site.String() + Permalink(opts.Permalink, other)
+	}
+	postURL, _ := url.Parse(site.String() + Permalink(opts.Permalink, e))
+
+	var warnings []string
+	warn := func(format string, args ...interface{}) {
+		if w := fmt.Sprintf(format, args...); !contains(warnings, w) {
+			warnings = append(warnings, w)
+		}
+	}
+	absolute := func(raw string) string {
+		u, err := url.Parse(raw)
+		if err != nil || u.IsAbs() || strings.HasPrefix(raw, "#") || strings.HasPrefix(raw, "//") || strings.HasPrefix(raw, "{") {
+			return raw
+		}
+		return postURL.ResolveReference(u).String()
+	}
+
+	body := highlightBlockPattern.ReplaceAllFunc(doc.Body, func(m []byte) []byte {
+		sub := highlightBlockPattern.FindSubmatch(m)
+		return []byte(codeBlock(string(sub[2]), string(sub[1])))
+	})
+	var out bytes.Buffer
+	last := 0
+	text := func(t []byte) {
+		t = postURLPattern.ReplaceAllFunc(t, func(m []byte) []byte {
+			sub := postURLPattern.FindSubmatch(m)
+			target, ok := posts[string(sub[1])]
+			if !ok {
+				warn("no post named %s, its post_url tag was removed", sub[1])
+			}
+			return []byte(target + string(sub[2]))
+		})
+		t = liquidOutputPattern.ReplaceAllFunc(t, func(m []byte) []byte {
+			switch expr := string(liquidOutputPattern.FindSubmatch(m)[1]); expr {
+			case "site.baseurl":
+				return []byte(site.Path)
+			case "site.url":
+				return []byte(site.Scheme + "://" + site.Host)
+			default:
+				warn("Liquid output {{ %s }} does not translate and was removed", expr)
+				return nil
+			}
+		})
+		t = liquidTagPattern.ReplaceAllFunc(t, func(m []byte) []byte {
+			sub := liquidTagPattern.FindSubmatch(m)
+			if tag := string(sub[1]); tag == "include" {
+				warn("Liquid include%s does not translate and was removed", strings.TrimRight(string(sub[2]), " -"))
+			} else {
+				warn("Liquid tag %s does not translate and was removed", tag)
+			}
+			return nil
+		})
+		t = rewriteURLs(t, absolute)
+		if footnotePattern.Match(blank(t, codeSpanPattern)) {
+			warn("footnotes do not render on dev.to")
+		}
+		out.Write(t)
+	}
+	for _, m := range verbatimPattern.FindAllIndex(body, -1) {
+		text(body[last:m[0]])
+		out.Write(body[m[0]:m[1]])
+		last = m[1]
+	}
+	text(body[last:])
+
+	var tags []string
+	for _, t := range append(append([]string{}, e.Meta.Tags...), e.Meta.Categories...) {
+		if t = devToTag(t); t != "" && !contains(tags, t) {
+			tags = append(tags, t)
+		}
+	}
+	if len(tags) > DevToTags {
+		warn("dev.to takes %d tags, dropped %s", DevToTags, strings.Join(tags[DevToTags:], ", "))
+		tags = tags[:DevToTags]
+	}
+	published := !g.IsDraft(p) && (e.Meta.Published == nil || *e.Meta.Published)
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "---\ntitle: %s\npublished: %t\n", frontmatter.String(e.Meta.Title), published)
+	if extra.Description != "" {
+		fmt.Fprintf(&b, "description: %s\n", frontmatter.String(extra.Description))
+	}
+	if len(tags) > 0 {
+		fmt.Fprintf(&b, "tags: %s\n", strings.Join(tags, ", "))
+	}
+	canonical := postURL.String()
+	if e.Meta.CanonicalURL != "" {
+		canonical = e.Meta.CanonicalURL
+	}
+	fmt.Fprintf(&b, "canonical_url: %s\n", canonical)
+	image, _ := extra.Image.(string)
+	if m, ok := extra.Image.(map[string]interface{}); ok {
+		image, _ = m["path"].(string)
+	}
+	if image != "" {
+		fmt.Fprintf(&b, "cover_image: %s\n", absolute(image))
+	}
+	b.WriteString("---\n")
+	b.Write(out.Bytes())
+	return Export{Content: b.Bytes(), Warnings: warnings}, nil
+}
+
+// rewriteURLs replaces the URLs of the links, images, reference
+// definitions and HTML src and href attributes of text, outside code
+// spans and footnotes, with what replace returns for them.
+func rewriteURLs(text []byte, replace func(string) string) []byte {
+	masked := blank(blank(text, codeSpanPattern), footnoteDefPattern)
+	type span struct{ start, end int }
+	var spans []span
+	for _, pattern := range []*regexp.Regexp{imageURLPattern, referencePattern, urlAttrPattern} {
+		for _, m := range pattern.FindAllSubmatchIndex(masked, -1) {
+			for i := 2; i < len(m); i += 2 {
+				if m[i] >= 0 {
+					spans = append(spans, span{m[i], m[i+1]})
+				}
+			}
+		}
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	var b bytes.Buffer
+	last := 0
+	for _, s := range spans {
+		if s.start < last {
+			continue
+		}
+		b.Write(text[last:s.start])
+		b.WriteString(replace(string(text[s.start:s.end])))
+		last = s.end
+	}
+	b.Write(text[last:])
+	return b.Bytes()
+}
+
+// devToTag turns a tag into one dev.to accepts: lowercase letters and
+// digits only.
+func devToTag(tag string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(tag) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,291 @@
+package postgen
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultIgnore lists the hosts never checked by CheckExternal: the local
+// machine and the domains reserved for examples.
+var DefaultIgnore = []string{"localhost", "127.0.0.1", "0.0.0.0", "example.com", "example.org", "example.net"}
+
+// ExternalOptions configures CheckExternal.
+type ExternalOptions struct {
+	// Workers is the number of URLs checked at once.
+	Workers int
+	// Timeout bounds each request.
+	Timeout time.Duration
+	// Retries is the number of times a URL is retried after a network
+	// error, a 429 or a 5xx response.
+	Retries int
+	// Ignore lists the domains, along with their subdomains, not checked.
+	Ignore []string
+	// SiteURL is the site's url; links to it are internal and left to
+	// CheckLinks.
+	SiteURL string
+	// Cache holds the results of earlier runs; fresh ones are reused and
+	// the ones obtained are added to it. It may be nil.
+	Cache *LinkCache
+	// Client sends the requests; one honouring Timeout and not following
+	// redirects is used when it is nil.
+	Client *http.Client
+}
+
+// URLStatus is the outcome of checking a URL.
+type URLStatus struct {
+	// Status is the HTTP status code, zero when no response was received.
+	Status  int       `json:"status"`
+	Err     string    `json:"error,omitempty"`
+	Checked time.Time `json:"checked"`
+}
+
+// Alive reports whether the URL answered with a 2xx or 3xx status.
+func (s URLStatus) Alive() bool {
+	return s.Err == "" && s.Status >= 200 && s.Status < 400
+}
+
+// transient reports whether checking the URL again may succeed.
+func (s URLStatus) transient() bool {
+	return s.Err != "" || s.Status == http.StatusTooManyRequests || s.Status >= 500
+}
+
+func (s URLStatus) String() string {
+	if s.Err != "" {
+		return s.Err
+	}
+	return strings.TrimSpace(fmt.Sprintf("%d %s", s.Status, http.StatusText(s.Status)))
+}
+
+// LinkCache holds URL statuses by URL, each valid for TTL.
+type LinkCache struct {
+	TTL     time.Duration
+	Entries map[string]URLStatus
+	mu      sync.Mutex
+}
+
+func (c *LinkCache) get(u string, now time.Time) (URLStatus, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.Entries[u]
+	if !ok || now.Sub(s.Checked) >= c.TTL {
+		return URLStatus{}, false
+	}
+	return s, true
+}
+
+func (c *LinkCache) put(u string, s URLStatus) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Entries == nil {
+		c.Entries = make(map[string]URLStatus)
+	}
+	c.Entries[u] = s
+}
+
+// ReadLinkCache reads the cache file p, returning an empty cache when it
+// does not exist.
+func (g *Generator) ReadLinkCache(p string, ttl time.Duration) (*LinkCache, error) {
+	c := &LinkCache{TTL: ttl, Entries: make(map[string]URLStatus)}
+	b, err := g.FS.ReadFile(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading file %s: %w", p, err)
+	}
+	if err := json.Unmarshal(b, &c.Entries); err != nil {
+		return nil, fmt.Errorf("parsing link cache %s: %w", p, err)
+	}
+	return c, nil
+}
+
+// WriteLinkCache writes c to the cache file p, dropping the expired
+// entries.
+func (g *Generator) WriteLinkCache(p string, c *LinkCache) error {
+	now := g.Now()
+	fresh := make(map[string]URLStatus)
+	for u, s := range c.Entries {
+		if now.Sub(s.Checked) < c.TTL {
+			fresh[u] = s
+		}
+	}
+	b, err := json.MarshalIndent(fresh, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding link cache: %w", err)
+	}
+	return g.writeFile(p, append(b, '\n'), os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+}
+
+// ExternalLinks returns the http and https links of every post and draft,
+// leaving out the ones to siteURL and to ignored domains.
+func (g *Generator) ExternalLinks(siteURL string, ignore []string) ([]Link, error) {
+	var site string
+	if siteURL != "" {
+		u, err := url.Parse(siteURL)
+		if err != nil {
+			return nil, fmt.Errorf("parsing site url %s: %w", siteURL, err)
+		}
+		site = u.Host
+	}
+	files, err := g.Files()
+	if err != nil {
+		return nil, err
+	}
+	var links []Link
+	for _, p := range files {
+		content, err := g.FS.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("reading file %s: %w", p, err)
+		}
+		for _, link := range Links(p, content) {
+			u, err := url.Parse(link.URL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || strings.Contains(link.URL, "{{") {
+				continue
+			}
+			if strings.EqualFold(u.Host, site) || ignored(u.Hostname(), ignore) {
+				continue
+			}
+			links = append(links, link)
+		}
+	}
+	return links, nil
+}
+
+// ignored reports whether host is one of domains or a subdomain of one.
+func ignored(host string, domains []string) bool {
+	host = strings.ToLower(host)
+	for _, d := range domains {
+		d = strings.ToLower(strings.TrimPrefix(d, "."))
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckExternal checks the external links of every post and draft with a
+// pool of opts.Workers workers, returning the ones whose URL is not alive.
+// Each URL is requested once however many posts link to it. Interrupted
+// through ctx, it returns the broken links among the URLs checked so far.
+func (g *Generator) CheckExternal(ctx context.Context, opts ExternalOptions) ([]BrokenLink, error) {
+	links, err := g.ExternalLinks(opts.SiteURL, append(append([]string{}, DefaultIgnore...), opts.Ignore...))
+	if err != nil {
+		return nil, err
+	}
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{
+			Timeout: opts.Timeout,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+	}
+	var urls []string
+	statuses := make(map[string]URLStatus)
+	for _, link := range links {
+		u := withoutFragment(link.URL)
+		if _, ok := statuses[u]; ok {
+			continue
+		}
+		statuses[u] = URLStatus{}
+		if opts.Cache != nil {
+			if s, ok := opts.Cache.get(u, g.Now()); ok {
+				statuses[u] = s
+				continue
+			}
+		}
+		urls = append(urls, u)
+	}
+
+	workers := opts.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	var mu sync.Mutex
+	// Requests in flight when ctx is done are finished, their timeout
+	// bounding the wait.
+	requests := context.WithoutCancel(ctx)
+	n, interrupted := g.runJobs(ctx, workers, urls, func(i int) {
+		s := checkURL(requests, client, urls[i], opts.Retries)
+		s.Checked = g.Now()
+		mu.Lock()
+		statuses[urls[i]] = s
+		mu.Unlock()
+		// Transient failures are tried again next time.
+		if opts.Cache != nil && !s.transient() {
+			opts.Cache.put(urls[i], s)
+		}
+	})
+	unchecked := make(map[string]bool, len(urls)-n)
+	for _, u := range urls[n:] {
+		unchecked[u] = true
+	}
+
+	var broken []BrokenLink
+	for _, link := range links {
+		u := withoutFragment(link.URL)
+		if s := statuses[u]; !unchecked[u] && !s.Alive() {
+			broken = append(broken, BrokenLink{link, s.String()})
+		}
+	}
+	sort.SliceStable(broken, func(i, j int) bool { return broken[i].Post < broken[j].Post })
+	return broken, interrupted
+}
+
+func withoutFragment(raw string) string {
+	u, _, _ := strings.Cut(raw, "#")
+	return u
+}
+
+// checkURL requests u, with GET when the server refuses HEAD, retrying
+// transient failures with a growing pause.
+func checkURL(ctx context.Context, client *http.Client, u string, retries int) URLStatus {
+	var s URLStatus
+	for attempt := 0; ; attempt++ {
+		s = request(ctx, client, http.MethodHead, u)
+		switch s.Status {
+		case http.StatusMethodNotAllowed, http.StatusForbidden, http.StatusNotImplemented:
+			s = request(ctx, client, http.MethodGet, u)
+		}
+		if !s.transient() || attempt >= retries {
+			return s
+		}
+		select {
+		case <-time.After(time.Duration(attempt+1) * time.Second):
+		case <-ctx.Done():
+			return s
+		}
+	}
+}
+
+func request(ctx context.Context, client *http.Client, method, u string) URLStatus {
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return URLStatus{Err: "invalid URL"}
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; postgen link checker)")
+	resp, err := client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			if urlErr.Timeout() {
+				return URLStatus{Err: "timeout"}
+			}
+			err = urlErr.Err
+		}
+		return URLStatus{Err: err.Error()}
+	}
+	resp.Body.Close()
+	return URLStatus{Status: resp.StatusCode}
+}
@@ -0,0 +1,129 @@
+package postgen
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// DefaultFeaturedMax is the number of posts PlanFeature keeps featured
+// unless told otherwise.
+const DefaultFeaturedMax = 5
+
+// FeaturedPlan is the outcome of PlanFeature and PlanUnfeature: the
+// featured posts in order, the ones no longer featured and the changes
+// writing their featured and featured_order keys.
+type FeaturedPlan struct {
+	Featured  []Entry
+	Displaced []Entry
+	Changes   []Change
+}
+
+// Featured returns the posts with featured: true, drafts left out, by
+// featured_order, then newest first for the ones without one. Unreadable
+// posts are reported as in List.
+func (g *Generator) Featured() ([]Entry, []*FileError, error) {
+	entries, bad, err := g.List()
+	if err != nil {
+		return nil, nil, err
+	}
+	var featured []Entry
+	for _, e := range entries {
+		if e.Meta.Featured {
+			featured = append(featured, e)
+		}
+	}
+	sort.SliceStable(featured, func(i, j int) bool {
+		a, b := featured[i].Meta.FeaturedOrder, featured[j].Meta.FeaturedOrder
+		return a > 0 && (b <= 0 || a < b)
+	})
+	return featured, bad, nil
+}
+
+// PlanFeature computes featuring the post at p at position, from 1, or
+// last when position is zero or past the end, moving it when it is
+// featured already. The posts past max, DefaultFeaturedMax when zero,
+// are no longer featured, the last ones first; the post itself is put at
+// max at the latest, displacing the lowest-priority of the others.
+func (g *Generator) PlanFeature(p string, position, max int) (FeaturedPlan, error) {
+	if g.IsDraft(p) {
+		return FeaturedPlan{}, fmt.Errorf("%s is a draft, publish it before featuring it", p)
+	}
+	if max == 0 {
+		max = DefaultFeaturedMax
+	}
+	featured, _, err := g.Featured()
+	if err != nil {
+		return FeaturedPlan{}, err
+	}
+	e, err := g.Read(p)
+	if err != nil {
+		return FeaturedPlan{}, fmt.Errorf("parsing %s: %w", p, err)
+	}
+	featured = withoutEntry(featured, p)
+	if position <= 0 || position > len(featured) {
+		position = len(featured) + 1
+	}
+	if position > max {
+		position = max
+	}
+	featured = append(featured[:position-1], append([]Entry{e}, featured[position-1:]...)...)
+	var displaced []Entry
+	if len(featured) > max {
+		featured, displaced = featured[:max], featured[max:]
+	}
+	return g.planFeatured(featured, displaced)
+}
+
+// PlanUnfeature computes no longer featuring the post at p, moving the
+// ones after it up.
+func (g *Generator) PlanUnfeature(p string) (FeaturedPlan, error) {
+	featured, _, err := g.Featured()
+	if err != nil {
+		return FeaturedPlan{}, err
+	}
+	rest := withoutEntry(featured, p)
+	if len(rest) == len(featured) {
+		return FeaturedPlan{}, fmt.Errorf("%s is not featured", p)
+	}
+	e, err := g.Read(p)
+	if err != nil {
+		return FeaturedPlan{}, fmt.Errorf("parsing %s: %w", p, err)
+	}
+	return g.planFeatured(rest, []Entry{e})
+}
+
+// planFeatured plans numbering featured from 1 and removing the keys of
+// displaced, leaving out the files that would not change.
+func (g *Generator) planFeatured(featured, displaced []Entry) (FeaturedPlan, error) {
+	plan := FeaturedPlan{Featured: featured, Displaced: displaced}
+	for i, e := range append(append([]Entry{}, featured...), displaced...) {
+		doc, content, err := g.readDocument(e.Path)
+		if err != nil {
+			return FeaturedPlan{}, err
+		}
+		if i < len(featured) {
+			doc.SetRaw("featured", "true")
+			doc.SetRaw("featured_order", strconv.Itoa(i+1))
+		} else {
+			doc.Delete("featured")
+			doc.Delete("featured_order")
+		}
+		if updated := doc.Bytes(); !bytes.Equal(updated, content) {
+			plan.Changes = append(plan.Changes, Change{Path: e.Path, OldContent: content, NewContent: updated})
+		}
+	}
+	return plan, nil
+}
+
+// withoutEntry returns entries without the one at p.
+func withoutEntry(entries []Entry, p string) []Entry {
+	var rest []Entry
+	for _, e := range entries {
+		if e.Path != p {
+			rest = append(rest, e)
+		}
+	}
+	return rest
+}
@@ -0,0 +1,68 @@
+package postgen
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// featuredFS returns a memFS with the posts one to n featured in order
+// and the post new, not featured.
+func featuredFS(n int) *memFS {
+	files := map[string]string{
+		"docs/_posts/2024-05-01-new.markdown": "---\nlayout: post\ntitle: New\n---\n",
+	}
+	for i := 1; i <= n; i++ {
+		files[fmt.Sprintf("docs/_posts/2024-04-%02d-post-%d.markdown", i, i)] = fmt.Sprintf("---\nlayout: post\ntitle: Post %d\nfeatured: true\nfeatured_order: %d\n---\n", i, i)
+	}
+	return siteFS(files)
+}
+
+func slugs(entries []Entry) string {
+	var s []string
+	for _, e := range entries {
+		s = append(s, e.Slug)
+	}
+	return strings.Join(s, " ")
+}
+
+func TestPlanFeatureAtTheCap(t *testing.T) {
+	tests := []struct {
+		position  int
+		featured  string
+		displaced string
+	}{
+		{0, "post-1 post-2 post-3 post-4 new", "post-5"},
+		{9, "post-1 post-2 post-3 post-4 new", "post-5"},
+		{2, "post-1 new post-2 post-3 post-4", "post-5"},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.position), func(t *testing.T) {
+			g := testGenerator(featuredFS(DefaultFeaturedMax))
+			plan, err := g.PlanFeature("docs/_posts/2024-05-01-new.markdown", tt.position, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := slugs(plan.Featured); got != tt.featured {
+				t.Errorf("featured = %s, want %s", got, tt.featured)
+			}
+			if got := slugs(plan.Displaced); got != tt.displaced {
+				t.Errorf("displaced = %s, want %s", got, tt.displaced)
+			}
+		})
+	}
+}
+
+func TestPlanFeatureBelowTheCap(t *testing.T) {
+	g := testGenerator(featuredFS(2))
+	plan, err := g.PlanFeature("docs/_posts/2024-05-01-new.markdown", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := slugs(plan.Featured), "post-1 post-2 new"; got != want {
+		t.Errorf("featured = %s, want %s", got, want)
+	}
+	if len(plan.Displaced) > 0 || len(plan.Changes) != 1 {
+		t.Errorf("displaced %s with %d change(s), want only new changed", slugs(plan.Displaced), len(plan.Changes))
+	}
+}
@@ -0,0 +1,108 @@
+package postgen
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"time"
+)
+
+// Exts are the file extensions, without the leading dot, recognized as
+// posts and drafts whatever the generator's Ext.
+var Exts = []string{"markdown", "md"}
+
+// IsPostFile reports whether name has one of the Exts.
+func IsPostFile(name string) bool {
+	ext := strings.TrimPrefix(path.Ext(name), ".")
+	for _, e := range Exts {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// TrimExt removes any of the Exts from name.
+func TrimExt(name string) string {
+	if IsPostFile(name) {
+		return strings.TrimSuffix(name, path.Ext(name))
+	}
+	return name
+}
+
+// ParseFileName splits a post file name such as
+// 2024-05-02-my-post.markdown into its date and slug.
+func ParseFileName(name string) (time.Time, string, bool) {
+	name = strings.TrimSuffix(name, path.Ext(name))
+	if len(name) < len(FileDateLayout)+2 || name[len(FileDateLayout)] != '-' {
+		return time.Time{}, "", false
+	}
+	date, err := time.Parse(FileDateLayout, name[:len(FileDateLayout)])
+	if err != nil {
+		return time.Time{}, "", false
+	}
+	return date, name[len(FileDateLayout)+1:], true
+}
+
+// Find returns the path of the post or draft whose slug or file name is
+// slug, which may also be given as a path with either separator. It
+// fails when nothing matches or when several posts share the slug.
+func (g *Generator) Find(slug string) (string, error) {
+	slug = TrimExt(path.Base(strings.ReplaceAll(slug, "\\", "/")))
+	var matches []string
+	if entries, err := fs.ReadDir(g.FS, g.PostsDir); err == nil {
+		for _, e := range entries {
+			if e.IsDir() || !IsPostFile(e.Name()) {
+				continue
+			}
+			name := TrimExt(e.Name())
+			if _, s, ok := ParseFileName(e.Name()); name == slug || ok && s == slug {
+				matches = append(matches, path.Join(g.PostsDir, e.Name()))
+			}
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("reading folder %s: %w", g.PostsDir, err)
+	}
+	for _, ext := range Exts {
+		draft := path.Join(g.DraftsDir, slug+"."+ext)
+		if _, err := g.FS.Stat(draft); err == nil {
+			matches = append(matches, draft)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no post or draft named %s", slug)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("%s matches several files: %s", slug, strings.Join(matches, ", "))
+	}
+}
+
+// Files returns the post files in PostsDir and DraftsDir, posts
+// first, each group sorted by name. Missing folders are skipped.
+func (g *Generator) Files() ([]string, error) {
+	var files []string
+	for _, dir := range []string{g.PostsDir, g.DraftsDir} {
+		entries, err := fs.ReadDir(g.FS, dir)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading folder %s: %w", dir, err)
+		}
+		for _, e := range entries {
+			if !e.IsDir() && IsPostFile(e.Name()) {
+				files = append(files, path.Join(dir, e.Name()))
+			}
+		}
+	}
+	return files, nil
+}
+
+// IsDraft reports whether p lives in DraftsDir.
+func (g *Generator) IsDraft(p string) bool {
+	return path.Dir(p) == g.DraftsDir
+}
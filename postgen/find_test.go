@@ -0,0 +1,64 @@
+package postgen
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFind(t *testing.T) {
+	m := newMemFS(map[string]string{
+		"docs/_posts/2024-05-01-hello.markdown": "",
+		"docs/_posts/2024-05-02-twice.markdown": "",
+		"docs/_posts/2024-06-02-twice.md":       "",
+		"docs/_drafts/draft.md":                 "",
+	})
+	tests := []struct {
+		arg  string
+		want string
+	}{
+		{"hello", "docs/_posts/2024-05-01-hello.markdown"},
+		{"2024-05-01-hello", "docs/_posts/2024-05-01-hello.markdown"},
+		{"2024-05-01-hello.markdown", "docs/_posts/2024-05-01-hello.markdown"},
+		{"docs/_posts/2024-05-01-hello.markdown", "docs/_posts/2024-05-01-hello.markdown"},
+		{`docs\_posts\2024-05-01-hello.markdown`, "docs/_posts/2024-05-01-hello.markdown"},
+		{filepath.Join("docs", "_posts", "2024-05-01-hello.markdown"), "docs/_posts/2024-05-01-hello.markdown"},
+		{"draft", "docs/_drafts/draft.md"},
+		{filepath.Join("docs", "_drafts", "draft.md"), "docs/_drafts/draft.md"},
+		{"2024-06-02-twice", "docs/_posts/2024-06-02-twice.md"},
+	}
+	g := testGenerator(m)
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			got, err := g.Find(tt.arg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Find(%q) = %q, want %q", tt.arg, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindFails(t *testing.T) {
+	m := newMemFS(map[string]string{
+		"docs/_posts/2024-05-02-twice.markdown": "",
+		"docs/_posts/2024-06-02-twice.md":       "",
+	})
+	tests := []struct {
+		arg  string
+		want string
+	}{
+		{"missing", "no post or draft named missing"},
+		{"twice", "twice matches several files: docs/_posts/2024-05-02-twice.markdown, docs/_posts/2024-06-02-twice.md"},
+	}
+	g := testGenerator(m)
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			if _, err := g.Find(tt.arg); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Find(%q) = %v, want %q", tt.arg, err, tt.want)
+			}
+		})
+	}
+}
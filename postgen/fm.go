@@ -0,0 +1,71 @@
+package postgen
+
+import (
+	"fmt"
+	"github.com/tiagomelo/tiagomelo.github.io/postgen/frontmatter"
+)
+
+// KeyValue is the value of a front matter key in one file.
+type KeyValue struct {
+	Path string
+	// Raw is the YAML source of the value.
+	Raw   string
+	Found bool
+}
+
+// GetKey reads key from the front matter of each file in paths.
+func (g *Generator) GetKey(paths []string, key string) ([]KeyValue, error) {
+	var values []KeyValue
+	for _, p := range paths {
+		doc, _, err := g.readDocument(p)
+		if err != nil {
+			return nil, err
+		}
+		raw, found := doc.Raw(key)
+		values = append(values, KeyValue{Path: p, Raw: raw, Found: found})
+	}
+	return values, nil
+}
+
+// SetKey computes setting key to raw, a YAML source value, in the front
+// matter of each file in paths, leaving the other keys and the body
+// untouched. With missingOnly, files that already have the key are left
+// alone. Files that would not change are not returned.
+func (g *Generator) SetKey(paths []string, key, raw string, missingOnly bool) ([]Change, error) {
+	if !frontmatter.ValidKey(key) {
+		return nil, fmt.Errorf("invalid front matter key \"%s\"", key)
+	}
+	var changes []Change
+	for _, p := range paths {
+		doc, content, err := g.readDocument(p)
+		if err != nil {
+			return nil, err
+		}
+		if missingOnly && doc.Has(key) {
+			continue
+		}
+		if old, ok := doc.Raw(key); ok && old == raw {
+			continue
+		}
+		doc.SetRaw(key, raw)
+		var check map[string]interface{}
+		if err := doc.Decode(&check); err != nil {
+			return nil, fmt.Errorf("setting %s in %s: %w", key, p, err)
+		}
+		changes = append(changes, Change{Path: p, OldContent: content, NewContent: doc.Bytes()})
+	}
+	return changes, nil
+}
+
+// readDocument reads and parses the post or draft at p.
+func (g *Generator) readDocument(p string) (*frontmatter.Document, []byte, error) {
+	content, err := g.FS.ReadFile(p)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading file %s: %w", p, err)
+	}
+	doc, err := frontmatter.Parse(content)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing %s: %w", p, err)
+	}
+	return doc, content, nil
+}
@@ -0,0 +1,204 @@
+// Package frontmatter splits Jekyll markdown files into their YAML front
+// matter and body, and edits top-level front matter keys in place while
+// leaving every untouched line byte-for-byte intact.
+package frontmatter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const delimiter = "---"
+
+// bom is the UTF-8 byte order mark some Windows editors start files with.
+const bom = "\xef\xbb\xbf"
+
+// ErrNoFrontMatter is returned when content does not start with a
+// front matter block.
+var ErrNoFrontMatter = errors.New("missing front matter")
+
+// keyPattern matches a top-level mapping key, capturing the key and the
+// separator (colon plus any alignment whitespace) that follows it.
+var keyPattern = regexp.MustCompile(`^([^\s#:-][^:]*?)(\s*:(?:[ \t]+|$))`)
+
+// ValidKey reports whether key can be written as a top-level key.
+func ValidKey(key string) bool {
+	return strings.TrimSpace(key) == key && !strings.ContainsAny(key, "\n\r") && keyPattern.MatchString(key+": ")
+}
+
+// Document is a markdown file split into front matter and body.
+type Document struct {
+	lines []string
+	// bom and crlf record a byte order mark before the front matter and
+	// CRLF line endings in it, which Bytes writes back.
+	bom, crlf bool
+	Body      []byte
+}
+
+// Parse splits content into a Document. A byte order mark before the
+// front matter and CRLF line endings in it are accepted.
+func Parse(content []byte) (*Document, error) {
+	d := &Document{bom: bytes.HasPrefix(content, []byte(bom))}
+	first, rest, found := bytes.Cut(bytes.TrimPrefix(content, []byte(bom)), []byte("\n"))
+	d.crlf = bytes.HasSuffix(first, []byte("\r"))
+	if !found || !isDelimiter(first) {
+		return nil, ErrNoFrontMatter
+	}
+	for {
+		line, next, found := bytes.Cut(rest, []byte("\n"))
+		if isDelimiter(line) {
+			d.Body = next
+			return d, nil
+		}
+		if !found {
+			return nil, fmt.Errorf("unterminated front matter: %w", ErrNoFrontMatter)
+		}
+		if d.crlf {
+			line = bytes.TrimSuffix(line, []byte("\r"))
+		}
+		d.lines = append(d.lines, string(line))
+		rest = next
+	}
+}
+
+// isDelimiter reports whether line, without its newline, delimits the
+// front matter.
+func isDelimiter(line []byte) bool {
+	return strings.TrimRight(string(line), " \t\r") == delimiter
+}
+
+// Keys returns the top-level keys in the order they appear.
+func (d *Document) Keys() []string {
+	var keys []string
+	for _, line := range d.lines {
+		if m := keyPattern.FindStringSubmatch(line); m != nil {
+			keys = append(keys, m[1])
+		}
+	}
+	return keys
+}
+
+// Has reports whether key is present.
+func (d *Document) Has(key string) bool {
+	start, _ := d.span(key)
+	return start >= 0
+}
+
+// Raw returns the YAML source text of key's value, including any
+// continuation lines, with the key itself removed.
+func (d *Document) Raw(key string) (string, bool) {
+	start, end := d.span(key)
+	if start < 0 {
+		return "", false
+	}
+	m := keyPattern.FindStringSubmatch(d.lines[start])
+	first := strings.TrimSpace(d.lines[start][len(m[0]):])
+	rest := d.lines[start+1 : end]
+	if len(rest) == 0 {
+		return first, true
+	}
+	return strings.TrimSpace(first + "\n" + strings.Join(rest, "\n")), true
+}
+
+// SetRaw replaces the value of key with the given YAML source text,
+// keeping the key's position and its alignment whitespace. Keys that are
+// not present yet are appended. Multi-line values must start with a
+// newline, e.g. "\n  - a\n  - b".
+func (d *Document) SetRaw(key, value string) {
+	start, end := d.span(key)
+	separator := ": "
+	if start >= 0 {
+		separator = keyPattern.FindStringSubmatch(d.lines[start])[2]
+		if strings.TrimSpace(separator) == separator {
+			separator += " "
+		}
+	}
+	var replacement []string
+	if strings.HasPrefix(value, "\n") {
+		replacement = append([]string{key + ":"}, strings.Split(value[1:], "\n")...)
+	} else {
+		replacement = []string{key + separator + value}
+	}
+	if start < 0 {
+		d.lines = append(d.lines, replacement...)
+		return
+	}
+	lines := append([]string{}, d.lines[:start]...)
+	lines = append(lines, replacement...)
+	d.lines = append(lines, d.lines[end:]...)
+}
+
+// Merge copies the keys of from, with their values, into d: the ones d
+// lacks are appended, and the ones it has are replaced when overwrite is
+// set.
+func (d *Document) Merge(from *Document, overwrite bool) {
+	for _, key := range from.Keys() {
+		has := d.Has(key)
+		if has && !overwrite {
+			continue
+		}
+		start, end := from.span(key)
+		if !has {
+			d.lines = append(d.lines, from.lines[start:end]...)
+			continue
+		}
+		at, atEnd := d.span(key)
+		lines := append([]string{}, d.lines[:at]...)
+		lines = append(lines, from.lines[start:end]...)
+		d.lines = append(lines, d.lines[atEnd:]...)
+	}
+}
+
+// Delete removes key and its value.
+func (d *Document) Delete(key string) {
+	start, end := d.span(key)
+	if start < 0 {
+		return
+	}
+	d.lines = append(d.lines[:start:start], d.lines[end:]...)
+}
+
+// Bytes reassembles the document, with the byte order mark and the line
+// endings of the front matter it was parsed from.
+func (d *Document) Bytes() []byte {
+	newline := "\n"
+	if d.crlf {
+		newline = "\r\n"
+	}
+	var b bytes.Buffer
+	if d.bom {
+		b.WriteString(bom)
+	}
+	b.WriteString(delimiter + newline)
+	for _, line := range d.lines {
+		b.WriteString(line + newline)
+	}
+	b.WriteString(delimiter + newline)
+	b.Write(d.Body)
+	return b.Bytes()
+}
+
+// span returns the line range [start, end) holding key and its
+// continuation lines, or -1 when the key is absent.
+func (d *Document) span(key string) (int, int) {
+	for i, line := range d.lines {
+		m := keyPattern.FindStringSubmatch(line)
+		if m == nil || m[1] != key {
+			continue
+		}
+		end := i + 1
+		for end < len(d.lines) && isContinuation(d.lines[end]) {
+			end++
+		}
+		return i, end
+	}
+	return -1, -1
+}
+
+func isContinuation(line string) bool {
+	return strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") ||
+		line == "-" || strings.HasPrefix(line, "- ")
+}
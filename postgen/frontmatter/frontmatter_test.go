@@ -0,0 +1,49 @@
+package frontmatter
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseWindowsFiles(t *testing.T) {
+	tests := map[string]string{
+		"lf":       "---\ntitle: Hello\ntags:\n  - go\n---\nBody.\n",
+		"crlf":     "---\r\ntitle: Hello\r\ntags:\r\n  - go\r\n---\r\nBody.\r\n",
+		"bom":      "\xef\xbb\xbf---\ntitle: Hello\ntags:\n  - go\n---\nBody.\n",
+		"bom crlf": "\xef\xbb\xbf---\r\ntitle: Hello\r\ntags:\r\n  - go\r\n---\r\nBody.\r\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			d, err := Parse([]byte(content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Join(d.Keys(), " "); got != "title tags" {
+				t.Errorf("keys = %s, want title tags", got)
+			}
+			var meta struct {
+				Title string   `yaml:"title"`
+				Tags  []string `yaml:"tags"`
+			}
+			if err := d.Decode(&meta); err != nil || meta.Title != "Hello" || strings.Join(meta.Tags, ",") != "go" {
+				t.Errorf("decoded %+v, %v", meta, err)
+			}
+			if got := string(d.Bytes()); got != content {
+				t.Errorf("Bytes = %q, want %q", got, content)
+			}
+			d.SetRaw("title", "Bye")
+			if want := strings.Replace(content, "Hello", "Bye", 1); string(d.Bytes()) != want {
+				t.Errorf("after SetRaw, Bytes = %q, want %q", d.Bytes(), want)
+			}
+		})
+	}
+}
+
+func TestParseWithoutFrontMatter(t *testing.T) {
+	for _, content := range []string{"", "Body.\n", "\xef\xbb\xbfBody.\r\n", "---\r\ntitle: x\r\n"} {
+		if _, err := Parse([]byte(content)); !errors.Is(err, ErrNoFrontMatter) {
+			t.Errorf("Parse(%q) = %v, want ErrNoFrontMatter", content, err)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package frontmatter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Decode unmarshals the front matter into v.
+func (d *Document) Decode(v interface{}) error {
+	return yaml.Unmarshal([]byte(strings.Join(d.lines, "\n")), v)
+}
+
+// timeLayouts are the date formats Jekyll accepts in front matter.
+var timeLayouts = []string{
+	"2006-01-02 15:04:05 -0700",
+	"2006-01-02 15:04:05 -07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04 -0700",
+	"2006-01-02 15:04",
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// ParseTime parses a front matter date, taking one without an offset to
+// be in UTC.
+func ParseTime(s string) (time.Time, error) {
+	return ParseTimeIn(s, time.UTC)
+}
+
+// ParseTimeIn is ParseTime taking a date without an offset to be in loc,
+// as Jekyll does with the site's timezone.
+func ParseTimeIn(s string, loc *time.Location) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date \"%s\"", s)
+}
+
+// Time is a front matter date.
+type Time struct {
+	time.Time
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (t *Time) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind != yaml.ScalarNode {
+		return fmt.Errorf("line %d: date must be a scalar", n.Line)
+	}
+	parsed, err := ParseTime(n.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: %w", n.Line, err)
+	}
+	t.Time = parsed
+	return nil
+}
+
+// List is a list of strings written either as a YAML sequence or, as
+// Jekyll allows for categories and tags, as a space-separated string.
+type List []string
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (l *List) UnmarshalYAML(n *yaml.Node) error {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		*l = strings.Fields(n.Value)
+		return nil
+	case yaml.SequenceNode:
+		var items []string
+		if err := n.Decode(&items); err != nil {
+			return err
+		}
+		*l = items
+		return nil
+	default:
+		return fmt.Errorf("line %d: expected a list or a space-separated string", n.Line)
+	}
+}
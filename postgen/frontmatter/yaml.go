@@ -0,0 +1,73 @@
+package frontmatter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// String renders s as a double-quoted YAML scalar, escaping the
+// characters that would otherwise end the string or change its meaning.
+func String(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\x%02x`, r)
+				continue
+			}
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// plainScalarPattern matches strings that YAML reads back unchanged as
+// strings when written without quotes.
+var plainScalarPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9 _.+#-]*$`)
+
+// Scalar renders s as a plain YAML scalar when that is unambiguous
+// and falls back to String otherwise.
+func Scalar(s string) string {
+	if !plainScalarPattern.MatchString(s) || strings.HasSuffix(s, " ") || strings.Contains(s, " #") {
+		return String(s)
+	}
+	switch strings.ToLower(s) {
+	case "y", "n", "yes", "no", "true", "false", "on", "off", "null":
+		return String(s)
+	}
+	return s
+}
+
+// BlockList renders items as the value of a block sequence, in the form
+// SetRaw expects for multi-line values.
+func BlockList(items []string) string {
+	var b strings.Builder
+	for _, item := range items {
+		b.WriteString("\n  - ")
+		b.WriteString(Scalar(item))
+	}
+	return b.String()
+}
+
+// FlowList renders items as a flow sequence such as [a, b].
+func FlowList(items []string) string {
+	quoted := make([]string, len(items))
+	for i, item := range items {
+		quoted[i] = Scalar(item)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
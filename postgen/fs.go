@@ -0,0 +1,122 @@
+package postgen
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// FS is the filesystem a Generator reads and writes. Like io/fs, names
+// are slash-separated paths relative to the filesystem's root.
+type FS interface {
+	fs.StatFS
+	fs.ReadFileFS
+	OpenFile(name string, flag int, perm fs.FileMode) (io.WriteCloser, error)
+	Mkdir(name string, perm fs.FileMode) error
+	MkdirAll(name string, perm fs.FileMode) error
+	Remove(name string) error
+	RemoveAll(name string) error
+	Rename(oldname, newname string) error
+}
+
+// DirFS returns an FS backed by the operating system directory dir. The
+// errors of the operating system match ErrIO.
+func DirFS(dir string) FS {
+	return dirFS(dir)
+}
+
+type dirFS string
+
+func (d dirFS) join(op, name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	return filepath.Join(string(d), filepath.FromSlash(name)), nil
+}
+
+func (d dirFS) Open(name string) (fs.File, error) {
+	path, err := d.join("open", name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, ioError(err)
+	}
+	return f, nil
+}
+
+func (d dirFS) Stat(name string) (fs.FileInfo, error) {
+	path, err := d.join("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	return info, ioError(err)
+}
+
+func (d dirFS) ReadFile(name string) ([]byte, error) {
+	path, err := d.join("read", name)
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(path)
+	return b, ioError(err)
+}
+
+func (d dirFS) OpenFile(name string, flag int, perm fs.FileMode) (io.WriteCloser, error) {
+	path, err := d.join("open", name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, flag, perm)
+	if err != nil {
+		return nil, ioError(err)
+	}
+	return f, nil
+}
+
+func (d dirFS) Mkdir(name string, perm fs.FileMode) error {
+	path, err := d.join("mkdir", name)
+	if err != nil {
+		return err
+	}
+	return ioError(os.Mkdir(path, perm))
+}
+
+func (d dirFS) MkdirAll(name string, perm fs.FileMode) error {
+	path, err := d.join("mkdir", name)
+	if err != nil {
+		return err
+	}
+	return ioError(os.MkdirAll(path, perm))
+}
+
+func (d dirFS) Remove(name string) error {
+	path, err := d.join("remove", name)
+	if err != nil {
+		return err
+	}
+	return ioError(os.Remove(path))
+}
+
+func (d dirFS) Rename(oldname, newname string) error {
+	oldpath, err := d.join("rename", oldname)
+	if err != nil {
+		return err
+	}
+	newpath, err := d.join("rename", newname)
+	if err != nil {
+		return err
+	}
+	return ioError(os.Rename(oldpath, newpath))
+}
+
+func (d dirFS) RemoveAll(name string) error {
+	path, err := d.join("remove", name)
+	if err != nil {
+		return err
+	}
+	return ioError(os.RemoveAll(path))
+}
@@ -0,0 +1,250 @@
+package postgen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen/frontmatter"
+	"gopkg.in/yaml.v3"
+)
+
+// HeaderKeys are the front matter keys RenderHeader writes, in their
+// default order.
+var HeaderKeys = []string{
+	"layout", "title", "date", "description", "permalink", "canonical_url", "author",
+	"lang", "categories", "tags", "image", "series", "series_part", "published",
+}
+
+// The quoting styles of header values: plain writes the value as is,
+// auto without quotes when YAML reads it back unchanged, double and
+// single with quotes.
+const (
+	QuotePlain  = "plain"
+	QuoteAuto   = "auto"
+	QuoteDouble = "double"
+	QuoteSingle = "single"
+)
+
+// The styles of header lists: space joins the items in a string, as
+// Jekyll allows, flow writes [a, b] and block one - item per line.
+const (
+	ListSpace = "space"
+	ListFlow  = "flow"
+	ListBlock = "block"
+)
+
+var (
+	quoteStyles = []string{QuotePlain, QuoteAuto, QuoteDouble, QuoteSingle}
+	listStyles  = []string{ListSpace, ListFlow, ListBlock}
+	// listKeys are the HeaderKeys holding lists, and numberKeys those
+	// holding numbers or booleans, always written plain.
+	listKeys   = []string{"categories", "tags"}
+	numberKeys = []string{"series_part", "published"}
+)
+
+// HeaderFormat configures how RenderHeader writes the front matter of
+// new posts. Its zero value, like each field left empty, selects what
+// DefaultHeaderFormat does.
+type HeaderFormat struct {
+	// Order lists keys of HeaderKeys in the order they are written; the
+	// others follow in their default order.
+	Order []string `yaml:"order,omitempty" json:"order,omitempty"`
+	// Quote maps keys to quoting styles, for a list in the space style
+	// the quoting of the joined string.
+	Quote map[string]string `yaml:"quote,omitempty" json:"quote,omitempty"`
+	// Lists maps the list keys, categories and tags, to list styles.
+	Lists map[string]string `yaml:"lists,omitempty" json:"lists,omitempty"`
+	// Align lists the keys whose values start at the same column.
+	Align []string `yaml:"align,omitempty" json:"align,omitempty"`
+	// Empty writes the keys without a value too, as null unless quoted,
+	// except series_part and published, which only posts of a series and
+	// drafts have.
+	Empty bool `yaml:"empty,omitempty" json:"empty,omitempty"`
+}
+
+// DefaultHeaderFormat is the format of the posts of this site.
+var DefaultHeaderFormat = HeaderFormat{
+	Order: HeaderKeys,
+	Quote: map[string]string{
+		"layout": QuotePlain, "title": QuoteDouble, "date": QuotePlain, "description": QuoteDouble,
+		"permalink": QuotePlain, "canonical_url": QuoteAuto, "author": QuoteAuto, "lang": QuotePlain, "categories": QuotePlain,
+		"tags": QuoteAuto, "image": QuotePlain, "series": QuoteDouble,
+	},
+	Lists: map[string]string{"categories": ListSpace, "tags": ListBlock},
+	Align: []string{"layout", "title", "date"},
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler, rejecting unknown keys and
+// styles.
+func (f *HeaderFormat) UnmarshalYAML(n *yaml.Node) error {
+	type plain HeaderFormat
+	if err := n.Decode((*plain)(f)); err != nil {
+		return err
+	}
+	if err := f.check(); err != nil {
+		return fmt.Errorf("line %d: %s", n.Line, err)
+	}
+	return nil
+}
+
+func (f HeaderFormat) check() error {
+	known := func(key string) error {
+		if !contains(HeaderKeys, key) {
+			return fmt.Errorf("unknown key %s: expected one of %s", key, strings.Join(HeaderKeys, ", "))
+		}
+		return nil
+	}
+	seen := make(map[string]bool)
+	for _, key := range append(append([]string{}, f.Order...), f.Align...) {
+		if err := known(key); err != nil {
+			return err
+		}
+	}
+	for _, key := range f.Order {
+		if seen[key] {
+			return fmt.Errorf("key %s is ordered twice", key)
+		}
+		seen[key] = true
+	}
+	for key, style := range f.Quote {
+		if err := known(key); err != nil {
+			return err
+		}
+		if contains(numberKeys, key) {
+			return fmt.Errorf("key %s holds a number or boolean, which is not quoted", key)
+		}
+		if !contains(quoteStyles, style) {
+			return fmt.Errorf("unknown quoting style %s for %s: expected one of %s", style, key, strings.Join(quoteStyles, ", "))
+		}
+	}
+	for key, style := range f.Lists {
+		if !contains(listKeys, key) {
+			return fmt.Errorf("key %s is not a list: expected one of %s", key, strings.Join(listKeys, ", "))
+		}
+		if !contains(listStyles, style) {
+			return fmt.Errorf("unknown list style %s for %s: expected one of %s", style, key, strings.Join(listStyles, ", "))
+		}
+	}
+	return nil
+}
+
+// withDefaults returns f with what it leaves empty taken from
+// DefaultHeaderFormat.
+func (f HeaderFormat) withDefaults() HeaderFormat {
+	d := DefaultHeaderFormat
+	order := append([]string{}, f.Order...)
+	for _, key := range d.Order {
+		if !contains(order, key) {
+			order = append(order, key)
+		}
+	}
+	merged := HeaderFormat{Order: order, Quote: map[string]string{}, Lists: map[string]string{}, Align: f.Align, Empty: f.Empty}
+	for _, m := range []map[string]string{d.Quote, f.Quote} {
+		for k, v := range m {
+			merged.Quote[k] = v
+		}
+	}
+	for _, m := range []map[string]string{d.Lists, f.Lists} {
+		for k, v := range m {
+			merged.Lists[k] = v
+		}
+	}
+	if merged.Align == nil {
+		merged.Align = d.Align
+	}
+	return merged
+}
+
+// RenderHeader renders the front matter of a new post from data in format
+// f, delimiters included. Its keys are those of HeaderKeys, series_part
+// only with a series and published: false only for drafts.
+func RenderHeader(data TemplateData, f HeaderFormat) ([]byte, error) {
+	if err := f.check(); err != nil {
+		return nil, err
+	}
+	f = f.withDefaults()
+	values := map[string]string{
+		"layout": data.Layout, "title": data.Title, "date": data.Date, "description": data.Description,
+		"permalink": data.Permalink, "canonical_url": data.CanonicalURL, "author": data.Author,
+		"lang": data.Lang, "image": data.Image, "series": data.Series,
+	}
+	lists := map[string][]string{"categories": data.Categories, "tags": data.Tags}
+
+	type line struct{ key, value string }
+	var lines []line
+	for _, key := range f.Order {
+		switch {
+		case key == "series_part":
+			if data.Series != "" {
+				lines = append(lines, line{key, strconv.Itoa(data.SeriesPart)})
+			}
+		case key == "published":
+			if data.Draft {
+				lines = append(lines, line{key, "false"})
+			}
+		case contains(listKeys, key):
+			items := lists[key]
+			if len(items) == 0 && !f.Empty {
+				continue
+			}
+			switch f.Lists[key] {
+			case ListFlow:
+				lines = append(lines, line{key, frontmatter.FlowList(items)})
+			case ListBlock:
+				if len(items) == 0 {
+					lines = append(lines, line{key, "[]"})
+				} else {
+					lines = append(lines, line{key, frontmatter.BlockList(items)})
+				}
+			default:
+				lines = append(lines, line{key, quote(strings.Join(items, " "), f.Quote[key])})
+			}
+		default:
+			if value := values[key]; value != "" || f.Empty {
+				lines = append(lines, line{key, quote(value, f.Quote[key])})
+			}
+		}
+	}
+
+	width := 0
+	for _, l := range lines {
+		if contains(f.Align, l.key) && len(l.key) > width {
+			width = len(l.key)
+		}
+	}
+	var b strings.Builder
+	b.WriteString("---\n")
+	for _, l := range lines {
+		b.WriteString(l.key + ":")
+		switch {
+		case strings.HasPrefix(l.value, "\n"):
+			b.WriteString(l.value)
+		case l.value != "":
+			pad := 1
+			if contains(f.Align, l.key) {
+				pad += width - len(l.key)
+			}
+			b.WriteString(strings.Repeat(" ", pad) + l.value)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("---\n")
+	return []byte(b.String()), nil
+}
+
+// quote renders s in the quoting style.
+func quote(s, style string) string {
+	switch style {
+	case QuotePlain:
+		return s
+	case QuoteDouble:
+		return frontmatter.String(s)
+	case QuoteSingle:
+		return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+	}
+	if s == "" {
+		return ""
+	}
+	return frontmatter.Scalar(s)
+}
@@ -0,0 +1,221 @@
+package postgen
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+)
+
+func TestPlanTitleRoundTrips(t *testing.T) {
+	titles := []string{
+		"Go: tips & tricks",
+		`She said "hello"`,
+		"It's a title",
+		"- leading dash",
+		"#hash: and colon",
+		"[brackets] {braces}",
+		`back\slash`,
+		"@at *star !bang",
+		"Configuração de ambiente Go",
+	}
+	for _, title := range titles {
+		t.Run(title, func(t *testing.T) {
+			r, err := testGenerator(newMemFS(nil)).Plan(Post{Layout: "post", Title: title, Slug: "x"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var meta struct {
+				Title string `yaml:"title"`
+			}
+			frontMatter(t, r.Content, &meta)
+			if meta.Title != title {
+				t.Errorf("title = %q, want %q", meta.Title, title)
+			}
+		})
+	}
+}
+
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+// headerData are the headers rendered by the golden tests: a minimal
+// post, a post with every key and a draft part of a series.
+var headerData = map[string]TemplateData{
+	"minimal": {Layout: "post", Title: "Hello", Date: "2024-05-01 10:30:00 -0300"},
+	"full": {
+		Layout:       "post",
+		Title:        `Go: "tips" & tricks`,
+		Date:         "2024-05-01 10:30:00 -0300",
+		Description:  "Tips: for Go",
+		Permalink:    "/go-tips/",
+		CanonicalURL: "https://dev.to/me/go-tips",
+		Author:       "Tiago Melo",
+		Lang:         "en",
+		Categories:   []string{"go", "tips"},
+		Tags:         []string{"go", "docker: compose", "yes"},
+		Image:        "/assets/images/2024-05-01-go-tips/cover.png",
+	},
+	"series_draft": {
+		Layout:     "post",
+		Title:      "Part two",
+		Date:       "2024-05-01 10:30:00 -0300",
+		Categories: []string{"go"},
+		Series:     "Go: from scratch",
+		SeriesPart: 2,
+		Draft:      true,
+	},
+}
+
+func TestDefaultHeaderFormatGolden(t *testing.T) {
+	tmpl, err := template.New("default").Funcs(templateFuncs(time.Now)).Parse(DefaultTemplate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range headerData {
+		t.Run(name, func(t *testing.T) {
+			got, err := RenderHeader(data, DefaultHeaderFormat)
+			if err != nil {
+				t.Fatal(err)
+			}
+			zero, err := RenderHeader(data, HeaderFormat{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(zero, got) {
+				t.Errorf("zero HeaderFormat renders:\n%s\nDefaultHeaderFormat:\n%s", zero, got)
+			}
+			var want bytes.Buffer
+			if err := tmpl.Execute(&want, data); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want.Bytes()) {
+				t.Errorf("RenderHeader:\n%s\nDefaultTemplate:\n%s", got, want.Bytes())
+			}
+			golden := filepath.Join("testdata", "header_"+name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			b, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, b) {
+				t.Errorf("RenderHeader differs from %s:\n%s", golden, got)
+			}
+		})
+	}
+}
+
+// headerValue returns the value RenderHeader wrote for key in header,
+// after its alignment.
+func headerValue(t *testing.T, header []byte, key string) string {
+	t.Helper()
+	for _, line := range strings.Split(string(header), "\n") {
+		if rest, ok := strings.CutPrefix(line, key+":"); ok {
+			return strings.TrimLeft(rest, " ")
+		}
+	}
+	t.Fatalf("no %s in:\n%s", key, header)
+	return ""
+}
+
+func TestRenderHeaderQuoting(t *testing.T) {
+	tests := []struct {
+		style string
+		title string
+		want  string
+	}{
+		{QuotePlain, "It's Go", "It's Go"},
+		{QuoteAuto, "Hello Go", "Hello Go"},
+		{QuoteAuto, "Go: tips", `"Go: tips"`},
+		{QuoteAuto, "yes", `"yes"`},
+		{QuoteDouble, "It's Go", `"It's Go"`},
+		{QuoteDouble, `say "hi"`, `"say \"hi\""`},
+		{QuoteSingle, "It's Go", `'It''s Go'`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.style+" "+tt.title, func(t *testing.T) {
+			f := HeaderFormat{Quote: map[string]string{"title": tt.style}}
+			header, err := RenderHeader(TemplateData{Layout: "post", Title: tt.title, Date: "2024-05-01"}, f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := headerValue(t, header, "title"); got != tt.want {
+				t.Errorf("title: %s, want %s", got, tt.want)
+			}
+			if tt.style == QuotePlain {
+				return
+			}
+			var meta struct {
+				Title string `yaml:"title"`
+			}
+			frontMatter(t, header, &meta)
+			if meta.Title != tt.title {
+				t.Errorf("title reads back as %q, want %q", meta.Title, tt.title)
+			}
+		})
+	}
+}
+
+func TestRenderHeaderLists(t *testing.T) {
+	tags := []string{"go", "docker: compose"}
+	tests := []struct {
+		style string
+		want  string
+	}{
+		{ListSpace, `tags: "go docker: compose"`},
+		{ListFlow, `tags: [go, "docker: compose"]`},
+		{ListBlock, "tags:\n  - go\n  - \"docker: compose\""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.style, func(t *testing.T) {
+			f := HeaderFormat{Lists: map[string]string{"tags": tt.style}}
+			header, err := RenderHeader(TemplateData{Layout: "post", Title: "Hello", Date: "2024-05-01", Tags: tags}, f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(header), "\n"+tt.want+"\n") {
+				t.Errorf("no\n%s\nin:\n%s", tt.want, header)
+			}
+			if tt.style == ListSpace {
+				return
+			}
+			var meta struct {
+				Tags []string `yaml:"tags"`
+			}
+			frontMatter(t, header, &meta)
+			if strings.Join(meta.Tags, ",") != strings.Join(tags, ",") {
+				t.Errorf("tags read back as %q, want %q", meta.Tags, tags)
+			}
+		})
+	}
+}
+
+func TestRenderHeaderEmpty(t *testing.T) {
+	data := TemplateData{Layout: "post", Title: "Hello", Date: "2024-05-01"}
+	header, err := RenderHeader(data, HeaderFormat{
+		Order: []string{"layout", "title", "date", "author", "tags"},
+		Quote: map[string]string{"author": QuoteDouble},
+		Lists: map[string]string{"tags": ListFlow},
+		Empty: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{"author": `""`, "tags": "[]", "description": `""`, "permalink": ""} {
+		if got := headerValue(t, header, key); got != want {
+			t.Errorf("%s: %s, want %s", key, got, want)
+		}
+	}
+	for _, key := range []string{"series_part", "published"} {
+		if strings.Contains(string(header), key+":") {
+			t.Errorf("%s written without a series or draft:\n%s", key, header)
+		}
+	}
+}
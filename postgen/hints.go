@@ -0,0 +1,56 @@
+package postgen
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultTitleLength is about the number of characters search engines
+// show of a title before truncating it; DefaultSlugLength the length past
+// which a slug makes for an unwieldy URL.
+const (
+	DefaultTitleLength = 60
+	DefaultSlugLength  = 50
+)
+
+// HintOptions configures TitleHints; zero lengths select the defaults.
+type HintOptions struct {
+	TitleLength int
+	SlugLength  int
+}
+
+// TitleHints returns what would make the title or slug of a new post
+// show poorly: a title longer than opts.TitleLength characters, a slug
+// longer than opts.SlugLength, or a title equal, ignoring case, to the
+// front matter title of an existing post or draft. Files whose front
+// matter cannot be parsed are skipped.
+func (g *Generator) TitleHints(title, slug string, opts HintOptions) ([]string, error) {
+	if opts.TitleLength == 0 {
+		opts.TitleLength = DefaultTitleLength
+	}
+	if opts.SlugLength == 0 {
+		opts.SlugLength = DefaultSlugLength
+	}
+	var hints []string
+	if n := utf8.RuneCountInString(title); n > opts.TitleLength {
+		hints = append(hints, fmt.Sprintf("the title is %d characters long, search results truncate it past about %d", n, opts.TitleLength))
+	}
+	if n := utf8.RuneCountInString(slug); n > opts.SlugLength {
+		hints = append(hints, fmt.Sprintf("the slug is %d characters long, over %d, use --slug for a shorter one", n, opts.SlugLength))
+	}
+	files, err := g.Files()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range files {
+		e, err := g.Read(p)
+		if err != nil {
+			continue
+		}
+		if strings.EqualFold(strings.TrimSpace(e.Meta.Title), strings.TrimSpace(title)) {
+			hints = append(hints, fmt.Sprintf("%s already has the title \"%s\"", p, e.Meta.Title))
+		}
+	}
+	return hints, nil
+}
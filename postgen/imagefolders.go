@@ -0,0 +1,111 @@
+package postgen
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+)
+
+// FolderRename is renaming an images folder after the one post that
+// references it, together with the change pointing that post at it.
+type FolderRename struct {
+	Folder    string
+	NewFolder string
+	Post      Change
+}
+
+// SkippedFolder is an images folder named after no post that is left
+// alone: either several posts reference it or Taken, the folder of its
+// single post, already exists.
+type SkippedFolder struct {
+	Folder string
+	Posts  []string
+	Taken  string
+}
+
+// FolderPlan is the outcome of PlanImageFolders.
+type FolderPlan struct {
+	Renames []FolderRename
+	Skipped []SkippedFolder
+}
+
+// PlanImageFolders maps every dated images folder to the posts and drafts
+// referencing it and plans renaming the ones named after no post to the
+// folder of the single post referencing them. Folders referenced by
+// several posts, or whose new name is taken, are skipped; folders nothing
+// references are left to CheckImages.
+func (g *Generator) PlanImageFolders() (FolderPlan, error) {
+	files, err := g.Files()
+	if err != nil {
+		return FolderPlan{}, err
+	}
+	contents := make(map[string][]byte, len(files))
+	owned := make(map[string]bool, len(files))
+	for _, p := range files {
+		if contents[p], err = g.FS.ReadFile(p); err != nil {
+			return FolderPlan{}, fmt.Errorf("reading file %s: %w", p, err)
+		}
+		owned[path.Base(g.ImagesFolder(p))] = true
+	}
+	folders, err := fs.ReadDir(g.FS, g.ImagesDir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return FolderPlan{}, fmt.Errorf("reading folder %s: %w", g.ImagesDir, err)
+	}
+	var plan FolderPlan
+	taken := make(map[string]bool)
+	for _, folder := range folders {
+		name := folder.Name()
+		if _, _, ok := ParseFileName(name); !ok || !folder.IsDir() || owned[name] {
+			continue
+		}
+		dir := path.Join(g.ImagesDir, name)
+		re := imagesFolderPattern(g.ImagesURL(), name)
+		var posts []string
+		for _, p := range files {
+			if re.Match(contents[p]) {
+				posts = append(posts, p)
+			}
+		}
+		switch {
+		case len(posts) == 0:
+			continue
+		case len(posts) > 1:
+			plan.Skipped = append(plan.Skipped, SkippedFolder{Folder: dir, Posts: posts})
+			continue
+		}
+		p := posts[0]
+		target := g.ImagesFolder(p)
+		if _, err := g.FS.Stat(target); err == nil || taken[target] {
+			plan.Skipped = append(plan.Skipped, SkippedFolder{Folder: dir, Posts: posts, Taken: target})
+			continue
+		}
+		taken[target] = true
+		plan.Renames = append(plan.Renames, FolderRename{
+			Folder:    dir,
+			NewFolder: target,
+			Post: Change{
+				Path:       p,
+				OldContent: contents[p],
+				NewContent: RewriteImagesFolder(contents[p], g.ImagesURL(), name, path.Base(target)),
+			},
+		})
+	}
+	return plan, nil
+}
+
+// ApplyFolderRename carries out r, moving the folder back when the post
+// cannot be updated.
+func (g *Generator) ApplyFolderRename(r FolderRename) error {
+	if _, err := g.FS.Stat(r.NewFolder); err == nil {
+		return conflictf("folder %s already exists", r.NewFolder)
+	}
+	if err := g.FS.Rename(r.Folder, r.NewFolder); err != nil {
+		return fmt.Errorf("renaming folder %s: %w", r.Folder, err)
+	}
+	if err := g.WriteChanges([]Change{r.Post}); err != nil {
+		g.FS.Rename(r.NewFolder, r.Folder)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package postgen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"io/fs"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Image is an image file to add to a new post.
+type Image struct {
+	// Name is the original file name; it is sanitized with ImageFileName.
+	Name string
+	Data []byte
+}
+
+// ImageFormat returns the format of the image in data, such as png or
+// jpeg, read from its header rather than trusted from a file extension.
+// It fails when data is not a PNG, JPEG or GIF image.
+func ImageFormat(data []byte) (string, error) {
+	_, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return "", errors.New("not a PNG, JPEG or GIF image")
+	}
+	return format, nil
+}
+
+// ImageFileName sanitizes an image file name the way Slugify does titles,
+// keeping its lowercased extension.
+func ImageFileName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	ext := path.Ext(name)
+	base := Slugify(strings.TrimSuffix(name, ext))
+	if base == "" {
+		base = "image"
+	}
+	if ext = Slugify(ext); ext != "" {
+		return base + "." + ext
+	}
+	return base
+}
+
+// uniqueName returns name, or name with a numeric suffix when taken
+// already has it, and records the result in taken.
+func uniqueName(name string, taken map[string]bool) string {
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	unique := name
+	for i := 2; taken[unique]; i++ {
+		unique = fmt.Sprintf("%s-%d%s", base, i, ext)
+	}
+	taken[unique] = true
+	return unique
+}
+
+// ImageRef is a reference to an image from a post body.
+type ImageRef struct {
+	Post string
+	Line int
+	// URL is the reference as written in the post.
+	URL string
+	// Path is the referenced file, relative to the generator's filesystem.
+	Path string
+}
+
+// ImageReport lists the broken image references and the image files no
+// post references.
+type ImageReport struct {
+	Missing []ImageRef
+	Orphans []string
+}
+
+var (
+	markdownImagePattern = regexp.MustCompile(`!\[[^\]]*\]\(\s*<?([^)\s>]+)`)
+	htmlImagePattern     = regexp.MustCompile(`(?i)<img\b[^>]*?\bsrc\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))`)
+	liquidPrefixPattern  = regexp.MustCompile(`^(\{\{[^}]*\}\})+`)
+)
+
+// ImageRefs returns the references from content, written either as
+// Markdown images or as HTML img tags, that point into the images folder.
+func (g *Generator) ImageRefs(post string, content []byte) []ImageRef {
+	var refs []ImageRef
+	add := func(offset int, raw string) {
+		file, ok := g.imagePath(raw)
+		if !ok {
+			return
+		}
+		refs = append(refs, ImageRef{
+			Post: post,
+			Line: bytes.Count(content[:offset], []byte("\n")) + 1,
+			URL:  raw,
+			Path: file,
+		})
+	}
+	for _, m := range markdownImagePattern.FindAllSubmatchIndex(content, -1) {
+		add(m[0], string(content[m[2]:m[3]]))
+	}
+	for _, m := range htmlImagePattern.FindAllSubmatchIndex(content, -1) {
+		for i := 2; i < len(m); i += 2 {
+			if m[i] >= 0 {
+				add(m[0], string(content[m[i]:m[i+1]]))
+			}
+		}
+	}
+	sort.SliceStable(refs, func(i, j int) bool { return refs[i].Line < refs[j].Line })
+	return refs
+}
+
+// sitePath maps a URL of the site, such as the one of an image front
+// matter key, to the file it is served from, reporting whether it is a
+// local URL at all.
+func (g *Generator) sitePath(raw string) (string, bool) {
+	u, err := url.Parse(liquidPrefixPattern.ReplaceAllString(strings.TrimSpace(raw), ""))
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "" {
+		return "", false
+	}
+	return path.Join(path.Dir(g.PostsDir), strings.TrimPrefix(path.Clean("/"+u.Path), "/")), true
+}
+
+// imagePath maps an image URL to the file it refers to, reporting whether
+// it points into the images folder at all.
+func (g *Generator) imagePath(raw string) (string, bool) {
+	u, err := url.Parse(liquidPrefixPattern.ReplaceAllString(strings.TrimSpace(raw), ""))
+	if err != nil || u.Scheme != "" || u.Host != "" {
+		return "", false
+	}
+	p := strings.TrimPrefix(path.Clean("/"+u.Path), "/")
+	rest := strings.TrimPrefix(p, g.ImagesURL()+"/")
+	if rest == p {
+		return "", false
+	}
+	return path.Join(g.ImagesDir, rest), true
+}
+
+// CheckImages scans every post and draft for image references, reporting
+// the ones pointing at files that do not exist and the files in the posts'
+// images folders that nothing references.
+func (g *Generator) CheckImages() (ImageReport, error) {
+	files, err := g.Files()
+	if err != nil {
+		return ImageReport{}, err
+	}
+	var report ImageReport
+	referenced := make(map[string]bool)
+	for _, p := range files {
+		content, err := g.FS.ReadFile(p)
+		if err != nil {
+			return ImageReport{}, fmt.Errorf("reading file %s: %w", p, err)
+		}
+		for _, ref := range g.ImageRefs(p, content) {
+			referenced[ref.Path] = true
+			if _, err := g.FS.Stat(ref.Path); err != nil {
+				report.Missing = append(report.Missing, ref)
+			}
+		}
+	}
+	folders, err := fs.ReadDir(g.FS, g.ImagesDir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return ImageReport{}, fmt.Errorf("reading folder %s: %w", g.ImagesDir, err)
+	}
+	for _, folder := range folders {
+		// Only the dated folders belong to posts; anything else, such as
+		// images used by pages, is left alone.
+		if _, _, ok := ParseFileName(folder.Name()); !ok || !folder.IsDir() {
+			continue
+		}
+		dir := path.Join(g.ImagesDir, folder.Name())
+		err := fs.WalkDir(g.FS, dir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && !referenced[p] {
+				report.Orphans = append(report.Orphans, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return ImageReport{}, fmt.Errorf("reading folder %s: %w", dir, err)
+		}
+	}
+	return report, nil
+}
+
+// PruneImages removes the given image files, as reported in
+// ImageReport.Orphans.
+func (g *Generator) PruneImages(orphans []string) error {
+	for _, p := range orphans {
+		if err := g.FS.Remove(p); err != nil {
+			return fmt.Errorf("removing file %s: %w", p, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package postgen
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestImageFileName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"diagram.png", "diagram.png"},
+		{"My Diagram.PNG", "my-diagram.png"},
+		{"shots/diagram.png", "diagram.png"},
+		{`C:\Users\me\Pictures\My Diagram.png`, "my-diagram.png"},
+		{filepath.Join("shots", "2024", "diagram.png"), "diagram.png"},
+		{"🚀.gif", "image.gif"},
+		{"README", "readme"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ImageFileName(tt.name); got != tt.want {
+				t.Errorf("ImageFileName(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanImageReferencesUseSlashes(t *testing.T) {
+	g := testGenerator(newMemFS(nil))
+	r, err := g.Plan(Post{
+		Layout: "post",
+		Title:  "Hello",
+		Slug:   "hello",
+		Images: []Image{
+			{Name: `C:\shots\First Shot.png`},
+			{Name: filepath.Join("shots", "second.png")},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"docs/assets/images/2024-05-01-hello/first-shot.png",
+		"docs/assets/images/2024-05-01-hello/second.png",
+	}
+	if strings.Join(r.Images, " ") != strings.Join(want, " ") {
+		t.Errorf("images = %q, want %q", r.Images, want)
+	}
+	for _, ref := range []string{
+		"![first-shot](/assets/images/2024-05-01-hello/first-shot.png)",
+		"![second](/assets/images/2024-05-01-hello/second.png)",
+	} {
+		if !strings.Contains(string(r.Content), ref) {
+			t.Errorf("no %s in:\n%s", ref, r.Content)
+		}
+	}
+	refs := g.ImageRefs(r.MarkdownPath, r.Content)
+	if len(refs) != len(want) {
+		t.Fatalf("image refs = %v, want %d", refs, len(want))
+	}
+	for i, ref := range refs {
+		if ref.Path != want[i] {
+			t.Errorf("image ref %d points at %s, want %s", i, ref.Path, want[i])
+		}
+	}
+}
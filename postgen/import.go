@@ -0,0 +1,166 @@
+package postgen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen/frontmatter"
+)
+
+// embedPattern matches Obsidian's ![[file]] and ![[file|size]] embeds.
+var embedPattern = regexp.MustCompile(`!\[\[([^\]|#\n]+)(?:[|#][^\]\n]*)?\]\]`)
+
+// imageExts are the extensions of the files Obsidian embeds that are
+// images, rather than notes.
+var imageExts = []string{".avif", ".bmp", ".gif", ".jpeg", ".jpg", ".png", ".svg", ".webp"}
+
+// localImage is a reference to a local image file. It spans [start, end)
+// of the text it was found in: its path, or the whole of an embed.
+type localImage struct {
+	start, end int
+	ref        string
+	embed      bool
+}
+
+// localImages returns the references of content, outside code, to image
+// files given by a relative path: Markdown images, HTML img tags and
+// Obsidian embeds.
+func localImages(content []byte) []localImage {
+	masked := blank(blank(content, codeBlockPattern), codeSpanPattern)
+	var images []localImage
+	add := func(img localImage) {
+		if u, err := url.Parse(img.ref); err != nil || u.IsAbs() || u.Host != "" || strings.HasPrefix(img.ref, "/") || strings.HasPrefix(img.ref, "#") || strings.HasPrefix(img.ref, "{") {
+			return
+		}
+		images = append(images, img)
+	}
+	for _, m := range markdownImagePattern.FindAllSubmatchIndex(masked, -1) {
+		add(localImage{m[2], m[3], string(content[m[2]:m[3]]), false})
+	}
+	for _, m := range htmlImagePattern.FindAllSubmatchIndex(masked, -1) {
+		for i := 2; i < len(m); i += 2 {
+			if m[i] >= 0 {
+				add(localImage{m[i], m[i+1], string(content[m[i]:m[i+1]]), false})
+			}
+		}
+	}
+	for _, m := range embedPattern.FindAllSubmatchIndex(masked, -1) {
+		ref := strings.TrimSpace(string(content[m[2]:m[3]]))
+		if contains(imageExts, strings.ToLower(path.Ext(ref))) {
+			add(localImage{m[0], m[1], ref, true})
+		}
+	}
+	sort.Slice(images, func(i, j int) bool { return images[i].start < images[j].start })
+	return images
+}
+
+// LocalImages returns the relative paths, as written, of the image files
+// content references, for PlanImport.
+func LocalImages(content []byte) []string {
+	var refs []string
+	for _, img := range localImages(content) {
+		if !contains(refs, img.ref) {
+			refs = append(refs, img.ref)
+		}
+	}
+	return refs
+}
+
+// PlanImport computes a post titled p.Title from content, a Markdown file
+// written outside the site, with or without front matter. The front
+// matter the template generates for p is merged into the file's own,
+// whose keys win unless overwrite is set; the file's date, when kept,
+// dates the post. images maps paths LocalImages returned to the data of
+// the files they reference, which are copied into the post's images
+// folder with their references pointed there; the other references are
+// left alone.
+func (g *Generator) PlanImport(content []byte, images map[string][]byte, p Post, overwrite bool) (Result, error) {
+	doc, err := frontmatter.Parse(content)
+	if errors.Is(err, frontmatter.ErrNoFrontMatter) && !bytes.HasPrefix(content, []byte("---")) {
+		doc, err = &frontmatter.Document{Body: content}, nil
+	}
+	if err != nil {
+		return Result{}, fmt.Errorf("parsing front matter: %w", err)
+	}
+	if p.Date.IsZero() {
+		p.Date = g.Now()
+	}
+	if raw, ok := doc.Raw("date"); ok && !overwrite {
+		date, err := frontmatter.ParseTimeIn(strings.Trim(raw, `"'`), p.Date.Location())
+		if err != nil {
+			return Result{}, fmt.Errorf("parsing the date: %w", err)
+		}
+		p.Date = date
+	}
+	p.Images = nil
+	planned, err := g.Plan(p)
+	if err != nil {
+		return Result{}, err
+	}
+	generated, err := frontmatter.Parse(planned.Content)
+	if err != nil {
+		return Result{}, fmt.Errorf("parsing the generated front matter: %w", err)
+	}
+	doc.Merge(generated, overwrite)
+
+	name := TrimExt(path.Base(planned.MarkdownPath))
+	r := Result{
+		MarkdownPath: planned.MarkdownPath,
+		ImagesPath:   planned.ImagesPath,
+		Slug:         p.Slug,
+		Date:         p.Date,
+	}
+	urls := make(map[string]string)
+	taken := make(map[string]bool)
+	for _, ref := range LocalImages(doc.Body) {
+		data, ok := images[ref]
+		if !ok {
+			continue
+		}
+		base := path.Base(strings.ReplaceAll(ref, "\\", "/"))
+		if unescaped, err := url.PathUnescape(base); err == nil {
+			base = unescaped
+		}
+		file := uniqueName(ImageFileName(base), taken)
+		urls[ref] = "/" + path.Join(g.ImagesURL(), name, file)
+		r.Images = append(r.Images, path.Join(r.ImagesPath, file))
+		r.images = append(r.images, data)
+	}
+	var body bytes.Buffer
+	last := 0
+	for _, img := range localImages(doc.Body) {
+		u, ok := urls[img.ref]
+		if !ok || img.start < last {
+			continue
+		}
+		body.Write(doc.Body[last:img.start])
+		if img.embed {
+			alt := path.Base(strings.ReplaceAll(img.ref, "\\", "/"))
+			u = "![" + strings.TrimSuffix(alt, path.Ext(alt)) + "](" + u + ")"
+		}
+		body.WriteString(u)
+		last = img.end
+	}
+	body.Write(doc.Body[last:])
+	doc.Body = body.Bytes()
+	r.Content = lf(doc.Bytes())
+	return r, nil
+}
+
+// Import creates the post planned by PlanImport.
+func (g *Generator) Import(content []byte, images map[string][]byte, p Post, overwrite bool) (Result, error) {
+	r, err := g.PlanImport(content, images, p, overwrite)
+	if err != nil {
+		return Result{}, err
+	}
+	if err := g.apply(r); err != nil {
+		return Result{}, err
+	}
+	return r, nil
+}
@@ -0,0 +1,101 @@
+package postgen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen/frontmatter"
+)
+
+// IndexMarker separates the hand-written preamble of the posts index
+// from the list generated below it.
+const IndexMarker = "<!-- postgen:index -->"
+
+// uncategorized is the group of the posts without categories.
+const uncategorized = "Uncategorized"
+
+// IndexOptions configures PlanIndex.
+type IndexOptions struct {
+	// Layout and Title set the front matter of a new index page.
+	Layout string
+	Title  string
+	// Permalink is the site's permalink setting, as passed to Permalink,
+	// and BaseURL its baseurl, prefixed to the links.
+	Permalink string
+	BaseURL   string
+}
+
+// PlanIndex computes the page at p listing every published post grouped
+// by category, categories in alphabetical order and posts newest first,
+// a post appearing under each of its categories. The content above
+// IndexMarker in an existing page is kept; a new page gets a front matter
+// block instead. OldContent is nil when p does not exist, and equal to
+// NewContent when it is up to date.
+func (g *Generator) PlanIndex(p string, opts IndexOptions) (Change, error) {
+	entries, bad, err := g.List()
+	if err != nil {
+		return Change{}, err
+	}
+	if len(bad) > 0 {
+		return Change{}, bad[0]
+	}
+	groups := make(map[string][]Entry)
+	for _, e := range published(entries) {
+		if len(e.Meta.Categories) == 0 {
+			groups[uncategorized] = append(groups[uncategorized], e)
+		}
+		var seen []string
+		for _, c := range e.Meta.Categories {
+			if !contains(seen, c) {
+				seen = append(seen, c)
+				groups[c] = append(groups[c], e)
+			}
+		}
+	}
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		if name != uncategorized {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if a, b := strings.ToLower(names[i]), strings.ToLower(names[j]); a != b {
+			return a < b
+		}
+		return names[i] < names[j]
+	})
+	if _, ok := groups[uncategorized]; ok {
+		names = append(names, uncategorized)
+	}
+
+	old, err := g.FS.ReadFile(p)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return Change{}, fmt.Errorf("reading file %s: %w", p, err)
+	}
+	var b bytes.Buffer
+	switch preamble, _, found := bytes.Cut(old, []byte(IndexMarker)); {
+	case old == nil:
+		fmt.Fprintf(&b, "---\nlayout: %s\ntitle:  %s\n---\n\n", opts.Layout, frontmatter.String(opts.Title))
+	case found:
+		b.Write(preamble)
+	default:
+		b.Write(old)
+		if !bytes.HasSuffix(old, []byte("\n")) {
+			b.WriteByte('\n')
+		}
+		b.WriteByte('\n')
+	}
+	b.WriteString(IndexMarker + "\n")
+	baseURL := strings.TrimSuffix(opts.BaseURL, "/")
+	for _, name := range names {
+		fmt.Fprintf(&b, "\n## %s\n\n", name)
+		for _, e := range groups[name] {
+			fmt.Fprintf(&b, "- %s [%s](%s%s)\n", e.Date.Format(FileDateLayout), escapeLinkText(e.Meta.Title), baseURL, Permalink(opts.Permalink, e))
+		}
+	}
+	return Change{Path: p, OldContent: old, NewContent: b.Bytes()}, nil
+}
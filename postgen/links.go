@@ -0,0 +1,412 @@
+package postgen
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen/frontmatter"
+)
+
+// LinkOptions describes how the site serves its pages, for CheckLinks.
+type LinkOptions struct {
+	// Permalink is the site's permalink setting, as passed to Permalink.
+	Permalink string
+	// BaseURL is the site's baseurl, stripped from links before they are
+	// resolved.
+	BaseURL string
+	// SiteURL is the site's url; absolute links to it are checked like
+	// root-relative ones.
+	SiteURL string
+	// Generated lists URLs served without a source file, such as the
+	// feeds and sitemaps written by plugins.
+	Generated []string
+}
+
+// Link is a link found in a post body.
+type Link struct {
+	Post string
+	Line int
+	// URL is the link as written in the post, a post_url tag being
+	// written as post_url followed by the post name.
+	URL string
+}
+
+// BrokenLink is a link whose target does not exist.
+type BrokenLink struct {
+	Link
+	Reason string
+}
+
+var (
+	codeBlockPattern = regexp.MustCompile("(?ms)^[ \t]*(?:```.*?^[ \t]*```|~~~.*?^[ \t]*~~~)[ \t]*$" +
+		`|\{%-?\s*(?:highlight|raw)\b.*?\{%-?\s*end(?:highlight|raw)\s*-?%\}`)
+	codeSpanPattern     = regexp.MustCompile("`[^`\n]+`")
+	markdownLinkPattern = regexp.MustCompile(`\[[^\]]*\]\(\s*<?([^)\s>]+)`)
+	referencePattern    = regexp.MustCompile(`(?m)^[ \t]{0,3}\[[^\]]+\]:[ \t]*<?([^\s>]+)`)
+	htmlLinkPattern     = regexp.MustCompile(`(?i)<a\b[^>]*?\bhref\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))`)
+	postURLPattern      = regexp.MustCompile(`\{%-?\s*post_url\s+(\S+?)\s*-?%\}(#[^\s)"'<>]*)?`)
+	headingPattern      = regexp.MustCompile(`(?m)^(#{1,6})[ \t]+(.*?)(?:[ \t]+#+)?[ \t]*$`)
+	headingIDPattern    = regexp.MustCompile(`\{:?[ \t]*#([^\s}]+)[^}]*\}[ \t]*$`)
+	idPattern           = regexp.MustCompile(`(?i)\b(?:id|name)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+	inlineMarkupPattern = regexp.MustCompile("!?\\[([^\\]]*)\\]\\([^)]*\\)|<[^>]*>|[*`~]")
+)
+
+// blank replaces what pattern matches in content with spaces, keeping line
+// breaks so that offsets still map to the same lines.
+func blank(content []byte, pattern *regexp.Regexp) []byte {
+	return pattern.ReplaceAllFunc(content, func(code []byte) []byte {
+		return bytes.Map(func(r rune) rune {
+			if r == '\n' {
+				return r
+			}
+			return ' '
+		}, code)
+	})
+}
+
+// Links returns the links written in content as Markdown links, reference
+// definitions, HTML anchors or post_url tags. Images and links inside code
+// are left out.
+func Links(post string, content []byte) []Link {
+	content = blank(blank(content, codeBlockPattern), codeSpanPattern)
+	var links []Link
+	add := func(offset int, raw string) {
+		links = append(links, Link{
+			Post: post,
+			Line: bytes.Count(content[:offset], []byte("\n")) + 1,
+			URL:  raw,
+		})
+	}
+	for _, m := range markdownLinkPattern.FindAllSubmatchIndex(content, -1) {
+		if m[0] > 0 && content[m[0]-1] == '!' {
+			continue
+		}
+		if raw := string(content[m[2]:m[3]]); !strings.HasPrefix(raw, "{%") {
+			add(m[0], raw)
+		}
+	}
+	for _, m := range referencePattern.FindAllSubmatchIndex(content, -1) {
+		if raw := string(content[m[2]:m[3]]); !strings.HasPrefix(raw, "{%") {
+			add(m[0], raw)
+		}
+	}
+	for _, m := range htmlLinkPattern.FindAllSubmatchIndex(content, -1) {
+		for i := 2; i < len(m); i += 2 {
+			if m[i] < 0 {
+				continue
+			}
+			if raw := string(content[m[i]:m[i+1]]); !strings.HasPrefix(raw, "{%") {
+				add(m[0], raw)
+			}
+		}
+	}
+	for _, m := range postURLPattern.FindAllSubmatchIndex(content, -1) {
+		raw := "post_url " + string(content[m[2]:m[3]])
+		if m[4] >= 0 {
+			raw += string(content[m[4]:m[5]])
+		}
+		add(m[0], raw)
+	}
+	sort.SliceStable(links, func(i, j int) bool { return links[i].Line < links[j].Line })
+	return links
+}
+
+// HeadingID returns the id Jekyll's default Markdown converter, kramdown
+// with GFM input, gives a heading with the given text.
+func HeadingID(text string) string {
+	text = inlineMarkupPattern.ReplaceAllString(text, "$1")
+	var b strings.Builder
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case r == ' ' || r == '\t':
+			b.WriteRune('-')
+		case r == '-' || r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r):
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Heading is an ATX heading of a Markdown document.
+type Heading struct {
+	Level int
+	// Text is the heading as written, without its {#id} attribute.
+	Text string
+	// ID is the id set with {#id}, or else the one kramdown generates,
+	// numbered -1, -2 for repeated ones.
+	ID string
+}
+
+// Headings returns the headings of content outside code blocks.
+func Headings(content []byte) []Heading {
+	var headings []Heading
+	seen := make(map[string]int)
+	for _, m := range headingPattern.FindAllSubmatch(blank(content, codeBlockPattern), -1) {
+		h := Heading{Level: len(m[1]), Text: string(m[2])}
+		if id := headingIDPattern.FindStringSubmatchIndex(h.Text); id != nil {
+			h.ID = h.Text[id[2]:id[3]]
+			h.Text = strings.TrimSpace(h.Text[:id[0]])
+			headings = append(headings, h)
+			continue
+		}
+		h.ID = HeadingID(h.Text)
+		if n := seen[h.ID]; n > 0 {
+			seen[h.ID]++
+			h.ID += "-" + strconv.Itoa(n)
+		} else {
+			seen[h.ID] = 1
+		}
+		headings = append(headings, h)
+	}
+	return headings
+}
+
+// Anchors returns the fragment identifiers content defines: its headings'
+// ids, explicit {#id} attributes and HTML id and name attributes.
+func Anchors(content []byte) map[string]bool {
+	anchors := make(map[string]bool)
+	for _, h := range Headings(content) {
+		anchors[h.ID] = true
+	}
+	for _, m := range idPattern.FindAllSubmatch(blank(content, codeBlockPattern), -1) {
+		anchors[string(m[1])+string(m[2])] = true
+	}
+	return anchors
+}
+
+// anchors caches the fragment identifiers of the files links point to,
+// safely for concurrent use.
+type anchors struct {
+	fs    FS
+	mu    sync.Mutex
+	files map[string]map[string]bool
+}
+
+func (a *anchors) has(p, fragment string) (bool, error) {
+	a.mu.Lock()
+	ids, ok := a.files[p]
+	a.mu.Unlock()
+	if !ok {
+		content, err := a.fs.ReadFile(p)
+		if err != nil {
+			return false, fmt.Errorf("reading file %s: %w", p, err)
+		}
+		ids = Anchors(content)
+		a.mu.Lock()
+		a.files[p] = ids
+		a.mu.Unlock()
+	}
+	return ids[fragment], nil
+}
+
+// urlKey normalizes a URL path so that the forms Jekyll serves a page at,
+// with or without a trailing slash, .html or index.html, compare equal.
+func urlKey(p string) string {
+	p = strings.TrimSuffix(path.Clean("/"+p), "/index.html")
+	p = strings.TrimSuffix(p, ".html")
+	return strings.TrimSuffix(p, "/")
+}
+
+// pageURL returns the URL Jekyll serves the page at p, relative to the
+// source directory, at when its front matter sets no permalink.
+func pageURL(p string) string {
+	p = strings.TrimSuffix(p, path.Ext(p)) + ".html"
+	if path.Base(p) == "index.html" {
+		return "/" + path.Dir(p) + "/"
+	}
+	return "/" + p
+}
+
+// sitePages maps the URL of every page in the source directory to its
+// path.
+func (g *Generator) sitePages() (map[string]string, error) {
+	source := path.Dir(g.PostsDir)
+	pages := make(map[string]string)
+	err := g.walkPages(func(p string, doc *frontmatter.Document) error {
+		var meta struct {
+			Permalink string `yaml:"permalink"`
+		}
+		doc.Decode(&meta)
+		rel := strings.TrimPrefix(p, source+"/")
+		if source == "." {
+			rel = p
+		}
+		u := meta.Permalink
+		if u == "" {
+			u = pageURL(rel)
+		}
+		pages[urlKey(u)] = p
+		return nil
+	})
+	return pages, err
+}
+
+// walkPages calls fn with every page in the source directory, that is
+// every Markdown or HTML file with front matter outside the folders Jekyll
+// treats specially, and its front matter, in lexical order. The front
+// matter is shared with the corpus and must not be modified.
+func (g *Generator) walkPages(fn func(p string, doc *frontmatter.Document) error) error {
+	source := path.Dir(g.PostsDir)
+	err := fs.WalkDir(g.FS, source, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if p != source && (strings.HasPrefix(name, "_") || strings.HasPrefix(name, ".") || name == "vendor" || name == "node_modules" || p == g.ImagesDir) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !IsPostFile(name) && path.Ext(name) != ".html" {
+			return nil
+		}
+		page := g.corpus().Read(p)
+		if page.Content == nil {
+			return page.Err
+		}
+		if page.Err != nil {
+			// Without front matter the file is copied as is.
+			return nil
+		}
+		return fn(p, page.Doc)
+	})
+	if err != nil {
+		return fmt.Errorf("reading folder %s: %w", source, err)
+	}
+	return nil
+}
+
+// CheckLinks resolves the internal links of every post and draft against
+// the posts' permalinks and redirects, the site's pages and its static
+// files, returning the broken ones. Fragments are checked against the
+// headings of the target. Links to other sites and relative links are not
+// checked. Posts whose front matter cannot be parsed are reported in the
+// returned FileErrors and cannot be linked to. Interrupted through ctx,
+// it returns the broken links of the posts checked so far.
+func (g *Generator) CheckLinks(ctx context.Context, opts LinkOptions) ([]BrokenLink, []*FileError, error) {
+	entries, bad, err := g.List()
+	if err != nil {
+		return nil, nil, err
+	}
+	targets, err := g.sitePages()
+	if err != nil {
+		return nil, nil, err
+	}
+	posts := make(map[string]string)
+	for _, e := range entries {
+		if e.Meta.Published != nil && !*e.Meta.Published {
+			continue
+		}
+		posts[TrimExt(strings.TrimPrefix(e.Path, g.PostsDir+"/"))] = e.Path
+		targets[urlKey(Permalink(opts.Permalink, e))] = e.Path
+		for _, u := range e.Meta.RedirectFrom {
+			targets[urlKey(u)] = e.Path
+		}
+	}
+	generated := make(map[string]bool)
+	for _, u := range opts.Generated {
+		generated[urlKey(u)] = true
+	}
+	var siteURL *url.URL
+	if opts.SiteURL != "" {
+		if siteURL, err = url.Parse(opts.SiteURL); err != nil {
+			return nil, nil, fmt.Errorf("parsing site url %s: %w", opts.SiteURL, err)
+		}
+	}
+	baseURL := strings.TrimSuffix(path.Clean("/"+opts.BaseURL), "/")
+	source := path.Dir(g.PostsDir)
+	cache := &anchors{fs: g.FS, files: make(map[string]map[string]bool)}
+
+	loaded, err := g.loadFiles()
+	if err != nil {
+		return nil, nil, err
+	}
+	files := make([]string, len(loaded))
+	for i, post := range loaded {
+		files[i] = post.Path
+	}
+	found := make([][]BrokenLink, len(files))
+	errs := make([]error, len(files))
+	n, interrupted := g.run(ctx, files, func(i int) {
+		if loaded[i].Content == nil {
+			errs[i] = fmt.Errorf("reading file %s: %w", files[i], loaded[i].Err)
+			return
+		}
+		for _, link := range Links(files[i], loaded[i].Content) {
+			var (
+				target   string
+				fragment string
+				reason   string
+			)
+			if name, ok := strings.CutPrefix(link.URL, "post_url "); ok {
+				name, fragment, _ = strings.Cut(name, "#")
+				if target, ok = posts[name]; !ok {
+					reason = "no post named " + name
+				}
+			} else {
+				u, err := url.Parse(liquidPrefixPattern.ReplaceAllString(strings.TrimSpace(link.URL), ""))
+				if err != nil {
+					// Not something a browser would resolve within the
+					// site either.
+					continue
+				}
+				internal := u.Scheme == "" && u.Host == "" && u.Opaque == ""
+				if siteURL != nil && u.Host != "" && strings.EqualFold(u.Host, siteURL.Host) {
+					internal = true
+				}
+				if !internal || (u.Path != "" && !strings.HasPrefix(u.Path, "/")) {
+					continue
+				}
+				fragment = u.Fragment
+				switch rel := strings.TrimPrefix(u.Path, baseURL); {
+				case u.Path == "":
+					target = link.Post
+				case !strings.HasPrefix(u.Path, baseURL+"/") && u.Path != baseURL:
+					reason = "outside the site's baseurl " + opts.BaseURL
+				case targets[urlKey(rel)] != "":
+					target = targets[urlKey(rel)]
+				case generated[urlKey(rel)]:
+				default:
+					if _, err := g.FS.Stat(path.Join(source, rel)); err != nil {
+						reason = "no post, page or file at " + rel
+					}
+				}
+			}
+			if reason == "" && fragment != "" && target != "" {
+				ok, err := cache.has(target, fragment)
+				if err != nil {
+					errs[i] = err
+					return
+				}
+				if !ok {
+					reason = "no heading #" + fragment + " in " + target
+				}
+			}
+			if reason != "" {
+				found[i] = append(found[i], BrokenLink{link, reason})
+			}
+		}
+	})
+	var broken []BrokenLink
+	for i := range found[:n] {
+		if errs[i] != nil {
+			return nil, nil, errs[i]
+		}
+		broken = append(broken, found[i]...)
+	}
+	if interrupted != nil {
+		return broken, bad, interrupted
+	}
+	return broken, bad, nil
+}
@@ -0,0 +1,479 @@
+package postgen
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen/frontmatter"
+)
+
+// LintRule is a check of post bodies.
+type LintRule struct {
+	Name        string
+	Description string
+	check       func(lines []lintLine) []LintProblem
+}
+
+// LintProblem is a violation of a LintRule.
+type LintProblem struct {
+	Post    string
+	Line    int
+	Rule    string
+	Message string
+}
+
+func (p LintProblem) String() string {
+	return fmt.Sprintf("%s:%d: %s: %s", p.Post, p.Line, p.Rule, p.Message)
+}
+
+// lintLine is a line of a post body.
+type lintLine struct {
+	n    int
+	text string
+	// code is set for the lines inside a code block, fences excluded.
+	code bool
+	// fence is the info string of an opening fence, and fenced whether
+	// the line opens a fenced code block at all.
+	fence  string
+	fenced bool
+}
+
+// LintRules lists the rules Lint applies, in the order they are reported.
+var LintRules = []LintRule{
+	{"h1", "the body has an H1, which the layout already renders from the title", lintH1},
+	{"heading-increment", "a heading is more than one level deeper than the previous one", lintHeadingIncrement},
+	{"fence-language", "a fenced code block has no language", lintFenceLanguage},
+	{"bare-url", "a URL is written as plain text instead of as a link", lintBareURL},
+	{"trailing-whitespace", "a line ends with spaces or tabs", lintTrailingWhitespace},
+	{"references", "a reference link has no definition, or a definition is unused or repeated", lintReferences},
+	{"footnotes", "a footnote has no body, or a footnote body is unreferenced or repeated", lintFootnotes},
+	{"image-alt", "an image has empty alt text, or an img tag no alt attribute", lintImageAlt},
+	{"link-text", "a link's text is \"here\" or a bare URL, which says nothing out of context", lintLinkText},
+}
+
+// LintDisableMarker, followed by rule names, disables them for the post
+// whose body has it, or every rule without names.
+const LintDisableMarker = "postgen:lint-disable"
+
+var (
+	atxHeadingPattern = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]|$)`)
+	fencePattern      = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})[ \t]*([^`\\s]*)")
+	highlightPattern  = regexp.MustCompile(`^\s*\{%-?\s*(end)?highlight\b`)
+	bareURLPattern    = regexp.MustCompile(`https?://[^\s<>"'()\[\]]+`)
+	// linkedPattern matches the constructs a URL may appear in without
+	// being bare: code spans, links, images, autolinks, HTML anchors and
+	// tags, and reference definitions.
+	linkedPattern = regexp.MustCompile("`[^`]*`" + `|!?\[[^\]]*\]\([^)]*\)|<https?://[^>]*>|(?i:<a\b.*?</a>)|<[^>]*>|^\s*\[[^\]]+\]:.*`)
+	// definitionPattern matches a reference definition or, its label
+	// starting with ^, a footnote body; bracketPattern a bracketed text
+	// optionally followed by a reference label.
+	definitionPattern = regexp.MustCompile(`^ {0,3}\[((?:[^\[\]\\]|\\.)+)\]:`)
+	bracketPattern    = regexp.MustCompile(`\[((?:[^\[\]\\]|\\.)*)\](?:\[((?:[^\[\]\\]|\\.)*)\])?`)
+	// literalPattern matches what holds no reference: code spans, inline
+	// links and images, escaped brackets, Liquid markup and kramdown
+	// abbreviation definitions.
+	literalPattern = regexp.MustCompile("`[^`]*`" + `|\]\([^)]*\)|\\[\[\]]|\{%.*?%\}|\{\{.*?\}\}|^ {0,3}\*\[.*`)
+	// altPattern matches a Markdown image, capturing its alt text and
+	// URL; imgTagPattern an img tag and altAttrPattern its alt.
+	altPattern     = regexp.MustCompile(`!\[([^\]]*)\]\(\s*<?([^)\s>]+)[^)]*\)`)
+	imgTagPattern  = regexp.MustCompile(`(?i)<img\b[^>]*>`)
+	altAttrPattern = regexp.MustCompile(`(?i)\salt\s*=`)
+	// linkTextPattern matches a Markdown link or an HTML anchor,
+	// capturing its text.
+	linkTextPattern = regexp.MustCompile(`\[([^\]]+)\]\([^)]*\)|(?i:<a\b[^>]*>(.*?)</a>)`)
+	// lintDisablePattern matches a LintDisableMarker comment, capturing
+	// the rule names.
+	lintDisablePattern = regexp.MustCompile(`<!--\s*` + LintDisableMarker + `((?:\s+[\w-]+)*)\s*-->`)
+)
+
+// vagueLinkTexts are the link texts lintLinkText reports, lowercase.
+var vagueLinkTexts = map[string]bool{"here": true, "click here": true, "this link": true, "link": true}
+
+// Lint checks the body of the post at post, whose content is given,
+// against every rule not in disabled.
+func Lint(post string, content []byte, disabled map[string]bool) []LintProblem {
+	lines := lintLines(content)
+	off := lintDisabled(lines)
+	var problems []LintProblem
+	for _, rule := range LintRules {
+		if disabled[rule.Name] || off[rule.Name] || off[LintDisableMarker] {
+			continue
+		}
+		for _, p := range rule.check(lines) {
+			p.Post, p.Rule = post, rule.Name
+			problems = append(problems, p)
+		}
+	}
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].Line < problems[j].Line })
+	return problems
+}
+
+// lintLines splits the body of content into lines numbered as in
+// content, marking the ones inside code blocks.
+func lintLines(content []byte) []lintLine {
+	body := content
+	first := 1
+	if doc, err := frontmatter.Parse(content); err == nil {
+		body = doc.Body
+		first += bytes.Count(content[:len(content)-len(body)], []byte("\n"))
+	}
+	var lines []lintLine
+	var open string
+	inHighlight := false
+	for i, text := range strings.Split(strings.TrimSuffix(string(body), "\n"), "\n") {
+		l := lintLine{n: first + i, text: strings.TrimSuffix(text, "\r")}
+		m := fencePattern.FindStringSubmatch(l.text)
+		switch {
+		case open != "":
+			if isClosingFence(l.text, open) {
+				open = ""
+			} else {
+				l.code = true
+			}
+		case inHighlight:
+			if hm := highlightPattern.FindStringSubmatch(l.text); hm != nil && hm[1] != "" {
+				inHighlight = false
+			} else {
+				l.code = true
+			}
+		case m != nil:
+			open, l.fence, l.fenced = m[1], m[2], true
+		case highlightPattern.MatchString(l.text):
+			inHighlight = true
+		}
+		lines = append(lines, l)
+	}
+	return lines
+}
+
+// LintFiles runs Lint over each file in paths.
+func (g *Generator) LintFiles(paths []string, disabled map[string]bool) ([]LintProblem, error) {
+	var problems []LintProblem
+	for _, p := range paths {
+		content, err := g.FS.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("reading file %s: %w", p, err)
+		}
+		problems = append(problems, Lint(p, content, disabled)...)
+	}
+	return problems, nil
+}
+
+func headingLevel(l lintLine) int {
+	if l.code || l.fenced {
+		return 0
+	}
+	if m := atxHeadingPattern.FindStringSubmatch(l.text); m != nil {
+		return len(m[1])
+	}
+	return 0
+}
+
+func lintH1(lines []lintLine) []LintProblem {
+	var problems []LintProblem
+	for _, l := range lines {
+		if headingLevel(l) == 1 {
+			problems = append(problems, LintProblem{Line: l.n, Message: "H1 in the body; start sections at H2"})
+		}
+	}
+	return problems
+}
+
+func lintHeadingIncrement(lines []lintLine) []LintProblem {
+	var problems []LintProblem
+	// The layout renders the title as the H1.
+	previous := 1
+	for _, l := range lines {
+		level := headingLevel(l)
+		if level == 0 {
+			continue
+		}
+		if level > previous+1 {
+			problems = append(problems, LintProblem{Line: l.n, Message: fmt.Sprintf("H%d follows H%d", level, previous)})
+		}
+		previous = level
+	}
+	return problems
+}
+
+func lintFenceLanguage(lines []lintLine) []LintProblem {
+	var problems []LintProblem
+	for _, l := range lines {
+		if l.fenced && l.fence == "" {
+			problems = append(problems, LintProblem{Line: l.n, Message: "fenced code block without a language"})
+		}
+	}
+	return problems
+}
+
+func lintBareURL(lines []lintLine) []LintProblem {
+	var problems []LintProblem
+	for _, l := range lines {
+		if l.code || l.fenced {
+			continue
+		}
+		for _, u := range bareURLPattern.FindAllString(linkedPattern.ReplaceAllString(l.text, " "), -1) {
+			// Trailing punctuation ends the sentence, not the URL.
+			u = strings.TrimRight(u, ".,:;!?")
+			problems = append(problems, LintProblem{Line: l.n, Message: fmt.Sprintf("bare URL %s; write it as <%s> or a link", u, u)})
+		}
+	}
+	return problems
+}
+
+func lintTrailingWhitespace(lines []lintLine) []LintProblem {
+	var problems []LintProblem
+	for _, l := range lines {
+		if trimmed := strings.TrimRight(l.text, " \t"); trimmed != l.text {
+			problems = append(problems, LintProblem{Line: l.n, Message: "trailing whitespace"})
+		}
+	}
+	return problems
+}
+
+func lintReferences(lines []lintLine) []LintProblem {
+	return lintLabels(lines, false)
+}
+
+func lintFootnotes(lines []lintLine) []LintProblem {
+	return lintLabels(lines, true)
+}
+
+// lintLabels matches the uses of reference labels to their definitions,
+// or with footnotes the footnote markers to the footnote bodies. Labels
+// are compared as CommonMark does, ignoring case and collapsing
+// whitespace. A shortcut reference, [label] alone, counts as one only
+// when label is defined, being literal text otherwise.
+func lintLabels(lines []lintLine, footnotes bool) []LintProblem {
+	kind := "reference"
+	if footnotes {
+		kind = "footnote"
+	}
+	type label struct {
+		text     string
+		line     int
+		shortcut bool
+	}
+	var uses, definitions []label
+	defined := make(map[string]int)
+	var problems []LintProblem
+	for _, l := range lines {
+		if l.code || l.fenced {
+			continue
+		}
+		text := l.text
+		if m := definitionPattern.FindStringSubmatchIndex(text); m != nil {
+			name := text[m[2]:m[3]]
+			if strings.HasPrefix(name, "^") == footnotes {
+				if first, ok := defined[referenceLabel(name)]; ok {
+					problems = append(problems, LintProblem{Line: l.n, Message: fmt.Sprintf("%s [%s] is defined again, first on line %d", kind, name, first)})
+				} else {
+					defined[referenceLabel(name)] = l.n
+					definitions = append(definitions, label{name, l.n, false})
+				}
+			}
+			if !strings.HasPrefix(name, "^") {
+				// The rest of a reference definition is its URL and title.
+				continue
+			}
+			// The rest of a footnote body is text that may use labels.
+			text = strings.Repeat(" ", m[1]) + text[m[1]:]
+		}
+		masked := literalPattern.ReplaceAllStringFunc(text, func(m string) string { return strings.Repeat(" ", len(m)) })
+		for _, m := range bracketPattern.FindAllStringSubmatchIndex(masked, -1) {
+			inner := text[m[2]:m[3]]
+			switch {
+			case strings.HasPrefix(inner, "^") != footnotes:
+			case footnotes:
+				uses = append(uses, label{inner, l.n, false})
+			case m[4] < 0:
+				uses = append(uses, label{inner, l.n, true})
+			case m[5] > m[4]:
+				uses = append(uses, label{text[m[4]:m[5]], l.n, false})
+			default:
+				// A collapsed reference, [label][].
+				uses = append(uses, label{inner, l.n, false})
+			}
+		}
+	}
+	used := make(map[string]bool)
+	for _, u := range uses {
+		key := referenceLabel(u.text)
+		if _, ok := defined[key]; ok {
+			used[key] = true
+		} else if !u.shortcut && key != "" {
+			problems = append(problems, LintProblem{Line: u.line, Message: fmt.Sprintf("%s [%s] is not defined", kind, u.text)})
+		}
+	}
+	for _, d := range definitions {
+		if !used[referenceLabel(d.text)] {
+			problems = append(problems, LintProblem{Line: d.line, Message: fmt.Sprintf("%s [%s] is never used", kind, d.text)})
+		}
+	}
+	return problems
+}
+
+// referenceLabel normalizes a reference label as CommonMark matches them.
+func referenceLabel(label string) string {
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
+
+// lintDisabled returns the rules the LintDisableMarker comments of lines
+// disable, or LintDisableMarker itself for a comment naming none.
+func lintDisabled(lines []lintLine) map[string]bool {
+	off := make(map[string]bool)
+	for _, l := range lines {
+		if l.code {
+			continue
+		}
+		for _, m := range lintDisablePattern.FindAllStringSubmatch(l.text, -1) {
+			names := strings.Fields(m[1])
+			if len(names) == 0 {
+				off[LintDisableMarker] = true
+			}
+			for _, name := range names {
+				off[name] = true
+			}
+		}
+	}
+	return off
+}
+
+func lintImageAlt(lines []lintLine) []LintProblem {
+	var problems []LintProblem
+	for _, l := range lines {
+		if l.code || l.fenced {
+			continue
+		}
+		text := codeSpanPattern.ReplaceAllString(l.text, "")
+		for _, m := range altPattern.FindAllStringSubmatch(text, -1) {
+			if strings.TrimSpace(m[1]) == "" {
+				problems = append(problems, LintProblem{Line: l.n, Message: fmt.Sprintf("image %s has no alt text", m[2])})
+			}
+		}
+		for _, tag := range imgTagPattern.FindAllString(text, -1) {
+			if !altAttrPattern.MatchString(tag) {
+				problems = append(problems, LintProblem{Line: l.n, Message: fmt.Sprintf("%s has no alt attribute", tag)})
+			}
+		}
+	}
+	return problems
+}
+
+func lintLinkText(lines []lintLine) []LintProblem {
+	var problems []LintProblem
+	for _, l := range lines {
+		if l.code || l.fenced {
+			continue
+		}
+		text := codeSpanPattern.ReplaceAllString(l.text, "")
+		for _, m := range linkTextPattern.FindAllStringSubmatchIndex(text, -1) {
+			// An image is not a link, and the text of an image link is the
+			// image, whose alt text image-alt checks.
+			if m[0] > 0 && text[m[0]-1] == '!' {
+				continue
+			}
+			label := ""
+			for i := 2; i < len(m); i += 2 {
+				if m[i] >= 0 {
+					label = strings.TrimSpace(text[m[i]:m[i+1]])
+				}
+			}
+			switch {
+			case vagueLinkTexts[strings.ToLower(strings.Trim(label, ".!"))]:
+				problems = append(problems, LintProblem{Line: l.n, Message: fmt.Sprintf("link text \"%s\" does not say where the link goes", label)})
+			case label != "" && bareURLPattern.FindString(label) == label:
+				problems = append(problems, LintProblem{Line: l.n, Message: fmt.Sprintf("link text %s is a bare URL; say where the link goes", label)})
+			}
+		}
+	}
+	return problems
+}
+
+// FixAltText gives each Markdown image with empty alt text, and each img
+// tag without an alt attribute, the humanized name of its file as a
+// placeholder alt, leaving code alone. It returns the number of images
+// fixed.
+func FixAltText(body []byte) ([]byte, int) {
+	masked := blank(blank(body, codeBlockPattern), codeSpanPattern)
+	type insert struct {
+		at   int
+		text string
+	}
+	var inserts []insert
+	for _, m := range altPattern.FindAllSubmatchIndex(masked, -1) {
+		if len(bytes.TrimSpace(body[m[2]:m[3]])) == 0 {
+			inserts = append(inserts, insert{m[2], humanizeFileName(string(body[m[4]:m[5]]))})
+		}
+	}
+	for _, m := range imgTagPattern.FindAllIndex(masked, -1) {
+		tag := body[m[0]:m[1]]
+		if altAttrPattern.Match(tag) {
+			continue
+		}
+		src := ""
+		if s := htmlImagePattern.FindSubmatch(tag); s != nil {
+			src = string(bytes.Join(s[1:], nil))
+		}
+		inserts = append(inserts, insert{m[0] + len("<img"), ` alt="` + html.EscapeString(humanizeFileName(src)) + `"`})
+	}
+	sort.Slice(inserts, func(i, j int) bool { return inserts[i].at < inserts[j].at })
+	var b bytes.Buffer
+	last := 0
+	for _, in := range inserts {
+		b.Write(body[last:in.at])
+		b.WriteString(in.text)
+		last = in.at
+	}
+	b.Write(body[last:])
+	return b.Bytes(), len(inserts)
+}
+
+// humanizeFileName turns the file name of the image URL u into words,
+// such as "Request flow" for /images/request_flow.png.
+func humanizeFileName(u string) string {
+	name := path.Base(strings.SplitN(strings.SplitN(u, "?", 2)[0], "#", 2)[0])
+	if unescaped, err := url.PathUnescape(name); err == nil {
+		name = unescaped
+	}
+	words := strings.Fields(strings.NewReplacer("-", " ", "_", " ", ".", " ", "+", " ").Replace(strings.TrimSuffix(name, path.Ext(name))))
+	if len(words) == 0 {
+		return "Image"
+	}
+	s := strings.Join(words, " ")
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+// PlanFixAlt computes running FixAltText over the bodies of the files in
+// paths, skipping the ones whose lint-disable comment covers image-alt.
+// The int is the number of images fixed.
+func (g *Generator) PlanFixAlt(paths []string) ([]Change, int, error) {
+	var changes []Change
+	fixed := 0
+	for _, p := range paths {
+		doc, content, err := g.readDocument(p)
+		if err != nil {
+			return nil, 0, err
+		}
+		if off := lintDisabled(lintLines(content)); off["image-alt"] || off[LintDisableMarker] {
+			continue
+		}
+		body, n := FixAltText(doc.Body)
+		if n == 0 {
+			continue
+		}
+		doc.Body = body
+		fixed += n
+		changes = append(changes, Change{Path: p, OldContent: content, NewContent: doc.Bytes()})
+	}
+	return changes, fixed, nil
+}
@@ -0,0 +1,205 @@
+package postgen
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen/frontmatter"
+)
+
+// LiquidProblem is a Liquid tag or output that breaks the site build.
+type LiquidProblem struct {
+	Post    string
+	Line    int
+	Message string
+}
+
+func (p LiquidProblem) String() string {
+	return fmt.Sprintf("%s:%d: %s", p.Post, p.Line, p.Message)
+}
+
+// liquidBlocks are the block tags Liquid and Jekyll define, each closed by
+// end<name>; liquidBranches the tags allowed directly inside them.
+var (
+	liquidBlocks = map[string]bool{
+		"if": true, "unless": true, "case": true, "for": true, "tablerow": true,
+		"capture": true, "comment": true, "raw": true, "highlight": true,
+	}
+	liquidBranches = map[string][]string{
+		"else":  {"if", "unless", "case", "for"},
+		"elsif": {"if", "unless"},
+		"when":  {"case"},
+	}
+	liquidVerbatimEnd = map[string]*regexp.Regexp{
+		"raw":     regexp.MustCompile(`\{%-?\s*endraw\s*-?%\}`),
+		"comment": regexp.MustCompile(`\{%-?\s*endcomment\s*-?%\}`),
+	}
+	liquidTagNamePattern = regexp.MustCompile(`(?s)^\{%-?\s*(\w*)\s*(.*?)\s*-?%\}$`)
+)
+
+// liquidBlock is an open block tag.
+type liquidBlock struct {
+	name string
+	line int
+}
+
+// CheckLiquid statically checks the Liquid of the posts and drafts at
+// paths: every tag and output must be closed, block tags balanced and
+// properly nested, post_url tags must name a published post and link tags
+// an existing file of the site. Raw blocks and comments are not looked
+// into.
+func (g *Generator) CheckLiquid(paths []string) ([]LiquidProblem, error) {
+	entries, _, err := g.List()
+	if err != nil {
+		return nil, err
+	}
+	posts := make(map[string]bool)
+	for _, e := range entries {
+		if e.Meta.Published == nil || *e.Meta.Published {
+			posts[TrimExt(strings.TrimPrefix(e.Path, g.PostsDir+"/"))] = true
+		}
+	}
+	source := path.Dir(g.PostsDir)
+	var problems []LiquidProblem
+	for _, p := range paths {
+		content, err := g.FS.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("reading file %s: %w", p, err)
+		}
+		body, first := content, 1
+		if doc, err := frontmatter.Parse(content); err == nil {
+			body = doc.Body
+			first += bytes.Count(content[:len(content)-len(body)], []byte("\n"))
+		}
+		problems = append(problems, checkLiquid(p, body, first, func(kind, arg string) string {
+			switch kind {
+			case "post_url":
+				if name, _, _ := strings.Cut(arg, "#"); !posts[name] {
+					return "no post named " + name
+				}
+			case "link":
+				if !g.exists(path.Join(source, strings.TrimPrefix(arg, "/"))) {
+					return "no file " + arg
+				}
+			}
+			return ""
+		})...)
+	}
+	return problems, nil
+}
+
+// checkLiquid checks body, whose first line is line first of post,
+// asking resolve what is wrong with the argument of its post_url and link
+// tags.
+func checkLiquid(post string, body []byte, first int, resolve func(kind, arg string) string) []LiquidProblem {
+	var problems []LiquidProblem
+	lineOf := func(offset int) int { return first + bytes.Count(body[:offset], []byte("\n")) }
+	report := func(offset int, format string, args ...interface{}) {
+		problems = append(problems, LiquidProblem{Post: post, Line: lineOf(offset), Message: fmt.Sprintf(format, args...)})
+	}
+	var stack []liquidBlock
+	for i := 0; i < len(body); {
+		open := bytes.Index(body[i:], []byte("{"))
+		if open < 0 {
+			break
+		}
+		start := i + open
+		rest := body[start:]
+		switch {
+		case bytes.HasPrefix(rest, []byte("{{")):
+			end := liquidEnd(rest, "}}")
+			if end < 0 {
+				report(start, "output {{ is not closed with }}")
+				i = start + 2
+				continue
+			}
+			if start+end < len(body) && body[start+end] == '}' {
+				report(start, "output %s is followed by a stray }", bytes.TrimSpace(rest[:end]))
+			}
+			i = start + end
+		case bytes.HasPrefix(rest, []byte("{%")):
+			end := liquidEnd(rest, "%}")
+			if end < 0 {
+				report(start, "tag {%% is not closed with %%}")
+				i = start + 2
+				continue
+			}
+			i = start + end
+			m := liquidTagNamePattern.FindSubmatch(rest[:end])
+			if m == nil || len(m[1]) == 0 {
+				report(start, "tag %s has no name", rest[:end])
+				continue
+			}
+			name, arg := string(m[1]), string(m[2])
+			switch {
+			case name == "raw" || name == "comment":
+				// Their content is not Liquid: skip to the closing tag.
+				closing := liquidVerbatimEnd[name].FindIndex(body[i:])
+				if closing == nil {
+					report(start, "%s opened here is never closed", name)
+					i = len(body)
+					continue
+				}
+				i += closing[1]
+			case liquidBlocks[name]:
+				stack = append(stack, liquidBlock{name, lineOf(start)})
+			case strings.HasPrefix(name, "end") && liquidBlocks[name[3:]]:
+				switch opened := name[3:]; {
+				case len(stack) == 0:
+					report(start, "%s closes no %s", name, opened)
+				case stack[len(stack)-1].name != opened:
+					top := stack[len(stack)-1]
+					report(start, "%s closes the %s opened on line %d", name, top.name, top.line)
+					// Recover when the block was merely left open inside
+					// the one being closed.
+					for j := len(stack) - 1; j >= 0; j-- {
+						if stack[j].name == opened {
+							stack = stack[:j]
+							break
+						}
+					}
+				default:
+					stack = stack[:len(stack)-1]
+				}
+			case liquidBranches[name] != nil:
+				if len(stack) == 0 || !contains(liquidBranches[name], stack[len(stack)-1].name) {
+					report(start, "%s outside %s", name, strings.Join(liquidBranches[name], ", "))
+				}
+			case name == "post_url" || name == "link":
+				if arg == "" {
+					report(start, "%s has no argument", name)
+				} else if !strings.Contains(arg, "{{") {
+					if reason := resolve(name, strings.Fields(arg)[0]); reason != "" {
+						report(start, "%s %s: %s", name, strings.Fields(arg)[0], reason)
+					}
+				}
+			}
+		default:
+			i = start + 1
+		}
+	}
+	for _, b := range stack {
+		problems = append(problems, LiquidProblem{Post: post, Line: b.line, Message: fmt.Sprintf("%s opened here is never closed", b.name)})
+	}
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].Line < problems[j].Line })
+	return problems
+}
+
+// liquidEnd returns the offset just past the closing delimiter of the tag
+// or output text starts with, or -1 when another one opens first or there
+// is none.
+func liquidEnd(text []byte, closing string) int {
+	end := bytes.Index(text[2:], []byte(closing))
+	if end < 0 {
+		return -1
+	}
+	inner := text[2 : 2+end]
+	if bytes.Contains(inner, []byte("{{")) || bytes.Contains(inner, []byte("{%")) {
+		return -1
+	}
+	return 2 + end + len(closing)
+}
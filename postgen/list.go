@@ -0,0 +1,174 @@
+package postgen
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen/frontmatter"
+	"github.com/tiagomelo/tiagomelo.github.io/postgen/posts"
+)
+
+// Meta is the subset of front matter postgen understands.
+type Meta struct {
+	Layout     string           `yaml:"layout"`
+	Title      string           `yaml:"title"`
+	Date       frontmatter.Time `yaml:"date"`
+	Categories frontmatter.List `yaml:"categories"`
+	Tags       frontmatter.List `yaml:"tags"`
+	Published  *bool            `yaml:"published"`
+	Permalink  string           `yaml:"permalink"`
+	// RedirectFrom and RedirectTo are the jekyll-redirect-from keys.
+	RedirectFrom frontmatter.List `yaml:"redirect_from"`
+	RedirectTo   string           `yaml:"redirect_to"`
+	Series       string           `yaml:"series"`
+	SeriesPart   int              `yaml:"series_part"`
+	// LastModifiedAt is the jekyll-last-modified-at and jekyll-seo-tag
+	// key for the date of the last update.
+	LastModifiedAt frontmatter.Time `yaml:"last_modified_at"`
+	// CanonicalURL is where the post first appeared, when elsewhere.
+	CanonicalURL string `yaml:"canonical_url"`
+	// Description is the summary search engines and feeds show.
+	Description string `yaml:"description"`
+	// Lang is the language of the post, TranslationOf the slug of the
+	// post it translates or is translated by.
+	Lang          string `yaml:"lang"`
+	TranslationOf string `yaml:"translation_of"`
+	// Related lists the slugs of the posts related writes.
+	Related frontmatter.List `yaml:"related"`
+	// ReviewedAt and Outdated are the keys audit writes: the date the post
+	// was last checked to still hold, and whether it no longer does.
+	ReviewedAt frontmatter.Time `yaml:"reviewed_at"`
+	Outdated   bool             `yaml:"outdated"`
+	// Featured and FeaturedOrder are the keys featured maintains: whether
+	// the homepage lists the post, and where, from 1.
+	Featured      bool `yaml:"featured"`
+	FeaturedOrder int  `yaml:"featured_order"`
+}
+
+// Entry is an existing post read back from disk.
+type Entry struct {
+	Path string
+	Slug string
+	// Date is the front matter date, or the file name date when the
+	// front matter has none.
+	Date time.Time
+	Meta Meta
+}
+
+// FileError is a problem with a single file found while reading many.
+type FileError struct {
+	Path string
+	Err  error
+}
+
+func (e *FileError) Error() string {
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// corpus returns the posts.Corpus the generator reads posts through,
+// which keeps them parsed from one call to the next.
+func (g *Generator) corpus() *posts.Corpus {
+	if g.posts == nil {
+		g.posts = posts.New(g.FS)
+		g.posts.Match = IsPostFile
+		g.posts.Workers = g.Jobs
+	}
+	return g.posts
+}
+
+// loadPosts loads the posts of PostsDir into the corpus, returning them
+// by name.
+func (g *Generator) loadPosts() ([]*posts.Post, error) {
+	c := g.corpus()
+	if err := c.Load(g.PostsDir); err != nil {
+		return nil, err
+	}
+	return c.Posts(), nil
+}
+
+// loadFiles loads the posts of PostsDir and the drafts of DraftsDir, the
+// files Files lists, into the corpus, returning them by folder and name.
+func (g *Generator) loadFiles() ([]*posts.Post, error) {
+	var dirs []string
+	for _, dir := range []string{g.PostsDir, g.DraftsDir} {
+		if _, err := g.FS.Stat(dir); !errors.Is(err, fs.ErrNotExist) {
+			dirs = append(dirs, dir)
+		}
+	}
+	c := g.corpus()
+	if err := c.Load(dirs...); err != nil {
+		return nil, err
+	}
+	return c.Posts(), nil
+}
+
+// List reads every post in PostsDir, newest first. Files whose front
+// matter cannot be parsed are skipped and reported in the returned
+// FileErrors; the error is only set when the folder cannot be read.
+func (g *Generator) List() ([]Entry, []*FileError, error) {
+	loaded, err := g.loadPosts()
+	if err != nil {
+		return nil, nil, err
+	}
+	var (
+		entries []Entry
+		bad     []*FileError
+	)
+	for _, post := range loaded {
+		if post.Err != nil {
+			bad = append(bad, &FileError{Path: post.Path, Err: post.Err})
+			continue
+		}
+		var meta Meta
+		if err := post.Doc.Decode(&meta); err != nil {
+			bad = append(bad, &FileError{Path: post.Path, Err: err})
+			continue
+		}
+		entries = append(entries, newEntry(post.Path, meta))
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].Date.Equal(entries[j].Date) {
+			return entries[i].Date.After(entries[j].Date)
+		}
+		return entries[i].Path > entries[j].Path
+	})
+	return entries, bad, nil
+}
+
+// Read reads the post or draft at p.
+func (g *Generator) Read(p string) (Entry, error) {
+	content, err := g.FS.ReadFile(p)
+	if err != nil {
+		return Entry{}, err
+	}
+	doc, err := frontmatter.Parse(content)
+	if err != nil {
+		return Entry{}, err
+	}
+	var meta Meta
+	if err := doc.Decode(&meta); err != nil {
+		return Entry{}, err
+	}
+	return newEntry(p, meta), nil
+}
+
+// newEntry returns the Entry of the post at p with the front matter meta.
+func newEntry(p string, meta Meta) Entry {
+	fileDate, slug, ok := ParseFileName(path.Base(p))
+	if !ok {
+		slug = strings.TrimSuffix(path.Base(p), path.Ext(p))
+	}
+	date := meta.Date.Time
+	if date.IsZero() {
+		date = fileDate
+	}
+	return Entry{Path: p, Slug: slug, Date: date, Meta: meta}
+}
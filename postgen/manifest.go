@@ -0,0 +1,157 @@
+package postgen
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// ManifestFile is the name of the manifest file in the source directory.
+const ManifestFile = ".post-manifest.json"
+
+// Manifest maps the file names of the published posts, relative to
+// PostsDir, to the SHA-256 of their bodies, front matter excluded so that
+// editing metadata does not change it.
+type Manifest map[string]string
+
+// ManifestDiff is a post whose body differs from its manifest entry.
+type ManifestDiff struct {
+	Post string
+	// Status is changed, added or removed.
+	Status string
+}
+
+// manifestJSON is the layout of the manifest file.
+type manifestJSON struct {
+	Posts Manifest `json:"posts"`
+}
+
+// BodyChecksum returns the manifest checksum of the post or draft at p.
+func (g *Generator) BodyChecksum(p string) (string, error) {
+	doc, _, err := g.readDocument(p)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(doc.Body)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// BuildManifest computes the manifest of the posts published in
+// PostsDir. Posts whose front matter cannot be parsed are reported in the
+// returned FileErrors.
+func (g *Generator) BuildManifest() (Manifest, []*FileError, error) {
+	entries, bad, err := g.List()
+	if err != nil {
+		return nil, nil, err
+	}
+	m := make(Manifest)
+	for _, e := range published(entries) {
+		sum, err := g.BodyChecksum(e.Path)
+		if err != nil {
+			return nil, nil, err
+		}
+		m[g.manifestKey(e.Path)] = sum
+	}
+	return m, bad, nil
+}
+
+// manifestKey returns the manifest key of the post at p.
+func (g *Generator) manifestKey(p string) string {
+	return strings.TrimPrefix(p, g.PostsDir+"/")
+}
+
+// UpdateManifest refreshes the entry of the post at p in m, removing it
+// when the post is not published anymore.
+func (g *Generator) UpdateManifest(m Manifest, p string) error {
+	if g.IsDraft(p) {
+		return fmt.Errorf("%s is a draft, which the manifest does not list", p)
+	}
+	e, err := g.Read(p)
+	if err != nil {
+		return fmt.Errorf("parsing %s: %w", p, err)
+	}
+	if len(published([]Entry{e})) == 0 {
+		delete(m, g.manifestKey(p))
+		return nil
+	}
+	sum, err := g.BodyChecksum(p)
+	if err != nil {
+		return err
+	}
+	m[g.manifestKey(p)] = sum
+	return nil
+}
+
+// CheckManifest compares the published posts with old, a manifest read
+// back, as CompareManifest does. Posts whose front matter cannot be
+// parsed are reported in the returned FileErrors rather than as removed.
+func (g *Generator) CheckManifest(old Manifest) ([]ManifestDiff, []*FileError, error) {
+	current, bad, err := g.BuildManifest()
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, b := range bad {
+		if sum, ok := old[g.manifestKey(b.Path)]; ok {
+			current[g.manifestKey(b.Path)] = sum
+		}
+	}
+	return CompareManifest(old, current), bad, nil
+}
+
+// CompareManifest returns the posts of current whose bodies changed since
+// old was written, those added and those removed, by name.
+func CompareManifest(old, current Manifest) []ManifestDiff {
+	var diffs []ManifestDiff
+	for name, sum := range current {
+		switch was, ok := old[name]; {
+		case !ok:
+			diffs = append(diffs, ManifestDiff{name, "added"})
+		case was != sum:
+			diffs = append(diffs, ManifestDiff{name, "changed"})
+		}
+	}
+	for name := range old {
+		if _, ok := current[name]; !ok {
+			diffs = append(diffs, ManifestDiff{name, "removed"})
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Post < diffs[j].Post })
+	return diffs
+}
+
+// ManifestPath returns the path of the manifest file.
+func (g *Generator) ManifestPath() string {
+	return path.Join(path.Dir(g.PostsDir), ManifestFile)
+}
+
+// ReadManifest reads the manifest file, failing with an error matching
+// fs.ErrNotExist when there is none.
+func (g *Generator) ReadManifest() (Manifest, error) {
+	p := g.ManifestPath()
+	content, err := g.FS.ReadFile(p)
+	if err != nil {
+		return nil, fmt.Errorf("reading manifest %s: %w", p, err)
+	}
+	var m manifestJSON
+	if err := json.Unmarshal(content, &m); err != nil {
+		return nil, fmt.Errorf("parsing manifest %s: %w", p, err)
+	}
+	if m.Posts == nil {
+		m.Posts = make(Manifest)
+	}
+	return m.Posts, nil
+}
+
+// WriteManifest writes m to the manifest file, its entries sorted by name
+// so that the file diffs cleanly.
+func (g *Generator) WriteManifest(m Manifest) error {
+	content, err := json.MarshalIndent(manifestJSON{m}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding manifest: %w", err)
+	}
+	return g.WritePage(Change{Path: g.ManifestPath(), NewContent: append(content, '\n')})
+}
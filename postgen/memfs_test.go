@@ -0,0 +1,221 @@
+package postgen
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// memFS is an in-memory FS. Folders are its fs.ModeDir entries and the
+// parents of its files. The operations named in fail, such as "mkdir
+// docs/assets/images/x", fail with the error they map to.
+type memFS struct {
+	files fstest.MapFS
+	fail  map[string]error
+}
+
+func newMemFS(files map[string]string) *memFS {
+	m := &memFS{files: fstest.MapFS{}, fail: map[string]error{}}
+	for name, content := range files {
+		m.files[name] = &fstest.MapFile{Data: []byte(content), Mode: 0644}
+	}
+	return m
+}
+
+func (m *memFS) failure(op, name string) error {
+	if err, ok := m.fail[op+" "+name]; ok {
+		return &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	return nil
+}
+
+func (m *memFS) isDir(name string) bool {
+	if name == "." {
+		return true
+	}
+	if f, ok := m.files[name]; ok {
+		return f.Mode.IsDir()
+	}
+	for other := range m.files {
+		if strings.HasPrefix(other, name+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *memFS) exists(name string) bool {
+	_, ok := m.files[name]
+	return ok || m.isDir(name)
+}
+
+func (m *memFS) Open(name string) (fs.File, error) { return m.files.Open(name) }
+
+func (m *memFS) Stat(name string) (fs.FileInfo, error) { return m.files.Stat(name) }
+
+func (m *memFS) ReadFile(name string) ([]byte, error) { return m.files.ReadFile(name) }
+
+func (m *memFS) ReadDir(name string) ([]fs.DirEntry, error) { return m.files.ReadDir(name) }
+
+func (m *memFS) OpenFile(name string, flag int, perm fs.FileMode) (io.WriteCloser, error) {
+	if err := m.failure("open", name); err != nil {
+		return nil, err
+	}
+	f, ok := m.files[name]
+	switch {
+	case !m.isDir(path.Dir(name)), !ok && flag&os.O_CREATE == 0:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	case ok && f.Mode.IsDir():
+		return nil, &fs.PathError{Op: "open", Path: name, Err: errors.New("is a directory")}
+	case ok && flag&os.O_EXCL != 0:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrExist}
+	}
+	w := &memFile{fs: m, name: name, mode: perm}
+	if ok {
+		w.mode = f.Mode
+		if flag&os.O_TRUNC == 0 {
+			w.Write(f.Data)
+		}
+	}
+	// Like os.OpenFile, the file exists once opened.
+	m.files[name] = &fstest.MapFile{Data: append([]byte{}, w.Bytes()...), Mode: w.mode, ModTime: time.Now()}
+	return w, nil
+}
+
+// memFile is a file of a memFS, written when closed.
+type memFile struct {
+	bytes.Buffer
+	fs   *memFS
+	name string
+	mode fs.FileMode
+}
+
+func (f *memFile) Write(p []byte) (int, error) {
+	if err := f.fs.failure("write", f.name); err != nil {
+		return 0, err
+	}
+	return f.Buffer.Write(p)
+}
+
+func (f *memFile) Close() error {
+	f.fs.files[f.name] = &fstest.MapFile{Data: append([]byte{}, f.Bytes()...), Mode: f.mode, ModTime: time.Now()}
+	return nil
+}
+
+func (m *memFS) Mkdir(name string, perm fs.FileMode) error {
+	if err := m.failure("mkdir", name); err != nil {
+		return err
+	}
+	switch {
+	case m.exists(name):
+		return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrExist}
+	case !m.isDir(path.Dir(name)):
+		return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrNotExist}
+	}
+	m.files[name] = &fstest.MapFile{Mode: fs.ModeDir | perm}
+	return nil
+}
+
+func (m *memFS) MkdirAll(name string, perm fs.FileMode) error {
+	if err := m.failure("mkdir", name); err != nil {
+		return err
+	}
+	if name == "." || m.isDir(name) {
+		return nil
+	}
+	if m.exists(name) {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: errors.New("not a directory")}
+	}
+	if err := m.MkdirAll(path.Dir(name), perm); err != nil {
+		return err
+	}
+	m.files[name] = &fstest.MapFile{Mode: fs.ModeDir | perm}
+	return nil
+}
+
+func (m *memFS) Remove(name string) error {
+	if err := m.failure("remove", name); err != nil {
+		return err
+	}
+	if !m.exists(name) {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	for other := range m.files {
+		if strings.HasPrefix(other, name+"/") {
+			return &fs.PathError{Op: "remove", Path: name, Err: errors.New("directory not empty")}
+		}
+	}
+	delete(m.files, name)
+	return nil
+}
+
+func (m *memFS) RemoveAll(name string) error {
+	for other := range m.files {
+		if other == name || strings.HasPrefix(other, name+"/") {
+			delete(m.files, other)
+		}
+	}
+	return nil
+}
+
+func (m *memFS) Rename(oldname, newname string) error {
+	if err := m.failure("rename", oldname); err != nil {
+		return err
+	}
+	if !m.exists(oldname) {
+		return &fs.PathError{Op: "rename", Path: oldname, Err: fs.ErrNotExist}
+	}
+	for other, f := range m.files {
+		if other == oldname || strings.HasPrefix(other, oldname+"/") {
+			delete(m.files, other)
+			m.files[newname+strings.TrimPrefix(other, oldname)] = f
+		}
+	}
+	return nil
+}
+
+// content returns the content of the file name of m, failing t when it
+// does not exist.
+func (m *memFS) content(t *testing.T, name string) string {
+	t.Helper()
+	b, err := m.ReadFile(name)
+	if err != nil {
+		t.Fatalf("reading %s: %v", name, err)
+	}
+	return string(b)
+}
+
+// testGenerator returns a Generator on m with a fixed clock.
+func testGenerator(m *memFS) *Generator {
+	g := NewGenerator(m)
+	g.Now = func() time.Time { return testDate }
+	return g
+}
+
+var testDate = time.Date(2024, 5, 1, 10, 30, 0, 0, time.FixedZone("", -3*60*60))
+
+// frontMatter unmarshals the front matter of content into v, failing t
+// when it is not valid YAML between --- lines.
+func frontMatter(t *testing.T, content []byte, v interface{}) {
+	t.Helper()
+	rest, ok := bytes.CutPrefix(content, []byte("---\n"))
+	if !ok {
+		t.Fatalf("no front matter in:\n%s", content)
+	}
+	header, _, ok := bytes.Cut(rest, []byte("\n---\n"))
+	if !ok {
+		t.Fatalf("unclosed front matter in:\n%s", content)
+	}
+	if err := yaml.Unmarshal(header, v); err != nil {
+		t.Fatalf("front matter is not valid YAML: %v\n%s", err, content)
+	}
+}
@@ -0,0 +1,114 @@
+package postgen
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+)
+
+// move writes content to newPath, renames the images folder oldImages to
+// newImages and removes oldPath, undoing the completed steps when a
+// later one fails. Empty image paths skip the folder rename; equal
+// markdown paths rewrite the file in place.
+func (g *Generator) move(oldPath, newPath string, content []byte, oldImages, newImages string) error {
+	if oldImages != newImages && oldImages != "" {
+		if _, err := g.FS.Stat(newImages); err == nil {
+			return conflictf("folder %s already exists", newImages)
+		}
+	}
+	if oldPath == newPath {
+		return g.writeFile(newPath, content, os.O_WRONLY|os.O_TRUNC)
+	}
+	if err := g.writeFile(newPath, content, os.O_WRONLY|os.O_CREATE|os.O_EXCL); err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return existsf("file %s already exists", newPath)
+		}
+		return err
+	}
+	movedImages := false
+	if oldImages != newImages && oldImages != "" {
+		if err := g.FS.Rename(oldImages, newImages); err != nil {
+			g.FS.Remove(newPath)
+			return fmt.Errorf("renaming folder %s: %w", oldImages, err)
+		}
+		movedImages = true
+	}
+	if err := g.FS.Remove(oldPath); err != nil {
+		if movedImages {
+			g.FS.Rename(newImages, oldImages)
+		}
+		g.FS.Remove(newPath)
+		return fmt.Errorf("removing file %s: %w", oldPath, err)
+	}
+	return nil
+}
+
+// writeFile writes content to name opened with flag, removing the file
+// again when a newly created one cannot be written completely. Existing
+// files opened with os.O_TRUNC are replaced with replaceFile instead.
+func (g *Generator) writeFile(name string, content []byte, flag int) error {
+	if flag&os.O_TRUNC != 0 {
+		if _, err := g.FS.Stat(name); err == nil {
+			return g.replaceFile(name, content)
+		}
+	}
+	f, err := g.FS.OpenFile(name, flag, 0644)
+	if err != nil {
+		return fmt.Errorf("writing file %s: %w", name, err)
+	}
+	_, err = f.Write(content)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		if flag&os.O_EXCL != 0 {
+			g.FS.Remove(name)
+		}
+		return fmt.Errorf("writing file %s: %w", name, err)
+	}
+	return nil
+}
+
+// replaceFile writes content to a temporary file next to name, syncs it
+// and renames it over name, keeping its mode, so that an interrupted
+// write leaves name with either its old content or the new one.
+func (g *Generator) replaceFile(name string, content []byte) error {
+	info, err := g.FS.Stat(name)
+	if err != nil {
+		return fmt.Errorf("writing file %s: %w", name, err)
+	}
+	var tmp string
+	var f io.WriteCloser
+	for i := 0; ; i++ {
+		tmp = path.Join(path.Dir(name), fmt.Sprintf(".%s.%d.tmp", path.Base(name), i))
+		if f, err = g.FS.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm()); !errors.Is(err, fs.ErrExist) {
+			break
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("writing file %s: %w", name, err)
+	}
+	_, err = f.Write(content)
+	// OpenFile's mode is subject to the umask, and only a synced file is
+	// safe to rename over the original.
+	if c, ok := f.(interface{ Chmod(fs.FileMode) error }); ok && err == nil {
+		err = c.Chmod(info.Mode().Perm())
+	}
+	if s, ok := f.(interface{ Sync() error }); ok && err == nil {
+		err = s.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = g.FS.Rename(tmp, name)
+	}
+	if err != nil {
+		g.FS.Remove(tmp)
+		return fmt.Errorf("writing file %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package postgen
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// failingFS is an FS whose files opened with OpenFile write half of
+// what they are given and then fail, or fail when closed.
+type failingFS struct {
+	FS
+	failClose bool
+}
+
+var errDiskFull = errors.New("disk full")
+
+func (f failingFS) OpenFile(name string, flag int, perm fs.FileMode) (io.WriteCloser, error) {
+	w, err := f.FS.OpenFile(name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	return &failingFile{w.(*os.File), f.failClose}, nil
+}
+
+type failingFile struct {
+	*os.File
+	failClose bool
+}
+
+func (f *failingFile) Write(p []byte) (int, error) {
+	if f.failClose {
+		return f.File.Write(p)
+	}
+	n, _ := f.File.Write(p[:len(p)/2])
+	return n, errDiskFull
+}
+
+func (f *failingFile) Close() error {
+	err := f.File.Close()
+	if f.failClose {
+		return errDiskFull
+	}
+	return err
+}
+
+func TestReplaceFileKeepsOriginalOnFailure(t *testing.T) {
+	for _, failClose := range []bool{false, true} {
+		name := "write"
+		if failClose {
+			name = "close"
+		}
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			file := filepath.Join(dir, "post.markdown")
+			if err := os.WriteFile(file, []byte("original\n"), 0600); err != nil {
+				t.Fatal(err)
+			}
+			g := NewGenerator(failingFS{DirFS(dir), failClose})
+			if err := g.replaceFile("post.markdown", []byte("replaced content\n")); !errors.Is(err, errDiskFull) {
+				t.Fatalf("err = %v, want %v", err, errDiskFull)
+			}
+			b, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != "original\n" {
+				t.Errorf("content = %q, want the original", b)
+			}
+			info, err := os.Stat(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Mode().Perm() != 0600 {
+				t.Errorf("mode = %v, want %v", info.Mode().Perm(), fs.FileMode(0600))
+			}
+			if tmp, _ := filepath.Glob(filepath.Join(dir, ".*.tmp")); len(tmp) > 0 {
+				t.Errorf("temporary files left: %v", tmp)
+			}
+		})
+	}
+}
+
+func TestReplaceFileKeepsMode(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "post.markdown")
+	if err := os.WriteFile(file, []byte("original\n"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	// WriteFile's mode is subject to the umask.
+	if err := os.Chmod(file, 0640); err != nil {
+		t.Fatal(err)
+	}
+	// A leftover of an earlier run is not overwritten.
+	leftover := filepath.Join(dir, ".post.markdown.0.tmp")
+	if err := os.WriteFile(leftover, []byte("leftover"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	g := NewGenerator(DirFS(dir))
+	if err := g.replaceFile("post.markdown", []byte("replaced\n")); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "replaced\n" {
+		t.Errorf("content = %q, want %q", b, "replaced\n")
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0640 {
+		t.Errorf("mode = %v, want %v", info.Mode().Perm(), fs.FileMode(0640))
+	}
+	if b, err := os.ReadFile(leftover); err != nil || string(b) != "leftover" {
+		t.Errorf("leftover temporary file changed: %q, %v", b, err)
+	}
+}
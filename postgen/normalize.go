@@ -0,0 +1,97 @@
+package postgen
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+)
+
+// bom is the UTF-8 byte order mark some Windows editors start files with.
+var bom = []byte("\xef\xbb\xbf")
+
+// hardBreakPattern matches a line ending in the two or more spaces that
+// make a Markdown hard line break.
+var hardBreakPattern = regexp.MustCompile(`\S {2,}$`)
+
+// WhitespaceIssue is a problem Normalize fixes: a byte order mark, CRLF
+// line endings, trailing whitespace or a missing final newline.
+type WhitespaceIssue struct {
+	Path    string
+	Line    int
+	Message string
+}
+
+func (i WhitespaceIssue) String() string {
+	return fmt.Sprintf("%s:%d: %s", i.Path, i.Line, i.Message)
+}
+
+// lf returns content without a byte order mark and with LF line endings,
+// the form every file postgen creates is written in.
+func lf(content []byte) []byte {
+	return bytes.ReplaceAll(bytes.TrimPrefix(content, bom), []byte("\r\n"), []byte("\n"))
+}
+
+// Normalize returns content with LF line endings, no byte order mark, a
+// final newline and no trailing spaces or tabs, together with the issues
+// fixed, their Path left empty. Lines inside code blocks keep their
+// trailing whitespace, as do the hard line breaks of Markdown.
+func Normalize(content []byte) ([]byte, []WhitespaceIssue) {
+	var issues []WhitespaceIssue
+	if bytes.HasPrefix(content, bom) {
+		issues = append(issues, WhitespaceIssue{Line: 1, Message: "UTF-8 byte order mark"})
+	}
+	if n := bytes.Count(content, []byte("\r\n")); n > 0 {
+		line := 1 + bytes.Count(content[:bytes.Index(content, []byte("\r\n"))], []byte("\n"))
+		issues = append(issues, WhitespaceIssue{Line: line, Message: fmt.Sprintf("CRLF line endings on %d line(s)", n)})
+	}
+	content = lf(content)
+	if len(content) == 0 {
+		return content, issues
+	}
+	// body holds the lines of the body, true for the ones in code.
+	body := make(map[int]bool)
+	for _, l := range lintLines(content) {
+		body[l.n] = l.code
+	}
+	final := bytes.HasSuffix(content, []byte("\n"))
+	lines := bytes.Split(bytes.TrimSuffix(content, []byte("\n")), []byte("\n"))
+	for i, line := range lines {
+		trimmed := bytes.TrimRight(line, " \t")
+		code, inBody := body[i+1]
+		if len(trimmed) == len(line) || code {
+			continue
+		}
+		if inBody && hardBreakPattern.Match(line) && i+1 < len(lines) && len(bytes.TrimSpace(lines[i+1])) > 0 {
+			continue
+		}
+		issues = append(issues, WhitespaceIssue{Line: i + 1, Message: "trailing whitespace"})
+		lines[i] = trimmed
+	}
+	if !final {
+		issues = append(issues, WhitespaceIssue{Line: len(lines), Message: "no final newline"})
+	}
+	return append(bytes.Join(lines, []byte("\n")), '\n'), issues
+}
+
+// PlanNormalize runs Normalize over each file in paths, returning the
+// changes fixing the files with issues and the issues themselves.
+func (g *Generator) PlanNormalize(paths []string) ([]Change, []WhitespaceIssue, error) {
+	var changes []Change
+	var issues []WhitespaceIssue
+	for _, p := range paths {
+		content, err := g.FS.ReadFile(p)
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading file %s: %w", p, err)
+		}
+		normalized, found := Normalize(content)
+		if len(found) == 0 {
+			continue
+		}
+		for _, issue := range found {
+			issue.Path = p
+			issues = append(issues, issue)
+		}
+		changes = append(changes, Change{Path: p, OldContent: content, NewContent: normalized})
+	}
+	return changes, issues, nil
+}
@@ -0,0 +1,116 @@
+package postgen
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"path"
+	"strings"
+)
+
+// Defaults of OptimizeOptions.
+const (
+	DefaultImageQuality  = 85
+	DefaultImageMaxWidth = 1200
+)
+
+// OptimizeOptions configures OptimizeImage.
+type OptimizeOptions struct {
+	// Quality is the JPEG quality photos are encoded at, 1 to 100.
+	Quality int
+	// MaxWidth is the width, in pixels, wider images are scaled down to.
+	MaxWidth int
+}
+
+// OptimizeImage shrinks a PNG or JPEG image: it is scaled down to at most
+// opts.MaxWidth pixels wide, photos are encoded as JPEG at opts.Quality,
+// and the other PNGs with the best compression. Images in other formats,
+// and the ones the result would only make larger, are returned unchanged.
+// The name of the returned image has the extension of its format.
+func OptimizeImage(img Image, opts OptimizeOptions) (Image, error) {
+	src, format, err := image.Decode(bytes.NewReader(img.Data))
+	if err != nil || format != "png" && format != "jpeg" {
+		return img, nil
+	}
+	resized := false
+	if opts.MaxWidth > 0 && src.Bounds().Dx() > opts.MaxWidth {
+		src = scaleDown(src, opts.MaxWidth)
+		resized = true
+	}
+	var b bytes.Buffer
+	ext := ".png"
+	if format == "jpeg" || isPhoto(src) {
+		ext = ".jpg"
+		err = jpeg.Encode(&b, src, &jpeg.Options{Quality: opts.Quality})
+	} else {
+		err = (&png.Encoder{CompressionLevel: png.BestCompression}).Encode(&b, src)
+	}
+	if err != nil {
+		return Image{}, fmt.Errorf("encoding image %s: %w", img.Name, err)
+	}
+	if !resized && b.Len() >= len(img.Data) {
+		return img, nil
+	}
+	name := strings.TrimSuffix(img.Name, path.Ext(img.Name))
+	if format == "jpeg" {
+		// A JPEG keeps its extension, .jpeg or .jpg alike.
+		name, ext = img.Name, ""
+	}
+	return Image{Name: name + ext, Data: b.Bytes()}, nil
+}
+
+// scaleDown returns src scaled to width pixels wide, averaging the source
+// pixels each one covers.
+func scaleDown(src image.Image, width int) image.Image {
+	sb := src.Bounds()
+	height := sb.Dy() * width / sb.Dx()
+	if height < 1 {
+		height = 1
+	}
+	dst := image.NewRGBA64(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0, y1 := sb.Min.Y+y*sb.Dy()/height, sb.Min.Y+(y+1)*sb.Dy()/height
+		if y1 == y0 {
+			y1++
+		}
+		for x := 0; x < width; x++ {
+			x0, x1 := sb.Min.X+x*sb.Dx()/width, sb.Min.X+(x+1)*sb.Dx()/width
+			if x1 == x0 {
+				x1++
+			}
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, b, a, n = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca), n+1
+				}
+			}
+			dst.SetRGBA64(x, y, color.RGBA64{uint16(r / n), uint16(g / n), uint16(b / n), uint16(a / n)})
+		}
+	}
+	return dst
+}
+
+// isPhoto guesses whether img is a photo rather than a screenshot or a
+// drawing: it is opaque and most of a sample of its pixels have distinct
+// colors, where the others repeat a few flat ones.
+func isPhoto(img image.Image) bool {
+	b := img.Bounds()
+	const samples = 100
+	seen := make(map[color.RGBA64]bool)
+	n := 0
+	for i := 0; i < samples; i++ {
+		for j := 0; j < samples; j++ {
+			r, g, bl, a := img.At(b.Min.X+i*b.Dx()/samples, b.Min.Y+j*b.Dy()/samples).RGBA()
+			if a != 0xffff {
+				return false
+			}
+			seen[color.RGBA64{uint16(r), uint16(g), uint16(bl), uint16(a)}] = true
+			n++
+		}
+	}
+	return len(seen) > n/2
+}
@@ -0,0 +1,57 @@
+package postgen
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// OutlineTODO is the placeholder written under each outline heading.
+const OutlineTODO = "<!-- TODO -->"
+
+// OutlineItem is a section of a post outline and its subsections.
+type OutlineItem struct {
+	Title    string
+	Children []OutlineItem
+}
+
+// ParseOutline parses an outline written one section per line, indented
+// lines being subsections of the section above them. Blank lines are
+// skipped and titles are trimmed, nothing else.
+func ParseOutline(text string) ([]OutlineItem, error) {
+	var items []OutlineItem
+	for i, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		title := strings.TrimSpace(line)
+		if title == "" {
+			continue
+		}
+		if line[0] != ' ' && line[0] != '\t' {
+			items = append(items, OutlineItem{Title: title})
+			continue
+		}
+		if len(items) == 0 {
+			return nil, fmt.Errorf("line %d: subsection \"%s\" has no section above it", i+1, title)
+		}
+		last := &items[len(items)-1]
+		last.Children = append(last.Children, OutlineItem{Title: title})
+	}
+	return items, nil
+}
+
+// OutlineBody renders items as H2 headings, their children as H3s, each
+// followed by OutlineTODO.
+func OutlineBody(items []OutlineItem) []byte {
+	var b bytes.Buffer
+	var write func(items []OutlineItem, level int)
+	write = func(items []OutlineItem, level int) {
+		for _, item := range items {
+			if b.Len() > 0 {
+				b.WriteString("\n")
+			}
+			b.WriteString(strings.Repeat("#", level) + " " + item.Title + "\n\n" + OutlineTODO + "\n")
+			write(item.Children, level+1)
+		}
+	}
+	write(items, 2)
+	return b.Bytes()
+}
@@ -0,0 +1,79 @@
+package postgen
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"unicode"
+)
+
+// permalinkStyles maps Jekyll's built-in permalink style names to their
+// patterns.
+var permalinkStyles = map[string]string{
+	"date":    "/:categories/:year/:month/:day/:title:output_ext",
+	"pretty":  "/:categories/:year/:month/:day/:title/",
+	"ordinal": "/:categories/:year/:y_day/:title:output_ext",
+	"none":    "/:categories/:title:output_ext",
+}
+
+// DefaultPermalink is Jekyll's default permalink style.
+const DefaultPermalink = "date"
+
+// ParsePermalink checks the permalink front matter value of a post,
+// which must start with a slash and contain no whitespace, and returns it
+// cleaned, with a trailing slash unless it names a file such as
+// /about.html.
+func ParsePermalink(raw string) (string, error) {
+	if !strings.HasPrefix(raw, "/") || strings.IndexFunc(raw, unicode.IsSpace) >= 0 {
+		return "", fmt.Errorf("invalid permalink %s: expected a path starting with a slash, without spaces", raw)
+	}
+	p := path.Clean(raw)
+	if p != "/" && path.Ext(p) == "" {
+		p += "/"
+	}
+	return p, nil
+}
+
+// Permalink returns the URL Jekyll serves e at, given the site's
+// permalink setting (a style name or a pattern). A permalink front
+// matter key on the post wins over the site setting.
+func Permalink(pattern string, e Entry) string {
+	if e.Meta.Permalink != "" {
+		pattern = e.Meta.Permalink
+	}
+	if pattern == "" {
+		pattern = DefaultPermalink
+	}
+	if style, ok := permalinkStyles[pattern]; ok {
+		pattern = style
+	}
+	var categories []string
+	seen := make(map[string]bool)
+	for _, c := range e.Meta.Categories {
+		c = strings.ToLower(c)
+		if !seen[c] {
+			seen[c] = true
+			categories = append(categories, c)
+		}
+	}
+	d := e.Date
+	url := strings.NewReplacer(
+		":categories", strings.Join(categories, "/"),
+		":year", fmt.Sprintf("%04d", d.Year()),
+		":short_year", fmt.Sprintf("%02d", d.Year()%100),
+		":i_month", fmt.Sprint(int(d.Month())),
+		":month", fmt.Sprintf("%02d", d.Month()),
+		":i_day", fmt.Sprint(d.Day()),
+		":day", fmt.Sprintf("%02d", d.Day()),
+		":y_day", fmt.Sprintf("%03d", d.YearDay()),
+		":title", e.Slug,
+		":slug", e.Slug,
+		":output_ext", ".html",
+	).Replace(pattern)
+	trailing := strings.HasSuffix(url, "/")
+	url = path.Clean("/" + url)
+	if trailing && url != "/" {
+		url += "/"
+	}
+	return url
+}
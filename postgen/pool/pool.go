@@ -0,0 +1,70 @@
+// Package pool works through the files of a site, or any other named
+// items, with a bounded number of goroutines, reporting progress and
+// stopping early on cancellation.
+package pool
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// Options tunes Run.
+type Options struct {
+	// Jobs is the number of items worked on at once, GOMAXPROCS when it
+	// is zero.
+	Jobs int
+	// Progress, when set, is called after each item with the number of
+	// items done out of total and the name of the item just done. Calls
+	// never overlap.
+	Progress func(done, total int, name string)
+}
+
+// Run calls work with the index of each of items, at most opts.Jobs at
+// once, handing them out in order. Once ctx is done no more items are
+// started: the ones in flight are finished, work being left to honour
+// any deadline of its own, and Run returns the number of items done,
+// which are always the first ones, with ctx's error. The error is nil
+// when every item was done.
+func Run(ctx context.Context, items []string, opts Options, work func(i int)) (int, error) {
+	jobs := opts.Jobs
+	if jobs <= 0 {
+		jobs = runtime.GOMAXPROCS(0)
+	}
+	var (
+		mu    sync.Mutex
+		wg    sync.WaitGroup
+		done  int
+		queue = make(chan int)
+	)
+	for w := 0; w < jobs && w < len(items); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				work(i)
+				mu.Lock()
+				done++
+				if opts.Progress != nil {
+					opts.Progress(done, len(items), items[i])
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	started := 0
+dispatch:
+	for ; started < len(items) && ctx.Err() == nil; started++ {
+		select {
+		case queue <- started:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(queue)
+	wg.Wait()
+	if started < len(items) {
+		return started, ctx.Err()
+	}
+	return started, nil
+}
@@ -0,0 +1,129 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func names(n int) []string {
+	items := make([]string, n)
+	for i := range items {
+		items[i] = fmt.Sprintf("item-%d", i)
+	}
+	return items
+}
+
+func TestRunBoundsConcurrency(t *testing.T) {
+	const jobs = 3
+	var active, peak int32
+	results := make([]int, 20)
+	n, err := Run(context.Background(), names(len(results)), Options{Jobs: jobs}, func(i int) {
+		now := atomic.AddInt32(&active, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if now <= p || atomic.CompareAndSwapInt32(&peak, p, now) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		results[i] = i * i
+		atomic.AddInt32(&active, -1)
+	})
+	if n != len(results) || err != nil {
+		t.Fatalf("Run = %d, %v, want %d, nil", n, err, len(results))
+	}
+	if peak > jobs {
+		t.Errorf("%d items worked on at once, want at most %d", peak, jobs)
+	}
+	for i, r := range results {
+		if r != i*i {
+			t.Errorf("result %d = %d, want %d", i, r, i*i)
+		}
+	}
+}
+
+func TestRunStopsInOrderWhenCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	items := names(100)
+	var mu sync.Mutex
+	done := make(map[int]bool)
+	n, err := Run(ctx, items, Options{Jobs: 4}, func(i int) {
+		if i == 10 {
+			cancel()
+		}
+		// A slow worker lets the cancellation land while items are in
+		// flight.
+		time.Sleep(2 * time.Millisecond)
+		mu.Lock()
+		done[i] = true
+		mu.Unlock()
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want %v", err, context.Canceled)
+	}
+	if n <= 10 || n >= len(items) {
+		t.Fatalf("%d items done, want more than 10 and fewer than %d", n, len(items))
+	}
+	if len(done) != n {
+		t.Errorf("%d items done, Run reports %d", len(done), n)
+	}
+	for i := 0; i < n; i++ {
+		if !done[i] {
+			t.Errorf("item %d of the first %d not done", i, n)
+		}
+	}
+}
+
+func TestRunProgressNeverOverlaps(t *testing.T) {
+	items := names(50)
+	var inside int32
+	var calls []int
+	seen := make(map[string]bool)
+	_, err := Run(context.Background(), items, Options{
+		Jobs: 8,
+		Progress: func(done, total int, name string) {
+			if atomic.AddInt32(&inside, 1) != 1 {
+				t.Error("overlapping Progress calls")
+			}
+			time.Sleep(100 * time.Microsecond)
+			calls = append(calls, done)
+			seen[name] = true
+			if total != len(items) {
+				t.Errorf("total = %d, want %d", total, len(items))
+			}
+			atomic.AddInt32(&inside, -1)
+		},
+	}, func(int) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != len(items) || len(seen) != len(items) {
+		t.Fatalf("%d Progress calls for %d names, want %d", len(calls), len(seen), len(items))
+	}
+	for i, done := range calls {
+		if done != i+1 {
+			t.Errorf("call %d reports %d done, want %d", i, done, i+1)
+		}
+	}
+}
+
+func TestRunZeroItems(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	for name, ctx := range map[string]context.Context{"background": context.Background(), "canceled": canceled} {
+		t.Run(name, func(t *testing.T) {
+			n, err := Run(ctx, nil, Options{Progress: func(int, int, string) { t.Error("Progress called") }}, func(int) {
+				t.Error("work called")
+			})
+			if n != 0 || err != nil {
+				t.Errorf("Run = %d, %v, want 0, nil", n, err)
+			}
+		})
+	}
+}
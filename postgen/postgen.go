@@ -1,86 +1,465 @@
-package main
+// Package postgen scaffolds Jekyll posts: it renders their front matter,
+// creates the markdown file and the matching images folder, and promotes
+// drafts into dated posts.
+package postgen
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path"
 	"strings"
 	"text/template"
 	"time"
 
-	"github.com/jessevdk/go-flags"
-	"github.com/pkg/errors"
+	"github.com/tiagomelo/tiagomelo.github.io/postgen/pool"
+	"github.com/tiagomelo/tiagomelo.github.io/postgen/posts"
 )
 
-type options struct {
-	Title string `short:"t" long:"title" description:"article's title" required:"true"`
+const (
+	// DateLayout is the layout of the front matter date field.
+	DateLayout = "2006-01-02 15:04:05 -0700"
+	// FileDateLayout is the layout of the date prefixing post file and
+	// images folder names.
+	FileDateLayout = "2006-01-02"
+)
+
+// Post describes a post to scaffold.
+type Post struct {
+	Layout      string
+	Title       string
+	Description string
+	// CanonicalURL is where the post first appeared, when elsewhere; it
+	// should be an absolute http(s) URL, see ParseCanonicalURL.
+	CanonicalURL string
+	// Permalink is the post's own URL, replacing the site's default; see
+	// ParsePermalink.
+	Permalink  string
+	Slug       string
+	Author     string
+	Lang       string
+	Categories []string
+	Tags       []string
+	// Series names the series the post belongs to, SeriesPart being its
+	// number in it.
+	Series     string
+	SeriesPart int
+	// Date is the publication date; the generator's clock is used when
+	// it is zero.
+	Date  time.Time
+	Draft bool
+	// Images are copied into the post's images folder and referenced at
+	// the end of its body.
+	Images []Image
+	// Cover, when set, is copied into the images folder too and becomes
+	// the image front matter key, read by layouts and social cards.
+	Cover *Image
+	// Archetype, when set, is executed with the same data as the header
+	// template; see applyArchetype.
+	Archetype *template.Template
+	// Explicit names the front matter keys given explicitly, such as with
+	// flags, which keep their value over the Archetype's.
+	Explicit map[string]bool
+	// Body follows the archetype's body, if any, and precedes the
+	// references to Images.
+	Body []byte
+	// Vars, as ParseVars returns them, are set in the front matter and
+	// passed to the templates as .Vars.
+	Vars map[string]interface{}
 }
 
-const headerTemplate = `---
-layout: post
-title:  ""
-date:   {{ .Date }}
-categories:
----
-`
-
-func run(title string) error {
-	const (
-		docsDir   = "docs"
-		postsDir  = "_posts"
-		imagesDir = "assets/images"
-	)
-	now := time.Now().UTC()
-	publishedDateLayout := "2006-01-02 15:04:05 -0000"
-	formattedPublishedDate := now.Format(publishedDateLayout)
-	markdownDateLayout := "2006-01-02"
-	formattedMarkdownDateLayout := now.Format(markdownDateLayout)
-	markdownFilePath := fmt.Sprintf("%s/%s/%s-%s.markdown", docsDir, postsDir, formattedMarkdownDateLayout, title)
-	markdownFile, err := os.Create(markdownFilePath)
-	if err != nil {
-		return errors.Wrapf(err, "writing file %s", markdownFilePath)
+// Result describes the files created, or to be created, for a post.
+// Paths are relative to the generator's filesystem.
+type Result struct {
+	MarkdownPath string
+	ImagesPath   string
+	Slug         string
+	Date         time.Time
+	Content      []byte
+	// Images holds the image files written into ImagesPath, in the order
+	// of Post.Images.
+	Images []string
+	images [][]byte
+}
+
+// Generator creates posts inside FS.
+type Generator struct {
+	FS FS
+	// PostsDir, DraftsDir and ImagesDir are slash-separated paths
+	// relative to FS.
+	PostsDir  string
+	DraftsDir string
+	ImagesDir string
+	// Ext is the extension, without the leading dot, of the files
+	// created; existing files with any of the Exts are read.
+	Ext string
+	// Schema, when set, is enforced by Plan, and so Generate, on the
+	// front matter it renders, and by Validate.
+	Schema *Schema
+	// Now is the clock used when a post has no date.
+	Now func() time.Time
+	// Template renders the front matter when set; otherwise RenderHeader
+	// does, in the Header format.
+	Template *template.Template
+	Header   HeaderFormat
+	// Force overwrites existing markdown files.
+	Force bool
+	// KeepOnError keeps partially created files when Generate fails.
+	KeepOnError bool
+	// AllowDuplicateSlug lets a post reuse the slug of a post of another
+	// day.
+	AllowDuplicateSlug bool
+	// Jobs is the number of files site-wide checks such as Validate work
+	// on at once, GOMAXPROCS when zero, and Progress, when set, is told
+	// of each file they are done with.
+	Jobs     int
+	Progress func(done, total int, name string)
+
+	posts *posts.Corpus
+}
+
+// NewGenerator returns a Generator for the standard docs/ layout in fsys.
+func NewGenerator(fsys FS) *Generator {
+	return &Generator{
+		FS:        fsys,
+		PostsDir:  "docs/_posts",
+		DraftsDir: "docs/_drafts",
+		ImagesDir: "docs/assets/images",
+		Ext:       "markdown",
+		Now:       time.Now,
+	}
+}
+
+// ImagesURL returns the images folder as referenced from post bodies,
+// that is, relative to the Jekyll source directory holding PostsDir.
+func (g *Generator) ImagesURL() string {
+	source := path.Dir(g.PostsDir)
+	if source == "." {
+		return g.ImagesDir
+	}
+	if url := strings.TrimPrefix(g.ImagesDir, source+"/"); url != g.ImagesDir {
+		return url
 	}
-	fmt.Printf("markdownFilePath: %v\n", markdownFilePath)
-	tmpl, err := template.New("header").Parse(headerTemplate)
+	return g.ImagesDir
+}
+
+// run works through items with a pool of Jobs goroutines reporting to
+// Progress, returning how many of them were done and, when ctx was done
+// first, an error matching ErrInterrupted.
+func (g *Generator) run(ctx context.Context, items []string, work func(i int)) (int, error) {
+	return g.runJobs(ctx, g.Jobs, items, work)
+}
+
+// runJobs is run with jobs goroutines rather than Jobs.
+func (g *Generator) runJobs(ctx context.Context, jobs int, items []string, work func(i int)) (int, error) {
+	n, err := pool.Run(ctx, items, pool.Options{Jobs: jobs, Progress: g.Progress}, work)
 	if err != nil {
-		return errors.Wrap(err, "parsing template")
+		return n, Mark(ErrInterrupted, fmt.Errorf("interrupted after %d of %d (%w)", n, len(items), err))
+	}
+	return n, nil
+}
+
+// Plan computes and renders what Generate would create for p without
+// touching the filesystem.
+func (g *Generator) Plan(p Post) (Result, error) {
+	if p.Date.IsZero() {
+		p.Date = g.Now()
+	}
+	name := p.Date.Format(FileDateLayout) + "-" + p.Slug
+	dir := g.PostsDir
+	if p.Draft {
+		name = p.Slug
+		dir = g.DraftsDir
+	}
+	taken := make(map[string]bool)
+	cover, coverURL := "", ""
+	if p.Cover != nil {
+		cover = uniqueName(ImageFileName(p.Cover.Name), taken)
+		coverURL = "/" + path.Join(g.ImagesURL(), name, cover)
+	}
+	data := TemplateData{
+		Layout:       p.Layout,
+		Title:        p.Title,
+		Description:  p.Description,
+		CanonicalURL: p.CanonicalURL,
+		Permalink:    p.Permalink,
+		Slug:         p.Slug,
+		Date:         p.Date.Format(DateLayout),
+		Author:       p.Author,
+		Lang:         p.Lang,
+		Categories:   p.Categories,
+		Tags:         p.Tags,
+		Series:       p.Series,
+		SeriesPart:   p.SeriesPart,
+		Draft:        p.Draft,
+		Image:        coverURL,
+		Vars:         p.Vars,
+	}
+	var content bytes.Buffer
+	if g.Template != nil {
+		if err := g.execute(&content, g.Template, data); err != nil {
+			return Result{}, Mark(ErrTemplate, fmt.Errorf("executing template: %w", err))
+		}
+	} else {
+		header, err := RenderHeader(data, g.Header)
+		if err != nil {
+			return Result{}, fmt.Errorf("rendering front matter: %w", err)
+		}
+		content.Write(header)
+	}
+	if p.Archetype != nil {
+		merged, err := g.applyArchetype(content.Bytes(), p.Archetype, data, p.Explicit)
+		if err != nil {
+			return Result{}, err
+		}
+		content.Reset()
+		content.Write(merged)
+	}
+	if len(p.Vars) > 0 {
+		set, err := setVars(content.Bytes(), p.Vars)
+		if err != nil {
+			return Result{}, err
+		}
+		content.Reset()
+		content.Write(set)
+	}
+	if len(p.Body) > 0 {
+		if !bytes.HasSuffix(content.Bytes(), []byte("\n\n")) {
+			content.WriteString("\n")
+		}
+		content.Write(p.Body)
+	}
+	r := Result{
+		MarkdownPath: path.Join(dir, name+"."+g.Ext),
+		ImagesPath:   path.Join(g.ImagesDir, name),
+		Slug:         p.Slug,
+		Date:         p.Date,
+	}
+	if p.Cover != nil {
+		r.Images = append(r.Images, path.Join(r.ImagesPath, cover))
+		r.images = append(r.images, p.Cover.Data)
+	}
+	for _, img := range p.Images {
+		file := uniqueName(ImageFileName(img.Name), taken)
+		fmt.Fprintf(&content, "\n![%s](/%s)\n", strings.TrimSuffix(file, path.Ext(file)), path.Join(g.ImagesURL(), name, file))
+		r.Images = append(r.Images, path.Join(r.ImagesPath, file))
+		r.images = append(r.images, img.Data)
+	}
+	r.Content = lf(content.Bytes())
+	if g.Schema != nil {
+		problems, err := g.Schema.Check(r.MarkdownPath, r.Content)
+		if err != nil {
+			return Result{}, err
+		}
+		if len(problems) > 0 {
+			var messages []string
+			for _, p := range problems {
+				messages = append(messages, p.Field+": "+p.Message)
+			}
+			return Result{}, fmt.Errorf("the front matter of %s does not match the schema: %s", r.MarkdownPath, strings.Join(messages, "; "))
+		}
+	}
+	return r, nil
+}
+
+// Collision returns an error when creating r would overwrite an existing
+// markdown file, duplicate one with another extension, or add a post
+// whose slug is near-identical to that of another post of the same day,
+// and Force is not set, or reuse the slug of a post of any day, and
+// AllowDuplicateSlug is not set. The error matches ErrConflict.
+func (g *Generator) Collision(r Result) error {
+	if !g.AllowDuplicateSlug {
+		taken, err := g.postSlugs(r.MarkdownPath)
+		if err != nil {
+			return err
+		}
+		if other, ok := taken[r.Slug]; ok {
+			n := 2
+			for ; taken[fmt.Sprintf("%s-%d", r.Slug, n)] != ""; n++ {
+			}
+			return conflictf("%s already uses the slug %s, pick another such as %s-%d or one with a qualifier, or use --allow-duplicate-slug", other, r.Slug, r.Slug, n)
+		}
+	}
+	if g.Force {
+		return nil
 	}
-	if err := tmpl.Execute(markdownFile, map[string]string{"Date": formattedPublishedDate}); err != nil {
-		return errors.Wrap(err, "executing template")
+	if _, err := g.FS.Stat(r.MarkdownPath); err == nil {
+		return existsf("file %s already exists, use --force to overwrite it", r.MarkdownPath)
 	}
-	imagesFolderPath := fmt.Sprintf("%s/%s/%s-%s", docsDir, imagesDir, formattedMarkdownDateLayout, title)
-	if err := os.Mkdir(imagesFolderPath, os.ModePerm); err != nil {
-		return errors.Wrapf(err, "creating folder %s", imagesFolderPath)
+	// The same post under another extension would publish to the same
+	// URL.
+	base := strings.TrimSuffix(r.MarkdownPath, path.Ext(r.MarkdownPath))
+	for _, ext := range Exts {
+		if p := base + "." + ext; p != r.MarkdownPath {
+			if _, err := g.FS.Stat(p); err == nil {
+				return existsf("file %s already exists", p)
+			}
+		}
+	}
+	similar, err := g.similar(r.MarkdownPath)
+	if err != nil {
+		return err
+	}
+	if similar != "" {
+		return conflictf("%s has a near-identical slug, use --force to create %s anyway", similar, r.MarkdownPath)
 	}
-	fmt.Printf("imagesFolderPath: %v\n", imagesFolderPath)
 	return nil
 }
 
-func main() {
-	var opts options
-	parser := flags.NewParser(&opts, flags.Default)
-	if _, err := parser.Parse(); err != nil {
-		switch flagsErr := err.(type) {
-		case flags.ErrorType:
-			if flagsErr == flags.ErrHelp {
-				fmt.Println(err)
-				os.Exit(0)
+// similar returns the post in the folder of p, dated the same day, or the
+// draft when p is one, whose slug only differs from p's in case or in
+// repeated, leading or trailing hyphens, or an empty string when there is
+// none.
+func (g *Generator) similar(p string) (string, error) {
+	dir, name := path.Split(p)
+	date, slug := "", TrimExt(name)
+	if _, s, ok := ParseFileName(name); ok && !g.IsDraft(p) {
+		date, slug = name[:len(FileDateLayout)], s
+	}
+	entries, err := fs.ReadDir(g.FS, path.Clean(dir))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("reading folder %s: %w", dir, err)
+	}
+	key := slugKey(slug)
+	for _, e := range entries {
+		other := e.Name()
+		if e.IsDir() || !IsPostFile(other) || TrimExt(other) == TrimExt(name) {
+			continue
+		}
+		otherSlug := TrimExt(other)
+		if date != "" {
+			d, s, ok := ParseFileName(other)
+			if !ok || d.Format(FileDateLayout) != date {
+				continue
 			}
-			fmt.Println(err)
-			os.Exit(1)
-		default:
-			os.Exit(1)
+			otherSlug = s
+		}
+		if slugKey(otherSlug) == key {
+			return path.Join(dir, other), nil
 		}
 	}
-	if containsSpace(opts.Title) {
-		fmt.Printf("title \"%s\" contains space(s)\n", opts.Title)
-		os.Exit(1)
+	return "", nil
+}
+
+// postSlugs maps the slugs of the posts in PostsDir, the file except
+// aside, to one of the posts using them.
+func (g *Generator) postSlugs(except string) (map[string]string, error) {
+	entries, err := fs.ReadDir(g.FS, g.PostsDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading folder %s: %w", g.PostsDir, err)
 	}
-	if err := run(opts.Title); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+	slugs := make(map[string]string)
+	for _, e := range entries {
+		p := path.Join(g.PostsDir, e.Name())
+		if _, slug, ok := ParseFileName(e.Name()); ok && !e.IsDir() && IsPostFile(e.Name()) && p != except && slugs[slug] == "" {
+			slugs[slug] = p
+		}
 	}
+	return slugs, nil
+}
+
+// slugKey reduces slug to what near-identical slugs share: its words,
+// lowercased and separated by single hyphens.
+func slugKey(slug string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(slug), func(r rune) bool { return r == '-' }), "-")
 }
 
-func containsSpace(s string) bool {
-	return strings.Contains(s, " ")
+// missingDirs returns dir and its parents that do not exist in fsys,
+// outermost first.
+func missingDirs(fsys fs.StatFS, dir string) []string {
+	var dirs []string
+	for ; dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if _, err := fsys.Stat(dir); err == nil {
+			break
+		}
+		dirs = append([]string{dir}, dirs...)
+	}
+	return dirs
+}
+
+// Generate creates the markdown file and images folder for p.
+func (g *Generator) Generate(p Post) (Result, error) {
+	r, err := g.Plan(p)
+	if err != nil {
+		return Result{}, err
+	}
+	if err := g.apply(r); err != nil {
+		return Result{}, err
+	}
+	return r, nil
+}
+
+func (g *Generator) apply(r Result) (err error) {
+	if err := g.Collision(r); err != nil {
+		return err
+	}
+	dir := path.Dir(r.MarkdownPath)
+	if err := g.FS.MkdirAll(dir, fs.ModePerm); err != nil {
+		return fmt.Errorf("creating folder %s: %w", dir, err)
+	}
+
+	// Everything created by this run is removed again if a later step
+	// fails, so a failed run leaves the tree as it found it.
+	var created []string
+	defer func() {
+		if err == nil || g.KeepOnError {
+			return
+		}
+		for i := len(created) - 1; i >= 0; i-- {
+			g.FS.Remove(created[i])
+		}
+	}()
+
+	err = g.writeFile(r.MarkdownPath, r.Content, os.O_WRONLY|os.O_CREATE|os.O_EXCL)
+	switch {
+	case err == nil:
+		created = append(created, r.MarkdownPath)
+	case errors.Is(err, fs.ErrExist) && g.Force:
+		err = g.writeFile(r.MarkdownPath, r.Content, os.O_WRONLY|os.O_TRUNC)
+	case errors.Is(err, fs.ErrExist):
+		return g.Collision(r)
+	}
+	if err != nil {
+		return err
+	}
+	// The images folder may be the first of its parents, which are only
+	// removed again when this run created them.
+	for _, dir := range missingDirs(g.FS, path.Dir(r.ImagesPath)) {
+		if err := g.FS.Mkdir(dir, fs.ModePerm); err != nil {
+			return fmt.Errorf("creating folder %s: %w", dir, err)
+		}
+		created = append(created, dir)
+	}
+	if err := g.FS.Mkdir(r.ImagesPath, fs.ModePerm); err == nil {
+		created = append(created, r.ImagesPath)
+	} else if !errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("creating folder %s: %w", r.ImagesPath, err)
+	}
+	for i, file := range r.Images {
+		// Only the images already there are overwritten in place, so the
+		// new ones are removed again like the rest of the run.
+		flag := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+		if g.Force && g.exists(file) {
+			flag = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		}
+		if err := g.writeFile(file, r.images[i], flag); err != nil {
+			if errors.Is(err, fs.ErrExist) {
+				return conflictf("file %s already exists, use --force to overwrite it", file)
+			}
+			return err
+		}
+		if flag&os.O_EXCL != 0 {
+			created = append(created, file)
+		}
+	}
+	return nil
 }
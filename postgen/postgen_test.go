@@ -0,0 +1,199 @@
+package postgen
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+const (
+	testPost   = "docs/_posts/2024-05-01-hello.markdown"
+	testImages = "docs/assets/images/2024-05-01-hello"
+)
+
+// siteFS returns a memFS holding files and the posts and images folders
+// sites have, which stay when emptied.
+func siteFS(files map[string]string) *memFS {
+	m := newMemFS(files)
+	for _, dir := range []string{"docs/_posts", "docs/assets/images"} {
+		if _, ok := m.files[dir]; !ok {
+			m.files[dir] = &fstest.MapFile{Mode: fs.ModeDir | 0755}
+		}
+	}
+	return m
+}
+
+func helloPost() Post {
+	return Post{Layout: "post", Title: "Hello", Slug: "hello", Date: testDate}
+}
+
+func TestGenerateRefusesExistingPost(t *testing.T) {
+	m := siteFS(map[string]string{testPost: "existing"})
+	_, err := testGenerator(m).Generate(helloPost())
+	if !errors.Is(err, ErrPostExists) {
+		t.Fatalf("err = %v, want ErrPostExists", err)
+	}
+	if got := m.content(t, testPost); got != "existing" {
+		t.Errorf("post overwritten with:\n%s", got)
+	}
+	if m.exists(testImages) {
+		t.Errorf("images folder %s created", testImages)
+	}
+}
+
+func TestGenerateForceOverwrites(t *testing.T) {
+	m := siteFS(map[string]string{testPost: "existing"})
+	g := testGenerator(m)
+	g.Force = true
+	if _, err := g.Generate(helloPost()); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.content(t, testPost); !strings.Contains(got, `title:  "Hello"`) {
+		t.Errorf("post not regenerated:\n%s", got)
+	}
+}
+
+func TestGenerateKeepsExistingImagesFolder(t *testing.T) {
+	m := siteFS(map[string]string{testImages + "/banner.png": "png"})
+	if _, err := testGenerator(m).Generate(helloPost()); err != nil {
+		t.Fatalf("existing images folder is fatal: %v", err)
+	}
+	m.content(t, testPost)
+	if got := m.content(t, testImages+"/banner.png"); got != "png" {
+		t.Errorf("image changed to %q", got)
+	}
+}
+
+func TestGenerateRefusesExtensionTwin(t *testing.T) {
+	twin := "docs/_posts/2024-05-01-hello.md"
+	m := siteFS(map[string]string{twin: "existing"})
+	g := testGenerator(m)
+	// Otherwise the twin is refused for reusing the slug.
+	g.AllowDuplicateSlug = true
+	_, err := g.Generate(helloPost())
+	if !errors.Is(err, ErrPostExists) || !strings.Contains(err.Error(), twin) {
+		t.Fatalf("err = %v, want ErrPostExists naming %s", err, twin)
+	}
+	if m.exists(testPost) {
+		t.Errorf("%s created next to %s", testPost, twin)
+	}
+}
+
+func TestGenerateRollsBackOnError(t *testing.T) {
+	tests := []struct {
+		name  string
+		force bool
+		fail  string
+	}{
+		{"images folder", false, "mkdir " + testImages},
+		{"image", false, "write " + testImages + "/second.png"},
+		{"image with force", true, "write " + testImages + "/second.png"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := siteFS(nil)
+			m.fail[tt.fail] = fs.ErrPermission
+			g := testGenerator(m)
+			g.Force = tt.force
+			post := helloPost()
+			post.Images = []Image{{Name: "first.png", Data: []byte("1")}, {Name: "second.png", Data: []byte("2")}}
+			if _, err := g.Generate(post); !errors.Is(err, fs.ErrPermission) {
+				t.Fatalf("err = %v, want fs.ErrPermission", err)
+			}
+			for _, p := range []string{testPost, testImages + "/first.png", testImages + "/second.png", testImages} {
+				if m.exists(p) {
+					t.Errorf("%s left behind", p)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateKeepOnError(t *testing.T) {
+	m := siteFS(nil)
+	m.fail["mkdir "+testImages] = fs.ErrPermission
+	g := testGenerator(m)
+	g.KeepOnError = true
+	if _, err := g.Generate(helloPost()); !errors.Is(err, fs.ErrPermission) {
+		t.Fatalf("err = %v, want fs.ErrPermission", err)
+	}
+	if got := m.content(t, testPost); !strings.Contains(got, `title:  "Hello"`) {
+		t.Errorf("kept post is incomplete:\n%s", got)
+	}
+}
+
+func TestGenerateRefusesNearIdenticalSlug(t *testing.T) {
+	tests := []struct {
+		existing string
+		slug     string
+		refused  bool
+	}{
+		{"go-generics", "Go-Generics", true},
+		{"go-generics", "go-generics-", true},
+		{"go-generics", "-go-generics", true},
+		{"go-generics", "go--generics", true},
+		{"go-generics", "go-generics-2", false},
+		{"the-best-x", "theb-est-x", false},
+		{"the-best-x", "thebest-x", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.slug, func(t *testing.T) {
+			existing := "docs/_posts/2024-05-01-" + tt.existing + ".markdown"
+			m := siteFS(map[string]string{existing: "existing"})
+			post := helloPost()
+			post.Slug = tt.slug
+			_, err := testGenerator(m).Generate(post)
+			if refused := errors.Is(err, ErrConflict); refused != tt.refused {
+				t.Errorf("%s next to %s: err = %v, want refused %t", tt.slug, tt.existing, err, tt.refused)
+			}
+			if err != nil && !tt.refused {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestGenerateForceAllowsNearIdenticalSlug(t *testing.T) {
+	m := siteFS(map[string]string{"docs/_posts/2024-05-01-hello.markdown": "existing"})
+	g := testGenerator(m)
+	g.Force = true
+	post := helloPost()
+	post.Slug = "hello-"
+	if _, err := g.Generate(post); err != nil {
+		t.Fatal(err)
+	}
+	m.content(t, "docs/_posts/2024-05-01-hello-.markdown")
+}
+
+func TestGenerateCreatesImagesParents(t *testing.T) {
+	m := newMemFS(nil)
+	g := testGenerator(m)
+	g.ImagesDir = "docs/assets/img"
+	r, err := g.Generate(helloPost())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.ImagesPath != "docs/assets/img/2024-05-01-hello" || !m.isDir(r.ImagesPath) {
+		t.Errorf("images folder %s not created", r.ImagesPath)
+	}
+}
+
+func TestGenerateRollsBackImagesParents(t *testing.T) {
+	m := newMemFS(map[string]string{"docs/assets/logo.png": "png"})
+	g := testGenerator(m)
+	g.ImagesDir = "docs/assets/img/posts"
+	m.fail["mkdir docs/assets/img/posts/2024-05-01-hello"] = fs.ErrPermission
+	if _, err := g.Generate(helloPost()); !errors.Is(err, fs.ErrPermission) {
+		t.Fatalf("err = %v, want fs.ErrPermission", err)
+	}
+	for _, dir := range []string{"docs/assets/img/posts", "docs/assets/img"} {
+		if m.exists(dir) {
+			t.Errorf("%s left behind", dir)
+		}
+	}
+	if !m.isDir("docs/assets") {
+		t.Errorf("existing folder docs/assets removed")
+	}
+}
@@ -0,0 +1,169 @@
+// Package posts loads the posts of a Jekyll site concurrently, keeping
+// their parsed front matter until the files change.
+package posts
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen/frontmatter"
+	"github.com/tiagomelo/tiagomelo.github.io/postgen/pool"
+)
+
+// Post is a post or draft file split into front matter and body.
+type Post struct {
+	// Path is the slash-separated path of the file in the corpus' FS.
+	Path string
+	// Slug is the file name without its extension and, for dated files,
+	// without its YYYY-MM-DD- prefix.
+	Slug    string
+	ModTime time.Time
+	Size    int64
+	// Content is nil when the file could not be read.
+	Content []byte
+	// Doc is nil when the file could not be read or its front matter
+	// parsed, Err telling why.
+	Doc *frontmatter.Document
+	Err error
+}
+
+// Corpus is a set of posts read from an FS. Its methods are safe for
+// concurrent use; the posts they return are shared and must not be
+// modified.
+type Corpus struct {
+	fsys fs.FS
+	// Match tells which file names are posts; all files are when it is
+	// nil.
+	Match func(name string) bool
+	// Workers is the number of files read at once, GOMAXPROCS when it
+	// is zero.
+	Workers int
+
+	mu    sync.Mutex
+	cache map[string]*Post
+	posts []*Post
+}
+
+// New returns an empty Corpus reading fsys.
+func New(fsys fs.FS) *Corpus {
+	return &Corpus{fsys: fsys, cache: make(map[string]*Post)}
+}
+
+// Load reads the posts of dirs, which then make up the corpus, each
+// folder's sorted by name. Files unchanged since a previous Load, going by
+// their size and modification time, are not read again.
+func (c *Corpus) Load(dirs ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var loaded, stale []*Post
+	for _, dir := range dirs {
+		entries, err := fs.ReadDir(c.fsys, dir)
+		if err != nil {
+			return fmt.Errorf("reading folder %s: %w", dir, err)
+		}
+		for _, e := range entries {
+			if e.IsDir() || c.Match != nil && !c.Match(e.Name()) {
+				continue
+			}
+			info, err := e.Info()
+			if err != nil {
+				return fmt.Errorf("reading file %s: %w", path.Join(dir, e.Name()), err)
+			}
+			p := path.Join(dir, e.Name())
+			if cached, ok := c.cache[p]; ok && cached.Size == info.Size() && cached.ModTime.Equal(info.ModTime()) {
+				loaded = append(loaded, cached)
+				continue
+			}
+			post := &Post{Path: p, Slug: slug(e.Name()), ModTime: info.ModTime(), Size: info.Size()}
+			loaded = append(loaded, post)
+			stale = append(stale, post)
+		}
+	}
+
+	names := make([]string, len(stale))
+	for i, post := range stale {
+		names[i] = post.Path
+	}
+	pool.Run(context.Background(), names, pool.Options{Jobs: c.Workers}, func(i int) { c.read(stale[i]) })
+	for _, post := range stale {
+		c.cache[post.Path] = post
+	}
+	c.posts = loaded
+	return nil
+}
+
+// Read returns the file at p, which need not be one of the posts Load
+// read, reading it only when it changed since it was last read.
+func (c *Corpus) Read(p string) *Post {
+	info, err := fs.Stat(c.fsys, p)
+	if err != nil {
+		return &Post{Path: p, Slug: slug(path.Base(p)), Err: err}
+	}
+	c.mu.Lock()
+	cached, ok := c.cache[p]
+	c.mu.Unlock()
+	if ok && cached.Size == info.Size() && cached.ModTime.Equal(info.ModTime()) {
+		return cached
+	}
+	post := &Post{Path: p, Slug: slug(path.Base(p)), ModTime: info.ModTime(), Size: info.Size()}
+	c.read(post)
+	c.mu.Lock()
+	c.cache[p] = post
+	c.mu.Unlock()
+	return post
+}
+
+// read reads and parses the file of post.
+func (c *Corpus) read(post *Post) {
+	content, err := fs.ReadFile(c.fsys, post.Path)
+	if err != nil {
+		post.Err = err
+		return
+	}
+	if content == nil {
+		content = []byte{}
+	}
+	post.Content = content
+	post.Doc, post.Err = frontmatter.Parse(content)
+}
+
+// Posts returns the posts of the corpus, in the order Load read them.
+func (c *Corpus) Posts() []*Post {
+	return c.Filter(func(*Post) bool { return true })
+}
+
+// Filter returns the posts of the corpus keep accepts.
+func (c *Corpus) Filter(keep func(*Post) bool) []*Post {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var posts []*Post
+	for _, post := range c.posts {
+		if keep(post) {
+			posts = append(posts, post)
+		}
+	}
+	return posts
+}
+
+// Lookup returns the posts of the corpus with the given slug, which
+// several posts of different days may share.
+func (c *Corpus) Lookup(s string) []*Post {
+	return c.Filter(func(post *Post) bool { return post.Slug == s })
+}
+
+// slug returns the slug of the post file name.
+func slug(name string) string {
+	name = strings.TrimSuffix(name, path.Ext(name))
+	const dateLayout = "2006-01-02"
+	if len(name) > len(dateLayout)+1 && name[len(dateLayout)] == '-' {
+		if _, err := time.Parse(dateLayout, name[:len(dateLayout)]); err == nil {
+			return name[len(dateLayout)+1:]
+		}
+	}
+	return name
+}
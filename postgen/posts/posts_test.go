@@ -0,0 +1,123 @@
+package posts
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// corpusFS returns an FS with n dated posts in _posts and a draft in
+// _drafts.
+func corpusFS(n int) fstest.MapFS {
+	fsys := fstest.MapFS{
+		"_drafts/draft.md": {Data: []byte("---\ntitle: Draft\n---\nBody.\n")},
+	}
+	day := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("_posts/%s-post-%d.markdown", day.AddDate(0, 0, i).Format("2006-01-02"), i)
+		content := fmt.Sprintf("---\nlayout: post\ntitle: \"Post %d\"\ncategories: go\ntags: [go, testing]\n---\n# Post %d\n\nSome body text for post %d.\n", i, i, i)
+		fsys[name] = &fstest.MapFile{Data: []byte(content), ModTime: day}
+	}
+	return fsys
+}
+
+func TestLoad(t *testing.T) {
+	c := New(corpusFS(3))
+	if err := c.Load("_posts", "_drafts"); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, post := range c.Posts() {
+		if post.Err != nil {
+			t.Fatalf("%s: %v", post.Path, post.Err)
+		}
+		got = append(got, post.Slug)
+	}
+	if want := "post-0 post-1 post-2 draft"; strings.Join(got, " ") != want {
+		t.Errorf("slugs = %q, want %s", got, want)
+	}
+	if posts := c.Lookup("post-1"); len(posts) != 1 || posts[0].Path != "_posts/2020-01-02-post-1.markdown" {
+		t.Errorf("Lookup(post-1) = %v", posts)
+	}
+}
+
+func TestLoadRereadsChangedFiles(t *testing.T) {
+	fsys := corpusFS(2)
+	c := New(fsys)
+	if err := c.Load("_posts"); err != nil {
+		t.Fatal(err)
+	}
+	unchanged, changed := c.Posts()[0], c.Posts()[1]
+	fsys[changed.Path] = &fstest.MapFile{Data: []byte("---\ntitle: Changed\n---\n"), ModTime: changed.ModTime.Add(time.Second)}
+	if err := c.Load("_posts"); err != nil {
+		t.Fatal(err)
+	}
+	posts := c.Posts()
+	if posts[0] != unchanged {
+		t.Errorf("unchanged %s read again", unchanged.Path)
+	}
+	if posts[1] == changed || string(posts[1].Content) != "---\ntitle: Changed\n---\n" {
+		t.Errorf("changed %s not read again", changed.Path)
+	}
+}
+
+func TestRead(t *testing.T) {
+	fsys := corpusFS(1)
+	c := New(fsys)
+	if err := c.Load("_posts"); err != nil {
+		t.Fatal(err)
+	}
+	loaded := c.Posts()[0]
+	if post := c.Read(loaded.Path); post != loaded {
+		t.Errorf("%s read again", loaded.Path)
+	}
+	fsys[loaded.Path] = &fstest.MapFile{Data: []byte("---\ntitle: Changed\n---\n"), ModTime: loaded.ModTime.Add(time.Second)}
+	if post := c.Read(loaded.Path); post == loaded || post.Err != nil || string(post.Content) != "---\ntitle: Changed\n---\n" {
+		t.Errorf("changed %s not read again", loaded.Path)
+	}
+	if post := c.Read("_drafts/draft.md"); post.Err != nil || post.Slug != "draft" {
+		t.Errorf("Read(_drafts/draft.md) = %+v", post)
+	}
+	fsys["_posts/empty.md"] = &fstest.MapFile{}
+	if post := c.Read("_posts/empty.md"); post.Content == nil || post.Err == nil {
+		t.Errorf("empty file: content %v, err %v, want read but not parsed", post.Content, post.Err)
+	}
+	if post := c.Read("_posts/missing.md"); post.Content != nil || post.Err == nil {
+		t.Errorf("missing file: content %v, err %v, want an error", post.Content, post.Err)
+	}
+}
+
+// TestConcurrentLoad is meant to be run with -race.
+func TestConcurrentLoad(t *testing.T) {
+	c := New(corpusFS(50))
+	c.Workers = 4
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.Load("_posts", "_drafts"); err != nil {
+				t.Error(err)
+				return
+			}
+			if n := len(c.Posts()); n != 51 {
+				t.Errorf("%d posts, want 51", n)
+			}
+			c.Lookup("post-7")
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkLoad(b *testing.B) {
+	fsys := corpusFS(500)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := New(fsys).Load("_posts"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
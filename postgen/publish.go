@@ -0,0 +1,98 @@
+package postgen
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen/frontmatter"
+)
+
+// Publish moves the draft <slug> from DraftsDir into PostsDir under a
+// file name dated with the generator's clock, stamps its front matter
+// date, drops published: false and renames its images folder to match.
+// A draft left by Unpublish with an OriginalDateKey gets that date back
+// instead, and one with an OriginalPublishedKey that published value. Nothing is left half-moved when a step fails.
+func (g *Generator) Publish(slug string) (Result, error) {
+	draftPath := path.Join(g.DraftsDir, slug+"."+g.Ext)
+	if _, err := g.FS.Stat(draftPath); err != nil {
+		for _, ext := range Exts {
+			if p := path.Join(g.DraftsDir, slug+"."+ext); p != draftPath {
+				if _, err := g.FS.Stat(p); err == nil {
+					draftPath = p
+					break
+				}
+			}
+		}
+	}
+	content, err := g.FS.ReadFile(draftPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return Result{}, fmt.Errorf("draft %s does not exist", draftPath)
+		}
+		return Result{}, fmt.Errorf("reading file %s: %w", draftPath, err)
+	}
+	doc, err := frontmatter.Parse(content)
+	if err != nil {
+		return Result{}, fmt.Errorf("parsing %s: %w", draftPath, err)
+	}
+	now := g.Now()
+	date := now.Format(DateLayout)
+	if raw, ok := doc.Raw(OriginalDateKey); ok {
+		if now, err = frontmatter.ParseTimeIn(strings.Trim(raw, `"'`), now.Location()); err != nil {
+			return Result{}, fmt.Errorf("parsing the %s of %s: %w", OriginalDateKey, draftPath, err)
+		}
+		date = raw
+		doc.Delete(OriginalDateKey)
+	}
+	name := now.Format(FileDateLayout) + "-" + slug
+	// The draft keeps its extension when published.
+	postPath := path.Join(g.PostsDir, name+path.Ext(draftPath))
+	draftImagesPath := path.Join(g.ImagesDir, slug)
+	postImagesPath := path.Join(g.ImagesDir, name)
+	doc.SetRaw("date", date)
+	if raw, ok := doc.Raw(OriginalPublishedKey); ok {
+		doc.SetRaw("published", raw)
+		doc.Delete(OriginalPublishedKey)
+	} else {
+		doc.Delete("published")
+	}
+	g.rewriteImagesFolder(doc, slug, name)
+	content = doc.Bytes()
+
+	r := Result{MarkdownPath: postPath, Slug: slug, Date: now, Content: content}
+	oldImages := ""
+	if _, err := g.FS.Stat(draftImagesPath); err == nil {
+		oldImages, r.ImagesPath = draftImagesPath, postImagesPath
+	}
+	if err := g.move(draftPath, postPath, content, oldImages, r.ImagesPath); err != nil {
+		return Result{}, err
+	}
+	return r, nil
+}
+
+// RewriteImagesFolder points references to <imagesURL>/<from> in body at
+// <imagesURL>/<to>, leaving folders that merely share the prefix alone.
+func RewriteImagesFolder(body []byte, imagesURL, from, to string) []byte {
+	return imagesFolderPattern(imagesURL, from).ReplaceAll(body, []byte(imagesURL+"/"+to+"${1}"))
+}
+
+// rewriteImagesFolder points the references of doc to the images folder
+// from, in its body and its image front matter key, at the folder to.
+func (g *Generator) rewriteImagesFolder(doc *frontmatter.Document, from, to string) {
+	doc.Body = RewriteImagesFolder(doc.Body, g.ImagesURL(), from, to)
+	if raw, ok := doc.Raw("image"); ok {
+		if rewritten := string(RewriteImagesFolder([]byte(raw), g.ImagesURL(), from, to)); rewritten != raw {
+			doc.SetRaw("image", rewritten)
+		}
+	}
+}
+
+// imagesFolderPattern matches references to <imagesURL>/<name>, capturing
+// the character that ends the folder name.
+func imagesFolderPattern(imagesURL, name string) *regexp.Regexp {
+	return regexp.MustCompile(regexp.QuoteMeta(imagesURL+"/"+name) + `([/"')\s]|$)`)
+}
@@ -0,0 +1,114 @@
+package postgen
+
+import (
+	"strings"
+	"testing"
+)
+
+// coverPost returns a post or draft whose cover and body image point
+// into the images folder folder.
+func coverPost(folder string) string {
+	return "---\nlayout: post\ntitle:  \"Hello\"\ndate:   2024-04-01 08:00:00 -0300\nimage: /assets/images/" + folder + "/cover.png\n---\n![diagram](/assets/images/" + folder + "/diagram.png)\n"
+}
+
+// checkImagesFolder fails t unless both references of content, made by
+// coverPost, point into folder.
+func checkImagesFolder(t *testing.T, content, folder string) {
+	t.Helper()
+	var meta struct {
+		Image string `yaml:"image"`
+	}
+	frontMatter(t, []byte(content), &meta)
+	if want := "/assets/images/" + folder + "/cover.png"; meta.Image != want {
+		t.Errorf("image = %q, want %q", meta.Image, want)
+	}
+	if want := "](/assets/images/" + folder + "/diagram.png)"; !strings.Contains(content, want) {
+		t.Errorf("body does not reference %s:\n%s", want, content)
+	}
+}
+
+func TestPublishMovesCover(t *testing.T) {
+	m := siteFS(map[string]string{
+		"docs/_drafts/hello.markdown":          coverPost("hello"),
+		"docs/assets/images/hello/cover.png":   "png",
+		"docs/assets/images/hello/diagram.png": "png",
+	})
+	r, err := testGenerator(m).Publish("hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkImagesFolder(t, m.content(t, r.MarkdownPath), "2024-05-01-hello")
+	m.content(t, "docs/assets/images/2024-05-01-hello/cover.png")
+}
+
+func TestUnpublishMovesCover(t *testing.T) {
+	m := siteFS(map[string]string{
+		"docs/_posts/2024-04-01-hello.markdown":           coverPost("2024-04-01-hello"),
+		"docs/assets/images/2024-04-01-hello/cover.png":   "png",
+		"docs/assets/images/2024-04-01-hello/diagram.png": "png",
+	})
+	r, err := testGenerator(m).Unpublish("docs/_posts/2024-04-01-hello.markdown", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkImagesFolder(t, m.content(t, r.MarkdownPath), "hello")
+	m.content(t, "docs/assets/images/hello/cover.png")
+}
+
+func TestRenameMovesCover(t *testing.T) {
+	m := siteFS(map[string]string{
+		"docs/_posts/2024-04-01-hello.markdown":           coverPost("2024-04-01-hello"),
+		"docs/assets/images/2024-04-01-hello/cover.png":   "png",
+		"docs/assets/images/2024-04-01-hello/diagram.png": "png",
+	})
+	g := testGenerator(m)
+	r, err := g.PlanRename("docs/_posts/2024-04-01-hello.markdown", "Goodbye", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.ApplyRename(r); err != nil {
+		t.Fatal(err)
+	}
+	checkImagesFolder(t, m.content(t, r.NewPath), "2024-04-01-goodbye")
+	m.content(t, "docs/assets/images/2024-04-01-goodbye/cover.png")
+}
+
+func TestRenameKeepsOtherCovers(t *testing.T) {
+	content := strings.Replace(coverPost("2024-04-01-hello"), "/assets/images/2024-04-01-hello/cover.png", "/assets/images/2024-04-01-hello-world/cover.png", 1)
+	m := siteFS(map[string]string{"docs/_posts/2024-04-01-hello.markdown": content})
+	r, err := testGenerator(m).PlanRename("docs/_posts/2024-04-01-hello.markdown", "Goodbye", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var meta struct {
+		Image string `yaml:"image"`
+	}
+	frontMatter(t, r.NewContent, &meta)
+	if want := "/assets/images/2024-04-01-hello-world/cover.png"; meta.Image != want {
+		t.Errorf("image = %q, want %q", meta.Image, want)
+	}
+}
+
+func TestUnpublishPublishRoundTrip(t *testing.T) {
+	for _, published := range []string{"", "published: true\n"} {
+		t.Run(published, func(t *testing.T) {
+			post := "---\nlayout: post\ntitle:  \"Hello\"\ndate:   2024-04-01 08:00:00 -0300\n" + published + "tags: [go]\n---\nBody.\n"
+			p := "docs/_posts/2024-04-01-hello.markdown"
+			m := siteFS(map[string]string{p: post})
+			g := testGenerator(m)
+			if _, err := g.Unpublish(p, true); err != nil {
+				t.Fatal(err)
+			}
+			r, err := g.Publish("hello")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.MarkdownPath != p {
+				t.Errorf("published as %s, want %s", r.MarkdownPath, p)
+			}
+			if got := m.content(t, p); got != post {
+				t.Errorf("round trip changed the post to:\n%s\nfrom:\n%s", got, post)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package postgen
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultWordsPerMinute is the reading speed used when none is configured.
+const DefaultWordsPerMinute = 200
+
+var (
+	linkPattern   = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+	markupPattern = regexp.MustCompile(`<[^>]*>|\{%.*?%\}|\{\{.*?\}\}`)
+)
+
+// TextCount measures the prose of a post body.
+type TextCount struct {
+	Words int
+	// Chars counts the characters of the words, spaces excluded.
+	Chars int
+}
+
+// CountText measures a post body, leaving out code blocks, highlight and
+// raw blocks included, the URLs of links and images, HTML tags and Liquid
+// markup.
+func CountText(body []byte) TextCount {
+	var count TextCount
+	for _, field := range bytes.Fields(prose(body)) {
+		if bytes.IndexFunc(field, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) >= 0 {
+			count.Words++
+			count.Chars += utf8.RuneCount(field)
+		}
+	}
+	return count
+}
+
+// prose returns body without its code blocks, link and image URLs, HTML
+// tags and Liquid markup.
+func prose(body []byte) []byte {
+	body = codeBlockPattern.ReplaceAll(body, nil)
+	body = linkPattern.ReplaceAll(body, []byte(" $1 "))
+	return markupPattern.ReplaceAll(body, []byte(" "))
+}
+
+// CountFile measures the body of the post or draft at p with CountText.
+func (g *Generator) CountFile(p string) (TextCount, error) {
+	doc, _, err := g.readDocument(p)
+	if err != nil {
+		return TextCount{}, err
+	}
+	return CountText(doc.Body), nil
+}
+
+// CountWords counts the words of a post body as CountText does.
+func CountWords(body []byte) int {
+	return CountText(body).Words
+}
+
+// ReadingMinutes returns the minutes needed to read words words at wpm
+// words per minute, rounded up and never less than one.
+func ReadingMinutes(words, wpm int) int {
+	minutes := (words + wpm - 1) / wpm
+	if minutes < 1 {
+		return 1
+	}
+	return minutes
+}
+
+// ReadingTimeChange is a post whose reading_time front matter key is
+// missing or stale.
+type ReadingTimeChange struct {
+	Change
+	// Stored is the current value, empty when the key is missing.
+	Stored  string
+	Minutes int
+}
+
+// ReadingTimes computes the reading time of every post and draft at wpm
+// words per minute, returning the ones whose reading_time key needs to
+// be written.
+func (g *Generator) ReadingTimes(wpm int) ([]ReadingTimeChange, error) {
+	if wpm <= 0 {
+		return nil, fmt.Errorf("invalid words per minute %d", wpm)
+	}
+	files, err := g.Files()
+	if err != nil {
+		return nil, err
+	}
+	var changes []ReadingTimeChange
+	for _, p := range files {
+		ch, stale, err := g.PlanReadingTime(p, wpm)
+		if err != nil {
+			return nil, err
+		}
+		if stale {
+			changes = append(changes, ch)
+		}
+	}
+	return changes, nil
+}
+
+// PlanReadingTime computes the reading time of the post or draft at p at
+// wpm words per minute, reporting whether its reading_time key needs to
+// be written.
+func (g *Generator) PlanReadingTime(p string, wpm int) (ReadingTimeChange, bool, error) {
+	doc, content, err := g.readDocument(p)
+	if err != nil {
+		return ReadingTimeChange{}, false, err
+	}
+	minutes := ReadingMinutes(CountWords(doc.Body), wpm)
+	stored, _ := doc.Raw("reading_time")
+	if strings.TrimSpace(stored) == strconv.Itoa(minutes) {
+		return ReadingTimeChange{}, false, nil
+	}
+	doc.SetRaw("reading_time", strconv.Itoa(minutes))
+	return ReadingTimeChange{
+		Change:  Change{Path: p, OldContent: content, NewContent: doc.Bytes()},
+		Stored:  stored,
+		Minutes: minutes,
+	}, true, nil
+}
@@ -0,0 +1,150 @@
+package postgen
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen/diff"
+	"github.com/tiagomelo/tiagomelo.github.io/postgen/frontmatter"
+)
+
+// SlugMismatch is a post whose file name slug is not the one its title
+// generates.
+type SlugMismatch struct {
+	Entry     Entry
+	TitleSlug string
+}
+
+// titleWordPattern matches what Slugify separates with hyphens.
+var titleWordPattern = regexp.MustCompile(`[^\s\-_/\\]+`)
+
+// SlugMismatches returns the posts and drafts at paths whose title, run
+// through Slugify, does not give their file name slug. Posts without a
+// title are left out.
+func (g *Generator) SlugMismatches(paths []string) ([]SlugMismatch, error) {
+	var mismatches []SlugMismatch
+	for _, p := range paths {
+		e, err := g.Read(p)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", p, err)
+		}
+		if slug := Slugify(e.Meta.Title); slug != "" && slug != e.Slug {
+			mismatches = append(mismatches, SlugMismatch{Entry: e, TitleSlug: slug})
+		}
+	}
+	return mismatches, nil
+}
+
+// TitleFromSlug returns title changed as little as possible so that
+// Slugify gives slug: the words of title missing from slug are removed,
+// those of slug missing from title inserted, capitalized when the word
+// they replace is. Punctuation is kept.
+func TitleFromSlug(title, slug string) string {
+	type word struct {
+		sep, text string
+	}
+	var words []word
+	var keys []string
+	var keyed []int
+	last := 0
+	for _, m := range titleWordPattern.FindAllStringIndex(title, -1) {
+		text := title[m[0]:m[1]]
+		if key := Slugify(text); key != "" {
+			keys = append(keys, key)
+			keyed = append(keyed, len(words))
+		}
+		words = append(words, word{title[last:m[0]], text})
+		last = m[1]
+	}
+	tail := title[last:]
+
+	// replaced holds the words slug changes, by their index in words;
+	// inserted the words slug adds after them, -1 being the beginning.
+	replaced := make(map[int]string)
+	removed := make(map[int]bool)
+	inserted := make(map[int][]string)
+	i, at := 0, -1
+	var deleted []int
+	for _, e := range diff.Compute(keys, strings.Split(slug, "-")) {
+		switch e.Op {
+		case diff.Equal:
+			at, deleted = keyed[i], nil
+			i++
+		case diff.Delete:
+			removed[keyed[i]] = true
+			deleted = append(deleted, keyed[i])
+			i++
+		case diff.Insert:
+			if len(deleted) > 0 {
+				w := deleted[0]
+				deleted = deleted[1:]
+				delete(removed, w)
+				replaced[w] = recase(e.Line, words[w].text)
+				at = w
+				continue
+			}
+			inserted[at] = append(inserted[at], e.Line)
+		}
+	}
+
+	var b strings.Builder
+	add := func(sep, text string) {
+		if b.Len() == 0 {
+			sep = ""
+		} else if sep == "" {
+			sep = " "
+		}
+		b.WriteString(sep + text)
+	}
+	for _, w := range inserted[-1] {
+		add(" ", w)
+	}
+	for j, w := range words {
+		switch {
+		case removed[j]:
+		case replaced[j] != "":
+			add(w.sep, replaced[j])
+		default:
+			add(w.sep, w.text)
+		}
+		for _, w := range inserted[j] {
+			add(" ", w)
+		}
+	}
+	b.WriteString(tail)
+	out := strings.TrimSpace(b.String())
+	if r, t := []rune(out), []rune(title); len(r) > 0 && unicode.IsUpper(t[0]) {
+		r[0] = unicode.ToUpper(r[0])
+		out = string(r)
+	}
+	return out
+}
+
+// recase returns word, a lowercase slug word, capitalized when like is,
+// with the leading and trailing punctuation of like.
+func recase(word, like string) string {
+	r := []rune(like)
+	start, end := 0, len(r)
+	for start < end && Slugify(string(r[start])) == "" {
+		start++
+	}
+	for end > start && Slugify(string(r[end-1])) == "" {
+		end--
+	}
+	if end > start && unicode.IsUpper(r[start]) {
+		word = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return string(r[:start]) + word + string(r[end:])
+}
+
+// PlanRetitle computes setting the title of the post at p to title.
+func (g *Generator) PlanRetitle(p, title string) (Change, error) {
+	doc, content, err := g.readDocument(p)
+	if err != nil {
+		return Change{}, err
+	}
+	doc.SetRaw("title", frontmatter.String(title))
+	return Change{Path: p, OldContent: content, NewContent: doc.Bytes()}, nil
+}
@@ -0,0 +1,193 @@
+package postgen
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen/frontmatter"
+)
+
+// RedirectProblem is a redirect that does not lead where it should.
+type RedirectProblem struct {
+	Post    string
+	URL     string
+	Message string
+}
+
+func (p RedirectProblem) String() string {
+	return p.Post + ": " + p.URL + ": " + p.Message
+}
+
+// addRedirect adds url to the redirect_from list, keeping it sorted and
+// free of duplicates.
+func addRedirect(doc *frontmatter.Document, url string) error {
+	var meta struct {
+		RedirectFrom frontmatter.List `yaml:"redirect_from"`
+	}
+	if err := doc.Decode(&meta); err != nil {
+		return err
+	}
+	urls := []string{url}
+	for _, existing := range meta.RedirectFrom {
+		if existing == url {
+			return nil
+		}
+		urls = append(urls, existing)
+	}
+	sort.Strings(urls)
+	doc.SetRaw("redirect_from", frontmatter.BlockList(urls))
+	return nil
+}
+
+// published returns the posts of entries that Jekyll renders.
+func published(entries []Entry) []Entry {
+	var live []Entry
+	for _, e := range entries {
+		if e.Meta.Published == nil || *e.Meta.Published {
+			live = append(live, e)
+		}
+	}
+	return live
+}
+
+// RedirectCollision returns an error when a redirect from url would
+// shadow the live permalink of a post, or duplicate the redirect of
+// another one. permalink is the site's permalink setting. The post at
+// skip, whose permalink is about to change, is not considered. Collisions
+// match ErrConflict.
+func (g *Generator) RedirectCollision(url, permalink, skip string) error {
+	if !strings.HasPrefix(url, "/") {
+		return fmt.Errorf("invalid redirect %s: expected a URL path starting with /", url)
+	}
+	entries, _, err := g.List()
+	if err != nil {
+		return err
+	}
+	key := urlKey(url)
+	for _, e := range published(entries) {
+		if e.Path != skip && urlKey(Permalink(permalink, e)) == key {
+			return conflictf("redirect %s would shadow the permalink of %s", url, e.Path)
+		}
+	}
+	for _, e := range entries {
+		if e.Path == skip {
+			continue
+		}
+		for _, u := range e.Meta.RedirectFrom {
+			if urlKey(u) == key {
+				return conflictf("%s already redirects from %s", e.Path, u)
+			}
+		}
+	}
+	return nil
+}
+
+// PlanRedirect computes adding url to the redirect_from list of the post
+// at p, failing on the collisions RedirectCollision reports. No change is
+// returned when p already redirects from url.
+func (g *Generator) PlanRedirect(p, url, permalink string) ([]Change, error) {
+	if g.IsDraft(p) {
+		return nil, errors.New("drafts are not published, so there is nothing to redirect to")
+	}
+	doc, content, err := g.readDocument(p)
+	if err != nil {
+		return nil, err
+	}
+	if err := addRedirect(doc, url); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", p, err)
+	}
+	newContent := doc.Bytes()
+	if string(newContent) == string(content) {
+		return nil, nil
+	}
+	if err := g.RedirectCollision(url, permalink, ""); err != nil {
+		return nil, err
+	}
+	return []Change{{Path: p, OldContent: content, NewContent: newContent}}, nil
+}
+
+// CheckRedirects reports, for every published post, the redirect_from
+// entries that shadow a live permalink or another post's redirect, and
+// the redirects that lead to another redirect, through redirect_to or
+// redirect_from, or back to where they started.
+func (g *Generator) CheckRedirects(permalink string) ([]RedirectProblem, []*FileError, error) {
+	entries, bad, err := g.List()
+	if err != nil {
+		return nil, nil, err
+	}
+	entries = published(entries)
+	live := make(map[string]string)
+	for _, e := range entries {
+		live[urlKey(Permalink(permalink, e))] = e.Path
+	}
+
+	// next maps each URL that redirects to where it redirects to: a
+	// redirect_from source to its post's permalink, and the permalink of
+	// a post with redirect_to to that URL.
+	type hop struct {
+		post string
+		url  string
+	}
+	next := make(map[string]hop)
+	var problems []RedirectProblem
+	var starts []string
+	for _, e := range entries {
+		target := Permalink(permalink, e)
+		for _, u := range e.Meta.RedirectFrom {
+			key := urlKey(u)
+			if p, ok := live[key]; ok {
+				problems = append(problems, RedirectProblem{e.Path, u, "shadows the permalink of " + p})
+				continue
+			}
+			if h, ok := next[key]; ok {
+				problems = append(problems, RedirectProblem{e.Path, u, "also redirects to " + h.post})
+				continue
+			}
+			next[key] = hop{e.Path, target}
+			starts = append(starts, u)
+		}
+		if e.Meta.RedirectTo != "" && strings.HasPrefix(e.Meta.RedirectTo, "/") {
+			next[urlKey(target)] = hop{e.Path, e.Meta.RedirectTo}
+			starts = append(starts, target)
+		}
+	}
+
+	targeted := make(map[string]bool)
+	for _, h := range next {
+		targeted[urlKey(h.url)] = true
+	}
+	reported := make(map[string]bool)
+	for _, start := range starts {
+		path := []string{start}
+		seen := map[string]bool{urlKey(start): true}
+		loop := false
+		for h, ok := next[urlKey(start)]; ok; h, ok = next[urlKey(h.url)] {
+			path = append(path, h.url)
+			if seen[urlKey(h.url)] {
+				loop = true
+				break
+			}
+			seen[urlKey(h.url)] = true
+		}
+		first := next[urlKey(start)]
+		switch {
+		case loop:
+			// Every URL of a loop is a start; report it once.
+			keys := make([]string, 0, len(seen))
+			for k := range seen {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			if id := strings.Join(keys, " "); !reported[id] {
+				reported[id] = true
+				problems = append(problems, RedirectProblem{first.post, start, "redirect loop " + strings.Join(path, " -> ")})
+			}
+		case len(path) > 2 && !targeted[urlKey(start)]:
+			problems = append(problems, RedirectProblem{first.post, start, "redirect chain " + strings.Join(path, " -> ")})
+		}
+	}
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].Post < problems[j].Post })
+	return problems, bad, nil
+}
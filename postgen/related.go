@@ -0,0 +1,180 @@
+package postgen
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen/frontmatter"
+)
+
+// DefaultRelatedPosts is the number of related posts Related keeps per
+// post unless told otherwise.
+const DefaultRelatedPosts = 3
+
+// RelatedOptions configures Related.
+type RelatedOptions struct {
+	// Count is the number of related posts kept per post,
+	// DefaultRelatedPosts when zero.
+	Count int
+	// MinScore, from 0 to 1, is the score a post must exceed to be
+	// related at all.
+	MinScore float64
+	// StopWords are the words left out of the titles and descriptions
+	// compared, lowercase.
+	StopWords map[string]bool
+}
+
+// RelatedChange is writing the slugs of the posts related to a post into
+// its related key, or removing the key when Related is empty.
+type RelatedChange struct {
+	Change
+	Related []string
+}
+
+// Related scores every pair of published posts, drafts left out, half by the overlap of their
+// categories and tags and half by the cosine similarity of the TF-IDF
+// weights of the words of their titles and descriptions, and plans
+// writing the slugs of the best scoring ones over MinScore into the
+// related key of each post. Ties are broken by date, newest first, then
+// by slug, so that running it again changes nothing; only the posts whose
+// related posts change are returned. Unreadable posts are reported as in
+// List. Interrupted through ctx, it returns the changes planned so far.
+func (g *Generator) Related(ctx context.Context, opts RelatedOptions) ([]RelatedChange, []*FileError, error) {
+	all, bad, err := g.List()
+	if err != nil {
+		return nil, nil, err
+	}
+	count := opts.Count
+	if count == 0 {
+		count = DefaultRelatedPosts
+	}
+	entries := published(all)
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	terms := make([]map[string]bool, len(entries))
+	words := make([]map[string]float64, len(entries))
+	df := make(map[string]int)
+	for i, e := range entries {
+		terms[i] = make(map[string]bool)
+		for _, t := range append(append([]string{}, e.Meta.Categories...), e.Meta.Tags...) {
+			terms[i][strings.ToLower(t)] = true
+		}
+		words[i] = make(map[string]float64)
+		for _, w := range seoWords(e.Meta.Title + " " + e.Meta.Description) {
+			if !opts.StopWords[w] {
+				words[i][w]++
+			}
+		}
+		for w := range words[i] {
+			df[w]++
+		}
+	}
+	for i := range words {
+		for w, n := range words[i] {
+			words[i][w] = n * math.Log(float64(len(entries))/float64(df[w]))
+		}
+	}
+	type match struct {
+		entry Entry
+		score float64
+	}
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = e.Path
+	}
+	planned := make([]*RelatedChange, len(entries))
+	errs := make([]error, len(entries))
+	n, interrupted := g.run(ctx, names, func(i int) {
+		e := entries[i]
+		var matches []match
+		for j, other := range entries {
+			if i == j || other.Slug == e.Slug {
+				continue
+			}
+			score := (jaccard(terms[i], terms[j]) + cosine(words[i], words[j])) / 2
+			if score > opts.MinScore {
+				matches = append(matches, match{other, score})
+			}
+		}
+		sort.SliceStable(matches, func(a, b int) bool {
+			x, y := matches[a], matches[b]
+			switch {
+			case x.score != y.score:
+				return x.score > y.score
+			case !x.entry.Date.Equal(y.entry.Date):
+				return x.entry.Date.After(y.entry.Date)
+			}
+			return x.entry.Slug < y.entry.Slug
+		})
+		var related []string
+		for _, m := range matches {
+			if len(related) == count {
+				break
+			}
+			if !contains(related, m.entry.Slug) {
+				related = append(related, m.entry.Slug)
+			}
+		}
+		if strings.Join(related, " ") == strings.Join(e.Meta.Related, " ") {
+			return
+		}
+		doc, content, err := g.readDocument(e.Path)
+		if err != nil {
+			errs[i] = err
+			return
+		}
+		if len(related) == 0 {
+			doc.Delete("related")
+		} else if raw, ok := doc.Raw("related"); ok && strings.HasPrefix(raw, "[") {
+			doc.SetRaw("related", frontmatter.FlowList(related))
+		} else {
+			doc.SetRaw("related", frontmatter.BlockList(related))
+		}
+		planned[i] = &RelatedChange{
+			Change:  Change{Path: e.Path, OldContent: content, NewContent: doc.Bytes()},
+			Related: related,
+		}
+	})
+	var changes []RelatedChange
+	for i := range planned[:n] {
+		if errs[i] != nil {
+			return nil, nil, errs[i]
+		}
+		if planned[i] != nil {
+			changes = append(changes, *planned[i])
+		}
+	}
+	return changes, bad, interrupted
+}
+
+// jaccard returns the share of the items of a and b that both have.
+func jaccard(a, b map[string]bool) float64 {
+	shared := 0
+	for t := range a {
+		if b[t] {
+			shared++
+		}
+	}
+	if union := len(a) + len(b) - shared; union > 0 {
+		return float64(shared) / float64(union)
+	}
+	return 0
+}
+
+// cosine returns the cosine similarity of the weights a and b, summed in
+// sorted order so that the result is the same on every run.
+func cosine(a, b map[string]float64) float64 {
+	var dot, na, nb float64
+	for _, w := range sortedKeys(a) {
+		dot += a[w] * b[w]
+		na += a[w] * a[w]
+	}
+	for _, w := range sortedKeys(b) {
+		nb += b[w] * b[w]
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
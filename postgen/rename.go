@@ -0,0 +1,71 @@
+package postgen
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen/frontmatter"
+)
+
+// Rename describes retitling a post: its new file name, images folder
+// and content. Image paths are empty when the post has no folder.
+type Rename struct {
+	OldPath    string
+	NewPath    string
+	OldImages  string
+	NewImages  string
+	OldContent []byte
+	NewContent []byte
+}
+
+// PlanRename computes retitling the post or draft at markdownPath to
+// title, with slug as the new slug (generated from title when empty).
+// A non-empty redirectFrom is added to the redirect_from front matter
+// list.
+func (g *Generator) PlanRename(markdownPath, title, slug, redirectFrom string) (Rename, error) {
+	if slug == "" {
+		slug = title
+	}
+	slug = Slugify(slug)
+	if slug == "" {
+		return Rename{}, SlugError(title)
+	}
+	base := strings.TrimSuffix(path.Base(markdownPath), path.Ext(markdownPath))
+	name := slug
+	if date, _, ok := ParseFileName(path.Base(markdownPath)); ok {
+		name = date.Format(FileDateLayout) + "-" + slug
+	}
+	content, err := g.FS.ReadFile(markdownPath)
+	if err != nil {
+		return Rename{}, fmt.Errorf("reading file %s: %w", markdownPath, err)
+	}
+	doc, err := frontmatter.Parse(content)
+	if err != nil {
+		return Rename{}, fmt.Errorf("parsing %s: %w", markdownPath, err)
+	}
+	doc.SetRaw("title", frontmatter.String(title))
+	if redirectFrom != "" {
+		if err := addRedirect(doc, redirectFrom); err != nil {
+			return Rename{}, fmt.Errorf("parsing %s: %w", markdownPath, err)
+		}
+	}
+	g.rewriteImagesFolder(doc, base, name)
+	r := Rename{
+		OldPath:    markdownPath,
+		NewPath:    path.Join(path.Dir(markdownPath), name+path.Ext(markdownPath)),
+		OldContent: content,
+		NewContent: doc.Bytes(),
+	}
+	if folder := g.ImagesFolder(markdownPath); name != base {
+		if _, err := g.FS.Stat(folder); err == nil {
+			r.OldImages, r.NewImages = folder, path.Join(g.ImagesDir, name)
+		}
+	}
+	return r, nil
+}
+
+// ApplyRename carries out r, leaving nothing half-renamed on failure.
+func (g *Generator) ApplyRename(r Rename) error {
+	return g.move(r.OldPath, r.NewPath, r.NewContent, r.OldImages, r.NewImages)
+}
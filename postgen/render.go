@@ -0,0 +1,210 @@
+package postgen
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+// HTMLOptions configures RenderHTML.
+type HTMLOptions struct {
+	// SiteURL is the absolute URL the site is served at, such as
+	// https://tiagomelo.github.io, post_url links pointing at it when set;
+	// BaseURL is its baseurl, such as /blog.
+	SiteURL string
+	BaseURL string
+	// Permalink is the site's permalink setting, as passed to Permalink.
+	Permalink string
+}
+
+// rawTagPattern matches the tags of Liquid raw blocks.
+var rawTagPattern = regexp.MustCompile(`\{%-?\s*(?:end)?raw\s*-?%\}`)
+
+// htmlPage is the template RenderHTML wraps bodies in.
+var htmlPage = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html{{with .Lang}} lang="{{.}}"{{end}}>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { margin: 0 auto; max-width: 46rem; padding: 2rem 1rem; font: 18px/1.6 Georgia, serif; color: #222; }
+h1, h2, h3, h4, h5, h6 { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; line-height: 1.25; }
+.meta { color: #777; font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; font-size: .9rem; }
+a { color: #0b62a4; }
+img { max-width: 100%; height: auto; }
+pre { overflow-x: auto; padding: 1rem; background: #f6f8fa; border-radius: 4px; font-size: .85rem; line-height: 1.45; }
+code { font-family: SFMono-Regular, Menlo, Consolas, monospace; }
+:not(pre) > code { padding: .1em .3em; background: #f0f0f0; border-radius: 3px; font-size: .85em; }
+blockquote { margin: 0; padding-left: 1rem; border-left: 4px solid #ddd; color: #555; }
+table { border-collapse: collapse; }
+th, td { padding: .3rem .6rem; border: 1px solid #ddd; }
+.liquid { padding: .1em .3em; border: 1px dashed #c60; color: #c60; font-family: monospace; font-size: .85em; }
+</style>
+</head>
+<body>
+<article>
+<header>
+<h1>{{.Title}}</h1>
+<p class="meta">{{.Date}}{{if .Draft}}{{if .Date}} · {{end}}draft{{end}}</p>
+</header>
+{{.Body}}
+</article>
+</body>
+</html>
+`))
+
+// RenderHTML renders the post or draft at p as a standalone HTML page for
+// review: its body converted from Markdown, highlight blocks as code
+// blocks and post_url tags as links to the posts, the other Liquid tags
+// and outputs, which cannot be evaluated, showing as placeholders, and
+// the local images it references embedded as data URIs, so that the page
+// is a single file.
+func (g *Generator) RenderHTML(p string, opts HTMLOptions) (Export, error) {
+	e, err := g.Read(p)
+	if err != nil {
+		return Export{}, fmt.Errorf("parsing %s: %w", p, err)
+	}
+	doc, _, err := g.readDocument(p)
+	if err != nil {
+		return Export{}, err
+	}
+	entries, _, err := g.List()
+	if err != nil {
+		return Export{}, err
+	}
+	site := strings.TrimSuffix(opts.SiteURL, "/")
+	posts := make(map[string]string)
+	for _, other := range entries {
+		posts[TrimExt(strings.TrimPrefix(other.Path, g.PostsDir+"/"))] = site + Permalink(opts.Permalink, other)
+	}
+
+	var warnings []string
+	warn := func(format string, args ...interface{}) {
+		if w := fmt.Sprintf(format, args...); !contains(warnings, w) {
+			warnings = append(warnings, w)
+		}
+	}
+	placeholder := func(m []byte) []byte {
+		return []byte(`<span class="liquid">` + template.HTMLEscapeString(string(m)) + `</span>`)
+	}
+	body := highlightBlockPattern.ReplaceAllFunc(doc.Body, func(m []byte) []byte {
+		sub := highlightBlockPattern.FindSubmatch(m)
+		return []byte(codeBlock(string(sub[2]), string(sub[1])))
+	})
+	var md bytes.Buffer
+	last := 0
+	text := func(t []byte) {
+		t = postURLPattern.ReplaceAllFunc(t, func(m []byte) []byte {
+			sub := postURLPattern.FindSubmatch(m)
+			target, ok := posts[string(sub[1])]
+			if !ok {
+				warn("no post named %s, its post_url tag shows as a placeholder", sub[1])
+				return placeholder(m)
+			}
+			return []byte(target + string(sub[2]))
+		})
+		t = liquidOutputPattern.ReplaceAllFunc(t, func(m []byte) []byte {
+			switch expr := string(liquidOutputPattern.FindSubmatch(m)[1]); expr {
+			case "site.baseurl":
+				return []byte(opts.BaseURL)
+			case "site.url":
+				return []byte(strings.TrimSuffix(site, opts.BaseURL))
+			default:
+				warn("Liquid output {{ %s }} cannot be evaluated and shows as a placeholder", expr)
+				return placeholder(m)
+			}
+		})
+		t = liquidTagPattern.ReplaceAllFunc(t, func(m []byte) []byte {
+			warn("Liquid tag %s cannot be evaluated and shows as a placeholder", liquidTagPattern.FindSubmatch(m)[1])
+			return placeholder(m)
+		})
+		md.Write(t)
+	}
+	for _, m := range verbatimPattern.FindAllIndex(body, -1) {
+		text(body[last:m[0]])
+		md.Write(rawTagPattern.ReplaceAll(body[m[0]:m[1]], nil))
+		last = m[1]
+	}
+	text(body[last:])
+
+	var rendered bytes.Buffer
+	markdown := goldmark.New(
+		goldmark.WithExtensions(extension.GFM, extension.Footnote),
+		goldmark.WithParserOptions(parser.WithAutoHeadingID(), parser.WithAttribute()),
+		goldmark.WithRendererOptions(html.WithUnsafe()),
+	)
+	if err := markdown.Convert(md.Bytes(), &rendered); err != nil {
+		return Export{}, fmt.Errorf("rendering %s: %w", p, err)
+	}
+	out := rendered.Bytes()
+	var inlined bytes.Buffer
+	last = 0
+	for _, m := range htmlImagePattern.FindAllSubmatchIndex(out, -1) {
+		for i := 2; i < len(m); i += 2 {
+			if m[i] < 0 {
+				continue
+			}
+			src := string(out[m[i]:m[i+1]])
+			data, ok := g.dataURI(strings.TrimPrefix(src, opts.BaseURL))
+			if !ok {
+				continue
+			}
+			if data == "" {
+				warn("image %s does not exist", src)
+				continue
+			}
+			inlined.Write(out[last:m[i]])
+			inlined.WriteString(data)
+			last = m[i+1]
+		}
+	}
+	inlined.Write(out[last:])
+
+	date := ""
+	if !e.Date.IsZero() {
+		date = e.Date.Format("January 2, 2006")
+	}
+	var page bytes.Buffer
+	err = htmlPage.Execute(&page, struct {
+		Title, Lang, Date string
+		Draft             bool
+		Body              template.HTML
+	}{e.Meta.Title, e.Meta.Lang, date, g.IsDraft(p), template.HTML(inlined.String())})
+	if err != nil {
+		return Export{}, fmt.Errorf("rendering %s: %w", p, err)
+	}
+	return Export{Content: page.Bytes(), Warnings: warnings}, nil
+}
+
+// dataURI returns the data URI of the local file src, an image URL as
+// written in a rendered body, reporting whether src is local at all; the
+// URI is empty when the file cannot be read.
+func (g *Generator) dataURI(src string) (string, bool) {
+	file, ok := g.imagePath(src)
+	if !ok {
+		if file, ok = g.sitePath(src); !ok {
+			return "", false
+		}
+	}
+	data, err := g.FS.ReadFile(file)
+	if err != nil {
+		return "", true
+	}
+	kind := mime.TypeByExtension(path.Ext(file))
+	if kind == "" {
+		kind = http.DetectContentType(data)
+	}
+	return "data:" + kind + ";base64," + base64.StdEncoding.EncodeToString(data), true
+}
@@ -0,0 +1,43 @@
+package postgen
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RoundupMonthLayout is the layout of the months roundups cover.
+const RoundupMonthLayout = "2006-01"
+
+// Roundup returns a bulleted list of the posts published in the month
+// starting at month, in its location, oldest first: each links to the
+// post with a post_url tag and is followed by its description, if any.
+// The list is plain Markdown, meant to be annotated by hand.
+func (g *Generator) Roundup(month time.Time) ([]byte, error) {
+	end := month.AddDate(0, 1, 0)
+	entries, _, err := g.List()
+	if err != nil {
+		return nil, err
+	}
+	var posts []Entry
+	for _, e := range published(entries) {
+		if d := e.Date.In(month.Location()); !d.Before(month) && d.Before(end) {
+			posts = append(posts, e)
+		}
+	}
+	if len(posts) == 0 {
+		return nil, fmt.Errorf("no post was published in %s", month.Format("January 2006"))
+	}
+	sort.SliceStable(posts, func(i, j int) bool { return posts[i].Date.Before(posts[j].Date) })
+	var b bytes.Buffer
+	for _, e := range posts {
+		fmt.Fprintf(&b, "- [%s]({%% post_url %s %%})", escapeLinkText(e.Meta.Title), TrimExt(strings.TrimPrefix(e.Path, g.PostsDir+"/")))
+		if description := strings.Join(strings.Fields(e.Meta.Description), " "); description != "" {
+			b.WriteString(": " + description)
+		}
+		b.WriteString("\n")
+	}
+	return b.Bytes(), nil
+}
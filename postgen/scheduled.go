@@ -0,0 +1,81 @@
+package postgen
+
+import (
+	"path"
+	"time"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen/frontmatter"
+)
+
+// Scheduled is a post Jekyll does not render yet, because its date is in
+// the future, or whose file name and front matter dates are more than a
+// day apart.
+type Scheduled struct {
+	Path string
+	// Date is when Jekyll renders the post: its front matter date, or
+	// else the start of its file name date.
+	Date time.Time
+	// FileDate is the start of the file name date and FrontMatterDate
+	// the front matter date, zero when the post has none.
+	FileDate        time.Time
+	FrontMatterDate time.Time
+	Future          bool
+	// Mismatch is set when FileDate and FrontMatterDate are more than a
+	// day apart.
+	Mismatch bool
+}
+
+// ScheduledPosts returns the posts dated after now or with mismatched
+// dates, soonest first. Dates without an offset are taken to be in now's
+// location, the site's timezone.
+func (g *Generator) ScheduledPosts(now time.Time) ([]Scheduled, []*FileError, error) {
+	entries, bad, err := g.List()
+	if err != nil {
+		return nil, nil, err
+	}
+	var scheduled []Scheduled
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		doc, _, err := g.readDocument(e.Path)
+		if err != nil {
+			bad = append(bad, &FileError{Path: e.Path, Err: err})
+			continue
+		}
+		var meta struct {
+			Date string `yaml:"date"`
+		}
+		if err := doc.Decode(&meta); err != nil {
+			bad = append(bad, &FileError{Path: e.Path, Err: err})
+			continue
+		}
+		s := Scheduled{Path: e.Path}
+		if fileDate, _, ok := ParseFileName(path.Base(e.Path)); ok {
+			s.FileDate = time.Date(fileDate.Year(), fileDate.Month(), fileDate.Day(), 0, 0, 0, 0, now.Location())
+		}
+		if meta.Date != "" {
+			if s.FrontMatterDate, err = frontmatter.ParseTimeIn(meta.Date, now.Location()); err != nil {
+				bad = append(bad, &FileError{Path: e.Path, Err: err})
+				continue
+			}
+		}
+		s.Date = s.FrontMatterDate
+		if s.Date.IsZero() {
+			s.Date = s.FileDate
+		}
+		s.Future = s.Date.After(now) || s.FileDate.After(now)
+		if !s.FileDate.IsZero() && !s.FrontMatterDate.IsZero() {
+			days := calendarDays(s.FileDate, s.FrontMatterDate.In(now.Location()))
+			s.Mismatch = days > 1 || days < -1
+		}
+		if s.Future || s.Mismatch {
+			scheduled = append(scheduled, s)
+		}
+	}
+	return scheduled, bad, nil
+}
+
+// calendarDays returns the number of calendar days from a to b.
+func calendarDays(a, b time.Time) int {
+	day := func(t time.Time) time.Time { return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC) }
+	return int(day(b).Sub(day(a)).Hours() / 24)
+}
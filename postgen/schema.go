@@ -0,0 +1,157 @@
+package postgen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen/frontmatter"
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaTypes are the value types a Schema can require of a key.
+var SchemaTypes = []string{"string", "list", "bool", "date", "int"}
+
+// Schema describes the front matter keys posts may have.
+type Schema struct {
+	// Strict, the default, reports the keys the schema does not list.
+	Strict *bool                `yaml:"strict,omitempty" json:"strict,omitempty"`
+	Keys   map[string]KeySchema `yaml:"keys" json:"keys"`
+}
+
+// KeySchema describes a front matter key. In YAML, it may also be given
+// as just its type, such as tags: list.
+type KeySchema struct {
+	// Type is one of SchemaTypes, or empty for any value. A list may also
+	// be written as a space-separated string, as Jekyll allows.
+	Type     string `yaml:"type,omitempty" json:"type,omitempty"`
+	Required bool   `yaml:"required,omitempty" json:"required,omitempty"`
+	// Values, when set, lists the values allowed, the ones of the items
+	// for a list.
+	Values []string `yaml:"values,omitempty" json:"values,omitempty"`
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (k *KeySchema) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		k.Type = n.Value
+	} else {
+		type plain KeySchema
+		if err := n.Decode((*plain)(k)); err != nil {
+			return err
+		}
+	}
+	if k.Type != "" && !contains(SchemaTypes, k.Type) {
+		return fmt.Errorf("line %d: unknown type %s: expected one of %s", n.Line, k.Type, strings.Join(SchemaTypes, ", "))
+	}
+	return nil
+}
+
+// Check returns the problems of the front matter of content, the file p,
+// against s.
+func (s *Schema) Check(p string, content []byte) ([]Problem, error) {
+	doc, err := frontmatter.Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", p, err)
+	}
+	f := fields{}
+	if err := doc.Decode(&f); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", p, err)
+	}
+	var problems []Problem
+	s.check(f, func(key, format string, args ...interface{}) {
+		problems = append(problems, Problem{Path: p, Line: f.line(key), Field: key, Message: fmt.Sprintf(format, args...)})
+	})
+	return problems, nil
+}
+
+// check reports the keys of f missing, unknown or with a value s does
+// not allow through problem.
+func (s *Schema) check(f fields, problem func(key, format string, args ...interface{})) {
+	var keys []string
+	for key := range s.Keys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if n, ok := f[key]; s.Keys[key].Required && (!ok || n.Tag == "!!null") {
+			problem(key, "missing required key")
+		}
+	}
+	var present []string
+	for key := range f {
+		present = append(present, key)
+	}
+	sort.Strings(present)
+	for _, key := range present {
+		n := f[key]
+		k, ok := s.Keys[key]
+		if !ok {
+			if s.Strict != nil && !*s.Strict {
+				continue
+			}
+			for _, known := range keys {
+				if strings.EqualFold(known, key) {
+					problem(key, "unknown key, did you mean %s?", known)
+					ok = true
+					break
+				}
+			}
+			if !ok {
+				problem(key, "unknown key, expected one of %s", strings.Join(keys, ", "))
+			}
+			continue
+		}
+		if n.Tag == "!!null" {
+			continue
+		}
+		var values []*yaml.Node
+		switch k.Type {
+		case "string":
+			if n.Kind != yaml.ScalarNode {
+				problem(key, "must be a string")
+				continue
+			}
+		case "list":
+			if n.Kind != yaml.SequenceNode && (n.Kind != yaml.ScalarNode || n.Tag != "!!str") {
+				problem(key, "must be a list")
+				continue
+			}
+		case "bool":
+			if n.Tag != "!!bool" {
+				problem(key, "must be true or false")
+				continue
+			}
+		case "date":
+			if _, err := frontmatter.ParseTime(n.Value); n.Kind != yaml.ScalarNode || err != nil {
+				problem(key, "must be a date")
+				continue
+			}
+		case "int":
+			if n.Tag != "!!int" {
+				problem(key, "must be an integer")
+				continue
+			}
+		}
+		if len(k.Values) == 0 {
+			continue
+		}
+		switch n.Kind {
+		case yaml.SequenceNode:
+			values = n.Content
+		case yaml.ScalarNode:
+			values = []*yaml.Node{&n}
+			if k.Type == "list" {
+				values = nil
+				for _, v := range strings.Fields(n.Value) {
+					values = append(values, &yaml.Node{Kind: yaml.ScalarNode, Value: v})
+				}
+			}
+		}
+		for _, v := range values {
+			if v.Kind != yaml.ScalarNode || !contains(k.Values, v.Value) {
+				problem(key, "%s is not one of %s", frontmatter.String(v.Value), strings.Join(k.Values, ", "))
+			}
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package postgen
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen/posts"
+)
+
+// Match is a line of a post body matching a search.
+type Match struct {
+	Path string
+	// Line is the line of the file, front matter included, and Text its
+	// content; Start and End delimit the first match in Text.
+	Line       int
+	Text       string
+	Start, End int
+}
+
+// Search returns the lines of the bodies of the posts keep accepts,
+// front matter excluded, that pattern matches, by file and line. Posts
+// are read concurrently; the ones that cannot be parsed are reported in
+// the returned FileErrors.
+func (g *Generator) Search(pattern *regexp.Regexp, keep func(Entry) bool) ([]Match, []*FileError, error) {
+	loaded, err := g.loadPosts()
+	if err != nil {
+		return nil, nil, err
+	}
+	var (
+		mu      sync.Mutex
+		matches []Match
+		bad     []*FileError
+		names   = make([]string, len(loaded))
+	)
+	for i, post := range loaded {
+		names[i] = post.Path
+	}
+	g.run(context.Background(), names, func(i int) {
+		found, err := searchPost(loaded[i], pattern, keep)
+		mu.Lock()
+		if err != nil {
+			bad = append(bad, &FileError{Path: loaded[i].Path, Err: err})
+		}
+		matches = append(matches, found...)
+		mu.Unlock()
+	})
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Path != matches[j].Path {
+			return matches[i].Path < matches[j].Path
+		}
+		return matches[i].Line < matches[j].Line
+	})
+	sort.Slice(bad, func(i, j int) bool { return bad[i].Path < bad[j].Path })
+	return matches, bad, nil
+}
+
+func searchPost(post *posts.Post, pattern *regexp.Regexp, keep func(Entry) bool) ([]Match, error) {
+	if post.Err != nil {
+		return nil, post.Err
+	}
+	var meta Meta
+	if err := post.Doc.Decode(&meta); err != nil {
+		return nil, err
+	}
+	if !keep(newEntry(post.Path, meta)) {
+		return nil, nil
+	}
+	body := post.Doc.Body
+	line := bytes.Count(post.Content[:len(post.Content)-len(body)], []byte("\n")) + 1
+	var matches []Match
+	for _, text := range bytes.Split(body, []byte("\n")) {
+		if m := pattern.FindIndex(text); m != nil {
+			matches = append(matches, Match{Path: post.Path, Line: line, Text: string(text), Start: m[0], End: m[1]})
+		}
+		line++
+	}
+	return matches, nil
+}
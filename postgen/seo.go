@@ -0,0 +1,204 @@
+package postgen
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultSEOTerms is the number of terms SEO reports unless told otherwise.
+const DefaultSEOTerms = 10
+
+// StopWords are the words too common to tell what a post is about, by
+// language, which SEO leaves out of its term counts.
+var StopWords = map[string][]string{
+	"en": strings.Fields(`a about above after again against all also am an and any are aren't as at
+		be because been before being below between both but by can can't cannot could couldn't
+		did didn't do does doesn't doing don't down during each even every few for from further
+		get gets got had hadn't has hasn't have haven't having he her here hers herself him himself
+		his how however i i'm i've if in into is isn't it it's its itself just let's like make may
+		me might more most much must my myself no nor not now of off on once one only or other our
+		ours ourselves out over own same she should shouldn't so some such than that that's the
+		their theirs them themselves then there there's these they they're this those through to
+		too two under until up us use used using very via was wasn't we we're we've well were
+		weren't what what's when where which while who whom why will with won't would wouldn't
+		you you'll you're you've your yours yourself yourselves`),
+	"pt": strings.Fields(`a à agora ai aí ainda além algum alguma algumas alguns ao aos apenas após
+		aquela aquelas aquele aqueles aquilo as às até bem cada com como contra da das de dela delas
+		dele deles depois desde dessa dessas desse desses desta destas deste destes do dos e é ela
+		elas ele eles em entre era eram essa essas esse esses esta está estão estas estava este estes
+		eu foi for foram há isso isto já la lá lhe lhes mais mas me mesmo meu meus minha minhas
+		muito muitos na não nas nem no nos nós nossa nossas nosso nossos num numa o os ou para pela
+		pelas pelo pelos pode por porque pois qual quando que quem se sem ser será seu seus si sido
+		sob sobre sua suas também tem têm ter teu toda todas todo todos tu tua tuas um uma umas uns
+		vai você vocês`),
+}
+
+// SEOOptions configures SEO.
+type SEOOptions struct {
+	// Keyword is the post's primary keyword, defaulting to its first tag.
+	Keyword string
+	// Terms is the number of most frequent terms reported, DefaultSEOTerms
+	// when zero.
+	Terms int
+	// StopWords are the words left out of the term counts, lowercase.
+	StopWords map[string]bool
+}
+
+// Frequency is a word of a post body with the number of times it appears and
+// its share of the words of the body, in percent.
+type Frequency struct {
+	Term    string
+	Count   int
+	Density float64
+}
+
+// SEOReport is what SEO finds about a post.
+type SEOReport struct {
+	Words int
+	// Terms are the most frequent words of the body, stop words excluded,
+	// most frequent first.
+	Terms []Frequency
+	// Keyword is the primary keyword and KeywordCount the number of times
+	// the body has it; the In fields report where else it appears.
+	Keyword                                             string
+	KeywordCount                                        int
+	InTitle, InDescription, InFirstParagraph, InHeading bool
+	Description                                         string
+	// Warnings describes the obvious misses.
+	Warnings []string
+}
+
+// SEO reports the most frequent terms of the body of the post or draft at
+// p and where its primary keyword appears: the title, the description,
+// the first paragraph and the headings. Words are compared ignoring case,
+// and a keyword of several words, such as docker-compose, matches them in
+// sequence. A missing description, or one longer than DescriptionLength,
+// is warned about too.
+func (g *Generator) SEO(p string, opts SEOOptions) (SEOReport, error) {
+	if opts.Terms == 0 {
+		opts.Terms = DefaultSEOTerms
+	}
+	e, err := g.Read(p)
+	if err != nil {
+		return SEOReport{}, fmt.Errorf("parsing %s: %w", p, err)
+	}
+	doc, _, err := g.readDocument(p)
+	if err != nil {
+		return SEOReport{}, err
+	}
+	var meta struct {
+		Description string `yaml:"description"`
+	}
+	if err := doc.Decode(&meta); err != nil {
+		return SEOReport{}, fmt.Errorf("parsing %s: %w", p, err)
+	}
+	r := SEOReport{Keyword: opts.Keyword, Description: strings.Join(strings.Fields(meta.Description), " ")}
+	if r.Keyword == "" && len(e.Meta.Tags) > 0 {
+		r.Keyword = e.Meta.Tags[0]
+	}
+
+	words := seoWords(string(prose(doc.Body)))
+	r.Words = len(words)
+	counts := make(map[string]int)
+	for _, w := range words {
+		if !opts.StopWords[w] && utf8.RuneCountInString(w) > 1 && strings.IndexFunc(w, unicode.IsLetter) >= 0 {
+			counts[w]++
+		}
+	}
+	for w, n := range counts {
+		r.Terms = append(r.Terms, Frequency{w, n, density(n, r.Words)})
+	}
+	sort.Slice(r.Terms, func(i, j int) bool {
+		if r.Terms[i].Count != r.Terms[j].Count {
+			return r.Terms[i].Count > r.Terms[j].Count
+		}
+		return r.Terms[i].Term < r.Terms[j].Term
+	})
+	if len(r.Terms) > opts.Terms {
+		r.Terms = r.Terms[:opts.Terms]
+	}
+
+	warn := func(format string, args ...interface{}) {
+		r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+	}
+	switch n := utf8.RuneCountInString(r.Description); {
+	case n == 0:
+		warn("the post has no description")
+	case n > DescriptionLength:
+		warn("the description is %d characters long, search results truncate it past about %d", n, DescriptionLength)
+	}
+	keyword := seoWords(r.Keyword)
+	if len(keyword) == 0 {
+		warn("the post has no keyword to check, give it a tag or use --keyword")
+		return r, nil
+	}
+	r.KeywordCount = occurrences(words, keyword)
+	r.InTitle = occurrences(seoWords(e.Meta.Title), keyword) > 0
+	r.InDescription = occurrences(seoWords(r.Description), keyword) > 0
+	r.InFirstParagraph = occurrences(seoWords(Excerpt(doc.Body, math.MaxInt32)), keyword) > 0
+	for _, h := range Headings(doc.Body) {
+		if occurrences(seoWords(h.Text), keyword) > 0 {
+			r.InHeading = true
+			break
+		}
+	}
+	for _, miss := range []struct {
+		in   bool
+		what string
+	}{
+		{r.KeywordCount > 0, "the body"},
+		{r.InTitle, "the title"},
+		{r.InDescription || r.Description == "", "the description"},
+		{r.InFirstParagraph, "the first paragraph"},
+		{r.InHeading, "any heading"},
+	} {
+		if !miss.in {
+			warn("the keyword %s is not in %s", r.Keyword, miss.what)
+		}
+	}
+	return r, nil
+}
+
+// seoWords splits text into lowercase words, apostrophes kept within them.
+func seoWords(text string) []string {
+	text = strings.ToLower(strings.ReplaceAll(text, "’", "'"))
+	var words []string
+	for _, w := range strings.FieldsFunc(text, func(r rune) bool {
+		return r != '\'' && !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsMark(r)
+	}) {
+		if w = strings.Trim(w, "'"); w != "" {
+			words = append(words, w)
+		}
+	}
+	return words
+}
+
+// occurrences counts the times the words of phrase appear in sequence in
+// words.
+func occurrences(words, phrase []string) int {
+	n := 0
+	for i := 0; i+len(phrase) <= len(words); i++ {
+		match := true
+		for j, w := range phrase {
+			if words[i+j] != w {
+				match = false
+				break
+			}
+		}
+		if match {
+			n++
+		}
+	}
+	return n
+}
+
+func density(count, words int) float64 {
+	if words == 0 {
+		return 0
+	}
+	return float64(count) * 100 / float64(words)
+}
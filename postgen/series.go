@@ -0,0 +1,305 @@
+package postgen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen/frontmatter"
+)
+
+// Series is a set of posts sharing the same series front matter value.
+type Series struct {
+	Name string
+	// Parts are sorted by part number, then date.
+	Parts []Entry
+}
+
+// Gaps returns the part numbers missing between 1 and the last part.
+func (s Series) Gaps() []int {
+	have := make(map[int]bool)
+	last := 0
+	for _, e := range s.Parts {
+		have[e.Meta.SeriesPart] = true
+		if e.Meta.SeriesPart > last {
+			last = e.Meta.SeriesPart
+		}
+	}
+	var gaps []int
+	for i := 1; i <= last; i++ {
+		if !have[i] {
+			gaps = append(gaps, i)
+		}
+	}
+	return gaps
+}
+
+// NextPart returns the part number following the last one in the series.
+func (s Series) NextPart() int {
+	next := 1
+	for _, e := range s.Parts {
+		if e.Meta.SeriesPart >= next {
+			next = e.Meta.SeriesPart + 1
+		}
+	}
+	return next
+}
+
+// Series reads every post and draft and groups the ones belonging to a
+// series, sorted by name. Unreadable files are reported as in List.
+func (g *Generator) Series() ([]Series, []*FileError, error) {
+	files, err := g.Files()
+	if err != nil {
+		return nil, nil, err
+	}
+	var (
+		byName = make(map[string]*Series)
+		bad    []*FileError
+	)
+	for _, p := range files {
+		e, err := g.Read(p)
+		if err != nil {
+			bad = append(bad, &FileError{Path: p, Err: err})
+			continue
+		}
+		if e.Meta.Series == "" {
+			continue
+		}
+		s, ok := byName[e.Meta.Series]
+		if !ok {
+			s = &Series{Name: e.Meta.Series}
+			byName[e.Meta.Series] = s
+		}
+		s.Parts = append(s.Parts, e)
+	}
+	series := make([]Series, 0, len(byName))
+	for _, s := range byName {
+		sort.SliceStable(s.Parts, func(i, j int) bool {
+			if s.Parts[i].Meta.SeriesPart != s.Parts[j].Meta.SeriesPart {
+				return s.Parts[i].Meta.SeriesPart < s.Parts[j].Meta.SeriesPart
+			}
+			return s.Parts[i].Date.Before(s.Parts[j].Date)
+		})
+		series = append(series, *s)
+	}
+	sort.Slice(series, func(i, j int) bool { return series[i].Name < series[j].Name })
+	return series, bad, nil
+}
+
+// NextSeriesPart returns the part number a new post in the series name
+// gets: one more than the highest existing part, or 1 for a new series.
+func (g *Generator) NextSeriesPart(name string) (int, error) {
+	series, _, err := g.Series()
+	if err != nil {
+		return 0, err
+	}
+	for _, s := range series {
+		if s.Name == name {
+			return s.NextPart(), nil
+		}
+	}
+	return 1, nil
+}
+
+// SeriesOptions tunes PlanSeriesPages.
+type SeriesOptions struct {
+	// Dir is the slash-separated folder, relative to FS, holding the
+	// series index pages.
+	Dir    string
+	Layout string
+	// Permalink is the site's permalink setting, from which the
+	// navigation keys link to the parts.
+	Permalink string
+}
+
+// SeriesPlan lists the series index pages to write and to delete, and the
+// posts whose navigation keys change.
+type SeriesPlan struct {
+	// Write holds the new or changed pages; OldContent is nil for new
+	// ones.
+	Write  []Change
+	Delete []string
+	// Posts holds the parts whose series_prev or series_next changes.
+	Posts []Change
+	// Unchanged counts the pages already up to date.
+	Unchanged int
+}
+
+// seriesKey marks the pages written by PlanSeriesPages, so that only
+// those are ever deleted.
+const seriesKey = "series_index"
+
+// Navigation keys written into each part of a series.
+const (
+	seriesPrevKey = "series_prev"
+	seriesNextKey = "series_next"
+)
+
+// PlanSeriesPages computes one index page per series, listing its
+// published parts in order with their descriptions, and the series_prev
+// and series_next keys of each part, a title and url pair, removed from
+// the first and last parts and from posts no longer in a series. Pages
+// whose content would not change are left alone; generated pages of
+// series that no longer exist are deleted.
+func (g *Generator) PlanSeriesPages(opts SeriesOptions) (SeriesPlan, error) {
+	series, bad, err := g.Series()
+	if err != nil {
+		return SeriesPlan{}, err
+	}
+	if len(bad) > 0 {
+		return SeriesPlan{}, bad[0]
+	}
+	var plan SeriesPlan
+	wanted := make(map[string]bool)
+	navigated := make(map[string]bool)
+	for _, sr := range series {
+		var parts []Entry
+		for _, e := range sr.Parts {
+			if !g.IsDraft(e.Path) && (e.Meta.Published == nil || *e.Meta.Published) {
+				parts = append(parts, e)
+			}
+		}
+		if len(parts) == 0 {
+			continue
+		}
+		name := Slugify(sr.Name)
+		p := path.Join(opts.Dir, name+"."+g.Ext)
+		for _, ext := range Exts {
+			if existing := path.Join(opts.Dir, name+"."+ext); g.exists(existing) {
+				p = existing
+				break
+			}
+		}
+		wanted[p] = true
+		content, err := g.seriesPage(sr.Name, name, opts.Layout, parts)
+		if err != nil {
+			return SeriesPlan{}, err
+		}
+		old, err := g.FS.ReadFile(p)
+		switch {
+		case err == nil && bytes.Equal(old, content):
+			plan.Unchanged++
+		case err == nil:
+			plan.Write = append(plan.Write, Change{Path: p, OldContent: old, NewContent: content})
+		case errors.Is(err, fs.ErrNotExist):
+			plan.Write = append(plan.Write, Change{Path: p, NewContent: content})
+		default:
+			return SeriesPlan{}, fmt.Errorf("reading file %s: %w", p, err)
+		}
+		for i, e := range parts {
+			var prev, next *Entry
+			if i > 0 {
+				prev = &parts[i-1]
+			}
+			if i < len(parts)-1 {
+				next = &parts[i+1]
+			}
+			ch, err := g.planNavigation(e.Path, prev, next, opts.Permalink)
+			if err != nil {
+				return SeriesPlan{}, err
+			}
+			if !bytes.Equal(ch.OldContent, ch.NewContent) {
+				plan.Posts = append(plan.Posts, ch)
+			}
+			navigated[e.Path] = true
+		}
+	}
+
+	files, err := g.Files()
+	if err != nil {
+		return SeriesPlan{}, err
+	}
+	for _, p := range files {
+		if navigated[p] {
+			continue
+		}
+		ch, err := g.planNavigation(p, nil, nil, opts.Permalink)
+		if err != nil {
+			return SeriesPlan{}, err
+		}
+		if !bytes.Equal(ch.OldContent, ch.NewContent) {
+			plan.Posts = append(plan.Posts, ch)
+		}
+	}
+
+	dirEntries, err := fs.ReadDir(g.FS, opts.Dir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return SeriesPlan{}, fmt.Errorf("reading folder %s: %w", opts.Dir, err)
+	}
+	for _, de := range dirEntries {
+		p := path.Join(opts.Dir, de.Name())
+		if de.IsDir() || !IsPostFile(de.Name()) || wanted[p] {
+			continue
+		}
+		if doc, _, err := g.readDocument(p); err == nil && doc.Has(seriesKey) {
+			plan.Delete = append(plan.Delete, p)
+		}
+	}
+	return plan, nil
+}
+
+// ApplySeriesPages writes and deletes the pages in plan and rewrites its
+// posts.
+func (g *Generator) ApplySeriesPages(plan SeriesPlan) error {
+	for _, c := range plan.Write {
+		if err := g.WritePage(c); err != nil {
+			return err
+		}
+	}
+	for _, p := range plan.Delete {
+		if err := g.FS.Remove(p); err != nil {
+			return fmt.Errorf("removing file %s: %w", p, err)
+		}
+	}
+	return g.WriteChanges(plan.Posts)
+}
+
+// seriesPage renders the index page of the series name, whose file name
+// is slug, listing parts.
+func (g *Generator) seriesPage(name, slug, layout string, parts []Entry) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "---\nlayout: %s\ntitle:  %s\npermalink: /series/%s/\n%s: %s\n---\n\n", layout, frontmatter.String(name), slug, seriesKey, frontmatter.String(name))
+	for i, e := range parts {
+		doc, _, err := g.readDocument(e.Path)
+		if err != nil {
+			return nil, err
+		}
+		var meta struct {
+			Description string `yaml:"description"`
+		}
+		if err := doc.Decode(&meta); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", e.Path, err)
+		}
+		fmt.Fprintf(&b, "%d. [%s]({%% post_url %s %%})", i+1, escapeLinkText(e.Meta.Title), TrimExt(path.Base(e.Path)))
+		if d := strings.Join(strings.Fields(meta.Description), " "); d != "" {
+			fmt.Fprintf(&b, " — %s", d)
+		}
+		b.WriteString("\n")
+	}
+	return b.Bytes(), nil
+}
+
+// planNavigation computes setting the series_prev and series_next keys of
+// the post at p to the title and URL of prev and next, removing the ones
+// that are nil.
+func (g *Generator) planNavigation(p string, prev, next *Entry, permalink string) (Change, error) {
+	doc, content, err := g.readDocument(p)
+	if err != nil {
+		return Change{}, err
+	}
+	for _, nav := range []struct {
+		key   string
+		entry *Entry
+	}{{seriesPrevKey, prev}, {seriesNextKey, next}} {
+		if nav.entry == nil {
+			doc.Delete(nav.key)
+			continue
+		}
+		doc.SetRaw(nav.key, fmt.Sprintf("\n  title: %s\n  url: %s", frontmatter.String(nav.entry.Meta.Title), Permalink(permalink, *nav.entry)))
+	}
+	return Change{Path: p, OldContent: content, NewContent: doc.Bytes()}, nil
+}
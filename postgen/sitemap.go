@@ -0,0 +1,154 @@
+package postgen
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SitemapOptions describes how the site serves its pages, for
+// CheckSitemap.
+type SitemapOptions struct {
+	// Permalink is the site's permalink setting, as passed to Permalink.
+	Permalink string
+	// SiteURL is the site's url, the host the entries must be on, and
+	// BaseURL its baseurl, stripped from the entries before they are
+	// matched; either may be empty.
+	SiteURL string
+	BaseURL string
+	// Now is the time posts must be dated before to be rendered.
+	Now time.Time
+}
+
+// SitemapProblem is a post missing from the sitemap or listed in it while
+// not rendered, or an entry of the sitemap matching no post, page or
+// file, in which case Post is empty.
+type SitemapProblem struct {
+	Post    string
+	URL     string
+	Message string
+}
+
+func (p SitemapProblem) String() string {
+	if p.Post == "" {
+		return fmt.Sprintf("%s: %s", p.URL, p.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", p.Post, p.URL, p.Message)
+}
+
+// ParseSitemap returns the URLs listed in a sitemap, in order.
+func ParseSitemap(content []byte) ([]string, error) {
+	var sitemap struct {
+		XMLName xml.Name
+		URLs    []struct {
+			Loc string `xml:"loc"`
+		} `xml:"url"`
+	}
+	if err := xml.NewDecoder(bytes.NewReader(content)).Decode(&sitemap); err != nil {
+		return nil, fmt.Errorf("parsing sitemap: %w", err)
+	}
+	switch sitemap.XMLName.Local {
+	case "urlset":
+	case "sitemapindex":
+		return nil, errors.New("parsing sitemap: got a sitemap index, expected a sitemap listing pages")
+	default:
+		return nil, fmt.Errorf("parsing sitemap: unexpected root element %s, expected urlset", sitemap.XMLName.Local)
+	}
+	var urls []string
+	for _, u := range sitemap.URLs {
+		urls = append(urls, strings.TrimSpace(u.Loc))
+	}
+	return urls, nil
+}
+
+// CheckSitemap cross-checks the URLs of a sitemap against the site: every
+// post Jekyll renders, that is published and not dated after opts.Now,
+// must be listed at its permalink unless its front matter sets sitemap:
+// false, and every URL listed must be the permalink of such a post, a
+// page or a static file of the site. Posts whose front matter cannot be
+// parsed are reported in the returned FileErrors.
+func (g *Generator) CheckSitemap(urls []string, opts SitemapOptions) ([]SitemapProblem, []*FileError, error) {
+	entries, bad, err := g.List()
+	if err != nil {
+		return nil, nil, err
+	}
+	var site *url.URL
+	if opts.SiteURL != "" {
+		if site, err = url.Parse(opts.SiteURL); err != nil || !site.IsAbs() {
+			return nil, nil, fmt.Errorf("invalid site URL %s: expected an absolute URL", opts.SiteURL)
+		}
+	}
+	base := strings.TrimSuffix(opts.BaseURL, "/")
+	listed := make(map[string]bool)
+	var problems []SitemapProblem
+	targets, err := g.sitePages()
+	if err != nil {
+		return nil, nil, err
+	}
+	var live []Entry
+	posts := make(map[string]bool)
+	hidden := make(map[string]string)
+	for _, e := range entries {
+		hidden[urlKey(Permalink(opts.Permalink, e))] = e.Path
+	}
+	for _, e := range published(entries) {
+		if !e.Date.After(opts.Now) {
+			live = append(live, e)
+			posts[urlKey(Permalink(opts.Permalink, e))] = true
+		}
+	}
+	source := path.Dir(g.PostsDir)
+	for _, raw := range urls {
+		u, err := url.Parse(raw)
+		if err != nil {
+			problems = append(problems, SitemapProblem{URL: raw, Message: "not a valid URL"})
+			continue
+		}
+		if site != nil && u.IsAbs() && !strings.EqualFold(u.Host, site.Host) {
+			problems = append(problems, SitemapProblem{URL: raw, Message: "not on " + site.Host})
+			continue
+		}
+		p := u.Path
+		if base != "" {
+			if p != base && !strings.HasPrefix(p, base+"/") {
+				problems = append(problems, SitemapProblem{URL: raw, Message: "not under the baseurl " + base})
+				continue
+			}
+			p = strings.TrimPrefix(p, base)
+		}
+		key := urlKey(p)
+		listed[key] = true
+		if p, ok := hidden[key]; ok && !posts[key] {
+			problems = append(problems, SitemapProblem{Post: p, URL: raw, Message: "listed though not rendered, being unpublished or dated in the future"})
+			continue
+		}
+		if !posts[key] && targets[key] == "" && !g.exists(path.Join(source, strings.TrimPrefix(path.Clean("/"+p), "/"))) {
+			problems = append(problems, SitemapProblem{URL: raw, Message: "no post, page or file at " + p})
+		}
+	}
+	sort.Slice(live, func(i, j int) bool { return live[i].Path < live[j].Path })
+	for _, e := range live {
+		u := Permalink(opts.Permalink, e)
+		if listed[urlKey(u)] {
+			continue
+		}
+		doc, _, err := g.readDocument(e.Path)
+		if err != nil {
+			return nil, nil, err
+		}
+		var meta struct {
+			Sitemap *bool `yaml:"sitemap"`
+		}
+		if doc.Decode(&meta) == nil && meta.Sitemap != nil && !*meta.Sitemap {
+			continue
+		}
+		problems = append(problems, SitemapProblem{Post: e.Path, URL: u, Message: "missing from the sitemap"})
+	}
+	return problems, bad, nil
+}
@@ -0,0 +1,30 @@
+package postgen
+
+import "testing"
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"Hello World", "hello-world"},
+		{"Configuração de ambiente Go", "configuracao-de-ambiente-go"},
+		{"Über Straße", "uber-strasse"},
+		{"Go 🚀 rocks 🎉", "go-rocks"},
+		{"🚀", ""},
+		{"too   many -- _ separators", "too-many-separators"},
+		{"  - leading and trailing -  ", "leading-and-trailing"},
+		{"Trailing punctuation?!", "trailing-punctuation"},
+		{"What's new in Go 1.22?", "whats-new-in-go-122"},
+		{"docker/compose_tips", "docker-compose-tips"},
+		{`back\slash`, "back-slash"},
+		{"Go: tips & tricks", "go-tips-tricks"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			if got := Slugify(tt.title); got != tt.want {
+				t.Errorf("Slugify(%q) = %q, want %q", tt.title, got, tt.want)
+			}
+		})
+	}
+}
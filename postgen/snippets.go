@@ -0,0 +1,240 @@
+package postgen
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// snippetPattern matches a snippet directive, capturing the source
+	// file and the optional #L10-L42 range or #region name.
+	snippetPattern      = regexp.MustCompile(`^\s*<!--\s*snippet:\s*([^#\s]+)(?:#(\S+))?\s*-->\s*$`)
+	snippetRangePattern = regexp.MustCompile(`^L(\d+)(?:-L?(\d+))?$`)
+	regionStartPattern  = regexp.MustCompile(`\bsnippet:start\s+(\S+)`)
+	regionEndPattern    = regexp.MustCompile(`\bsnippet:end\s+(\S+)`)
+)
+
+// snippetLanguages maps source file extensions, or names for files
+// without one, to code fence languages.
+var snippetLanguages = map[string]string{
+	".go": "go", ".mod": "go", ".java": "java", ".py": "python", ".rb": "ruby",
+	".pl": "perl", ".rs": "rust", ".js": "javascript", ".ts": "typescript",
+	".sh": "bash", ".bash": "bash", ".sql": "sql", ".yml": "yaml", ".yaml": "yaml",
+	".json": "json", ".toml": "toml", ".xml": "xml", ".html": "html", ".css": "css",
+	".proto": "protobuf", ".c": "c", ".h": "c", ".cpp": "cpp", ".md": "markdown",
+	".properties": "properties", ".gradle": "groovy",
+	"Makefile": "makefile", "Dockerfile": "dockerfile",
+}
+
+// Snippet is a snippet directive in a post.
+type Snippet struct {
+	Line int
+	// Source is the directive's target as written, such as
+	// examples/foo/main.go#L10-L42.
+	Source string
+}
+
+// SnippetSync is a post some of whose snippets are out of date.
+type SnippetSync struct {
+	Change
+	Stale []Snippet
+}
+
+// SyncSnippets computes replacing the code block following each snippet
+// directive in the files in paths with the current content of its source,
+// a path relative to the generator's filesystem optionally followed by a
+// #L10-L42 line range or a #name region delimited by snippet:start name
+// and snippet:end name lines. A block is added when the directive is not
+// followed by one. Files whose snippets are up to date are not returned.
+func (g *Generator) SyncSnippets(paths []string) ([]SnippetSync, error) {
+	var syncs []SnippetSync
+	for _, p := range paths {
+		content, err := g.FS.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("reading file %s: %w", p, err)
+		}
+		newContent, stale, err := g.syncSnippets(p, string(content))
+		if err != nil {
+			return nil, err
+		}
+		if len(stale) > 0 {
+			syncs = append(syncs, SnippetSync{
+				Change: Change{Path: p, OldContent: content, NewContent: []byte(newContent)},
+				Stale:  stale,
+			})
+		}
+	}
+	return syncs, nil
+}
+
+func (g *Generator) syncSnippets(p, content string) (string, []Snippet, error) {
+	lines := strings.SplitAfter(content, "\n")
+	var (
+		out   []string
+		stale []Snippet
+		fence string
+	)
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		out = append(out, line)
+		if fence != "" {
+			if isClosingFence(line, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if m := fencePattern.FindStringSubmatch(strings.TrimRight(line, "\r\n")); m != nil {
+			fence = m[1]
+			continue
+		}
+		m := snippetPattern.FindStringSubmatch(strings.TrimRight(line, "\r\n"))
+		if m == nil {
+			continue
+		}
+		source := m[1]
+		if m[2] != "" {
+			source += "#" + m[2]
+		}
+		code, err := g.snippet(m[1], m[2])
+		if err != nil {
+			return "", nil, fmt.Errorf("%s:%d: snippet %s: %w", p, i+1, source, err)
+		}
+
+		// The block to replace starts at the first non-blank line after
+		// the directive, if that line opens a fence.
+		start, end, info := i+1, i+1, ""
+		for start < len(lines) && strings.TrimSpace(lines[start]) == "" {
+			start++
+		}
+		var fm []string
+		if start < len(lines) {
+			fm = fencePattern.FindStringSubmatch(strings.TrimRight(lines[start], "\r\n"))
+		}
+		if fm == nil {
+			start = i + 1
+		} else {
+			end = start + 1
+			for end < len(lines) && !isClosingFence(lines[end], fm[1]) {
+				end++
+			}
+			if end == len(lines) {
+				return "", nil, fmt.Errorf("%s:%d: unterminated code block after snippet %s", p, start+1, source)
+			}
+			end++
+			info = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(lines[start]), "`~"))
+		}
+		if info == "" {
+			info = snippetLanguage(m[1])
+		}
+		block := codeBlock(code, info)
+		if old := strings.Join(lines[start:end], ""); start == end || old != block {
+			stale = append(stale, Snippet{Line: i + 1, Source: source})
+		}
+		if !strings.HasSuffix(line, "\n") {
+			out[len(out)-1] += "\n"
+		}
+		out = append(out, lines[i+1:start]...)
+		out = append(out, block)
+		i = end - 1
+	}
+	return strings.Join(out, ""), stale, nil
+}
+
+// snippet returns the lines of source selected by selector, a line range
+// or a region name, without region markers and with their common
+// indentation removed.
+func (g *Generator) snippet(source, selector string) (string, error) {
+	b, err := g.FS.ReadFile(path.Clean(source))
+	if err != nil {
+		return "", fmt.Errorf("reading source: %w", err)
+	}
+	lines := strings.SplitAfter(strings.TrimSuffix(string(b), "\n"), "\n")
+	switch m := snippetRangePattern.FindStringSubmatch(selector); {
+	case selector == "":
+	case m != nil:
+		from, _ := strconv.Atoi(m[1])
+		to := from
+		if m[2] != "" {
+			to, _ = strconv.Atoi(m[2])
+		}
+		if from < 1 || to < from || to > len(lines) {
+			return "", fmt.Errorf("line range %s outside the %d lines of %s", selector, len(lines), source)
+		}
+		lines = lines[from-1 : to]
+	default:
+		start, end := -1, -1
+		for i, l := range lines {
+			if rm := regionStartPattern.FindStringSubmatch(l); rm != nil && rm[1] == selector && start < 0 {
+				start = i + 1
+			} else if rm := regionEndPattern.FindStringSubmatch(l); rm != nil && rm[1] == selector && start >= 0 {
+				end = i
+				break
+			}
+		}
+		if start < 0 || end < 0 {
+			return "", fmt.Errorf("no region %s in %s", selector, source)
+		}
+		lines = lines[start:end]
+	}
+	// Region markers of this or other regions are not part of the listing.
+	var code []string
+	for _, l := range lines {
+		if !regionStartPattern.MatchString(l) && !regionEndPattern.MatchString(l) {
+			code = append(code, l)
+		}
+	}
+	return dedent(strings.TrimSuffix(strings.Join(code, ""), "\n")), nil
+}
+
+// dedent removes the indentation all non-blank lines of code share.
+func dedent(code string) string {
+	lines := strings.Split(code, "\n")
+	prefix := ""
+	first := true
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		indent := l[:len(l)-len(strings.TrimLeft(l, " \t"))]
+		switch {
+		case first:
+			prefix, first = indent, false
+		default:
+			for !strings.HasPrefix(indent, prefix) {
+				prefix = prefix[:len(prefix)-1]
+			}
+		}
+	}
+	for i, l := range lines {
+		lines[i] = strings.TrimPrefix(l, prefix)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// codeBlock fences code, using more backticks than any run in it.
+func codeBlock(code, info string) string {
+	fence := "```"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	return fence + info + "\n" + code + "\n" + fence + "\n"
+}
+
+// isClosingFence reports whether line closes a block opened with fence.
+func isClosingFence(line, fence string) bool {
+	m := fencePattern.FindStringSubmatch(strings.TrimRight(line, "\r\n"))
+	return m != nil && m[1][0] == fence[0] && len(m[1]) >= len(fence) && m[2] == ""
+}
+
+func snippetLanguage(source string) string {
+	if lang, ok := snippetLanguages[path.Ext(source)]; ok {
+		return lang
+	}
+	if lang, ok := snippetLanguages[path.Base(source)]; ok {
+		return lang
+	}
+	return "text"
+}
@@ -0,0 +1,127 @@
+package postgen
+
+import (
+	"sort"
+	"time"
+)
+
+// PeriodCount is the number of posts in a year (YYYY) or month (YYYY-MM).
+type PeriodCount struct {
+	Period string
+	Count  int
+}
+
+// Gap is the time between two consecutive posts.
+type Gap struct {
+	From, To Entry
+}
+
+// Days returns the length of the gap in whole days.
+func (g Gap) Days() int {
+	return int(g.To.Date.Sub(g.From.Date).Hours() / 24)
+}
+
+// Stats summarizes the posting history of a site.
+type Stats struct {
+	Posts int
+	// PerYear and PerMonth run from the first post to the last, oldest
+	// first, including the periods without posts.
+	PerYear  []PeriodCount
+	PerMonth []PeriodCount
+	// AverageWords and MedianWords are computed with CountWords.
+	AverageWords float64
+	MedianWords  float64
+	// LongestGap is nil with fewer than two posts.
+	LongestGap *Gap
+	// Streak is the number of consecutive months with at least one post,
+	// ending with the current month, or the previous one while the
+	// current month has none yet.
+	Streak int
+}
+
+// Stats computes the posting statistics of every published post as of
+// now. With drafts, drafts and unpublished posts are counted too, dated
+// now when they have no date. Files whose front matter cannot be parsed
+// are skipped and returned apart.
+func (g *Generator) Stats(drafts bool, now time.Time) (Stats, []*FileError, error) {
+	loaded, err := g.loadFiles()
+	if err != nil {
+		return Stats{}, nil, err
+	}
+	var (
+		entries []Entry
+		words   []int
+		bad     []*FileError
+	)
+	for _, post := range loaded {
+		if post.Err != nil {
+			bad = append(bad, &FileError{Path: post.Path, Err: post.Err})
+			continue
+		}
+		var meta Meta
+		if err := post.Doc.Decode(&meta); err != nil {
+			bad = append(bad, &FileError{Path: post.Path, Err: err})
+			continue
+		}
+		e := newEntry(post.Path, meta)
+		unpublished := g.IsDraft(post.Path) || (e.Meta.Published != nil && !*e.Meta.Published)
+		if unpublished && !drafts {
+			continue
+		}
+		if e.Date.IsZero() {
+			e.Date = now
+		}
+		entries = append(entries, e)
+		words = append(words, CountWords(post.Doc.Body))
+	}
+	s := Stats{Posts: len(entries)}
+	if len(entries) == 0 {
+		return s, bad, nil
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Date.Before(entries[j].Date) })
+
+	total := 0
+	for _, w := range words {
+		total += w
+	}
+	s.AverageWords = float64(total) / float64(len(words))
+	sort.Ints(words)
+	if n := len(words); n%2 == 1 {
+		s.MedianWords = float64(words[n/2])
+	} else {
+		s.MedianWords = float64(words[n/2-1]+words[n/2]) / 2
+	}
+
+	for i := 1; i < len(entries); i++ {
+		gap := Gap{From: entries[i-1], To: entries[i]}
+		if s.LongestGap == nil || gap.To.Date.Sub(gap.From.Date) > s.LongestGap.To.Date.Sub(s.LongestGap.From.Date) {
+			s.LongestGap = &gap
+		}
+	}
+
+	months := make(map[string]int)
+	years := make(map[string]int)
+	for _, e := range entries {
+		months[e.Date.Format("2006-01")]++
+		years[e.Date.Format("2006")]++
+	}
+	first, last := entries[0].Date, entries[len(entries)-1].Date
+	for y := first.Year(); y <= last.Year(); y++ {
+		period := time.Date(y, 1, 1, 0, 0, 0, 0, time.UTC).Format("2006")
+		s.PerYear = append(s.PerYear, PeriodCount{period, years[period]})
+	}
+	end := time.Date(last.Year(), last.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for m := time.Date(first.Year(), first.Month(), 1, 0, 0, 0, 0, time.UTC); !m.After(end); m = m.AddDate(0, 1, 0) {
+		period := m.Format("2006-01")
+		s.PerMonth = append(s.PerMonth, PeriodCount{period, months[period]})
+	}
+
+	m := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if months[m.Format("2006-01")] == 0 {
+		m = m.AddDate(0, -1, 0)
+	}
+	for ; months[m.Format("2006-01")] > 0; m = m.AddDate(0, -1, 0) {
+		s.Streak++
+	}
+	return s, bad, nil
+}
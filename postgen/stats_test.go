@@ -0,0 +1,37 @@
+package postgen
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	g := testGenerator(newMemFS(map[string]string{
+		"docs/_posts/2024-01-10-one.markdown":    "---\ntitle: One\ndate: 2024-01-10\n---\none two three\n",
+		"docs/_posts/2024-03-05-two.markdown":    "---\ntitle: Two\ndate: 2024-03-05\n---\none\n",
+		"docs/_posts/2024-03-06-bad.markdown":    "---\ntitle: [bad\n---\n",
+		"docs/_drafts/draft.markdown":            "---\ntitle: Draft\n---\none two\n",
+		"docs/_posts/2024-02-01-hidden.markdown": "---\ntitle: Hidden\ndate: 2024-02-01\npublished: false\n---\none two three four\n",
+	}))
+	now := time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		drafts bool
+		posts  int
+		words  float64
+	}{
+		{false, 2, 2},
+		{true, 4, 2.5},
+	}
+	for _, tt := range tests {
+		s, bad, err := g.Stats(tt.drafts, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(bad) != 1 || bad[0].Path != "docs/_posts/2024-03-06-bad.markdown" {
+			t.Errorf("drafts %t: unreadable files = %v, want the bad post", tt.drafts, bad)
+		}
+		if s.Posts != tt.posts || s.AverageWords != tt.words {
+			t.Errorf("drafts %t: %d posts averaging %g words, want %d averaging %g", tt.drafts, s.Posts, s.AverageWords, tt.posts, tt.words)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package postgen
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen/frontmatter"
+)
+
+// categoryPattern matches category names Jekyll can turn into URL path
+// segments without escaping.
+var categoryPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// ParseCategories flattens repeated and comma-separated category
+// values, dropping blanks and duplicates while keeping the given order.
+func ParseCategories(values []string) ([]string, error) {
+	var categories []string
+	seen := make(map[string]bool)
+	for _, value := range values {
+		for _, category := range strings.Split(value, ",") {
+			category = strings.TrimSpace(category)
+			if category == "" || seen[category] {
+				continue
+			}
+			if !categoryPattern.MatchString(category) {
+				return nil, fmt.Errorf("invalid category \"%s\": only letters, digits, '-', '_' and '.' are allowed", category)
+			}
+			seen[category] = true
+			categories = append(categories, category)
+		}
+	}
+	return categories, nil
+}
+
+// ParseTags flattens repeated and comma-separated tag values into a
+// lowercased list without blanks or duplicates, keeping the given order.
+func ParseTags(values []string) []string {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, value := range values {
+		for _, tag := range strings.Split(value, ",") {
+			tag = strings.ToLower(strings.TrimSpace(tag))
+			if tag == "" || seen[tag] {
+				continue
+			}
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// TaxonomyKeys are the front matter keys holding categories and tags.
+var TaxonomyKeys = []string{"categories", "tags"}
+
+// taxonomy holds the categories and tags of a post.
+type taxonomy struct {
+	Categories frontmatter.List `yaml:"categories"`
+	Tags       frontmatter.List `yaml:"tags"`
+}
+
+func (t taxonomy) values(key string) []string {
+	if key == "categories" {
+		return t.Categories
+	}
+	return t.Tags
+}
+
+// TermChange is a post whose categories or tags are rewritten.
+type TermChange struct {
+	Change
+	// Keys are the front matter keys changed, among TaxonomyKeys.
+	Keys []string
+}
+
+// RenameTerm computes replacing from with to in the lists of every post
+// and draft under keys, among TaxonomyKeys, merging it into to where a
+// list has both. Each list keeps its style: a space-separated string, a
+// flow sequence or a block sequence.
+func (g *Generator) RenameTerm(from, to string, keys []string) ([]TermChange, error) {
+	if strings.TrimSpace(to) == "" {
+		return nil, errors.New("the new name cannot be empty")
+	}
+	files, err := g.Files()
+	if err != nil {
+		return nil, err
+	}
+	var changes []TermChange
+	for _, p := range files {
+		doc, content, err := g.readDocument(p)
+		if err != nil {
+			return nil, err
+		}
+		var t taxonomy
+		if err := doc.Decode(&t); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", p, err)
+		}
+		var changed []string
+		for _, key := range keys {
+			values := t.values(key)
+			if !contains(values, from) {
+				continue
+			}
+			var renamed []string
+			for _, v := range values {
+				if v == from {
+					v = to
+				}
+				if !contains(renamed, v) {
+					renamed = append(renamed, v)
+				}
+			}
+			raw, _ := doc.Raw(key)
+			doc.SetRaw(key, renderList(raw, renamed))
+			changed = append(changed, key)
+		}
+		if len(changed) > 0 {
+			changes = append(changes, TermChange{
+				Change: Change{Path: p, OldContent: content, NewContent: doc.Bytes()},
+				Keys:   changed,
+			})
+		}
+	}
+	return changes, nil
+}
+
+// renderList renders items in the style of raw, an existing list value.
+func renderList(raw string, items []string) string {
+	switch {
+	case strings.HasPrefix(raw, "-"):
+		return frontmatter.BlockList(items)
+	case strings.HasPrefix(raw, "["):
+		return frontmatter.FlowList(items)
+	case strings.HasPrefix(raw, `"`):
+		return frontmatter.String(strings.Join(items, " "))
+	}
+	return frontmatter.Scalar(strings.Join(items, " "))
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Term is a category or tag with the posts using it.
+type Term struct {
+	Name  string
+	Posts []string
+}
+
+// Terms aggregates the values of key, one of TaxonomyKeys, across every
+// post, most used first. Unreadable posts are reported as in List.
+func (g *Generator) Terms(key string) ([]Term, []*FileError, error) {
+	entries, bad, err := g.List()
+	if err != nil {
+		return nil, nil, err
+	}
+	byName := make(map[string]*Term)
+	var terms []*Term
+	for _, e := range entries {
+		values := e.Meta.Tags
+		if key == "categories" {
+			values = e.Meta.Categories
+		}
+		for _, v := range values {
+			t, ok := byName[v]
+			if !ok {
+				t = &Term{Name: v}
+				byName[v] = t
+				terms = append(terms, t)
+			}
+			if !contains(t.Posts, e.Path) {
+				t.Posts = append(t.Posts, e.Path)
+			}
+		}
+	}
+	sort.SliceStable(terms, func(i, j int) bool {
+		if len(terms[i].Posts) != len(terms[j].Posts) {
+			return len(terms[i].Posts) > len(terms[j].Posts)
+		}
+		return terms[i].Name < terms[j].Name
+	})
+	sorted := make([]Term, len(terms))
+	for i, t := range terms {
+		sorted[i] = *t
+	}
+	return sorted, bad, nil
+}
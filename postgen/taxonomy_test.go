@@ -0,0 +1,59 @@
+package postgen
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTagsRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		want   []string
+	}{
+		{"comma separated", []string{"go,docker"}, []string{"go", "docker"}},
+		{"repeated", []string{"go", "docker"}, []string{"go", "docker"}},
+		{"lowercased", []string{"Go, DOCKER"}, []string{"go", "docker"}},
+		{"deduplicated", []string{"go, Go", "docker,go"}, []string{"go", "docker"}},
+		{"blanks dropped", []string{" , go ,, ", ""}, []string{"go"}},
+		{"needing quotes", []string{"docker: compose, #go, yes"}, []string{"docker: compose", "#go", "yes"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tags := ParseTags(tt.values)
+			r, err := testGenerator(newMemFS(nil)).Plan(Post{
+				Layout:     "post",
+				Title:      "Tagged",
+				Slug:       "tagged",
+				Categories: []string{"go"},
+				Tags:       tags,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var meta struct {
+				Categories string   `yaml:"categories"`
+				Tags       []string `yaml:"tags"`
+			}
+			frontMatter(t, r.Content, &meta)
+			if !reflect.DeepEqual(meta.Tags, tt.want) {
+				t.Errorf("tags = %q, want %q", meta.Tags, tt.want)
+			}
+			if meta.Categories != "go" {
+				t.Errorf("categories = %q, want %q", meta.Categories, "go")
+			}
+		})
+	}
+}
+
+func TestPlanWithoutTags(t *testing.T) {
+	r, err := testGenerator(newMemFS(nil)).Plan(Post{Layout: "post", Title: "Untagged", Slug: "untagged", Tags: ParseTags(nil)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var meta map[string]interface{}
+	frontMatter(t, r.Content, &meta)
+	if _, ok := meta["tags"]; ok {
+		t.Errorf("tags written without tags:\n%s", r.Content)
+	}
+}
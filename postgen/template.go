@@ -0,0 +1,194 @@
+package postgen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen/frontmatter"
+)
+
+// DefaultTemplate is a front matter template writing what RenderHeader
+// does in the DefaultHeaderFormat, a starting point for custom templates.
+const DefaultTemplate = `---
+layout: {{ .Layout }}
+title:  {{ yamlString .Title }}
+date:   {{ .Date }}
+{{- if .Description }}
+description: {{ yamlString .Description }}
+{{- end }}
+{{- if .Permalink }}
+permalink: {{ .Permalink }}
+{{- end }}
+{{- if .CanonicalURL }}
+canonical_url: {{ yamlScalar .CanonicalURL }}
+{{- end }}
+{{- if .Author }}
+author: {{ yamlScalar .Author }}
+{{- end }}
+{{- if .Lang }}
+lang: {{ .Lang }}
+{{- end }}
+{{- if .Categories }}
+categories: {{ join .Categories " " }}
+{{- end }}
+{{- if .Tags }}
+tags:
+{{- range .Tags }}
+  - {{ yamlScalar . }}
+{{- end }}
+{{- end }}
+{{- if .Image }}
+image: {{ .Image }}
+{{- end }}
+{{- if .Series }}
+series: {{ yamlString .Series }}
+series_part: {{ .SeriesPart }}
+{{- end }}
+{{- if .Draft }}
+published: false
+{{- end }}
+---
+`
+
+// TemplateData holds the variables available to front matter templates.
+type TemplateData struct {
+	Layout      string
+	Title       string
+	Description string
+	// CanonicalURL is where the post first appeared, when elsewhere.
+	CanonicalURL string
+	Permalink    string
+	Slug         string
+	Date         string
+	Author       string
+	Lang         string
+	Categories   []string
+	Tags         []string
+	Series       string
+	SeriesPart   int
+	Draft        bool
+	// Image is the URL of the cover image.
+	Image string
+	// Vars are the one-off variables of the post, also written to its
+	// front matter; see ParseVars.
+	Vars map[string]interface{}
+}
+
+// TemplateFunc documents a function templates can call.
+type TemplateFunc struct {
+	Name        string
+	Usage       string
+	Description string
+	// Example is a template calling the function, executed with
+	// ExampleData.
+	Example string
+}
+
+// TemplateFuncs documents the template functions, in the order postgen
+// templates funcs lists them.
+var TemplateFuncs = []TemplateFunc{
+	{"slugify", "slugify TEXT", "turns TEXT into a slug the way postgen names post files", `permalink: /{{ slugify .Title }}/`},
+	{"now", "now LAYOUT", "formats the current time, in the site's timezone, with a Go time layout", `{{ now "2006-01-02" }}`},
+	{"upper", "upper TEXT", "returns TEXT in upper case", `{{ upper .Slug }}`},
+	{"lower", "lower TEXT", "returns TEXT in lower case", `{{ lower .Title }}`},
+	{"join", "join LIST SEPARATOR", "joins the items of LIST with SEPARATOR", `categories: {{ join .Categories " " }}`},
+	{"yamlList", "yamlList LIST", "renders LIST as a YAML flow sequence, quoting the items that need it", `tags: {{ yamlList .Tags }}`},
+	{"yamlString", "yamlString TEXT", "renders TEXT as a double-quoted YAML string", `title: {{ yamlString .Title }}`},
+	{"yamlScalar", "yamlScalar TEXT", "renders TEXT unquoted when YAML reads it back unchanged, double-quoted otherwise", `author: {{ yamlScalar "Tiago Melo" }}`},
+}
+
+// ExampleData is the data the TemplateFuncs examples are executed with.
+var ExampleData = TemplateData{
+	Layout:     "post",
+	Title:      "Go: a dockerized gRPC server",
+	Slug:       "go-dockerized-grpc-server",
+	Categories: []string{"go", "grpc"},
+	Tags:       []string{"go", "grpc", "docker: compose"},
+}
+
+// templateFuncs returns the template functions, now being the clock of
+// the now function.
+func templateFuncs(now func() time.Time) template.FuncMap {
+	return template.FuncMap{
+		"slugify":    Slugify,
+		"now":        func(layout string) string { return now().Format(layout) },
+		"upper":      strings.ToUpper,
+		"lower":      strings.ToLower,
+		"join":       strings.Join,
+		"yamlList":   frontmatter.FlowList,
+		"yamlString": frontmatter.String,
+		"yamlScalar": frontmatter.Scalar,
+	}
+}
+
+// Run executes the example of f with ExampleData, using now as the clock.
+func (f TemplateFunc) Run(now func() time.Time) (string, error) {
+	tmpl, err := template.New(f.Name).Funcs(templateFuncs(now)).Parse(f.Example)
+	if err != nil {
+		return "", fmt.Errorf("parsing the example of %s: %w", f.Name, err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, ExampleData); err != nil {
+		return "", fmt.Errorf("executing the example of %s: %w", f.Name, err)
+	}
+	return b.String(), nil
+}
+
+// ParseTemplate parses a front matter template with the TemplateFuncs
+// available, now using the current time until execute sets the
+// generator's clock. Parse and execution errors are reported
+// against name, so naming the template after its file makes them point
+// at the offending file and line.
+func ParseTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs(time.Now)).Parse(text)
+	if err != nil {
+		return nil, Mark(ErrTemplate, fmt.Errorf("parsing template: %w", err))
+	}
+	return tmpl, nil
+}
+
+// execute executes tmpl with data, the now function reading g's clock.
+func (g *Generator) execute(w io.Writer, tmpl *template.Template, data TemplateData) error {
+	return tmpl.Funcs(template.FuncMap{"now": templateFuncs(g.Now)["now"]}).Execute(w, data)
+}
+
+// applyArchetype executes archetype with data and merges the result into
+// header, the executed header template: the archetype's front matter keys
+// are added to it, replacing the generated values but for the keys in
+// explicit, and its body follows the header's after a blank line. An
+// archetype without front matter is all body.
+func (g *Generator) applyArchetype(header []byte, archetype *template.Template, data TemplateData, explicit map[string]bool) ([]byte, error) {
+	var b bytes.Buffer
+	if err := g.execute(&b, archetype, data); err != nil {
+		return nil, Mark(ErrTemplate, fmt.Errorf("executing archetype: %w", err))
+	}
+	doc, err := frontmatter.Parse(header)
+	if err != nil {
+		return nil, Mark(ErrTemplate, fmt.Errorf("parsing the executed template: %w", err))
+	}
+	body := b.Bytes()
+	arch, err := frontmatter.Parse(body)
+	switch {
+	case errors.Is(err, frontmatter.ErrNoFrontMatter) && !bytes.HasPrefix(body, []byte("---")):
+	case err != nil:
+		return nil, Mark(ErrTemplate, fmt.Errorf("parsing archetype %s: %w", archetype.Name(), err))
+	default:
+		for _, key := range arch.Keys() {
+			if explicit[key] && doc.Has(key) {
+				arch.Delete(key)
+			}
+		}
+		doc.Merge(arch, true)
+		body = arch.Body
+	}
+	if len(body) > 0 && body[0] != '\n' {
+		doc.Body = append(doc.Body, '\n')
+	}
+	doc.Body = append(doc.Body, body...)
+	return doc.Bytes(), nil
+}
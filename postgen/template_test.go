@@ -0,0 +1,96 @@
+package postgen
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTemplateFuncs(t *testing.T) {
+	post := Post{
+		Layout:     "post",
+		Title:      "Go: Tips & Tricks",
+		Slug:       "go-tips",
+		Categories: []string{"go", "tips"},
+		Tags:       []string{"go", "docker: compose"},
+	}
+	tests := map[string]struct {
+		text string
+		want string
+	}{
+		"slugify":    {`permalink: /{{ slugify .Title }}/`, "permalink: /go-tips-tricks/"},
+		"now":        {`updated: {{ now "2006-01-02 15:04" }}`, "updated: 2024-05-01 10:30"},
+		"upper":      {`code: {{ upper .Slug }}`, "code: GO-TIPS"},
+		"lower":      {`tag: {{ lower "Docker Compose" }}`, "tag: docker compose"},
+		"join":       {`categories: {{ join .Categories " " }}`, "categories: go tips"},
+		"yamlList":   {`tags: {{ yamlList .Tags }}`, `tags: [go, "docker: compose"]`},
+		"yamlString": {`title: {{ yamlString .Title }}`, `title: "Go: Tips & Tricks"`},
+		"yamlScalar": {`author: {{ yamlScalar "Tiago Melo" }} {{ yamlScalar "yes" }}`, `author: Tiago Melo "yes"`},
+	}
+	for _, f := range TemplateFuncs {
+		if _, ok := tests[f.Name]; !ok {
+			t.Errorf("template function %s is not tested", f.Name)
+		}
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tmpl, err := ParseTemplate(name, "---\nlayout: {{ .Layout }}\n"+tt.text+"\n---\n")
+			if err != nil {
+				t.Fatal(err)
+			}
+			g := testGenerator(newMemFS(nil))
+			g.Template = tmpl
+			r, err := g.Plan(post)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(r.Content), "\n"+tt.want+"\n") {
+				t.Errorf("no %s in:\n%s", tt.want, r.Content)
+			}
+		})
+	}
+}
+
+func TestTemplateFuncExamples(t *testing.T) {
+	now := func() time.Time { return testDate }
+	for _, f := range TemplateFuncs {
+		t.Run(f.Name, func(t *testing.T) {
+			out, err := f.Run(now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out == "" || strings.Contains(out, "{{") {
+				t.Errorf("example %s ran to %q", f.Example, out)
+			}
+		})
+	}
+}
+
+func TestArchetypeReplacesDefaults(t *testing.T) {
+	archetype, err := ParseTemplate("tutorial.md", "---\nlayout: tutorial\ntitle: \"{{.Title}} (tutorial)\"\ncategories: tutorials\ndifficulty: easy\n---\n## Steps\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	post := Post{Layout: "post", Title: "Hello", Slug: "hello", Categories: []string{"go"}, Archetype: archetype, Explicit: map[string]bool{"categories": true}}
+	r, err := testGenerator(newMemFS(nil)).Plan(post)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type meta struct {
+		Layout     string `yaml:"layout"`
+		Title      string `yaml:"title"`
+		Categories string `yaml:"categories"`
+		Difficulty string `yaml:"difficulty"`
+	}
+	var got meta
+	frontMatter(t, r.Content, &got)
+	if want := (meta{"tutorial", "Hello (tutorial)", "go", "easy"}); got != want {
+		t.Errorf("front matter = %+v, want %+v", got, want)
+	}
+	if !strings.HasPrefix(string(r.Content), "---\nlayout: tutorial\n") {
+		t.Errorf("layout not replaced in place:\n%s", r.Content)
+	}
+	if !strings.Contains(string(r.Content), "\n---\n\n## Steps\n") {
+		t.Errorf("archetype body missing:\n%s", r.Content)
+	}
+}
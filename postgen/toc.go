@@ -0,0 +1,103 @@
+package postgen
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// TOCStart and TOCEnd delimit the table of contents of a post.
+const (
+	TOCStart = "<!-- toc -->"
+	TOCEnd   = "<!-- /toc -->"
+)
+
+var (
+	tocStartPattern = regexp.MustCompile(`(?m)^[ \t]*<!--[ \t]*toc[ \t]*-->[ \t]*$`)
+	tocEndPattern   = regexp.MustCompile(`(?m)^[ \t]*<!--[ \t]*/toc[ \t]*-->[ \t]*$`)
+)
+
+// TOC returns body with the list between its TOCStart and TOCEnd lines
+// replaced by a nested list linking to each heading below H1. Without
+// the markers, body is returned unchanged unless insert is set, in which
+// case they are added after the first paragraph, or at the top when
+// there is none. The boolean reports whether body has a table of
+// contents; none is inserted in a body without headings to list.
+func TOC(body []byte, insert bool) ([]byte, bool) {
+	var list strings.Builder
+	var items []Heading
+	for _, h := range Headings(body) {
+		if h.Level > 1 {
+			items = append(items, h)
+		}
+	}
+	top := 6
+	for _, h := range items {
+		if h.Level < top {
+			top = h.Level
+		}
+	}
+	for _, h := range items {
+		text := strings.TrimSpace(inlineMarkupPattern.ReplaceAllString(h.Text, "$1"))
+		fmt.Fprintf(&list, "%s- [%s](#%s)\n", strings.Repeat("  ", h.Level-top), escapeLinkText(text), h.ID)
+	}
+
+	masked := blank(body, codeBlockPattern)
+	if start := tocStartPattern.FindIndex(masked); start != nil {
+		if end := tocEndPattern.FindIndex(masked[start[1]:]); end != nil {
+			var b bytes.Buffer
+			b.Write(body[:start[1]])
+			b.WriteString("\n" + list.String())
+			b.Write(body[start[1]+end[0]:])
+			return b.Bytes(), true
+		}
+	}
+	if !insert || len(items) == 0 {
+		return body, false
+	}
+	// The markers go after the first paragraph, the post's introduction.
+	at, from := 0, 0
+	breaks := append(paragraphBreakPattern.FindAllIndex(masked, -1), []int{len(masked), len(masked)})
+	for _, br := range breaks {
+		block := masked[from:br[0]]
+		if trimmed := bytes.TrimSpace(block); len(trimmed) > 0 && !nonProsePattern.Match(trimmed) {
+			at = from + len(bytes.TrimRight(block, " \t\n"))
+			break
+		}
+		from = br[1]
+	}
+	block := TOCStart + "\n" + list.String() + TOCEnd + "\n"
+	var b bytes.Buffer
+	if at == 0 {
+		rest := bytes.TrimLeft(body, "\n")
+		b.Write(body[:len(body)-len(rest)])
+		b.WriteString(block + "\n")
+		b.Write(rest)
+		return b.Bytes(), true
+	}
+	b.Write(body[:at])
+	b.WriteString("\n\n" + block)
+	rest := bytes.TrimLeft(body[at:], "\n")
+	if len(rest) > 0 {
+		b.WriteString("\n")
+	}
+	b.Write(rest)
+	return b.Bytes(), true
+}
+
+// PlanTOC computes refreshing, or with insert adding, the table of
+// contents of the post at p with TOC. The boolean reports whether the
+// post has one; NewContent equals OldContent when it is up to date.
+func (g *Generator) PlanTOC(p string, insert bool) (Change, bool, error) {
+	doc, content, err := g.readDocument(p)
+	if err != nil {
+		return Change{}, false, err
+	}
+	body, ok := TOC(doc.Body, insert)
+	if !ok {
+		return Change{}, false, nil
+	}
+	doc.Body = body
+	return Change{Path: p, OldContent: content, NewContent: doc.Bytes()}, true, nil
+}
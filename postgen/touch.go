@@ -0,0 +1,25 @@
+package postgen
+
+import (
+	"fmt"
+	"time"
+)
+
+// PlanTouch computes setting the last_modified_at key of the post at p to
+// modified, when that is more than threshold after the post's date and
+// the key does not already hold it. No change is returned otherwise.
+func (g *Generator) PlanTouch(p string, modified time.Time, threshold time.Duration) ([]Change, error) {
+	e, err := g.Read(p)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", p, err)
+	}
+	if modified.Sub(e.Date) <= threshold || e.Meta.LastModifiedAt.Equal(modified) {
+		return nil, nil
+	}
+	doc, content, err := g.readDocument(p)
+	if err != nil {
+		return nil, err
+	}
+	doc.SetRaw("last_modified_at", modified.In(g.Now().Location()).Format(DateLayout))
+	return []Change{{Path: p, OldContent: content, NewContent: doc.Bytes()}}, nil
+}
@@ -0,0 +1,110 @@
+package postgen
+
+import (
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen/frontmatter"
+)
+
+// translationDroppedKeys identify the original post and are not carried
+// over to its translations.
+var translationDroppedKeys = []string{"permalink", "redirect_from", "last_modified_at"}
+
+// TranslationOptions configures PlanTranslation.
+type TranslationOptions struct {
+	// Lang is the language of the translation, Title and Slug its title
+	// and slug, generated from the title when empty.
+	Lang  string
+	Title string
+	Slug  string
+	// OriginalLang is set as the lang of the original when it has none.
+	OriginalLang string
+}
+
+// Translation is a translated sibling of a post: the post to create and
+// the change linking the original to it.
+type Translation struct {
+	Post     Result
+	Original Change
+}
+
+// PlanTranslation computes a translation of the post or draft at p: a
+// post of the same date and folder keeping its front matter, with the
+// new title, lang and a translation_of key naming the original's slug,
+// and its body, whose images stay in the original's folder. The original
+// gets the reciprocal translation_of key.
+func (g *Generator) PlanTranslation(p string, opts TranslationOptions) (Translation, error) {
+	slug := opts.Slug
+	if slug == "" {
+		slug = opts.Title
+	}
+	if slug = Slugify(slug); slug == "" {
+		return Translation{}, SlugError(opts.Title)
+	}
+	e, err := g.Read(p)
+	if err != nil {
+		return Translation{}, fmt.Errorf("parsing %s: %w", p, err)
+	}
+	switch {
+	case slug == e.Slug:
+		return Translation{}, fmt.Errorf("the translation needs a slug other than %s, the one of the original", slug)
+	case e.Meta.TranslationOf != "":
+		return Translation{}, conflictf("%s is already paired with %s by translation_of", p, e.Meta.TranslationOf)
+	case e.Meta.Lang == opts.Lang:
+		return Translation{}, fmt.Errorf("%s is already in %s", p, opts.Lang)
+	}
+	original, content, err := g.readDocument(p)
+	if err != nil {
+		return Translation{}, err
+	}
+	doc, err := frontmatter.Parse(content)
+	if err != nil {
+		return Translation{}, fmt.Errorf("parsing %s: %w", p, err)
+	}
+	doc.SetRaw("title", frontmatter.String(opts.Title))
+	doc.SetRaw("lang", frontmatter.Scalar(opts.Lang))
+	doc.SetRaw("translation_of", frontmatter.Scalar(e.Slug))
+	for _, key := range translationDroppedKeys {
+		doc.Delete(key)
+	}
+	name := slug
+	if date, _, ok := ParseFileName(path.Base(p)); ok {
+		name = date.Format(FileDateLayout) + "-" + slug
+	}
+	if e.Meta.Lang == "" && opts.OriginalLang != "" && opts.OriginalLang != opts.Lang {
+		original.SetRaw("lang", frontmatter.Scalar(opts.OriginalLang))
+	}
+	original.SetRaw("translation_of", frontmatter.Scalar(slug))
+	return Translation{
+		Post: Result{
+			MarkdownPath: path.Join(path.Dir(p), name+path.Ext(p)),
+			ImagesPath:   g.ImagesFolder(p),
+			Slug:         slug,
+			Date:         e.Date,
+			Content:      lf(doc.Bytes()),
+		},
+		Original: Change{Path: p, OldContent: content, NewContent: original.Bytes()},
+	}, nil
+}
+
+// Translate creates the translation planned by PlanTranslation and links
+// the original to it, removing the translation again when that fails.
+func (g *Generator) Translate(p string, opts TranslationOptions) (Translation, error) {
+	t, err := g.PlanTranslation(p, opts)
+	if err != nil {
+		return Translation{}, err
+	}
+	if err := g.Collision(t.Post); err != nil {
+		return Translation{}, err
+	}
+	if err := g.writeFile(t.Post.MarkdownPath, t.Post.Content, os.O_WRONLY|os.O_CREATE|os.O_EXCL); err != nil {
+		return Translation{}, err
+	}
+	if err := g.WriteChanges([]Change{t.Original}); err != nil {
+		g.FS.Remove(t.Post.MarkdownPath)
+		return Translation{}, err
+	}
+	return t, nil
+}
@@ -0,0 +1,66 @@
+package postgen
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+)
+
+// OriginalDateKey holds the date of an unpublished post, which Publish
+// restores instead of stamping the current one.
+const OriginalDateKey = "original_date"
+
+// OriginalPublishedKey holds the published value an unpublished post had,
+// which Publish restores instead of dropping the key.
+const OriginalPublishedKey = "original_published"
+
+// Unpublish is the inverse of Publish: it moves the post at p from
+// PostsDir into DraftsDir under its undated slug, sets published: false
+// and renames its images folder to match. With keepDate, its front matter
+// date is also recorded under OriginalDateKey, so that publishing the
+// draft again restores the post as it was. A published value other than
+// false is recorded under OriginalPublishedKey for the same reason.
+func (g *Generator) Unpublish(p string, keepDate bool) (Result, error) {
+	if g.IsDraft(p) {
+		return Result{}, fmt.Errorf("%s is already a draft", p)
+	}
+	date, slug, ok := ParseFileName(path.Base(p))
+	if !ok {
+		return Result{}, fmt.Errorf("%s is not named after its date", p)
+	}
+	name := TrimExt(path.Base(p))
+	draftPath := path.Join(g.DraftsDir, slug+path.Ext(p))
+	postImagesPath := path.Join(g.ImagesDir, name)
+	draftImagesPath := path.Join(g.ImagesDir, slug)
+
+	doc, _, err := g.readDocument(p)
+	if err != nil {
+		return Result{}, err
+	}
+	if keepDate {
+		raw, ok := doc.Raw("date")
+		if !ok {
+			raw = date.Format(FileDateLayout)
+		}
+		doc.SetRaw(OriginalDateKey, raw)
+	}
+	if raw, ok := doc.Raw("published"); ok && raw != "false" {
+		doc.SetRaw(OriginalPublishedKey, raw)
+	}
+	doc.SetRaw("published", "false")
+	g.rewriteImagesFolder(doc, name, slug)
+	content := doc.Bytes()
+
+	r := Result{MarkdownPath: draftPath, Slug: slug, Date: date, Content: content}
+	oldImages := ""
+	if _, err := g.FS.Stat(postImagesPath); err == nil {
+		oldImages, r.ImagesPath = postImagesPath, draftImagesPath
+	}
+	if err := g.FS.MkdirAll(g.DraftsDir, fs.ModePerm); err != nil {
+		return Result{}, fmt.Errorf("creating folder %s: %w", g.DraftsDir, err)
+	}
+	if err := g.move(p, draftPath, content, oldImages, r.ImagesPath); err != nil {
+		return Result{}, err
+	}
+	return r, nil
+}
@@ -0,0 +1,65 @@
+package postgen
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+)
+
+// UpdatesTitle is the title of the section at the end of a post that
+// PlanUpdateNote adds its entries to.
+const UpdatesTitle = "Updates"
+
+// PlanUpdateNote computes adding note, dated today, as the first entry
+// of the "## Updates" section of the post or draft at p, the section
+// being appended to the body when it has none, and setting its
+// last_modified_at to now. The rest of the body is left as is.
+func (g *Generator) PlanUpdateNote(p, note string) (Change, error) {
+	note = strings.TrimSpace(note)
+	switch {
+	case note == "":
+		return Change{}, errors.New("the update note is empty")
+	case strings.ContainsAny(note, "\r\n"):
+		return Change{}, errors.New("the update note must fit on one line")
+	}
+	doc, content, err := g.readDocument(p)
+	if err != nil {
+		return Change{}, err
+	}
+	now := g.Now()
+	entry := "- " + now.Format(FileDateLayout) + ": " + note + "\n"
+	body := doc.Body
+	masked := blank(body, codeBlockPattern)
+	at := -1
+	for _, m := range headingPattern.FindAllSubmatchIndex(masked, -1) {
+		text := headingIDPattern.ReplaceAll(body[m[4]:m[5]], nil)
+		if m[3]-m[2] == 2 && strings.EqualFold(string(bytes.TrimSpace(text)), UpdatesTitle) {
+			at = m[1]
+		}
+	}
+	var b bytes.Buffer
+	if at < 0 {
+		b.Write(bytes.TrimRight(body, "\n"))
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		b.WriteString("## " + UpdatesTitle + "\n\n" + entry)
+	} else {
+		// The entry goes before the previous one, keeping them newest
+		// first, the blank lines after the heading kept.
+		rest := bytes.TrimLeft(body[at:], "\n")
+		sep := string(body[at : len(body)-len(rest)])
+		if len(sep) < 2 {
+			sep = "\n\n"
+		}
+		b.Write(body[:at])
+		b.WriteString(sep + entry)
+		if len(rest) > 0 && !bytes.HasPrefix(rest, []byte("- ")) {
+			b.WriteString("\n")
+		}
+		b.Write(rest)
+	}
+	doc.Body = b.Bytes()
+	doc.SetRaw("last_modified_at", now.Format(DateLayout))
+	return Change{Path: p, OldContent: content, NewContent: doc.Bytes()}, nil
+}
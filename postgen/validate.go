@@ -0,0 +1,386 @@
+package postgen
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen/frontmatter"
+	"github.com/tiagomelo/tiagomelo.github.io/postgen/posts"
+	"gopkg.in/yaml.v3"
+)
+
+// Problem is something wrong with a post found by Validate.
+type Problem struct {
+	Path string
+	// Line is the line of the file the problem was found on, or 0 when
+	// it concerns the file as a whole.
+	Line    int
+	Field   string
+	Message string
+}
+
+func (p Problem) String() string {
+	location := p.Path
+	if p.Line > 0 {
+		location = fmt.Sprintf("%s:%d", p.Path, p.Line)
+	}
+	return fmt.Sprintf("%s: %s: %s", location, p.Field, p.Message)
+}
+
+// ValidateOptions tunes Validate.
+type ValidateOptions struct {
+	// Layouts lists the layouts available to posts; the layout check is
+	// skipped when it is nil.
+	Layouts map[string]bool
+	// Authors and Categories list the values the site's data files
+	// allow for the author and categories keys; each check is skipped
+	// when its map is nil.
+	Authors    map[string]bool
+	Categories map[string]bool
+	// Languages lists the values allowed for the lang key; the check is
+	// skipped when it is nil.
+	Languages map[string]bool
+	// Permalink is the site's permalink setting, which tells which posts
+	// a permalink key shadows. With SiteURL, the absolute URL the site is
+	// served at, it also tells whether a canonical_url points back at the
+	// post itself; that check is skipped without SiteURL.
+	SiteURL   string
+	Permalink string
+}
+
+// requiredKeys must be present in every post's front matter.
+var requiredKeys = []string{"layout", "title", "date"}
+
+// Validate checks the front matter of every post and draft, against the
+// Schema too when there is one, returning the problems found sorted by
+// file and line. Interrupted through ctx, it returns the problems of the
+// files checked so far, leaving out the checks spanning several posts.
+func (g *Generator) Validate(ctx context.Context, opts ValidateOptions) ([]Problem, error) {
+	loaded, err := g.loadFiles()
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, len(loaded))
+	for i, post := range loaded {
+		files[i] = post.Path
+	}
+	found := make([][]Problem, len(files))
+	n, err := g.run(ctx, files, func(i int) { found[i] = g.validatePost(loaded[i], opts) })
+	var problems []Problem
+	for _, f := range found[:n] {
+		problems = append(problems, f...)
+	}
+	if err != nil {
+		SortProblems(problems)
+		return problems, err
+	}
+	site, err := g.ValidateSite(opts)
+	if err != nil {
+		return nil, err
+	}
+	problems = append(problems, site...)
+	SortProblems(problems)
+	return problems, nil
+}
+
+// ValidateSite runs the checks of Validate spanning several posts: the
+// permalinks shadowing one another, the slugs several posts share and the
+// translations not linked both ways.
+func (g *Generator) ValidateSite(opts ValidateOptions) ([]Problem, error) {
+	shadowed, err := g.permalinkProblems(opts.Permalink)
+	if err != nil {
+		return nil, err
+	}
+	duplicates, err := g.slugProblems()
+	if err != nil {
+		return nil, err
+	}
+	translations, err := g.translationProblems()
+	if err != nil {
+		return nil, err
+	}
+	return append(append(shadowed, duplicates...), translations...), nil
+}
+
+// SortProblems sorts problems by file and line.
+func SortProblems(problems []Problem) {
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Path != problems[j].Path {
+			return problems[i].Path < problems[j].Path
+		}
+		return problems[i].Line < problems[j].Line
+	})
+}
+
+// fields maps front matter keys to their YAML nodes.
+type fields map[string]yaml.Node
+
+// line returns the line of the file key is on, or 0 when it is absent.
+func (f fields) line(key string) int {
+	if n, ok := f[key]; ok {
+		// Node lines count from the first front matter line, which
+		// follows the opening delimiter.
+		return n.Line + 1
+	}
+	return 0
+}
+
+// ValidateFile runs the checks of Validate concerning the post or draft
+// at p alone, reading it again only when it changed since it was last
+// read.
+func (g *Generator) ValidateFile(p string, opts ValidateOptions) []Problem {
+	return g.validatePost(g.corpus().Read(p), opts)
+}
+
+// validatePost runs the checks of ValidateFile on post.
+func (g *Generator) validatePost(post *posts.Post, opts ValidateOptions) []Problem {
+	p := post.Path
+	if post.Content == nil {
+		return []Problem{{Path: p, Field: "file", Message: post.Err.Error()}}
+	}
+	if post.Err != nil {
+		return []Problem{{Path: p, Line: 1, Field: "front matter", Message: post.Err.Error()}}
+	}
+	f := fields{}
+	if err := post.Doc.Decode(&f); err != nil {
+		return []Problem{{Path: p, Line: 1, Field: "front matter", Message: err.Error()}}
+	}
+	var problems []Problem
+	problem := func(key, format string, args ...interface{}) {
+		pr := Problem{Path: p, Line: f.line(key), Field: key, Message: fmt.Sprintf(format, args...)}
+		for _, other := range problems {
+			if other == pr {
+				return
+			}
+		}
+		problems = append(problems, pr)
+	}
+	for _, key := range requiredKeys {
+		if n, ok := f[key]; !ok || n.Kind == yaml.ScalarNode && n.Tag == "!!null" {
+			problem(key, "missing required key")
+		}
+	}
+	if n, ok := f["title"]; ok && n.Kind != yaml.ScalarNode {
+		problem("title", "must be a string")
+	}
+	if n, ok := f["layout"]; ok && opts.Layouts != nil && n.Kind == yaml.ScalarNode && n.Value != "" && !opts.Layouts[n.Value] {
+		problem("layout", "unknown layout \"%s\", expected one of %s", n.Value, strings.Join(sortedKeys(opts.Layouts), ", "))
+	}
+	if n, ok := f["date"]; ok && n.Tag != "!!null" {
+		var date frontmatter.Time
+		if err := n.Decode(&date); err != nil {
+			problem("date", "%s", strings.TrimPrefix(err.Error(), fmt.Sprintf("line %d: ", n.Line)))
+		} else if fileDate, _, ok := ParseFileName(path.Base(p)); ok && !g.IsDraft(p) {
+			if d := date.Format(FileDateLayout); d != fileDate.Format(FileDateLayout) {
+				problem("date", "date %s does not match the file name date %s", d, fileDate.Format(FileDateLayout))
+			}
+		}
+	}
+	if n, ok := f["lang"]; ok && opts.Languages != nil && n.Kind == yaml.ScalarNode && n.Value != "" && !opts.Languages[n.Value] {
+		problem("lang", "unknown language \"%s\", expected one of %s", n.Value, strings.Join(sortedKeys(opts.Languages), ", "))
+	}
+	if n, ok := f["image"]; ok && n.Tag != "!!null" {
+		var image struct {
+			Path string `yaml:"path"`
+		}
+		value := n.Value
+		if n.Kind == yaml.MappingNode && n.Decode(&image) == nil {
+			value = image.Path
+		}
+		if file, ok := g.sitePath(value); ok {
+			if _, err := g.FS.Stat(file); err != nil {
+				problem("image", "%s does not exist", file)
+			}
+		}
+	}
+	if g.Schema != nil {
+		g.Schema.check(f, problem)
+	}
+	if _, _, ok := ParseFileName(path.Base(p)); !g.IsDraft(p) && !ok {
+		problems = append(problems, Problem{Path: p, Field: "file", Message: "file name does not start with a YYYY-MM-DD date"})
+	}
+	for _, key := range []string{"categories", "tags"} {
+		n, ok := f[key]
+		if !ok || n.Tag == "!!null" {
+			continue
+		}
+		var values []string
+		switch n.Kind {
+		case yaml.ScalarNode:
+			values = strings.Fields(n.Value)
+		case yaml.SequenceNode:
+			for _, item := range n.Content {
+				if item.Kind != yaml.ScalarNode || item.Value == "" {
+					problem(key, "entries must be non-empty strings")
+					break
+				}
+				values = append(values, item.Value)
+			}
+		default:
+			problem(key, "must be a list or a space-separated string")
+		}
+		if key == "categories" && opts.Categories != nil {
+			for _, v := range values {
+				if !opts.Categories[v] {
+					problem(key, "unknown category \"%s\", expected one of %s", v, strings.Join(sortedKeys(opts.Categories), ", "))
+				}
+			}
+		}
+	}
+	if n, ok := f["author"]; ok && opts.Authors != nil && n.Kind == yaml.ScalarNode && n.Value != "" && !opts.Authors[n.Value] {
+		problem("author", "unknown author \"%s\", expected one of %s", n.Value, strings.Join(sortedKeys(opts.Authors), ", "))
+	}
+	if n, ok := f["permalink"]; ok && n.Tag != "!!null" {
+		if n.Kind != yaml.ScalarNode {
+			problem("permalink", "must be a string")
+		} else if _, err := ParsePermalink(n.Value); err != nil {
+			problem("permalink", "%s", err)
+		}
+	}
+	if n, ok := f["canonical_url"]; ok && n.Tag != "!!null" {
+		if n.Kind != yaml.ScalarNode {
+			problem("canonical_url", "must be a string")
+		} else if canonical, err := ParseCanonicalURL(n.Value); err != nil {
+			problem("canonical_url", "%s", err)
+		} else if site, err := url.Parse(strings.TrimSuffix(opts.SiteURL, "/")); err == nil && site.IsAbs() {
+			var meta Meta
+			if err := post.Doc.Decode(&meta); err == nil {
+				if own, err := url.Parse(site.String() + Permalink(opts.Permalink, newEntry(p, meta))); err == nil && samePage(canonical, own) {
+					problem("canonical_url", "points at the post's own permalink, remove it unless the post first appeared elsewhere")
+				}
+			}
+		}
+	}
+	return problems
+}
+
+// permalinkProblems reports the posts declaring the permalink of another
+// post, be it declared too or the one the site's permalink setting gives
+// it. Posts that are not published are left out, as they are not served.
+func (g *Generator) permalinkProblems(setting string) ([]Problem, error) {
+	entries, _, err := g.List()
+	if err != nil {
+		return nil, err
+	}
+	byURL := make(map[string][]Entry)
+	var urls []string
+	for _, e := range entries {
+		if e.Meta.Published != nil && !*e.Meta.Published {
+			continue
+		}
+		u := strings.TrimSuffix(Permalink(setting, e), "/")
+		if byURL[u] == nil {
+			urls = append(urls, u)
+		}
+		byURL[u] = append(byURL[u], e)
+	}
+	var problems []Problem
+	for _, u := range urls {
+		for _, e := range byURL[u] {
+			if e.Meta.Permalink == "" {
+				continue
+			}
+			for _, other := range byURL[u] {
+				if other.Path == e.Path {
+					continue
+				}
+				message := fmt.Sprintf("permalink %s shadows the permalink of %s", e.Meta.Permalink, other.Path)
+				if other.Meta.Permalink != "" {
+					message = fmt.Sprintf("permalink %s is also declared by %s", e.Meta.Permalink, other.Path)
+				}
+				problems = append(problems, Problem{Path: e.Path, Line: g.keyLine(e.Path, "permalink"), Field: "permalink", Message: message})
+			}
+		}
+	}
+	return problems, nil
+}
+
+// slugProblems reports the posts sharing their slug with a post of
+// another day, which Jekyll serves fine but makes for ambiguous links.
+func (g *Generator) slugProblems() ([]Problem, error) {
+	files, err := g.Files()
+	if err != nil {
+		return nil, err
+	}
+	bySlug := make(map[string][]string)
+	for _, p := range files {
+		if _, slug, ok := ParseFileName(path.Base(p)); ok && !g.IsDraft(p) {
+			bySlug[slug] = append(bySlug[slug], p)
+		}
+	}
+	var problems []Problem
+	for slug, paths := range bySlug {
+		for _, p := range paths {
+			for _, other := range paths {
+				if other != p {
+					problems = append(problems, Problem{Path: p, Field: "file", Message: fmt.Sprintf("slug %s is also used by %s", slug, other)})
+				}
+			}
+		}
+	}
+	return problems, nil
+}
+
+// translationProblems reports the translation_of keys naming no post, or
+// a post not naming the one pointing at it back or in the same lang.
+func (g *Generator) translationProblems() ([]Problem, error) {
+	entries, _, err := g.List()
+	if err != nil {
+		return nil, err
+	}
+	bySlug := make(map[string][]Entry)
+	for _, e := range entries {
+		bySlug[e.Slug] = append(bySlug[e.Slug], e)
+	}
+	var problems []Problem
+	for _, e := range entries {
+		target := e.Meta.TranslationOf
+		if target == "" {
+			continue
+		}
+		problem := func(format string, args ...interface{}) {
+			problems = append(problems, Problem{Path: e.Path, Line: g.keyLine(e.Path, "translation_of"), Field: "translation_of", Message: fmt.Sprintf(format, args...)})
+		}
+		others := bySlug[target]
+		switch {
+		case target == e.Slug:
+			problem("names the post itself")
+		case len(others) == 0:
+			problem("no post has the slug %s", target)
+		case len(others) > 1:
+			problem("several posts have the slug %s", target)
+		case others[0].Meta.TranslationOf != e.Slug:
+			problem("%s does not name %s back in its translation_of", others[0].Path, e.Slug)
+		case e.Meta.Lang != "" && e.Meta.Lang == others[0].Meta.Lang:
+			problem("%s has the same lang %s", others[0].Path, e.Meta.Lang)
+		}
+	}
+	return problems, nil
+}
+
+// keyLine returns the line of the file p the front matter key is on, or
+// 0 when it cannot tell.
+func (g *Generator) keyLine(p, key string) int {
+	post := g.corpus().Read(p)
+	if post.Err != nil {
+		return 0
+	}
+	f := fields{}
+	if err := post.Doc.Decode(&f); err != nil {
+		return 0
+	}
+	return f.line(key)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
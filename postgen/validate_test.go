@@ -0,0 +1,142 @@
+package postgen
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+const validPost = "---\nlayout: post\ntitle:  \"Hello\"\ndate:   2024-05-01 10:30:00 -0300\ncategories: go\n---\nBody.\n"
+
+func TestValidateFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		want    []Problem
+	}{
+		{"valid", "docs/_posts/2024-05-01-hello.markdown", validPost, nil},
+		{
+			"missing title", "docs/_posts/2024-05-01-hello.markdown",
+			"---\nlayout: post\ndate:   2024-05-01 10:30:00 -0300\n---\n",
+			[]Problem{{Path: "docs/_posts/2024-05-01-hello.markdown", Field: "title", Message: "missing required key"}},
+		},
+		{
+			"unparsable", "docs/_posts/2024-05-01-hello.markdown",
+			"---\nlayout: post\ncategories: [go\n---\n",
+			[]Problem{{Path: "docs/_posts/2024-05-01-hello.markdown", Line: 1, Field: "front matter", Message: "yaml: line 1: did not find expected ',' or ']'"}},
+		},
+		{
+			"date off the file name", "docs/_posts/2024-05-02-hello.markdown", validPost,
+			[]Problem{{Path: "docs/_posts/2024-05-02-hello.markdown", Line: 4, Field: "date", Message: "date 2024-05-01 does not match the file name date 2024-05-02"}},
+		},
+		{
+			"unknown layout", "docs/_posts/2024-05-01-hello.markdown",
+			"---\nlayout: page\ntitle:  \"Hello\"\ndate:   2024-05-01 10:30:00 -0300\n---\n",
+			[]Problem{{Path: "docs/_posts/2024-05-01-hello.markdown", Line: 2, Field: "layout", Message: "unknown layout \"page\", expected one of post"}},
+		},
+		{
+			"undated post", "docs/_posts/hello.markdown", validPost,
+			[]Problem{{Path: "docs/_posts/hello.markdown", Field: "file", Message: "file name does not start with a YYYY-MM-DD date"}},
+		},
+		{"undated draft", "docs/_drafts/hello.markdown", validPost, nil},
+		{"crlf with a bom", "docs/_posts/2024-05-01-hello.markdown", "\xef\xbb\xbf" + strings.ReplaceAll(validPost, "\n", "\r\n"), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := testGenerator(newMemFS(map[string]string{tt.path: tt.content}))
+			got := g.ValidateFile(tt.path, ValidateOptions{Layouts: map[string]bool{"post": true}})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("problems = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateSite(t *testing.T) {
+	g := testGenerator(newMemFS(map[string]string{
+		"docs/_posts/2024-05-01-hello.markdown": validPost,
+		"docs/_posts/2024-06-01-hello.markdown": validPost,
+		"docs/_drafts/hello.markdown":           validPost,
+	}))
+	got, err := g.ValidateSite(ValidateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	SortProblems(got)
+	want := []Problem{
+		{Path: "docs/_posts/2024-05-01-hello.markdown", Field: "file", Message: "slug hello is also used by docs/_posts/2024-06-01-hello.markdown"},
+		{Path: "docs/_posts/2024-06-01-hello.markdown", Field: "file", Message: "slug hello is also used by docs/_posts/2024-05-01-hello.markdown"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("problems = %v, want %v", got, want)
+	}
+}
+
+func TestValidateCombinesFileAndSiteChecks(t *testing.T) {
+	m := newMemFS(map[string]string{
+		"docs/_posts/2024-05-01-hello.markdown": validPost,
+		"docs/_posts/2024-06-01-hello.markdown": validPost,
+	})
+	g := testGenerator(m)
+	got, err := g.Validate(context.Background(), ValidateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want []Problem
+	files, err := g.Files()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range files {
+		want = append(want, g.ValidateFile(p, ValidateOptions{})...)
+	}
+	site, err := g.ValidateSite(ValidateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = append(want, site...)
+	SortProblems(want)
+	if len(want) != 3 || !reflect.DeepEqual(got, want) {
+		t.Errorf("problems = %v, want %v", got, want)
+	}
+}
+
+func TestValidateInterrupted(t *testing.T) {
+	g := testGenerator(newMemFS(map[string]string{
+		"docs/_posts/2024-05-01-hello.markdown": validPost,
+		"docs/_posts/2024-06-01-hello.markdown": validPost,
+	}))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	got, err := g.Validate(ctx, ValidateOptions{})
+	if !errors.Is(err, ErrInterrupted) {
+		t.Fatalf("err = %v, want ErrInterrupted", err)
+	}
+	for _, p := range got {
+		if p.Field == "file" {
+			t.Errorf("site check run after the interruption: %v", p)
+		}
+	}
+}
+
+func TestValidateFileRereadsChangedFiles(t *testing.T) {
+	m := newMemFS(map[string]string{testPost: validPost, "docs/_drafts/draft.markdown": validPost})
+	g := testGenerator(m)
+	if _, err := g.Validate(context.Background(), ValidateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	loaded := g.corpus().Read(testPost)
+	if g.ValidateFile(testPost, ValidateOptions{}); g.corpus().Read(testPost) != loaded {
+		t.Errorf("unchanged %s read again", testPost)
+	}
+	m.files[testPost] = &fstest.MapFile{Data: []byte("---\nlayout: post\ndate:   2024-05-01 10:30:00 -0300\n---\n"), Mode: 0644, ModTime: time.Now()}
+	want := []Problem{{Path: testPost, Field: "title", Message: "missing required key"}}
+	if got := g.ValidateFile(testPost, ValidateOptions{}); !reflect.DeepEqual(got, want) {
+		t.Errorf("problems after the change = %v, want %v", got, want)
+	}
+}
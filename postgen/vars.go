@@ -0,0 +1,69 @@
+package postgen
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tiagomelo/tiagomelo.github.io/postgen/frontmatter"
+)
+
+// varKeyPattern matches the keys ParseVars accepts, which templates can
+// write as .Vars.key.
+var varKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ParseVars parses key=value pairs into the Vars of a post. Values are
+// strings, except true, false and integers written the way Go formats
+// them, which are booleans and ints. Keys must be identifiers other than
+// the HeaderKeys, which have flags of their own, and given once.
+func ParseVars(pairs []string) (map[string]interface{}, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	vars := make(map[string]interface{}, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		switch {
+		case !ok:
+			return nil, fmt.Errorf("invalid variable \"%s\": expected key=value", pair)
+		case !varKeyPattern.MatchString(key):
+			return nil, fmt.Errorf("invalid variable key \"%s\": expected letters, digits and underscores, not starting with a digit", key)
+		case contains(HeaderKeys, key):
+			return nil, fmt.Errorf("variable %s collides with the built-in %s key, set it with its own flag", key, key)
+		}
+		if _, ok := vars[key]; ok {
+			return nil, fmt.Errorf("variable %s is given twice", key)
+		}
+		if n, err := strconv.Atoi(value); err == nil && strconv.Itoa(n) == value {
+			vars[key] = n
+		} else if value == "true" || value == "false" {
+			vars[key] = value == "true"
+		} else {
+			vars[key] = value
+		}
+	}
+	return vars, nil
+}
+
+// setVars sets the vars in the front matter of content, in key order.
+func setVars(content []byte, vars map[string]interface{}) ([]byte, error) {
+	doc, err := frontmatter.Parse(content)
+	if err != nil {
+		return nil, Mark(ErrTemplate, fmt.Errorf("parsing the executed template: %w", err))
+	}
+	keys := make([]string, 0, len(vars))
+	for key := range vars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		raw := fmt.Sprint(vars[key])
+		if s, ok := vars[key].(string); ok {
+			raw = frontmatter.Scalar(s)
+		}
+		doc.SetRaw(key, raw)
+	}
+	return doc.Bytes(), nil
+}